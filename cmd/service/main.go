@@ -1,7 +1,11 @@
 package main
 
 import (
+	"os"
+
 	config "repo-scanner/internal/app"
+	"repo-scanner/internal/constants"
+	"repo-scanner/internal/repository/scanner"
 
 	log "github.com/sirupsen/logrus"
 )
@@ -12,9 +16,17 @@ func main() {
 		FullTimestamp: true,
 	})
 
+	// grab sessions of scannings are run by child processes of this executable
+	if len(os.Args) > 2 && os.Args[1] == constants.GrabSessionCommand {
+		config.Catch(scanner.RunGrabSession(os.Stdin, os.Args[2]))
+		return
+	}
+
 	app := config.NewApp()
 
 	config.Catch(app.InitEnv())
+	// APP_RUN_MODE tells whether API, workers or both are run
+	config.Catch(app.InitMode(""))
 	config.Catch(app.InitPosgres())
 	config.Catch(app.InitQuery())
 	config.Catch(app.InitServer())
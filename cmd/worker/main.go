@@ -0,0 +1,37 @@
+package main
+
+import (
+	"os"
+
+	config "repo-scanner/internal/app"
+	"repo-scanner/internal/constants"
+	"repo-scanner/internal/repository/scanner"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func main() {
+	// global log level
+	log.SetFormatter(&log.TextFormatter{
+		FullTimestamp: true,
+	})
+
+	// grab sessions of scannings are run by child processes of this executable
+	if len(os.Args) > 2 && os.Args[1] == constants.GrabSessionCommand {
+		config.Catch(scanner.RunGrabSession(os.Stdin, os.Args[2]))
+		return
+	}
+
+	app := config.NewApp()
+
+	config.Catch(app.InitEnv())
+	// Workers consume scanning queue fed by API services, no API is served
+	config.Catch(app.InitMode(constants.RunModeWorker))
+	config.Catch(app.InitPosgres())
+	config.Catch(app.InitQuery())
+	config.Catch(app.InitService())
+	defer app.Stop()
+
+	log.Info("Starting Repository Scanner workers...")
+	config.Catch(app.Start())
+}
@@ -1,11 +1,13 @@
 package config
 
 import (
+	"context"
 	"os"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 
+	"repo-scanner/internal"
 	"repo-scanner/internal/constants"
 	"repo-scanner/internal/model"
 	"repo-scanner/internal/repository/database"
@@ -19,10 +21,12 @@ import (
 
 type Config struct {
 	Hostname string
+	Mode     string
 	Server   *gin.Engine
 	DB       *database.DB
 	Service  *model.Service
 	Query    sqlq.SQLQuery
+	Usecases internal.UsecaseStore
 }
 
 func NewApp() Config {
@@ -47,6 +51,32 @@ func (c *Config) InitEnv() serror.SError {
 	return nil
 }
 
+// InitMode sets what this service runs by mode, APP_RUN_MODE env if it is empty, both API and workers by default
+func (c *Config) InitMode(mode string) serror.SError {
+	if mode == "" {
+		mode = utstring.Env(constants.AppRunMode, constants.RunModeAll)
+	}
+	switch mode {
+	case constants.RunModeAll, constants.RunModeApi, constants.RunModeWorker:
+		c.Mode = mode
+		return nil
+	}
+	errx := serror.Newf("%s must be one of %s, %s or %s", constants.AppRunMode,
+		constants.RunModeAll, constants.RunModeApi, constants.RunModeWorker)
+	errx.AddCommentf("[config][InitMode] while set run mode %q", mode)
+	return errx
+}
+
+// servesApi tells whether this service serves API
+func (c *Config) servesApi() bool {
+	return c.Mode != constants.RunModeWorker
+}
+
+// runsWorkers tells whether this service consumes scanning queue, reaps abandoned scannings and schedules scannings
+func (c *Config) runsWorkers() bool {
+	return c.Mode != constants.RunModeApi
+}
+
 func Catch(serr serror.SError) {
 	if serr != nil {
 		serr.Panic()
@@ -54,7 +84,17 @@ func Catch(serr serror.SError) {
 }
 
 func (c *Config) Start() (errx serror.SError) {
+	if c.runsWorkers() {
+		c.startWorkers()
+	}
+
 	ch := make(chan bool)
+	if !c.servesApi() {
+		log.Info("Running scan workers only")
+		<-ch
+		return nil
+	}
+
 	go func() {
 		log.Info("Running at PORT: ", c.Service.Port)
 		err := c.Server.Run(":" + utstring.IntToString(c.Service.Port))
@@ -67,6 +107,21 @@ func (c *Config) Start() (errx serror.SError) {
 	return nil
 }
 
+// startWorkers starts background subsystems consuming scanning queue
+func (c *Config) startWorkers() {
+	scanningUsecase := c.Usecases.ScanningUsecase
+
+	// Start scanning immediately which are unfinished
+	// Especially, adapting multiple services running simutanously
+	go scanningUsecase.StartScanningInQueue(context.Background())
+	// Scannings queued by API services are picked up
+	go scanningUsecase.StartScanningPoller(context.Background())
+	// Scannings abandoned by crashed services are queued again
+	go scanningUsecase.StartScanningReaper(context.Background())
+	// Repositories whose scheduled scan is due are queued
+	go scanningUsecase.StartScanningScheduler(context.Background())
+}
+
 func (ox *Config) Stop() {
 	//do nothing
 }
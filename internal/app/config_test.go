@@ -0,0 +1,41 @@
+package config
+
+import (
+	"testing"
+
+	"repo-scanner/internal/constants"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInitMode(t *testing.T) {
+	listTests := []struct {
+		mode        string
+		servesApi   bool
+		runsWorkers bool
+	}{
+		{mode: constants.RunModeAll, servesApi: true, runsWorkers: true},
+		{mode: constants.RunModeApi, servesApi: true, runsWorkers: false},
+		{mode: constants.RunModeWorker, servesApi: false, runsWorkers: true},
+	}
+
+	for _, test := range listTests {
+		var c Config
+		assert.Nil(t, c.InitMode(test.mode), test.mode)
+		assert.Equal(t, test.mode, c.Mode)
+		assert.Equal(t, test.servesApi, c.servesApi(), test.mode)
+		assert.Equal(t, test.runsWorkers, c.runsWorkers(), test.mode)
+	}
+
+	// Both API and workers are run by default
+	t.Setenv(constants.AppRunMode, "")
+	var c Config
+	assert.Nil(t, c.InitMode(""))
+	assert.Equal(t, constants.RunModeAll, c.Mode)
+
+	t.Setenv(constants.AppRunMode, constants.RunModeApi)
+	assert.Nil(t, c.InitMode(""))
+	assert.Equal(t, constants.RunModeApi, c.Mode)
+
+	assert.NotNil(t, c.InitMode("scheduler"))
+}
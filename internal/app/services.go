@@ -1,12 +1,25 @@
 package config
 
 import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
 	"repo-scanner/internal"
+	"repo-scanner/internal/constants"
+	"repo-scanner/internal/model"
 	"repo-scanner/internal/utils/serror"
+	"repo-scanner/internal/utils/utfloat"
+	"repo-scanner/internal/utils/utint"
+	"repo-scanner/internal/utils/utstring"
 
 	"repo-scanner/internal/delivery/rest"
+	"repo-scanner/internal/repository/archive"
 	"repo-scanner/internal/repository/postgres"
 	"repo-scanner/internal/repository/scanner"
+	"repo-scanner/internal/repository/secret"
 	"repo-scanner/internal/usecase"
 )
 
@@ -16,21 +29,149 @@ func (c *Config) InitService() serror.SError {
 
 	repositoryRepo := postgres.NewRepositoryRepository(c.DB, c.Query, trxRepo)
 	scanningRepo := postgres.NewScanningRepository(c.DB, c.Query, trxRepo)
+	ruleRepo := postgres.NewRuleRepository(c.DB, c.Query, trxRepo)
+	archiveRepo := archive.NewArchiveRepository(model.ArchiveOption{
+		Dir:              utstring.Env(constants.UploadDir, filepath.Join(os.TempDir(), constants.DefaultUploadDir)),
+		MaxExtractedSize: utint.StringToInt(utstring.Env(constants.UploadMaxExtractedSize), constants.DefaultUploadMaxExtractedSize),
+		MaxFiles:         int(utint.StringToInt(utstring.Env(constants.UploadMaxFiles), constants.DefaultUploadMaxFiles)),
+	})
+	secretRepo, errx := newSecretRepository()
+	if errx != nil {
+		errx.AddComments("[config][InitService] while create secret repository")
+		return errx
+	}
 	repoStore := internal.RepositoryStore{
 		RepositoryRepo: repositoryRepo,
 		ScanningRepo:   scanningRepo,
+		RuleRepo:       ruleRepo,
+		ArchiveRepo:    archiveRepo,
+		SecretRepo:     secretRepo,
+	}
+
+	// scan engines
+	var enabledEngines []string
+	if engines := utstring.Env(constants.ScanEngines); engines != "" {
+		enabledEngines = utstring.CleanSpit(engines, ",")
+	}
+	grabScanner, errx := scanner.NewScanEngineRegistry(enabledEngines,
+		scanner.NewGrabScanner(repoStore),
+		scanner.NewNativeScanner(repoStore),
+		scanner.NewEntropyScanner(model.EntropyOption{
+			Base64Threshold: utfloat.StringToFloat(utstring.Env(constants.EntropyBase64Threshold), constants.DefaultEntropyBase64Threshold),
+			HexThreshold:    utfloat.StringToFloat(utstring.Env(constants.EntropyHexThreshold), constants.DefaultEntropyHexThreshold),
+			MinLength:       int(utint.StringToInt(utstring.Env(constants.EntropyMinLength), constants.DefaultEntropyMinLength)),
+			SkipExtensions:  utstring.CleanSpit(utstring.Env(constants.EntropySkipExt, constants.DefaultEntropySkipExt), ","),
+		}),
+	)
+	if errx != nil {
+		errx.AddComments("[config][InitService] while register scan engines")
+		return errx
+	}
+
+	scanTimeout, err := time.ParseDuration(utstring.Env(constants.ScanTimeout, constants.DefaultScanTimeout))
+	if err != nil {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[config][InitService] while parse %s", constants.ScanTimeout)
+		return errx
+	}
+
+	scanLease, err := time.ParseDuration(utstring.Env(constants.ScanLease, constants.DefaultScanLease))
+	if err != nil || scanLease <= 0 {
+		errx = serror.Newf("%s must be a positive duration", constants.ScanLease)
+		errx.AddCommentf("[config][InitService] while parse %s", constants.ScanLease)
+		return errx
+	}
+
+	retryBackoff, err := time.ParseDuration(utstring.Env(constants.ScanRetryBackoff, constants.DefaultScanRetryBackoff))
+	if err != nil || retryBackoff < 0 {
+		errx = serror.Newf("%s must be a non-negative duration", constants.ScanRetryBackoff)
+		errx.AddCommentf("[config][InitService] while parse %s", constants.ScanRetryBackoff)
+		return errx
+	}
+
+	retryBackoffMax, err := time.ParseDuration(utstring.Env(constants.ScanRetryBackoffMax, constants.DefaultScanRetryBackoffMax))
+	if err != nil || retryBackoffMax < retryBackoff {
+		errx = serror.Newf("%s must be a duration not less than %s", constants.ScanRetryBackoffMax, constants.ScanRetryBackoff)
+		errx.AddCommentf("[config][InitService] while parse %s", constants.ScanRetryBackoffMax)
+		return errx
+	}
+
+	priorityAging, err := time.ParseDuration(utstring.Env(constants.ScanPriorityAging, constants.DefaultScanPriorityAging))
+	if err != nil || priorityAging < 0 {
+		errx = serror.Newf("%s must be a non-negative duration", constants.ScanPriorityAging)
+		errx.AddCommentf("[config][InitService] while parse %s", constants.ScanPriorityAging)
+		return errx
+	}
+
+	pollInterval, err := time.ParseDuration(utstring.Env(constants.ScanPollInterval, constants.DefaultScanPollInterval))
+	if err != nil || pollInterval <= 0 {
+		errx = serror.Newf("%s must be a positive duration", constants.ScanPollInterval)
+		errx.AddCommentf("[config][InitService] while parse %s", constants.ScanPollInterval)
+		return errx
 	}
 
-	grabScanner := scanner.NewGrabScanner(repoStore)
+	// API only service queues scannings for workers of other services, it runs none itself
+	scanWorkers := int(utint.StringToInt(utstring.Env(constants.ScanWorkers), constants.DefaultScanWorkers))
+	if !c.runsWorkers() {
+		scanWorkers = 0
+	} else if scanWorkers < 1 {
+		scanWorkers = 1
+	}
 
 	repositoryUsecase := usecase.NewRepositoryUsecase(repoStore, trxRepo)
-	scanningUsecase := usecase.NewScanningUsecase(repoStore, trxRepo, grabScanner)
+	scanningUsecase := usecase.NewScanningUsecase(repoStore, trxRepo, grabScanner, model.ScanningOption{
+		Timeout:         scanTimeout,
+		Workers:         scanWorkers,
+		Instance:        scanInstance(),
+		Lease:           scanLease,
+		MaxAttempts:     int(utint.StringToInt(utstring.Env(constants.ScanMaxAttempts), constants.DefaultScanMaxAttempts)),
+		RetryBackoff:    retryBackoff,
+		RetryBackoffMax: retryBackoffMax,
+		PriorityAging:   priorityAging,
+		PollInterval:    pollInterval,
+	})
+	ruleUsecase := usecase.NewRuleUsecase(repoStore, trxRepo)
 	usecaseStore := internal.UsecaseStore{
 		RepositoryUsecase: repositoryUsecase,
 		ScanningUsecase:   scanningUsecase,
+		RuleUsecase:       ruleUsecase,
 	}
 
-	rest.NewHandler(c.Server, usecaseStore)
+	c.Usecases = usecaseStore
+
+	if c.servesApi() {
+		rest.NewHandler(c.Server, usecaseStore)
+	}
 
 	return nil
 }
+
+// newSecretRepository creates secret repository by FINDINGS_* env, encryption key is base64 encoded
+func newSecretRepository() (internal.ISecretRepository, serror.SError) {
+	option := model.SecretOption{
+		HashKey:    []byte(utstring.Env(constants.FindingsHashKey)),
+		KeepSecret: utstring.Env(constants.FindingsKeepSecret) == "true",
+	}
+	if option.KeepSecret {
+		key, err := base64.StdEncoding.DecodeString(utstring.Env(constants.FindingsEncryptionKey))
+		if err != nil {
+			errx := serror.NewFromError(err)
+			errx.AddCommentf("[config][newSecretRepository] while decode %s", constants.FindingsEncryptionKey)
+			return nil, errx
+		}
+		option.EncryptionKey = key
+	}
+	return secret.NewSecretRepository(option)
+}
+
+// scanInstance names this service among its replicas by SCAN_INSTANCE env, host name and process id by default
+func scanInstance() string {
+	if instance := utstring.Env(constants.ScanInstance); instance != "" {
+		return instance
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = constants.DefaultAppKey
+	}
+	return fmt.Sprintf("%s:%d", hostname, os.Getpid())
+}
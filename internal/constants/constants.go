@@ -13,6 +13,12 @@ const (
 	DefaultAppPort int    = 8080
 )
 
+const (
+	RunModeAll    = "all"    // serves API and consumes scanning queue
+	RunModeApi    = "api"    // serves API only, queued scannings are left to workers
+	RunModeWorker = "worker" // consumes scanning queue and runs scheduler only
+)
+
 const (
 	AppDebug   = "APP_DEBUG"
 	AppEnv     = "APP_ENV"
@@ -21,6 +27,7 @@ const (
 	AppVersion = "APP_VERSION"
 	AppHost    = "APP_HOST"
 	AppPort    = "APP_PORT"
+	AppRunMode = "APP_RUN_MODE"
 
 	DBEngine       = "DB_ENGINE"
 	DBHost         = "DB_HOST"
@@ -37,6 +44,41 @@ const (
 	DBConnLifetime = "DB_CONN_LIFETIME"
 	DBConnMaxIdle  = "DB_CONN_MAX_IDLE"
 	DBConnMaxOpen  = "DB_CONN_MAX_OPEN"
+
+	ScanEngines         = "SCAN_ENGINES"
+	ScanTimeout         = "SCAN_TIMEOUT"
+	ScanWorkers         = "SCAN_WORKERS"
+	ScanInstance        = "SCAN_INSTANCE"
+	ScanLease           = "SCAN_LEASE"
+	ScanMaxAttempts     = "SCAN_MAX_ATTEMPTS"
+	ScanRetryBackoff    = "SCAN_RETRY_BACKOFF"
+	ScanRetryBackoffMax = "SCAN_RETRY_BACKOFF_MAX"
+	ScanPriorityAging   = "SCAN_PRIORITY_AGING"
+	ScanPollInterval    = "SCAN_POLL_INTERVAL"
+
+	GitHTTPUsername     = "GIT_HTTP_USERNAME"
+	GitHTTPPassword     = "GIT_HTTP_PASSWORD"
+	GitHTTPHosts        = "GIT_HTTP_HOSTS"
+	GitSSHKeyPath       = "GIT_SSH_KEY_PATH"
+	GitSSHKeyPassphrase = "GIT_SSH_KEY_PASSPHRASE"
+	GitSSHKnownHosts    = "GIT_SSH_KNOWN_HOSTS"
+
+	LocalRepositoryRoot = "LOCAL_REPOSITORY_ROOT"
+
+	EntropyBase64Threshold = "ENTROPY_BASE64_THRESHOLD"
+	EntropyHexThreshold    = "ENTROPY_HEX_THRESHOLD"
+	EntropyMinLength       = "ENTROPY_MIN_LENGTH"
+	EntropySkipExt         = "ENTROPY_SKIP_EXT"
+
+	UploadDir              = "UPLOAD_DIR"
+	UploadMaxSize          = "UPLOAD_MAX_SIZE"
+	UploadMaxExtractedSize = "UPLOAD_MAX_EXTRACTED_SIZE"
+	UploadMaxFiles         = "UPLOAD_MAX_FILES"
+
+	FindingsHashKey       = "FINDINGS_HASH_KEY"
+	FindingsKeepSecret    = "FINDINGS_KEEP_SECRET"
+	FindingsEncryptionKey = "FINDINGS_ENCRYPTION_KEY"
+	RevealTokens          = "REVEAL_TOKENS"
 )
 
 const (
@@ -44,6 +86,7 @@ const (
 	RegexUnnecessaryInquiryDetails = `^rp`
 	DefaultLimit                   = 10
 	DefaultPage                    = 1
+	LocalRepositoryScheme          = "file://"
 )
 
 const (
@@ -51,4 +94,71 @@ const (
 	ScanningStatusInProgress = "in_progress"
 	ScanningStatusSuccess    = "success"
 	ScanningStatusFailure    = "failure"
+	ScanningStatusCancelled  = "cancelled"
+	ScanningStatusDead       = "dead" // failed and run out of attempts
+)
+
+const (
+	ScanModeStandard = "standard" // scan whole ref or commits since last successful scanning
+	ScanModeDiff     = "diff"     // scan lines added from base ref to head ref only
+)
+
+const (
+	ScanEngineGrab    = "grab"
+	ScanEngineNative  = "native"
+	ScanEngineEntropy = "entropy"
+
+	GrabSessionCommand = "grab-session" // argument of child process running a grab session of the same executable
+
+	ScanEngineCapabilityRemote      = "remote"       // engine fetches repository from git provider by itself
+	ScanEngineCapabilityWorktree    = "worktree"     // engine scans a checked out work tree
+	ScanEngineCapabilitySignature   = "signature"    // engine matches built-in secret signatures
+	ScanEngineCapabilityCustomRules = "custom_rules" // engine matches detection rules kept in database
+)
+
+const (
+	FindingSeverityHigh   = "high"   // secret is matched in file content
+	FindingSeverityMedium = "medium" // file is matched by its name, extension or path which may contain secrets
+	FindingSeverityLow    = "low"    // string looks random, which may be a secret or not
+)
+
+const (
+	FindingOccurrenceNew       = "new"       // finding is not reported by previous scanning
+	FindingOccurrenceRecurring = "recurring" // finding is reported by previous scanning as well
+	FindingOccurrenceFixed     = "fixed"     // finding of previous scanning is no longer reported
+)
+
+const (
+	IgnoreFileName     = ".reposcanignore" // at root of repository, lists paths, rules and fingerprints to be suppressed
+	IgnoreInlineMarker = "reposcan:ignore" // comment on the line of finding to suppress it
+
+	SuppressionSourceIgnoreFile = "ignore_file" // finding is suppressed by .reposcanignore
+	SuppressionSourceInline     = "inline"      // finding is suppressed by inline comment
+)
+
+const (
+	DefaultScanTimeout         = "1h"  // maximum duration of a scanning, "0" for no limit
+	DefaultScanWorkers         = 4     // number of scannings running at once in a service
+	DefaultScanLease           = "2m"  // scanning not renewed by its worker as long as this is abandoned
+	DefaultScanMaxAttempts     = 3     // failed or abandoned scanning is queued again until it is attempted as many times
+	DefaultScanRetryBackoff    = "30s" // delay before the first retry, doubled on every next one
+	DefaultScanRetryBackoffMax = "30m" // maximum delay between retries
+	DefaultScanPriorityAging   = "5m"  // queued scanning is raised one priority every time it waits as long, "0" for no aging
+	DefaultScanPollInterval    = "10s" // idle workers look for scannings queued by other services as often
+)
+
+const (
+	FindingActionEntropy = "entropy" // Action of findings reported by entropy engine
+
+	DefaultEntropyBase64Threshold = 4.5
+	DefaultEntropyHexThreshold    = 3.0
+	DefaultEntropyMinLength       = 20
+	DefaultEntropySkipExt         = "lock,sum,svg,map,min.js,min.css"
+)
+
+const (
+	DefaultUploadDir              = "reposcan-uploads" // under system temporary directory
+	DefaultUploadMaxSize          = 100 << 20          // 100 MiB of archive
+	DefaultUploadMaxExtractedSize = 1 << 30            // 1 GiB of extracted files
+	DefaultUploadMaxFiles         = 100000
 )
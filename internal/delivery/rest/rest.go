@@ -1,7 +1,11 @@
 package rest
 
 import (
+	"crypto/subtle"
+	"io"
 	"net/http"
+	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -13,12 +17,15 @@ import (
 	"repo-scanner/internal/model"
 	"repo-scanner/internal/utils/response"
 	"repo-scanner/internal/utils/serror"
+	"repo-scanner/internal/utils/utgit"
 	"repo-scanner/internal/utils/utint"
+	"repo-scanner/internal/utils/utstring"
 )
 
 type handler struct {
 	repositoryUseCase internal.IRepositoryUsecase
 	scanningUsecase   internal.IScanningUsecase
+	ruleUsecase       internal.IRuleUsecase
 }
 
 func (hd handler) GetRepositoryList(ctx *gin.Context) {
@@ -48,7 +55,7 @@ func (hd handler) GetRepositoryList(ctx *gin.Context) {
 	}
 
 	var res []model.RepositoryListResponse
-	res, errx = hd.repositoryUseCase.GetRepositoryList(req)
+	res, errx = hd.repositoryUseCase.GetRepositoryList(ctx.Request.Context(), req)
 	if errx != nil {
 		errx.AddCommentf("[delivery][GetRepositoryList] while get repository list")
 		if errx.Code() < 1 {
@@ -86,25 +93,19 @@ func (hd handler) AddRepository(ctx *gin.Context) {
 		return
 	}
 
-	pathParts := strings.Split(req.Url, "/")
-	idx := 0
-	for ; idx < len(pathParts); idx++ {
-		if (pathParts[idx] == "github.com" ||
-			pathParts[idx] == "gitlab.com" ||
-			pathParts[idx] == "bitbucket.org") && len(pathParts[idx:]) == 3 {
-			req.Url = strings.Join(pathParts[idx:], "/")
-			break
-		}
-	}
-	if idx >= len(pathParts) {
+	var isValidUrl bool
+	req.Url, isValidUrl = normalizeRepositoryUrl(req.Url)
+	if !isValidUrl {
 		errx = serror.NewFromError(err)
 		errx.AddCommentf("[delivery][AddRepository] while validate url")
 		response.ResultError(ctx, response.ErrorUrlValidationFail, err)
 		return
 	}
 
+	req.DefaultRef = strings.TrimSpace(req.DefaultRef)
+
 	var res model.AddRepositoryResponse
-	res, errx = hd.repositoryUseCase.AddRepository(req)
+	res, errx = hd.repositoryUseCase.AddRepository(ctx.Request.Context(), req)
 	if errx != nil {
 		errx.AddCommentf("[delivery][AddRepository] while add new repository")
 		if errx.Code() < 1 {
@@ -151,17 +152,9 @@ func (hd handler) EditRepository(ctx *gin.Context) {
 	}
 
 	if req.Url != nil {
-		pathParts := strings.Split(*req.Url, "/")
-		idx := 0
-		for ; idx < len(pathParts); idx++ {
-			if (pathParts[idx] == "github.com" ||
-				pathParts[idx] == "gitlab.com" ||
-				pathParts[idx] == "bitbucket.org") && len(pathParts[idx:]) == 3 {
-				*req.Url = strings.Join(pathParts[idx:], "/")
-				break
-			}
-		}
-		if idx >= len(pathParts) {
+		var isValidUrl bool
+		*req.Url, isValidUrl = normalizeRepositoryUrl(*req.Url)
+		if !isValidUrl {
 			errx = serror.NewFromError(err)
 			errx.AddCommentf("[delivery][AddRepository] while validate url")
 			response.ResultError(ctx, response.ErrorUrlValidationFail, err)
@@ -169,8 +162,12 @@ func (hd handler) EditRepository(ctx *gin.Context) {
 		}
 	}
 
+	if req.DefaultRef != nil {
+		*req.DefaultRef = strings.TrimSpace(*req.DefaultRef)
+	}
+
 	var res model.EditRepositoryResponse
-	res, errx = hd.repositoryUseCase.EditRepository(req)
+	res, errx = hd.repositoryUseCase.EditRepository(ctx.Request.Context(), req)
 	if errx != nil {
 		errx.AddCommentf("[delivery][EditRepository] while edit repository")
 		if errx.Code() < 1 {
@@ -204,7 +201,7 @@ func (hd handler) DeleteRepository(ctx *gin.Context) {
 		return
 	}
 
-	errx = hd.repositoryUseCase.DeleteRepository(repoId)
+	errx = hd.repositoryUseCase.DeleteRepository(ctx.Request.Context(), repoId)
 	if errx != nil {
 		errx.AddCommentf("[delivery][EditRepository] while edit repository")
 		if errx.Code() < 1 {
@@ -218,6 +215,172 @@ func (hd handler) DeleteRepository(ctx *gin.Context) {
 	return
 }
 
+func (hd handler) GetRepositorySettings(ctx *gin.Context) {
+	var (
+		errx serror.SError
+	)
+
+	defer func() {
+		if errx != nil {
+			log.Error(errx.Comments())
+		}
+	}()
+
+	log.Infof("GetRepositorySettings invoked")
+
+	repoId := utint.StringToInt(ctx.Param("repository_id"), 0)
+	if repoId <= 0 {
+		errx = serror.New("Invalid repository_id")
+		response.ResultError(ctx, response.ErrorParamValidationFail, errx)
+		return
+	}
+
+	var res model.RepositorySettings
+	res, errx = hd.repositoryUseCase.GetRepositorySettings(ctx.Request.Context(), repoId)
+	if errx != nil {
+		errx.AddCommentf("[delivery][GetRepositorySettings] while get repository settings")
+		if errx.Code() < 1 {
+			errx = serror.Newic(http.StatusInternalServerError, errx.Error(), errx.Comments())
+		}
+		response.ResultSError(ctx, errx)
+		return
+	}
+
+	response.ResultWithData(ctx, response.SuccessGetDataOk, res)
+	return
+}
+
+func (hd handler) EditRepositorySettings(ctx *gin.Context) {
+	var (
+		errx serror.SError
+	)
+
+	defer func() {
+		if errx != nil {
+			log.Error(errx.Comments())
+		}
+	}()
+
+	log.Infof("EditRepositorySettings invoked")
+
+	repoId := utint.StringToInt(ctx.Param("repository_id"), 0)
+	if repoId <= 0 {
+		errx = serror.New("Invalid repository_id")
+		response.ResultError(ctx, response.ErrorParamValidationFail, errx)
+		return
+	}
+
+	// Settings are replaced as a whole, unset ones fall back to defaults
+	req := model.RepositorySettings{}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[delivery][EditRepositorySettings] while bind request body")
+		response.ResultError(ctx, response.ErrorPayloadValidationFail, err)
+		return
+	}
+
+	err := validator.New().Struct(req)
+	if err != nil {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[delivery][EditRepositorySettings] while validate struct")
+		response.ResultError(ctx, response.ErrorPayloadValidationFail, err)
+		return
+	}
+
+	var res model.RepositorySettings
+	res, errx = hd.repositoryUseCase.EditRepositorySettings(ctx.Request.Context(), repoId, req)
+	if errx != nil {
+		errx.AddCommentf("[delivery][EditRepositorySettings] while edit repository settings")
+		if errx.Code() < 1 {
+			errx = serror.Newic(http.StatusInternalServerError, errx.Error(), errx.Comments())
+		}
+		response.ResultSError(ctx, errx)
+		return
+	}
+
+	response.ResultWithData(ctx, response.SuccessUpdated, res)
+	return
+}
+
+func (hd handler) GetRepositorySchedule(ctx *gin.Context) {
+	var (
+		errx serror.SError
+	)
+
+	defer func() {
+		if errx != nil {
+			log.Error(errx.Comments())
+		}
+	}()
+
+	log.Infof("GetRepositorySchedule invoked")
+
+	repoId := utint.StringToInt(ctx.Param("repository_id"), 0)
+	if repoId <= 0 {
+		errx = serror.New("Invalid repository_id")
+		response.ResultError(ctx, response.ErrorParamValidationFail, errx)
+		return
+	}
+
+	var res model.RepositorySchedule
+	res, errx = hd.repositoryUseCase.GetRepositorySchedule(ctx.Request.Context(), repoId)
+	if errx != nil {
+		errx.AddCommentf("[delivery][GetRepositorySchedule] while get repository schedule")
+		if errx.Code() < 1 {
+			errx = serror.Newic(http.StatusInternalServerError, errx.Error(), errx.Comments())
+		}
+		response.ResultSError(ctx, errx)
+		return
+	}
+
+	response.ResultWithData(ctx, response.SuccessGetDataOk, res)
+	return
+}
+
+func (hd handler) EditRepositorySchedule(ctx *gin.Context) {
+	var (
+		errx serror.SError
+	)
+
+	defer func() {
+		if errx != nil {
+			log.Error(errx.Comments())
+		}
+	}()
+
+	log.Infof("EditRepositorySchedule invoked")
+
+	repoId := utint.StringToInt(ctx.Param("repository_id"), 0)
+	if repoId <= 0 {
+		errx = serror.New("Invalid repository_id")
+		response.ResultError(ctx, response.ErrorParamValidationFail, errx)
+		return
+	}
+
+	// Empty scan_schedule turns scheduled scans off
+	req := model.EditRepositoryScheduleRequest{}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[delivery][EditRepositorySchedule] while bind request body")
+		response.ResultError(ctx, response.ErrorPayloadValidationFail, err)
+		return
+	}
+
+	var res model.RepositorySchedule
+	res, errx = hd.repositoryUseCase.EditRepositorySchedule(ctx.Request.Context(), repoId, req)
+	if errx != nil {
+		errx.AddCommentf("[delivery][EditRepositorySchedule] while edit repository schedule")
+		if errx.Code() < 1 {
+			errx = serror.Newic(http.StatusInternalServerError, errx.Error(), errx.Comments())
+		}
+		response.ResultSError(ctx, errx)
+		return
+	}
+
+	response.ResultWithData(ctx, response.SuccessUpdated, res)
+	return
+}
+
 func (hd handler) TriggerRepoScanning(ctx *gin.Context) {
 	var (
 		errx serror.SError
@@ -238,8 +401,29 @@ func (hd handler) TriggerRepoScanning(ctx *gin.Context) {
 		return
 	}
 
+	// Request body is optional, it may only pick ref to be scanned
+	req := model.AddScanningRequest{}
+	if err := ctx.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[delivery][TriggerRepoScanning] while bind request body")
+		response.ResultError(ctx, response.ErrorPayloadValidationFail, err)
+		return
+	}
+	req.RepoId = repo_id
+	req.Ref = strings.TrimSpace(req.Ref)
+
+	if err := validator.New().Struct(req); err != nil {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[delivery][TriggerRepoScanning] while validate struct")
+		response.ResultError(ctx, response.ErrorPayloadValidationFail, err)
+		return
+	}
+
+	// full=true forces complete rescan instead of scanning new commits only
+	req.IsFullScan, _ = strconv.ParseBool(ctx.Query("full"))
+
 	var res model.ScanningResponse
-	res, errx = hd.scanningUsecase.AddNewScanning(repo_id)
+	res, errx = hd.scanningUsecase.AddNewScanning(ctx.Request.Context(), req)
 	if errx != nil {
 		errx.AddCommentf("[delivery][TriggerRepoScanning] while add new scanning")
 		if errx.Code() < 1 {
@@ -249,11 +433,16 @@ func (hd handler) TriggerRepoScanning(ctx *gin.Context) {
 		return
 	}
 
+	// A trigger coalesced into an identical queued scanning creates nothing
+	if res.IsCoalesced {
+		response.ResultWithData(ctx, response.SuccessGetDataOk, res)
+		return
+	}
 	response.ResultWithData(ctx, response.SuccessCreated, res)
 	return
 }
 
-func (hd handler) ScanningResult(ctx *gin.Context) {
+func (hd handler) TriggerRepoDiffScanning(ctx *gin.Context) {
 	var (
 		errx serror.SError
 	)
@@ -264,33 +453,127 @@ func (hd handler) ScanningResult(ctx *gin.Context) {
 		}
 	}()
 
-	log.Infof("ScanningResult invoked")
+	log.Infof("TriggerRepoDiffScanning invoked")
 
-	req := model.ScanningListRequest{
-		Limit: utint.StringToInt(ctx.Query("limit"), constants.DefaultLimit),
-		Page:  utint.StringToInt(ctx.Query("page"), constants.DefaultPage),
+	repo_id := utint.StringToInt(ctx.Param("repository_id"), 0)
+	if repo_id <= 0 {
+		errx = serror.New("Invalid repository_id")
+		response.ResultError(ctx, response.ErrorParamValidationFail, errx)
+		return
 	}
 
-	var ok bool
-	if req.Sort, ok = ctx.GetQuery("sort"); ok == false {
-		req.Sort = "desc"
+	req := model.AddDiffScanningRequest{}
+	ctx.BindJSON(&req)
+	req.BaseRef = strings.TrimSpace(req.BaseRef)
+	req.HeadRef = strings.TrimSpace(req.HeadRef)
+
+	err := validator.New().Struct(req)
+	if err != nil {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[delivery][TriggerRepoDiffScanning] while validate struct")
+		response.ResultError(ctx, response.ErrorPayloadValidationFail, err)
+		return
 	}
-	if req.Status, ok = ctx.GetQuery("status"); ok == false {
-		req.Status = "all"
+
+	var res model.ScanningResponse
+	res, errx = hd.scanningUsecase.AddNewScanning(ctx.Request.Context(), model.AddScanningRequest{
+		RepoId:      repo_id,
+		Mode:        constants.ScanModeDiff,
+		BaseRef:     req.BaseRef,
+		Ref:         req.HeadRef,
+		MaxAttempts: req.MaxAttempts,
+		Priority:    req.Priority,
+	})
+	if errx != nil {
+		errx.AddCommentf("[delivery][TriggerRepoDiffScanning] while add new diff scanning")
+		if errx.Code() < 1 {
+			errx = serror.Newic(http.StatusInternalServerError, errx.Error(), errx.Comments())
+		}
+		response.ResultSError(ctx, errx)
+		return
+	}
+
+	// A trigger coalesced into an identical queued scanning creates nothing
+	if res.IsCoalesced {
+		response.ResultWithData(ctx, response.SuccessGetDataOk, res)
+		return
+	}
+	response.ResultWithData(ctx, response.SuccessCreated, res)
+	return
+}
+
+func (hd handler) AddBaseline(ctx *gin.Context) {
+	var (
+		errx serror.SError
+	)
+
+	defer func() {
+		if errx != nil {
+			log.Error(errx.Comments())
+		}
+	}()
+
+	log.Infof("AddBaseline invoked")
+
+	repo_id := utint.StringToInt(ctx.Param("repository_id"), 0)
+	if repo_id <= 0 {
+		errx = serror.New("Invalid repository_id")
+		response.ResultError(ctx, response.ErrorParamValidationFail, errx)
+		return
 	}
 
+	req := model.AddBaselineRequest{}
+	ctx.BindJSON(&req)
+	req.RepositoryId = repo_id
+	req.CreatedBy = strings.TrimSpace(req.CreatedBy)
+
 	err := validator.New().Struct(req)
 	if err != nil {
 		errx = serror.NewFromError(err)
-		errx.AddCommentf("[delivery][ScanningResult] while validate struct")
-		response.ResultError(ctx, response.ErrorQueryValidationFail, err)
+		errx.AddCommentf("[delivery][AddBaseline] while validate struct")
+		response.ResultError(ctx, response.ErrorPayloadValidationFail, err)
 		return
 	}
 
-	var res []model.ScanningListResponse
-	res, errx = hd.scanningUsecase.GetScanningList(req)
+	var res model.BaselineResponse
+	res, errx = hd.scanningUsecase.AddBaseline(ctx.Request.Context(), req)
 	if errx != nil {
-		errx.AddCommentf("[delivery][ScanningResult] while get scanning list")
+		errx.AddCommentf("[delivery][AddBaseline] while add baseline")
+		if errx.Code() < 1 {
+			errx = serror.Newic(http.StatusInternalServerError, errx.Error(), errx.Comments())
+		}
+		response.ResultSError(ctx, errx)
+		return
+	}
+
+	response.ResultWithData(ctx, response.SuccessCreated, res)
+	return
+}
+
+func (hd handler) GetBaselineList(ctx *gin.Context) {
+	var (
+		errx serror.SError
+	)
+
+	defer func() {
+		if errx != nil {
+			log.Error(errx.Comments())
+		}
+	}()
+
+	log.Infof("GetBaselineList invoked")
+
+	repo_id := utint.StringToInt(ctx.Param("repository_id"), 0)
+	if repo_id <= 0 {
+		errx = serror.New("Invalid repository_id")
+		response.ResultError(ctx, response.ErrorParamValidationFail, errx)
+		return
+	}
+
+	var res []model.BaselineResponse
+	res, errx = hd.scanningUsecase.GetBaselineList(ctx.Request.Context(), repo_id)
+	if errx != nil {
+		errx.AddCommentf("[delivery][GetBaselineList] while get baseline list")
 		if errx.Code() < 1 {
 			errx = serror.Newic(http.StatusInternalServerError, errx.Error(), errx.Comments())
 		}
@@ -301,3 +584,505 @@ func (hd handler) ScanningResult(ctx *gin.Context) {
 	response.ResultWithData(ctx, response.SuccessGetDataOk, res)
 	return
 }
+
+func (hd handler) ActivateBaseline(ctx *gin.Context) {
+	var (
+		errx serror.SError
+	)
+
+	defer func() {
+		if errx != nil {
+			log.Error(errx.Comments())
+		}
+	}()
+
+	log.Infof("ActivateBaseline invoked")
+
+	repo_id := utint.StringToInt(ctx.Param("repository_id"), 0)
+	version := utint.StringToInt(ctx.Param("version"), 0)
+	if repo_id <= 0 || version <= 0 {
+		errx = serror.New("Invalid repository_id or version")
+		response.ResultError(ctx, response.ErrorParamValidationFail, errx)
+		return
+	}
+
+	req := model.ActivateBaselineRequest{}
+	ctx.BindJSON(&req)
+	req.RepositoryId = repo_id
+	req.Version = version
+	req.ActivatedBy = strings.TrimSpace(req.ActivatedBy)
+
+	err := validator.New().Struct(req)
+	if err != nil {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[delivery][ActivateBaseline] while validate struct")
+		response.ResultError(ctx, response.ErrorPayloadValidationFail, err)
+		return
+	}
+
+	var res model.BaselineResponse
+	res, errx = hd.scanningUsecase.ActivateBaseline(ctx.Request.Context(), req)
+	if errx != nil {
+		errx.AddCommentf("[delivery][ActivateBaseline] while activate baseline")
+		if errx.Code() < 1 {
+			errx = serror.Newic(http.StatusInternalServerError, errx.Error(), errx.Comments())
+		}
+		response.ResultSError(ctx, errx)
+		return
+	}
+
+	response.ResultWithData(ctx, response.SuccessUpdated, res)
+	return
+}
+
+func (hd handler) UploadScanning(ctx *gin.Context) {
+	var (
+		errx serror.SError
+	)
+
+	defer func() {
+		if errx != nil {
+			log.Error(errx.Comments())
+		}
+	}()
+
+	log.Infof("UploadScanning invoked")
+
+	// Reject oversized archive before it is buffered to disk
+	maxSize := utint.StringToInt(utstring.Env(constants.UploadMaxSize), constants.DefaultUploadMaxSize)
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxSize)
+
+	header, err := ctx.FormFile("file")
+	if err != nil {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[delivery][UploadScanning] while read uploaded file")
+		response.ResultError(ctx, response.ErrorPayloadValidationFail, err)
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[delivery][UploadScanning] while open uploaded file")
+		response.ResultError(ctx, response.ErrorPayloadValidationFail, err)
+		return
+	}
+	defer file.Close()
+
+	var res model.ScanningResponse
+	res, errx = hd.scanningUsecase.AddUploadScanning(ctx.Request.Context(), model.AddUploadScanningRequest{
+		Name: strings.TrimSpace(ctx.PostForm("repository_name")),
+		Archive: model.UploadArchive{
+			Filename: filepath.Base(header.Filename),
+			Size:     header.Size,
+			File:     file,
+		},
+	})
+	if errx != nil {
+		errx.AddCommentf("[delivery][UploadScanning] while add upload scanning")
+		if errx.Code() < 1 {
+			errx = serror.Newic(http.StatusInternalServerError, errx.Error(), errx.Comments())
+		}
+		response.ResultSError(ctx, errx)
+		return
+	}
+
+	response.ResultWithData(ctx, response.SuccessCreated, res)
+	return
+}
+
+func (hd handler) ScanningResult(ctx *gin.Context) {
+	var (
+		errx serror.SError
+	)
+
+	defer func() {
+		if errx != nil {
+			log.Error(errx.Comments())
+		}
+	}()
+
+	log.Infof("ScanningResult invoked")
+
+	req := model.ScanningListRequest{
+		Limit: utint.StringToInt(ctx.Query("limit"), constants.DefaultLimit),
+		Page:  utint.StringToInt(ctx.Query("page"), constants.DefaultPage),
+	}
+
+	var ok bool
+	if req.Sort, ok = ctx.GetQuery("sort"); ok == false {
+		req.Sort = "desc"
+	}
+	if req.Status, ok = ctx.GetQuery("status"); ok == false {
+		req.Status = "all"
+	}
+
+	err := validator.New().Struct(req)
+	if err != nil {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[delivery][ScanningResult] while validate struct")
+		response.ResultError(ctx, response.ErrorQueryValidationFail, err)
+		return
+	}
+
+	var res []model.ScanningListResponse
+	res, errx = hd.scanningUsecase.GetScanningList(ctx.Request.Context(), req)
+	if errx != nil {
+		errx.AddCommentf("[delivery][ScanningResult] while get scanning list")
+		if errx.Code() < 1 {
+			errx = serror.Newic(http.StatusInternalServerError, errx.Error(), errx.Comments())
+		}
+		response.ResultSError(ctx, errx)
+		return
+	}
+
+	response.ResultWithData(ctx, response.SuccessGetDataOk, res)
+	return
+}
+
+func (hd handler) GetFindingList(ctx *gin.Context) {
+	var (
+		errx serror.SError
+	)
+
+	defer func() {
+		if errx != nil {
+			log.Error(errx.Comments())
+		}
+	}()
+
+	log.Infof("GetFindingList invoked")
+
+	scanningId := utint.StringToInt(ctx.Param("scanning_id"), 0)
+	if scanningId <= 0 {
+		errx = serror.New("Invalid scanning_id")
+		response.ResultError(ctx, response.ErrorParamValidationFail, errx)
+		return
+	}
+
+	req := model.FindingListRequest{
+		ScanningId:   scanningId,
+		Limit:        utint.StringToInt(ctx.Query("limit"), constants.DefaultLimit),
+		Page:         utint.StringToInt(ctx.Query("page"), constants.DefaultPage),
+		Severity:     ctx.Query("severity"),
+		Occurrence:   ctx.Query("occurrence"),
+		Engine:       ctx.Query("engine"),
+		Rule:         ctx.Query("rule"),
+		FilePath:     ctx.Query("file_path"),
+		CommitHash:   ctx.Query("commit_hash"),
+		CommitAuthor: ctx.Query("commit_author"),
+		TriageStatus: ctx.Query("triage_status"),
+		Assignee:     ctx.Query("assignee"),
+		Suppressed:   ctx.Query("suppressed"),
+		Baselined:    ctx.Query("baselined"),
+	}
+
+	err := validator.New().Struct(req)
+	if err != nil {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[delivery][GetFindingList] while validate struct")
+		response.ResultError(ctx, response.ErrorQueryValidationFail, err)
+		return
+	}
+
+	var res []model.FindingListResponse
+	res, errx = hd.scanningUsecase.GetFindingList(ctx.Request.Context(), req)
+	if errx != nil {
+		errx.AddCommentf("[delivery][GetFindingList] while get finding list")
+		if errx.Code() < 1 {
+			errx = serror.Newic(http.StatusInternalServerError, errx.Error(), errx.Comments())
+		}
+		response.ResultSError(ctx, errx)
+		return
+	}
+
+	response.ResultWithData(ctx, response.SuccessGetDataOk, res)
+	return
+}
+
+func (hd handler) TriageFinding(ctx *gin.Context) {
+	var (
+		errx serror.SError
+	)
+
+	defer func() {
+		if errx != nil {
+			log.Error(errx.Comments())
+		}
+	}()
+
+	log.Infof("TriageFinding invoked")
+
+	scanningId := utint.StringToInt(ctx.Param("scanning_id"), 0)
+	findingId := strings.TrimSpace(ctx.Param("finding_id"))
+	if scanningId <= 0 || findingId == "" {
+		errx = serror.New("Invalid scanning_id or finding_id")
+		response.ResultError(ctx, response.ErrorParamValidationFail, errx)
+		return
+	}
+
+	req := model.TriageFindingRequest{}
+	ctx.BindJSON(&req)
+	req.ScanningId = scanningId
+	req.FindingId = findingId
+	req.Comment = strings.TrimSpace(req.Comment)
+	req.TriagedBy = strings.TrimSpace(req.TriagedBy)
+	if req.Assignee != nil {
+		assignee := strings.TrimSpace(*req.Assignee)
+		req.Assignee = &assignee
+	}
+
+	err := validator.New().Struct(req)
+	if err != nil {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[delivery][TriageFinding] while validate struct")
+		response.ResultError(ctx, response.ErrorPayloadValidationFail, err)
+		return
+	}
+
+	var res model.FindingTriageResponse
+	res, errx = hd.scanningUsecase.TriageFinding(ctx.Request.Context(), req)
+	if errx != nil {
+		errx.AddCommentf("[delivery][TriageFinding] while triage finding")
+		if errx.Code() < 1 {
+			errx = serror.Newic(http.StatusInternalServerError, errx.Error(), errx.Comments())
+		}
+		response.ResultSError(ctx, errx)
+		return
+	}
+
+	response.ResultWithData(ctx, response.SuccessUpdated, res)
+	return
+}
+
+func (hd handler) GetFindingComments(ctx *gin.Context) {
+	var (
+		errx serror.SError
+	)
+
+	defer func() {
+		if errx != nil {
+			log.Error(errx.Comments())
+		}
+	}()
+
+	log.Infof("GetFindingComments invoked")
+
+	scanningId := utint.StringToInt(ctx.Param("scanning_id"), 0)
+	findingId := strings.TrimSpace(ctx.Param("finding_id"))
+	if scanningId <= 0 || findingId == "" {
+		errx = serror.New("Invalid scanning_id or finding_id")
+		response.ResultError(ctx, response.ErrorParamValidationFail, errx)
+		return
+	}
+
+	var res []model.FindingComment
+	res, errx = hd.scanningUsecase.GetFindingComments(ctx.Request.Context(), scanningId, findingId)
+	if errx != nil {
+		errx.AddCommentf("[delivery][GetFindingComments] while get finding comments")
+		if errx.Code() < 1 {
+			errx = serror.Newic(http.StatusInternalServerError, errx.Error(), errx.Comments())
+		}
+		response.ResultSError(ctx, errx)
+		return
+	}
+
+	response.ResultWithData(ctx, response.SuccessGetDataOk, res)
+	return
+}
+
+func (hd handler) AddFindingComment(ctx *gin.Context) {
+	var (
+		errx serror.SError
+	)
+
+	defer func() {
+		if errx != nil {
+			log.Error(errx.Comments())
+		}
+	}()
+
+	log.Infof("AddFindingComment invoked")
+
+	scanningId := utint.StringToInt(ctx.Param("scanning_id"), 0)
+	findingId := strings.TrimSpace(ctx.Param("finding_id"))
+	if scanningId <= 0 || findingId == "" {
+		errx = serror.New("Invalid scanning_id or finding_id")
+		response.ResultError(ctx, response.ErrorParamValidationFail, errx)
+		return
+	}
+
+	req := model.AddFindingCommentRequest{}
+	ctx.BindJSON(&req)
+	req.ScanningId = scanningId
+	req.FindingId = findingId
+	req.Comment = strings.TrimSpace(req.Comment)
+	req.CreatedBy = strings.TrimSpace(req.CreatedBy)
+
+	err := validator.New().Struct(req)
+	if err != nil {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[delivery][AddFindingComment] while validate struct")
+		response.ResultError(ctx, response.ErrorPayloadValidationFail, err)
+		return
+	}
+
+	var res model.FindingComment
+	res, errx = hd.scanningUsecase.AddFindingComment(ctx.Request.Context(), req)
+	if errx != nil {
+		errx.AddCommentf("[delivery][AddFindingComment] while add finding comment")
+		if errx.Code() < 1 {
+			errx = serror.Newic(http.StatusInternalServerError, errx.Error(), errx.Comments())
+		}
+		response.ResultSError(ctx, errx)
+		return
+	}
+
+	response.ResultWithData(ctx, response.SuccessCreated, res)
+	return
+}
+
+func (hd handler) CancelScanning(ctx *gin.Context) {
+	var (
+		errx serror.SError
+	)
+
+	defer func() {
+		if errx != nil {
+			log.Error(errx.Comments())
+		}
+	}()
+
+	log.Infof("CancelScanning invoked")
+
+	scanningId := utint.StringToInt(ctx.Param("scanning_id"), 0)
+	if scanningId <= 0 {
+		errx = serror.New("Invalid scanning_id")
+		response.ResultError(ctx, response.ErrorParamValidationFail, errx)
+		return
+	}
+
+	var res model.ScanningResponse
+	res, errx = hd.scanningUsecase.CancelScanning(ctx.Request.Context(), scanningId)
+	if errx != nil {
+		errx.AddCommentf("[delivery][CancelScanning] while cancel scanning")
+		if errx.Code() < 1 {
+			errx = serror.Newic(http.StatusInternalServerError, errx.Error(), errx.Comments())
+		}
+		response.ResultSError(ctx, errx)
+		return
+	}
+
+	response.ResultWithData(ctx, response.SuccessUpdated, res)
+	return
+}
+
+func (hd handler) RevealFinding(ctx *gin.Context) {
+	var (
+		errx serror.SError
+	)
+
+	defer func() {
+		if errx != nil {
+			log.Error(errx.Comments())
+		}
+	}()
+
+	log.Infof("RevealFinding invoked")
+
+	// Only holders of a reveal token are authorized, its name is recorded as who revealed
+	user, ok := revealUser(ctx.GetHeader("Authorization"))
+	if !ok {
+		errx = serror.Newi(http.StatusUnauthorized, "Unauthorized|Unauthorized")
+		errx.AddCommentf("[delivery][RevealFinding] invalid reveal token")
+		response.ResultSError(ctx, errx)
+		return
+	}
+
+	scanningId := utint.StringToInt(ctx.Param("scanning_id"), 0)
+	findingId := strings.TrimSpace(ctx.Param("finding_id"))
+	if scanningId <= 0 || findingId == "" {
+		errx = serror.New("Invalid scanning_id or finding_id")
+		response.ResultError(ctx, response.ErrorParamValidationFail, errx)
+		return
+	}
+
+	req := model.RevealFindingRequest{}
+	ctx.BindJSON(&req)
+	req.ScanningId = scanningId
+	req.FindingId = findingId
+	req.Reason = strings.TrimSpace(req.Reason)
+	req.RevealedBy = user
+
+	err := validator.New().Struct(req)
+	if err != nil {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[delivery][RevealFinding] while validate struct")
+		response.ResultError(ctx, response.ErrorPayloadValidationFail, err)
+		return
+	}
+
+	var res model.RevealFindingResponse
+	res, errx = hd.scanningUsecase.RevealFinding(ctx.Request.Context(), req)
+	if errx != nil {
+		errx.AddCommentf("[delivery][RevealFinding] while reveal finding")
+		if errx.Code() < 1 {
+			errx = serror.Newic(http.StatusInternalServerError, errx.Error(), errx.Comments())
+		}
+		response.ResultSError(ctx, errx)
+		return
+	}
+
+	response.ResultWithData(ctx, response.SuccessGetDataOk, res)
+	return
+}
+
+// revealUser returns name of user whose token in REVEAL_TOKENS, formatted as name:token pairs
+// separated by comma, is given as bearer token of authorization header
+func revealUser(authorization string) (string, bool) {
+	token := strings.TrimSpace(strings.TrimPrefix(authorization, "Bearer "))
+	if token == "" || token == authorization {
+		return "", false
+	}
+
+	for _, pair := range utstring.CleanSpit(utstring.Env(constants.RevealTokens), ",") {
+		idx := strings.Index(pair, ":")
+		if idx <= 0 || idx == len(pair)-1 {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(pair[idx+1:]), []byte(token)) == 1 {
+			return pair[:idx], true
+		}
+	}
+	return "", false
+}
+
+// normalizeRepositoryUrl trims scheme of git provider url to host/owner/name,
+// local repository is accepted as absolute path or file:// url under LOCAL_REPOSITORY_ROOT with its symlinks resolved,
+// other git remote is kept as it is
+func normalizeRepositoryUrl(url string) (string, bool) {
+	if localPath, isLocal := utgit.LocalPath(url); isLocal {
+		localPath, isAllowed := utgit.LocalPathUnder(localPath, utstring.Env(constants.LocalRepositoryRoot))
+		if !isAllowed {
+			return "", false
+		}
+		if strings.HasPrefix(url, constants.LocalRepositoryScheme) {
+			return constants.LocalRepositoryScheme + localPath, true
+		}
+		return localPath, true
+	}
+
+	pathParts := strings.Split(url, "/")
+	for idx := 0; idx < len(pathParts); idx++ {
+		if (pathParts[idx] == "github.com" ||
+			pathParts[idx] == "gitlab.com" ||
+			pathParts[idx] == "bitbucket.org") && len(pathParts[idx:]) == 3 {
+			return strings.Join(pathParts[idx:], "/"), true
+		}
+	}
+
+	if utgit.IsRemote(url) {
+		return url, true
+	}
+	return url, false
+}
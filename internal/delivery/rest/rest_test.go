@@ -0,0 +1,122 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"repo-scanner/internal"
+	"repo-scanner/internal/constants"
+	"repo-scanner/internal/mocks"
+	"repo-scanner/internal/model"
+)
+
+func TestTriggerRepoScanning(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	testCases := []struct {
+		name       string
+		path       string
+		body       string
+		mode       string
+		coalesced  bool
+		wantStatus int
+	}{
+		{
+			name:       "queue new scanning",
+			path:       "/v1/repository/1/scan",
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "coalesce into queued scanning",
+			path:       "/v1/repository/1/scan",
+			coalesced:  true,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "queue new diff scanning",
+			path:       "/v1/repository/1/scan/diff",
+			body:       `{"base_ref":"main","head_ref":"feature"}`,
+			mode:       constants.ScanModeDiff,
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "coalesce into queued diff scanning",
+			path:       "/v1/repository/1/scan/diff",
+			body:       `{"base_ref":"main","head_ref":"feature"}`,
+			mode:       constants.ScanModeDiff,
+			coalesced:  true,
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			scanningMock := new(mocks.IScanningUsecase)
+			scanningMock.On("AddNewScanning", mock.Anything, mock.MatchedBy(func(req model.AddScanningRequest) bool {
+				return req.RepoId == 1 && req.Mode == tc.mode
+			})).Return(model.ScanningResponse{Id: 7, IsCoalesced: tc.coalesced}, nil).Once()
+
+			router := gin.New()
+			NewHandler(router, internal.UsecaseStore{ScanningUsecase: scanningMock})
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, tc.path, strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.wantStatus, rec.Code)
+			var body struct {
+				Data model.ScanningResponse `json:"data"`
+			}
+			assert.Nil(t, json.Unmarshal(rec.Body.Bytes(), &body))
+			assert.Equal(t, int64(7), body.Data.Id)
+			assert.Equal(t, tc.coalesced, body.Data.IsCoalesced)
+			scanningMock.AssertExpectations(t)
+		})
+	}
+}
+
+func TestNormalizeRepositoryUrl(t *testing.T) {
+	// Temporary directory may be a symlink itself, e.g. on macOS
+	root, _ := filepath.EvalSymlinks(t.TempDir())
+	repo := filepath.Join(root, "jquery")
+	outside := t.TempDir()
+	assert.Nil(t, os.Mkdir(repo, 0o755))
+	assert.Nil(t, os.Symlink(outside, filepath.Join(root, "escape")))
+
+	testCases := []struct {
+		name   string
+		root   string
+		url    string
+		want   string
+		wantOk bool
+	}{
+		{name: "git provider url", url: "https://github.com/jquery/jquery", want: "github.com/jquery/jquery", wantOk: true},
+		{name: "local path under root", root: root, url: repo + "/", want: repo, wantOk: true},
+		{name: "file url under root", root: root, url: "file://" + repo, want: "file://" + repo, wantOk: true},
+		{name: "local path without root", url: repo},
+		{name: "file url without root", url: "file://" + repo},
+		{name: "local path outside root", root: root, url: "/etc"},
+		{name: "local path escaping root", root: root, url: repo + "/../../etc"},
+		{name: "local path escaping root by symlink", root: root, url: filepath.Join(root, "escape")},
+		{name: "relative file url", root: root, url: "file://jquery"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv(constants.LocalRepositoryRoot, tc.root)
+			res, ok := normalizeRepositoryUrl(tc.url)
+			assert.Equal(t, tc.wantOk, ok)
+			assert.Equal(t, tc.want, res)
+		})
+	}
+}
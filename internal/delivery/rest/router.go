@@ -10,6 +10,7 @@ func NewHandler(router *gin.Engine, store internal.UsecaseStore) {
 	h := handler{
 		repositoryUseCase: store.RepositoryUsecase,
 		scanningUsecase:   store.ScanningUsecase,
+		ruleUsecase:       store.RuleUsecase,
 	}
 
 	// Repository handlers
@@ -17,12 +18,31 @@ func NewHandler(router *gin.Engine, store internal.UsecaseStore) {
 	router.POST("/v1/repository", h.AddRepository)
 	router.PUT("/v1/repository/:repository_id", h.EditRepository)
 	router.DELETE("/v1/repository/:repository_id", h.DeleteRepository)
+	router.GET("/v1/repository/:repository_id/settings", h.GetRepositorySettings)
+	router.PUT("/v1/repository/:repository_id/settings", h.EditRepositorySettings)
+	router.GET("/v1/repository/:repository_id/schedule", h.GetRepositorySchedule)
+	router.PUT("/v1/repository/:repository_id/schedule", h.EditRepositorySchedule)
 	router.POST("/v1/repository/:repository_id/scan", h.TriggerRepoScanning)
+	router.POST("/v1/repository/:repository_id/scan/diff", h.TriggerRepoDiffScanning)
+	router.GET("/v1/repository/:repository_id/baselines", h.GetBaselineList)
+	router.POST("/v1/repository/:repository_id/baselines", h.AddBaseline)
+	router.PUT("/v1/repository/:repository_id/baselines/:version/activate", h.ActivateBaseline)
+	router.POST("/v1/scan/upload", h.UploadScanning)
 
 	// Scanning handlers
 	router.GET("/v1/scanning/result", h.ScanningResult)
+	router.GET("/v1/scanning/:scanning_id/findings", h.GetFindingList)
+	router.POST("/v1/scanning/:scanning_id/cancel", h.CancelScanning)
+	router.POST("/v1/scanning/:scanning_id/findings/:finding_id/reveal", h.RevealFinding)
+	router.PUT("/v1/scanning/:scanning_id/findings/:finding_id/triage", h.TriageFinding)
+	router.GET("/v1/scanning/:scanning_id/findings/:finding_id/comments", h.GetFindingComments)
+	router.POST("/v1/scanning/:scanning_id/findings/:finding_id/comments", h.AddFindingComment)
 
-	// Start scanning immediately which are unfinished
-	// Especially, adapting multiple services running simutanously
-	go h.scanningUsecase.StartScanningInQueue()
+	// Detection rule handlers
+	router.GET("/v1/rules", h.GetRuleList)
+	router.POST("/v1/rules", h.AddRule)
+	router.PUT("/v1/rules/:rule_id", h.EditRule)
+	router.DELETE("/v1/rules/:rule_id", h.DeleteRule)
+	router.PUT("/v1/rules/:rule_id/enable", h.EnableRule)
+	router.PUT("/v1/rules/:rule_id/disable", h.DisableRule)
 }
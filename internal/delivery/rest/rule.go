@@ -0,0 +1,200 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	log "github.com/sirupsen/logrus"
+
+	"repo-scanner/internal/constants"
+	"repo-scanner/internal/model"
+	"repo-scanner/internal/utils/response"
+	"repo-scanner/internal/utils/serror"
+	"repo-scanner/internal/utils/utint"
+)
+
+func (hd handler) GetRuleList(ctx *gin.Context) {
+	var (
+		errx serror.SError
+	)
+
+	defer func() {
+		if errx != nil {
+			log.Error(errx.Comments())
+		}
+	}()
+
+	log.Infof("GetRuleList invoked")
+
+	req := model.RuleListRequest{
+		Limit: utint.StringToInt(ctx.Query("limit"), constants.DefaultLimit),
+		Page:  utint.StringToInt(ctx.Query("page"), constants.DefaultPage),
+	}
+
+	err := validator.New().Struct(req)
+	if err != nil {
+		errx = serror.NewFromError(err)
+		errx.AddComments("[delivery][GetRuleList] while validate struct")
+		response.ResultError(ctx, response.ErrorQueryValidationFail, err)
+		return
+	}
+
+	var res []model.RuleListResponse
+	res, errx = hd.ruleUsecase.GetRuleList(ctx.Request.Context(), req)
+	if errx != nil {
+		errx.AddCommentf("[delivery][GetRuleList] while get rule list")
+		if errx.Code() < 1 {
+			errx = serror.Newic(http.StatusInternalServerError, errx.Error(), errx.Comments())
+		}
+		response.ResultSError(ctx, errx)
+		return
+	}
+
+	response.ResultWithData(ctx, response.SuccessGetDataOk, res)
+	return
+}
+
+func (hd handler) AddRule(ctx *gin.Context) {
+	var (
+		errx serror.SError
+	)
+
+	defer func() {
+		if errx != nil {
+			log.Error(errx.Comments())
+		}
+	}()
+
+	log.Infof("AddRule invoked")
+
+	req := model.AddRuleRequest{}
+	ctx.BindJSON(&req)
+
+	err := validator.New().Struct(req)
+	if err != nil {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[delivery][AddRule] while validate struct")
+		response.ResultError(ctx, response.ErrorPayloadValidationFail, err)
+		return
+	}
+
+	var res model.AddRuleResponse
+	res, errx = hd.ruleUsecase.AddRule(ctx.Request.Context(), req)
+	if errx != nil {
+		errx.AddCommentf("[delivery][AddRule] while add new rule")
+		if errx.Code() < 1 {
+			errx = serror.Newic(http.StatusInternalServerError, errx.Error(), errx.Comments())
+		}
+		response.ResultSError(ctx, errx)
+		return
+	}
+
+	response.ResultWithData(ctx, response.SuccessCreated, res)
+	return
+}
+
+func (hd handler) EditRule(ctx *gin.Context) {
+	log.Infof("EditRule invoked")
+
+	req := model.EditRuleRequest{
+		Id: utint.StringToInt(ctx.Param("rule_id"), 0),
+	}
+	ctx.BindJSON(&req)
+
+	hd.editRule(ctx, req)
+}
+
+func (hd handler) EnableRule(ctx *gin.Context) {
+	log.Infof("EnableRule invoked")
+
+	isActive := true
+	hd.editRule(ctx, model.EditRuleRequest{
+		Id:       utint.StringToInt(ctx.Param("rule_id"), 0),
+		IsActive: &isActive,
+	})
+}
+
+func (hd handler) DisableRule(ctx *gin.Context) {
+	log.Infof("DisableRule invoked")
+
+	isActive := false
+	hd.editRule(ctx, model.EditRuleRequest{
+		Id:       utint.StringToInt(ctx.Param("rule_id"), 0),
+		IsActive: &isActive,
+	})
+}
+
+func (hd handler) editRule(ctx *gin.Context, req model.EditRuleRequest) {
+	var (
+		errx serror.SError
+	)
+
+	defer func() {
+		if errx != nil {
+			log.Error(errx.Comments())
+		}
+	}()
+
+	if req.Id <= 0 {
+		errx = serror.New("Invalid rule_id")
+		response.ResultError(ctx, response.ErrorParamValidationFail, errx)
+		return
+	}
+
+	err := validator.New().Struct(req)
+	if err != nil {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[delivery][EditRule] while validate struct")
+		response.ResultError(ctx, response.ErrorPayloadValidationFail, err)
+		return
+	}
+
+	var res model.EditRuleResponse
+	res, errx = hd.ruleUsecase.EditRule(ctx.Request.Context(), req)
+	if errx != nil {
+		errx.AddCommentf("[delivery][EditRule] while edit rule")
+		if errx.Code() < 1 {
+			errx = serror.Newic(http.StatusInternalServerError, errx.Error(), errx.Comments())
+		}
+		response.ResultSError(ctx, errx)
+		return
+	}
+
+	response.ResultWithData(ctx, response.SuccessUpdated, res)
+	return
+}
+
+func (hd handler) DeleteRule(ctx *gin.Context) {
+	var (
+		errx serror.SError
+	)
+
+	defer func() {
+		if errx != nil {
+			log.Error(errx.Comments())
+		}
+	}()
+
+	log.Infof("DeleteRule invoked")
+
+	ruleId := utint.StringToInt(ctx.Param("rule_id"), 0)
+	if ruleId <= 0 {
+		errx = serror.New("Invalid rule_id")
+		response.ResultError(ctx, response.ErrorParamValidationFail, errx)
+		return
+	}
+
+	errx = hd.ruleUsecase.DeleteRule(ctx.Request.Context(), ruleId)
+	if errx != nil {
+		errx.AddCommentf("[delivery][DeleteRule] while delete rule")
+		if errx.Code() < 1 {
+			errx = serror.Newic(http.StatusInternalServerError, errx.Error(), errx.Comments())
+		}
+		response.ResultSError(ctx, errx)
+		return
+	}
+
+	response.Result(ctx, response.SuccessDeleted)
+	return
+}
@@ -0,0 +1,70 @@
+// Code generated by mockery v2.14.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	model "repo-scanner/internal/model"
+
+	mock "github.com/stretchr/testify/mock"
+
+	serror "repo-scanner/internal/utils/serror"
+)
+
+// IArchiveRepository is an autogenerated mock type for the IArchiveRepository type
+type IArchiveRepository struct {
+	mock.Mock
+}
+
+// Extract provides a mock function with given fields: _a0
+func (_m *IArchiveRepository) Extract(_a0 model.UploadArchive) (string, serror.SError) {
+	ret := _m.Called(_a0)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(model.UploadArchive) string); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 serror.SError
+	if rf, ok := ret.Get(1).(func(model.UploadArchive) serror.SError); ok {
+		r1 = rf(_a0)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(serror.SError)
+		}
+	}
+
+	return r0, r1
+}
+
+// Remove provides a mock function with given fields: dir
+func (_m *IArchiveRepository) Remove(dir string) serror.SError {
+	ret := _m.Called(dir)
+
+	var r0 serror.SError
+	if rf, ok := ret.Get(0).(func(string) serror.SError); ok {
+		r0 = rf(dir)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(serror.SError)
+		}
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewIArchiveRepository interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewIArchiveRepository creates a new instance of IArchiveRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewIArchiveRepository(t mockConstructorTestingTNewIArchiveRepository) *IArchiveRepository {
+	mock := &IArchiveRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,72 @@
+// Code generated by mockery v2.14.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+
+	model "repo-scanner/internal/model"
+
+	serror "repo-scanner/internal/utils/serror"
+)
+
+// IScanEngine is an autogenerated mock type for the IScanEngine type
+type IScanEngine struct {
+	mock.Mock
+}
+
+// Info provides a mock function with given fields:
+func (_m *IScanEngine) Info() model.ScanEngineInfo {
+	ret := _m.Called()
+
+	var r0 model.ScanEngineInfo
+	if rf, ok := ret.Get(0).(func() model.ScanEngineInfo); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(model.ScanEngineInfo)
+	}
+
+	return r0
+}
+
+// Scan provides a mock function with given fields: _a0, _a1
+func (_m *IScanEngine) Scan(_a0 context.Context, _a1 model.ScanTarget) ([]model.Finding, serror.SError) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 []model.Finding
+	if rf, ok := ret.Get(0).(func(context.Context, model.ScanTarget) []model.Finding); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Finding)
+		}
+	}
+
+	var r1 serror.SError
+	if rf, ok := ret.Get(1).(func(context.Context, model.ScanTarget) serror.SError); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(serror.SError)
+		}
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewIScanEngine interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewIScanEngine creates a new instance of IScanEngine. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewIScanEngine(t mockConstructorTestingTNewIScanEngine) *IScanEngine {
+	mock := &IScanEngine{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -3,9 +3,13 @@
 package mocks
 
 import (
-	serror "repo-scanner/internal/utils/serror"
+	context "context"
 
 	mock "github.com/stretchr/testify/mock"
+
+	model "repo-scanner/internal/model"
+
+	serror "repo-scanner/internal/utils/serror"
 )
 
 // IGrabScanner is an autogenerated mock type for the IGrabScanner type
@@ -13,22 +17,36 @@ type IGrabScanner struct {
 	mock.Mock
 }
 
-// StartScanningSession provides a mock function with given fields: _a0
-func (_m *IGrabScanner) StartScanningSession(_a0 string) ([]byte, serror.SError) {
-	ret := _m.Called(_a0)
+// GetScanEngines provides a mock function with given fields:
+func (_m *IGrabScanner) GetScanEngines() []model.ScanEngineInfo {
+	ret := _m.Called()
 
-	var r0 []byte
-	if rf, ok := ret.Get(0).(func(string) []byte); ok {
-		r0 = rf(_a0)
+	var r0 []model.ScanEngineInfo
+	if rf, ok := ret.Get(0).(func() []model.ScanEngineInfo); ok {
+		r0 = rf()
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]byte)
+			r0 = ret.Get(0).([]model.ScanEngineInfo)
 		}
 	}
 
+	return r0
+}
+
+// StartScanningSession provides a mock function with given fields: _a0, _a1
+func (_m *IGrabScanner) StartScanningSession(_a0 context.Context, _a1 model.ScanTarget) (model.ScanResult, serror.SError) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 model.ScanResult
+	if rf, ok := ret.Get(0).(func(context.Context, model.ScanTarget) model.ScanResult); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Get(0).(model.ScanResult)
+	}
+
 	var r1 serror.SError
-	if rf, ok := ret.Get(1).(func(string) serror.SError); ok {
-		r1 = rf(_a0)
+	if rf, ok := ret.Get(1).(func(context.Context, model.ScanTarget) serror.SError); ok {
+		r1 = rf(_a0, _a1)
 	} else {
 		if ret.Get(1) != nil {
 			r1 = ret.Get(1).(serror.SError)
@@ -3,11 +3,15 @@
 package mocks
 
 import (
-	model "repo-scanner/internal/model"
+	context "context"
 
 	mock "github.com/stretchr/testify/mock"
 
+	model "repo-scanner/internal/model"
+
 	serror "repo-scanner/internal/utils/serror"
+
+	time "time"
 )
 
 // IRepositoryRepository is an autogenerated mock type for the IRepositoryRepository type
@@ -15,20 +19,20 @@ type IRepositoryRepository struct {
 	mock.Mock
 }
 
-// AddRepository provides a mock function with given fields: _a0, _a1
-func (_m *IRepositoryRepository) AddRepository(_a0 *model.Trx, _a1 model.AddRepositoryRequest) (model.AddRepositoryResponse, serror.SError) {
-	ret := _m.Called(_a0, _a1)
+// AddRepository provides a mock function with given fields: _a0, _a1, _a2
+func (_m *IRepositoryRepository) AddRepository(_a0 context.Context, _a1 *model.Trx, _a2 model.AddRepositoryRequest) (model.AddRepositoryResponse, serror.SError) {
+	ret := _m.Called(_a0, _a1, _a2)
 
 	var r0 model.AddRepositoryResponse
-	if rf, ok := ret.Get(0).(func(*model.Trx, model.AddRepositoryRequest) model.AddRepositoryResponse); ok {
-		r0 = rf(_a0, _a1)
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Trx, model.AddRepositoryRequest) model.AddRepositoryResponse); ok {
+		r0 = rf(_a0, _a1, _a2)
 	} else {
 		r0 = ret.Get(0).(model.AddRepositoryResponse)
 	}
 
 	var r1 serror.SError
-	if rf, ok := ret.Get(1).(func(*model.Trx, model.AddRepositoryRequest) serror.SError); ok {
-		r1 = rf(_a0, _a1)
+	if rf, ok := ret.Get(1).(func(context.Context, *model.Trx, model.AddRepositoryRequest) serror.SError); ok {
+		r1 = rf(_a0, _a1, _a2)
 	} else {
 		if ret.Get(1) != nil {
 			r1 = ret.Get(1).(serror.SError)
@@ -38,13 +42,61 @@ func (_m *IRepositoryRepository) AddRepository(_a0 *model.Trx, _a1 model.AddRepo
 	return r0, r1
 }
 
-// DeleteRepository provides a mock function with given fields: _a0, _a1
-func (_m *IRepositoryRepository) DeleteRepository(_a0 *model.Trx, _a1 int64) serror.SError {
-	ret := _m.Called(_a0, _a1)
+// AdvanceRepositorySchedule provides a mock function with given fields: ctx, tx, repo_id, due_at, next_at
+func (_m *IRepositoryRepository) AdvanceRepositorySchedule(ctx context.Context, tx *model.Trx, repo_id int64, due_at time.Time, next_at time.Time) (bool, serror.SError) {
+	ret := _m.Called(ctx, tx, repo_id, due_at, next_at)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Trx, int64, time.Time, time.Time) bool); ok {
+		r0 = rf(ctx, tx, repo_id, due_at, next_at)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 serror.SError
+	if rf, ok := ret.Get(1).(func(context.Context, *model.Trx, int64, time.Time, time.Time) serror.SError); ok {
+		r1 = rf(ctx, tx, repo_id, due_at, next_at)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(serror.SError)
+		}
+	}
+
+	return r0, r1
+}
+
+// DeactivateUploadRepository provides a mock function with given fields: ctx, tx, repo_id
+func (_m *IRepositoryRepository) DeactivateUploadRepository(ctx context.Context, tx *model.Trx, repo_id int64) (*string, serror.SError) {
+	ret := _m.Called(ctx, tx, repo_id)
+
+	var r0 *string
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Trx, int64) *string); ok {
+		r0 = rf(ctx, tx, repo_id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*string)
+		}
+	}
+
+	var r1 serror.SError
+	if rf, ok := ret.Get(1).(func(context.Context, *model.Trx, int64) serror.SError); ok {
+		r1 = rf(ctx, tx, repo_id)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(serror.SError)
+		}
+	}
+
+	return r0, r1
+}
+
+// DeleteRepository provides a mock function with given fields: _a0, _a1, _a2
+func (_m *IRepositoryRepository) DeleteRepository(_a0 context.Context, _a1 *model.Trx, _a2 int64) serror.SError {
+	ret := _m.Called(_a0, _a1, _a2)
 
 	var r0 serror.SError
-	if rf, ok := ret.Get(0).(func(*model.Trx, int64) serror.SError); ok {
-		r0 = rf(_a0, _a1)
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Trx, int64) serror.SError); ok {
+		r0 = rf(_a0, _a1, _a2)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(serror.SError)
@@ -54,20 +106,70 @@ func (_m *IRepositoryRepository) DeleteRepository(_a0 *model.Trx, _a1 int64) ser
 	return r0
 }
 
-// EditRepository provides a mock function with given fields: _a0, _a1
-func (_m *IRepositoryRepository) EditRepository(_a0 *model.Trx, _a1 model.EditRepositoryRequest) (model.EditRepositoryResponse, serror.SError) {
-	ret := _m.Called(_a0, _a1)
+// EditRepository provides a mock function with given fields: _a0, _a1, _a2
+func (_m *IRepositoryRepository) EditRepository(_a0 context.Context, _a1 *model.Trx, _a2 model.EditRepositoryRequest) (model.EditRepositoryResponse, serror.SError) {
+	ret := _m.Called(_a0, _a1, _a2)
 
 	var r0 model.EditRepositoryResponse
-	if rf, ok := ret.Get(0).(func(*model.Trx, model.EditRepositoryRequest) model.EditRepositoryResponse); ok {
-		r0 = rf(_a0, _a1)
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Trx, model.EditRepositoryRequest) model.EditRepositoryResponse); ok {
+		r0 = rf(_a0, _a1, _a2)
 	} else {
 		r0 = ret.Get(0).(model.EditRepositoryResponse)
 	}
 
 	var r1 serror.SError
-	if rf, ok := ret.Get(1).(func(*model.Trx, model.EditRepositoryRequest) serror.SError); ok {
-		r1 = rf(_a0, _a1)
+	if rf, ok := ret.Get(1).(func(context.Context, *model.Trx, model.EditRepositoryRequest) serror.SError); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(serror.SError)
+		}
+	}
+
+	return r0, r1
+}
+
+// EditRepositorySchedule provides a mock function with given fields: ctx, tx, repo_id, schedule
+func (_m *IRepositoryRepository) EditRepositorySchedule(ctx context.Context, tx *model.Trx, repo_id int64, schedule model.RepositorySchedule) (*model.RepositorySchedule, serror.SError) {
+	ret := _m.Called(ctx, tx, repo_id, schedule)
+
+	var r0 *model.RepositorySchedule
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Trx, int64, model.RepositorySchedule) *model.RepositorySchedule); ok {
+		r0 = rf(ctx, tx, repo_id, schedule)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.RepositorySchedule)
+		}
+	}
+
+	var r1 serror.SError
+	if rf, ok := ret.Get(1).(func(context.Context, *model.Trx, int64, model.RepositorySchedule) serror.SError); ok {
+		r1 = rf(ctx, tx, repo_id, schedule)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(serror.SError)
+		}
+	}
+
+	return r0, r1
+}
+
+// EditRepositorySettings provides a mock function with given fields: ctx, tx, repo_id, settings
+func (_m *IRepositoryRepository) EditRepositorySettings(ctx context.Context, tx *model.Trx, repo_id int64, settings model.RepositorySettings) (*model.RepositorySettings, serror.SError) {
+	ret := _m.Called(ctx, tx, repo_id, settings)
+
+	var r0 *model.RepositorySettings
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Trx, int64, model.RepositorySettings) *model.RepositorySettings); ok {
+		r0 = rf(ctx, tx, repo_id, settings)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.RepositorySettings)
+		}
+	}
+
+	var r1 serror.SError
+	if rf, ok := ret.Get(1).(func(context.Context, *model.Trx, int64, model.RepositorySettings) serror.SError); ok {
+		r1 = rf(ctx, tx, repo_id, settings)
 	} else {
 		if ret.Get(1) != nil {
 			r1 = ret.Get(1).(serror.SError)
@@ -77,13 +179,13 @@ func (_m *IRepositoryRepository) EditRepository(_a0 *model.Trx, _a1 model.EditRe
 	return r0, r1
 }
 
-// GetRepositoryById provides a mock function with given fields: repo_id
-func (_m *IRepositoryRepository) GetRepositoryById(repo_id int64) (*model.Repository, serror.SError) {
-	ret := _m.Called(repo_id)
+// GetRepositoryById provides a mock function with given fields: ctx, repo_id
+func (_m *IRepositoryRepository) GetRepositoryById(ctx context.Context, repo_id int64) (*model.Repository, serror.SError) {
+	ret := _m.Called(ctx, repo_id)
 
 	var r0 *model.Repository
-	if rf, ok := ret.Get(0).(func(int64) *model.Repository); ok {
-		r0 = rf(repo_id)
+	if rf, ok := ret.Get(0).(func(context.Context, int64) *model.Repository); ok {
+		r0 = rf(ctx, repo_id)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*model.Repository)
@@ -91,8 +193,8 @@ func (_m *IRepositoryRepository) GetRepositoryById(repo_id int64) (*model.Reposi
 	}
 
 	var r1 serror.SError
-	if rf, ok := ret.Get(1).(func(int64) serror.SError); ok {
-		r1 = rf(repo_id)
+	if rf, ok := ret.Get(1).(func(context.Context, int64) serror.SError); ok {
+		r1 = rf(ctx, repo_id)
 	} else {
 		if ret.Get(1) != nil {
 			r1 = ret.Get(1).(serror.SError)
@@ -102,13 +204,13 @@ func (_m *IRepositoryRepository) GetRepositoryById(repo_id int64) (*model.Reposi
 	return r0, r1
 }
 
-// GetRepositoryList provides a mock function with given fields: _a0
-func (_m *IRepositoryRepository) GetRepositoryList(_a0 model.RepositoryListRequest) ([]model.RepositoryListResponse, serror.SError) {
-	ret := _m.Called(_a0)
+// GetRepositoryList provides a mock function with given fields: _a0, _a1
+func (_m *IRepositoryRepository) GetRepositoryList(_a0 context.Context, _a1 model.RepositoryListRequest) ([]model.RepositoryListResponse, serror.SError) {
+	ret := _m.Called(_a0, _a1)
 
 	var r0 []model.RepositoryListResponse
-	if rf, ok := ret.Get(0).(func(model.RepositoryListRequest) []model.RepositoryListResponse); ok {
-		r0 = rf(_a0)
+	if rf, ok := ret.Get(0).(func(context.Context, model.RepositoryListRequest) []model.RepositoryListResponse); ok {
+		r0 = rf(_a0, _a1)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]model.RepositoryListResponse)
@@ -116,8 +218,83 @@ func (_m *IRepositoryRepository) GetRepositoryList(_a0 model.RepositoryListReque
 	}
 
 	var r1 serror.SError
-	if rf, ok := ret.Get(1).(func(model.RepositoryListRequest) serror.SError); ok {
-		r1 = rf(_a0)
+	if rf, ok := ret.Get(1).(func(context.Context, model.RepositoryListRequest) serror.SError); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(serror.SError)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetRepositorySchedule provides a mock function with given fields: ctx, repo_id
+func (_m *IRepositoryRepository) GetRepositorySchedule(ctx context.Context, repo_id int64) (*model.RepositorySchedule, serror.SError) {
+	ret := _m.Called(ctx, repo_id)
+
+	var r0 *model.RepositorySchedule
+	if rf, ok := ret.Get(0).(func(context.Context, int64) *model.RepositorySchedule); ok {
+		r0 = rf(ctx, repo_id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.RepositorySchedule)
+		}
+	}
+
+	var r1 serror.SError
+	if rf, ok := ret.Get(1).(func(context.Context, int64) serror.SError); ok {
+		r1 = rf(ctx, repo_id)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(serror.SError)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetRepositorySettings provides a mock function with given fields: ctx, repo_id
+func (_m *IRepositoryRepository) GetRepositorySettings(ctx context.Context, repo_id int64) (*model.RepositorySettings, serror.SError) {
+	ret := _m.Called(ctx, repo_id)
+
+	var r0 *model.RepositorySettings
+	if rf, ok := ret.Get(0).(func(context.Context, int64) *model.RepositorySettings); ok {
+		r0 = rf(ctx, repo_id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.RepositorySettings)
+		}
+	}
+
+	var r1 serror.SError
+	if rf, ok := ret.Get(1).(func(context.Context, int64) serror.SError); ok {
+		r1 = rf(ctx, repo_id)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(serror.SError)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetScheduledRepositories provides a mock function with given fields: ctx
+func (_m *IRepositoryRepository) GetScheduledRepositories(ctx context.Context) ([]model.ScheduledRepository, serror.SError) {
+	ret := _m.Called(ctx)
+
+	var r0 []model.ScheduledRepository
+	if rf, ok := ret.Get(0).(func(context.Context) []model.ScheduledRepository); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.ScheduledRepository)
+		}
+	}
+
+	var r1 serror.SError
+	if rf, ok := ret.Get(1).(func(context.Context) serror.SError); ok {
+		r1 = rf(ctx)
 	} else {
 		if ret.Get(1) != nil {
 			r1 = ret.Get(1).(serror.SError)
@@ -127,6 +304,22 @@ func (_m *IRepositoryRepository) GetRepositoryList(_a0 model.RepositoryListReque
 	return r0, r1
 }
 
+// LockRepository provides a mock function with given fields: ctx, tx, repo_id
+func (_m *IRepositoryRepository) LockRepository(ctx context.Context, tx *model.Trx, repo_id int64) serror.SError {
+	ret := _m.Called(ctx, tx, repo_id)
+
+	var r0 serror.SError
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Trx, int64) serror.SError); ok {
+		r0 = rf(ctx, tx, repo_id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(serror.SError)
+		}
+	}
+
+	return r0
+}
+
 type mockConstructorTestingTNewIRepositoryRepository interface {
 	mock.TestingT
 	Cleanup(func())
@@ -0,0 +1,170 @@
+// Code generated by mockery v2.14.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+
+	model "repo-scanner/internal/model"
+
+	serror "repo-scanner/internal/utils/serror"
+)
+
+// IRuleRepository is an autogenerated mock type for the IRuleRepository type
+type IRuleRepository struct {
+	mock.Mock
+}
+
+// AddRule provides a mock function with given fields: _a0, _a1, _a2
+func (_m *IRuleRepository) AddRule(_a0 context.Context, _a1 *model.Trx, _a2 model.AddRuleRequest) (model.AddRuleResponse, serror.SError) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 model.AddRuleResponse
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Trx, model.AddRuleRequest) model.AddRuleResponse); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Get(0).(model.AddRuleResponse)
+	}
+
+	var r1 serror.SError
+	if rf, ok := ret.Get(1).(func(context.Context, *model.Trx, model.AddRuleRequest) serror.SError); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(serror.SError)
+		}
+	}
+
+	return r0, r1
+}
+
+// DeleteRule provides a mock function with given fields: _a0, _a1, _a2
+func (_m *IRuleRepository) DeleteRule(_a0 context.Context, _a1 *model.Trx, _a2 int64) serror.SError {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 serror.SError
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Trx, int64) serror.SError); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(serror.SError)
+		}
+	}
+
+	return r0
+}
+
+// EditRule provides a mock function with given fields: _a0, _a1, _a2
+func (_m *IRuleRepository) EditRule(_a0 context.Context, _a1 *model.Trx, _a2 model.EditRuleRequest) (model.EditRuleResponse, serror.SError) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 model.EditRuleResponse
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Trx, model.EditRuleRequest) model.EditRuleResponse); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Get(0).(model.EditRuleResponse)
+	}
+
+	var r1 serror.SError
+	if rf, ok := ret.Get(1).(func(context.Context, *model.Trx, model.EditRuleRequest) serror.SError); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(serror.SError)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetActiveRules provides a mock function with given fields: _a0
+func (_m *IRuleRepository) GetActiveRules(_a0 context.Context) ([]model.RuleListResponse, serror.SError) {
+	ret := _m.Called(_a0)
+
+	var r0 []model.RuleListResponse
+	if rf, ok := ret.Get(0).(func(context.Context) []model.RuleListResponse); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.RuleListResponse)
+		}
+	}
+
+	var r1 serror.SError
+	if rf, ok := ret.Get(1).(func(context.Context) serror.SError); ok {
+		r1 = rf(_a0)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(serror.SError)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetRuleById provides a mock function with given fields: ctx, rule_id
+func (_m *IRuleRepository) GetRuleById(ctx context.Context, rule_id int64) (*model.Rule, serror.SError) {
+	ret := _m.Called(ctx, rule_id)
+
+	var r0 *model.Rule
+	if rf, ok := ret.Get(0).(func(context.Context, int64) *model.Rule); ok {
+		r0 = rf(ctx, rule_id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Rule)
+		}
+	}
+
+	var r1 serror.SError
+	if rf, ok := ret.Get(1).(func(context.Context, int64) serror.SError); ok {
+		r1 = rf(ctx, rule_id)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(serror.SError)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetRuleList provides a mock function with given fields: _a0, _a1
+func (_m *IRuleRepository) GetRuleList(_a0 context.Context, _a1 model.RuleListRequest) ([]model.RuleListResponse, serror.SError) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 []model.RuleListResponse
+	if rf, ok := ret.Get(0).(func(context.Context, model.RuleListRequest) []model.RuleListResponse); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.RuleListResponse)
+		}
+	}
+
+	var r1 serror.SError
+	if rf, ok := ret.Get(1).(func(context.Context, model.RuleListRequest) serror.SError); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(serror.SError)
+		}
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewIRuleRepository interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewIRuleRepository creates a new instance of IRuleRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewIRuleRepository(t mockConstructorTestingTNewIRuleRepository) *IRuleRepository {
+	mock := &IRuleRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -3,13 +3,15 @@
 package mocks
 
 import (
-	model "repo-scanner/internal/model"
+	context "context"
 
 	mock "github.com/stretchr/testify/mock"
 
+	model "repo-scanner/internal/model"
+
 	serror "repo-scanner/internal/utils/serror"
 
-	types "github.com/jmoiron/sqlx/types"
+	time "time"
 )
 
 // IScanningRepository is an autogenerated mock type for the IScanningRepository type
@@ -17,20 +19,251 @@ type IScanningRepository struct {
 	mock.Mock
 }
 
-// AddNewScanning provides a mock function with given fields: _a0, _a1
-func (_m *IScanningRepository) AddNewScanning(_a0 *model.Trx, _a1 int64) (model.ScanningResponse, serror.SError) {
-	ret := _m.Called(_a0, _a1)
+// ActivateBaseline provides a mock function with given fields: _a0, _a1, _a2
+func (_m *IScanningRepository) ActivateBaseline(_a0 context.Context, _a1 *model.Trx, _a2 model.ActivateBaselineRequest) (*model.BaselineResponse, serror.SError) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 *model.BaselineResponse
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Trx, model.ActivateBaselineRequest) *model.BaselineResponse); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.BaselineResponse)
+		}
+	}
+
+	var r1 serror.SError
+	if rf, ok := ret.Get(1).(func(context.Context, *model.Trx, model.ActivateBaselineRequest) serror.SError); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(serror.SError)
+		}
+	}
+
+	return r0, r1
+}
+
+// AddBaseline provides a mock function with given fields: _a0, _a1, _a2
+func (_m *IScanningRepository) AddBaseline(_a0 context.Context, _a1 *model.Trx, _a2 model.AddBaselineRequest) (model.BaselineResponse, serror.SError) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 model.BaselineResponse
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Trx, model.AddBaselineRequest) model.BaselineResponse); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Get(0).(model.BaselineResponse)
+	}
+
+	var r1 serror.SError
+	if rf, ok := ret.Get(1).(func(context.Context, *model.Trx, model.AddBaselineRequest) serror.SError); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(serror.SError)
+		}
+	}
+
+	return r0, r1
+}
+
+// AddFindingComment provides a mock function with given fields: _a0, _a1, _a2
+func (_m *IScanningRepository) AddFindingComment(_a0 context.Context, _a1 *model.Trx, _a2 model.AddFindingCommentRequest) (model.FindingComment, serror.SError) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 model.FindingComment
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Trx, model.AddFindingCommentRequest) model.FindingComment); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Get(0).(model.FindingComment)
+	}
+
+	var r1 serror.SError
+	if rf, ok := ret.Get(1).(func(context.Context, *model.Trx, model.AddFindingCommentRequest) serror.SError); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(serror.SError)
+		}
+	}
+
+	return r0, r1
+}
+
+// AddFindingFingerprints provides a mock function with given fields: _a0, _a1, _a2
+func (_m *IScanningRepository) AddFindingFingerprints(_a0 context.Context, _a1 *model.Trx, _a2 []model.FindingFingerprint) serror.SError {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 serror.SError
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Trx, []model.FindingFingerprint) serror.SError); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(serror.SError)
+		}
+	}
+
+	return r0
+}
+
+// AddFindingReveal provides a mock function with given fields: _a0, _a1, _a2
+func (_m *IScanningRepository) AddFindingReveal(_a0 context.Context, _a1 *model.Trx, _a2 model.RevealFindingRequest) (model.RevealFindingResponse, serror.SError) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 model.RevealFindingResponse
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Trx, model.RevealFindingRequest) model.RevealFindingResponse); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Get(0).(model.RevealFindingResponse)
+	}
+
+	var r1 serror.SError
+	if rf, ok := ret.Get(1).(func(context.Context, *model.Trx, model.RevealFindingRequest) serror.SError); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(serror.SError)
+		}
+	}
+
+	return r0, r1
+}
+
+// AddFindingSecrets provides a mock function with given fields: _a0, _a1, _a2
+func (_m *IScanningRepository) AddFindingSecrets(_a0 context.Context, _a1 *model.Trx, _a2 []model.FindingSecret) serror.SError {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 serror.SError
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Trx, []model.FindingSecret) serror.SError); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(serror.SError)
+		}
+	}
+
+	return r0
+}
+
+// AddFindings provides a mock function with given fields: ctx, tx, scanning_id, findings
+func (_m *IScanningRepository) AddFindings(ctx context.Context, tx *model.Trx, scanning_id int64, findings []model.Finding) serror.SError {
+	ret := _m.Called(ctx, tx, scanning_id, findings)
+
+	var r0 serror.SError
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Trx, int64, []model.Finding) serror.SError); ok {
+		r0 = rf(ctx, tx, scanning_id, findings)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(serror.SError)
+		}
+	}
+
+	return r0
+}
+
+// AddNewScanning provides a mock function with given fields: _a0, _a1, _a2
+func (_m *IScanningRepository) AddNewScanning(_a0 context.Context, _a1 *model.Trx, _a2 model.AddScanningRequest) (model.ScanningResponse, serror.SError) {
+	ret := _m.Called(_a0, _a1, _a2)
 
 	var r0 model.ScanningResponse
-	if rf, ok := ret.Get(0).(func(*model.Trx, int64) model.ScanningResponse); ok {
-		r0 = rf(_a0, _a1)
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Trx, model.AddScanningRequest) model.ScanningResponse); ok {
+		r0 = rf(_a0, _a1, _a2)
 	} else {
 		r0 = ret.Get(0).(model.ScanningResponse)
 	}
 
 	var r1 serror.SError
-	if rf, ok := ret.Get(1).(func(*model.Trx, int64) serror.SError); ok {
-		r1 = rf(_a0, _a1)
+	if rf, ok := ret.Get(1).(func(context.Context, *model.Trx, model.AddScanningRequest) serror.SError); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(serror.SError)
+		}
+	}
+
+	return r0, r1
+}
+
+// CancelScanningById provides a mock function with given fields: _a0, _a1, _a2
+func (_m *IScanningRepository) CancelScanningById(_a0 context.Context, _a1 *model.Trx, _a2 int64) (*model.ScanningResponse, serror.SError) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 *model.ScanningResponse
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Trx, int64) *model.ScanningResponse); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.ScanningResponse)
+		}
+	}
+
+	var r1 serror.SError
+	if rf, ok := ret.Get(1).(func(context.Context, *model.Trx, int64) serror.SError); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(serror.SError)
+		}
+	}
+
+	return r0, r1
+}
+
+// ClaimScanning provides a mock function with given fields: ctx, claimed_by, lease, aging
+func (_m *IScanningRepository) ClaimScanning(ctx context.Context, claimed_by string, lease time.Duration, aging time.Duration) (*model.ScanningListResponse, serror.SError) {
+	ret := _m.Called(ctx, claimed_by, lease, aging)
+
+	var r0 *model.ScanningListResponse
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Duration, time.Duration) *model.ScanningListResponse); ok {
+		r0 = rf(ctx, claimed_by, lease, aging)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.ScanningListResponse)
+		}
+	}
+
+	var r1 serror.SError
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Duration, time.Duration) serror.SError); ok {
+		r1 = rf(ctx, claimed_by, lease, aging)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(serror.SError)
+		}
+	}
+
+	return r0, r1
+}
+
+// CopyFindingSecrets provides a mock function with given fields: _a0, _a1, _a2
+func (_m *IScanningRepository) CopyFindingSecrets(_a0 context.Context, _a1 *model.Trx, _a2 model.CopyFindingSecretsRequest) serror.SError {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 serror.SError
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Trx, model.CopyFindingSecretsRequest) serror.SError); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(serror.SError)
+		}
+	}
+
+	return r0
+}
+
+// EditFindingTriage provides a mock function with given fields: _a0, _a1, _a2
+func (_m *IScanningRepository) EditFindingTriage(_a0 context.Context, _a1 *model.Trx, _a2 model.TriageFindingRequest) (model.FindingTriageResponse, serror.SError) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 model.FindingTriageResponse
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Trx, model.TriageFindingRequest) model.FindingTriageResponse); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Get(0).(model.FindingTriageResponse)
+	}
+
+	var r1 serror.SError
+	if rf, ok := ret.Get(1).(func(context.Context, *model.Trx, model.TriageFindingRequest) serror.SError); ok {
+		r1 = rf(_a0, _a1, _a2)
 	} else {
 		if ret.Get(1) != nil {
 			r1 = ret.Get(1).(serror.SError)
@@ -40,20 +273,95 @@ func (_m *IScanningRepository) AddNewScanning(_a0 *model.Trx, _a1 int64) (model.
 	return r0, r1
 }
 
-// EditScanningStatusById provides a mock function with given fields: _a0, _a1, _a2, _a3
-func (_m *IScanningRepository) EditScanningStatusById(_a0 *model.Trx, _a1 int64, _a2 string, _a3 types.JSONText) (model.ScanningResponse, serror.SError) {
-	ret := _m.Called(_a0, _a1, _a2, _a3)
+// EditScanningStatusById provides a mock function with given fields: _a0, _a1, _a2
+func (_m *IScanningRepository) EditScanningStatusById(_a0 context.Context, _a1 *model.Trx, _a2 model.EditScanningStatusRequest) (model.ScanningResponse, serror.SError) {
+	ret := _m.Called(_a0, _a1, _a2)
 
 	var r0 model.ScanningResponse
-	if rf, ok := ret.Get(0).(func(*model.Trx, int64, string, types.JSONText) model.ScanningResponse); ok {
-		r0 = rf(_a0, _a1, _a2, _a3)
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Trx, model.EditScanningStatusRequest) model.ScanningResponse); ok {
+		r0 = rf(_a0, _a1, _a2)
 	} else {
 		r0 = ret.Get(0).(model.ScanningResponse)
 	}
 
 	var r1 serror.SError
-	if rf, ok := ret.Get(1).(func(*model.Trx, int64, string, types.JSONText) serror.SError); ok {
-		r1 = rf(_a0, _a1, _a2, _a3)
+	if rf, ok := ret.Get(1).(func(context.Context, *model.Trx, model.EditScanningStatusRequest) serror.SError); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(serror.SError)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetActiveBaseline provides a mock function with given fields: ctx, repo_id
+func (_m *IScanningRepository) GetActiveBaseline(ctx context.Context, repo_id int64) (*model.BaselineResponse, serror.SError) {
+	ret := _m.Called(ctx, repo_id)
+
+	var r0 *model.BaselineResponse
+	if rf, ok := ret.Get(0).(func(context.Context, int64) *model.BaselineResponse); ok {
+		r0 = rf(ctx, repo_id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.BaselineResponse)
+		}
+	}
+
+	var r1 serror.SError
+	if rf, ok := ret.Get(1).(func(context.Context, int64) serror.SError); ok {
+		r1 = rf(ctx, repo_id)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(serror.SError)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetBaselineFingerprints provides a mock function with given fields: ctx, baseline_id
+func (_m *IScanningRepository) GetBaselineFingerprints(ctx context.Context, baseline_id int64) ([]string, serror.SError) {
+	ret := _m.Called(ctx, baseline_id)
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []string); ok {
+		r0 = rf(ctx, baseline_id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	var r1 serror.SError
+	if rf, ok := ret.Get(1).(func(context.Context, int64) serror.SError); ok {
+		r1 = rf(ctx, baseline_id)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(serror.SError)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetBaselineList provides a mock function with given fields: ctx, repo_id
+func (_m *IScanningRepository) GetBaselineList(ctx context.Context, repo_id int64) ([]model.BaselineResponse, serror.SError) {
+	ret := _m.Called(ctx, repo_id)
+
+	var r0 []model.BaselineResponse
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []model.BaselineResponse); ok {
+		r0 = rf(ctx, repo_id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.BaselineResponse)
+		}
+	}
+
+	var r1 serror.SError
+	if rf, ok := ret.Get(1).(func(context.Context, int64) serror.SError); ok {
+		r1 = rf(ctx, repo_id)
 	} else {
 		if ret.Get(1) != nil {
 			r1 = ret.Get(1).(serror.SError)
@@ -63,13 +371,188 @@ func (_m *IScanningRepository) EditScanningStatusById(_a0 *model.Trx, _a1 int64,
 	return r0, r1
 }
 
-// GetScanningList provides a mock function with given fields: _a0
-func (_m *IScanningRepository) GetScanningList(_a0 model.ScanningListRequest) ([]model.ScanningListResponse, serror.SError) {
-	ret := _m.Called(_a0)
+// GetFindingById provides a mock function with given fields: ctx, scanning_id, finding_id
+func (_m *IScanningRepository) GetFindingById(ctx context.Context, scanning_id int64, finding_id string) (*model.FindingListResponse, serror.SError) {
+	ret := _m.Called(ctx, scanning_id, finding_id)
+
+	var r0 *model.FindingListResponse
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) *model.FindingListResponse); ok {
+		r0 = rf(ctx, scanning_id, finding_id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.FindingListResponse)
+		}
+	}
+
+	var r1 serror.SError
+	if rf, ok := ret.Get(1).(func(context.Context, int64, string) serror.SError); ok {
+		r1 = rf(ctx, scanning_id, finding_id)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(serror.SError)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetFindingComments provides a mock function with given fields: ctx, repo_id, fingerprint
+func (_m *IScanningRepository) GetFindingComments(ctx context.Context, repo_id int64, fingerprint string) ([]model.FindingComment, serror.SError) {
+	ret := _m.Called(ctx, repo_id, fingerprint)
+
+	var r0 []model.FindingComment
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) []model.FindingComment); ok {
+		r0 = rf(ctx, repo_id, fingerprint)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.FindingComment)
+		}
+	}
+
+	var r1 serror.SError
+	if rf, ok := ret.Get(1).(func(context.Context, int64, string) serror.SError); ok {
+		r1 = rf(ctx, repo_id, fingerprint)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(serror.SError)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetFindingList provides a mock function with given fields: _a0, _a1
+func (_m *IScanningRepository) GetFindingList(_a0 context.Context, _a1 model.FindingListRequest) ([]model.FindingListResponse, serror.SError) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 []model.FindingListResponse
+	if rf, ok := ret.Get(0).(func(context.Context, model.FindingListRequest) []model.FindingListResponse); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.FindingListResponse)
+		}
+	}
+
+	var r1 serror.SError
+	if rf, ok := ret.Get(1).(func(context.Context, model.FindingListRequest) serror.SError); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(serror.SError)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetFindingSecret provides a mock function with given fields: ctx, scanning_id, finding_id
+func (_m *IScanningRepository) GetFindingSecret(ctx context.Context, scanning_id int64, finding_id string) (*model.FindingSecret, serror.SError) {
+	ret := _m.Called(ctx, scanning_id, finding_id)
+
+	var r0 *model.FindingSecret
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) *model.FindingSecret); ok {
+		r0 = rf(ctx, scanning_id, finding_id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.FindingSecret)
+		}
+	}
+
+	var r1 serror.SError
+	if rf, ok := ret.Get(1).(func(context.Context, int64, string) serror.SError); ok {
+		r1 = rf(ctx, scanning_id, finding_id)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(serror.SError)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetLastSuccessfulScanning provides a mock function with given fields: _a0, _a1, _a2
+func (_m *IScanningRepository) GetLastSuccessfulScanning(_a0 context.Context, _a1 int64, _a2 string) (*model.ScanningResponse, serror.SError) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 *model.ScanningResponse
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) *model.ScanningResponse); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.ScanningResponse)
+		}
+	}
+
+	var r1 serror.SError
+	if rf, ok := ret.Get(1).(func(context.Context, int64, string) serror.SError); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(serror.SError)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetPreviousScanning provides a mock function with given fields: ctx, repo_id, ref, scanning_id
+func (_m *IScanningRepository) GetPreviousScanning(ctx context.Context, repo_id int64, ref string, scanning_id int64) (*model.ScanningResponse, serror.SError) {
+	ret := _m.Called(ctx, repo_id, ref, scanning_id)
+
+	var r0 *model.ScanningResponse
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string, int64) *model.ScanningResponse); ok {
+		r0 = rf(ctx, repo_id, ref, scanning_id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.ScanningResponse)
+		}
+	}
+
+	var r1 serror.SError
+	if rf, ok := ret.Get(1).(func(context.Context, int64, string, int64) serror.SError); ok {
+		r1 = rf(ctx, repo_id, ref, scanning_id)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(serror.SError)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetScanningById provides a mock function with given fields: ctx, scanning_id
+func (_m *IScanningRepository) GetScanningById(ctx context.Context, scanning_id int64) (*model.ScanningResponse, serror.SError) {
+	ret := _m.Called(ctx, scanning_id)
+
+	var r0 *model.ScanningResponse
+	if rf, ok := ret.Get(0).(func(context.Context, int64) *model.ScanningResponse); ok {
+		r0 = rf(ctx, scanning_id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.ScanningResponse)
+		}
+	}
+
+	var r1 serror.SError
+	if rf, ok := ret.Get(1).(func(context.Context, int64) serror.SError); ok {
+		r1 = rf(ctx, scanning_id)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(serror.SError)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetScanningList provides a mock function with given fields: _a0, _a1
+func (_m *IScanningRepository) GetScanningList(_a0 context.Context, _a1 model.ScanningListRequest) ([]model.ScanningListResponse, serror.SError) {
+	ret := _m.Called(_a0, _a1)
 
 	var r0 []model.ScanningListResponse
-	if rf, ok := ret.Get(0).(func(model.ScanningListRequest) []model.ScanningListResponse); ok {
-		r0 = rf(_a0)
+	if rf, ok := ret.Get(0).(func(context.Context, model.ScanningListRequest) []model.ScanningListResponse); ok {
+		r0 = rf(_a0, _a1)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]model.ScanningListResponse)
@@ -77,8 +560,56 @@ func (_m *IScanningRepository) GetScanningList(_a0 model.ScanningListRequest) ([
 	}
 
 	var r1 serror.SError
-	if rf, ok := ret.Get(1).(func(model.ScanningListRequest) serror.SError); ok {
-		r1 = rf(_a0)
+	if rf, ok := ret.Get(1).(func(context.Context, model.ScanningListRequest) serror.SError); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(serror.SError)
+		}
+	}
+
+	return r0, r1
+}
+
+// ReapExpiredScannings provides a mock function with given fields: ctx
+func (_m *IScanningRepository) ReapExpiredScannings(ctx context.Context) ([]model.ReapedScanning, serror.SError) {
+	ret := _m.Called(ctx)
+
+	var r0 []model.ReapedScanning
+	if rf, ok := ret.Get(0).(func(context.Context) []model.ReapedScanning); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.ReapedScanning)
+		}
+	}
+
+	var r1 serror.SError
+	if rf, ok := ret.Get(1).(func(context.Context) serror.SError); ok {
+		r1 = rf(ctx)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(serror.SError)
+		}
+	}
+
+	return r0, r1
+}
+
+// RenewScanningLease provides a mock function with given fields: ctx, scanning_id, claimed_by, lease
+func (_m *IScanningRepository) RenewScanningLease(ctx context.Context, scanning_id int64, claimed_by string, lease time.Duration) (bool, serror.SError) {
+	ret := _m.Called(ctx, scanning_id, claimed_by, lease)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string, time.Duration) bool); ok {
+		r0 = rf(ctx, scanning_id, claimed_by, lease)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 serror.SError
+	if rf, ok := ret.Get(1).(func(context.Context, int64, string, time.Duration) serror.SError); ok {
+		r1 = rf(ctx, scanning_id, claimed_by, lease)
 	} else {
 		if ret.Get(1) != nil {
 			r1 = ret.Get(1).(serror.SError)
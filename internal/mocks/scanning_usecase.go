@@ -0,0 +1,381 @@
+// Code generated by mockery v2.14.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+
+	model "repo-scanner/internal/model"
+
+	serror "repo-scanner/internal/utils/serror"
+)
+
+// IScanningUsecase is an autogenerated mock type for the IScanningUsecase type
+type IScanningUsecase struct {
+	mock.Mock
+}
+
+// ActivateBaseline provides a mock function with given fields: _a0, _a1
+func (_m *IScanningUsecase) ActivateBaseline(_a0 context.Context, _a1 model.ActivateBaselineRequest) (model.BaselineResponse, serror.SError) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 model.BaselineResponse
+	if rf, ok := ret.Get(0).(func(context.Context, model.ActivateBaselineRequest) model.BaselineResponse); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Get(0).(model.BaselineResponse)
+	}
+
+	var r1 serror.SError
+	if rf, ok := ret.Get(1).(func(context.Context, model.ActivateBaselineRequest) serror.SError); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(serror.SError)
+		}
+	}
+
+	return r0, r1
+}
+
+// AddBaseline provides a mock function with given fields: _a0, _a1
+func (_m *IScanningUsecase) AddBaseline(_a0 context.Context, _a1 model.AddBaselineRequest) (model.BaselineResponse, serror.SError) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 model.BaselineResponse
+	if rf, ok := ret.Get(0).(func(context.Context, model.AddBaselineRequest) model.BaselineResponse); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Get(0).(model.BaselineResponse)
+	}
+
+	var r1 serror.SError
+	if rf, ok := ret.Get(1).(func(context.Context, model.AddBaselineRequest) serror.SError); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(serror.SError)
+		}
+	}
+
+	return r0, r1
+}
+
+// AddFindingComment provides a mock function with given fields: _a0, _a1
+func (_m *IScanningUsecase) AddFindingComment(_a0 context.Context, _a1 model.AddFindingCommentRequest) (model.FindingComment, serror.SError) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 model.FindingComment
+	if rf, ok := ret.Get(0).(func(context.Context, model.AddFindingCommentRequest) model.FindingComment); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Get(0).(model.FindingComment)
+	}
+
+	var r1 serror.SError
+	if rf, ok := ret.Get(1).(func(context.Context, model.AddFindingCommentRequest) serror.SError); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(serror.SError)
+		}
+	}
+
+	return r0, r1
+}
+
+// AddNewScanning provides a mock function with given fields: _a0, _a1
+func (_m *IScanningUsecase) AddNewScanning(_a0 context.Context, _a1 model.AddScanningRequest) (model.ScanningResponse, serror.SError) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 model.ScanningResponse
+	if rf, ok := ret.Get(0).(func(context.Context, model.AddScanningRequest) model.ScanningResponse); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Get(0).(model.ScanningResponse)
+	}
+
+	var r1 serror.SError
+	if rf, ok := ret.Get(1).(func(context.Context, model.AddScanningRequest) serror.SError); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(serror.SError)
+		}
+	}
+
+	return r0, r1
+}
+
+// AddUploadScanning provides a mock function with given fields: _a0, _a1
+func (_m *IScanningUsecase) AddUploadScanning(_a0 context.Context, _a1 model.AddUploadScanningRequest) (model.ScanningResponse, serror.SError) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 model.ScanningResponse
+	if rf, ok := ret.Get(0).(func(context.Context, model.AddUploadScanningRequest) model.ScanningResponse); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Get(0).(model.ScanningResponse)
+	}
+
+	var r1 serror.SError
+	if rf, ok := ret.Get(1).(func(context.Context, model.AddUploadScanningRequest) serror.SError); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(serror.SError)
+		}
+	}
+
+	return r0, r1
+}
+
+// CancelScanning provides a mock function with given fields: ctx, scanning_id
+func (_m *IScanningUsecase) CancelScanning(ctx context.Context, scanning_id int64) (model.ScanningResponse, serror.SError) {
+	ret := _m.Called(ctx, scanning_id)
+
+	var r0 model.ScanningResponse
+	if rf, ok := ret.Get(0).(func(context.Context, int64) model.ScanningResponse); ok {
+		r0 = rf(ctx, scanning_id)
+	} else {
+		r0 = ret.Get(0).(model.ScanningResponse)
+	}
+
+	var r1 serror.SError
+	if rf, ok := ret.Get(1).(func(context.Context, int64) serror.SError); ok {
+		r1 = rf(ctx, scanning_id)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(serror.SError)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetBaselineList provides a mock function with given fields: ctx, repo_id
+func (_m *IScanningUsecase) GetBaselineList(ctx context.Context, repo_id int64) ([]model.BaselineResponse, serror.SError) {
+	ret := _m.Called(ctx, repo_id)
+
+	var r0 []model.BaselineResponse
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []model.BaselineResponse); ok {
+		r0 = rf(ctx, repo_id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.BaselineResponse)
+		}
+	}
+
+	var r1 serror.SError
+	if rf, ok := ret.Get(1).(func(context.Context, int64) serror.SError); ok {
+		r1 = rf(ctx, repo_id)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(serror.SError)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetFindingComments provides a mock function with given fields: ctx, scanning_id, finding_id
+func (_m *IScanningUsecase) GetFindingComments(ctx context.Context, scanning_id int64, finding_id string) ([]model.FindingComment, serror.SError) {
+	ret := _m.Called(ctx, scanning_id, finding_id)
+
+	var r0 []model.FindingComment
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) []model.FindingComment); ok {
+		r0 = rf(ctx, scanning_id, finding_id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.FindingComment)
+		}
+	}
+
+	var r1 serror.SError
+	if rf, ok := ret.Get(1).(func(context.Context, int64, string) serror.SError); ok {
+		r1 = rf(ctx, scanning_id, finding_id)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(serror.SError)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetFindingList provides a mock function with given fields: _a0, _a1
+func (_m *IScanningUsecase) GetFindingList(_a0 context.Context, _a1 model.FindingListRequest) ([]model.FindingListResponse, serror.SError) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 []model.FindingListResponse
+	if rf, ok := ret.Get(0).(func(context.Context, model.FindingListRequest) []model.FindingListResponse); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.FindingListResponse)
+		}
+	}
+
+	var r1 serror.SError
+	if rf, ok := ret.Get(1).(func(context.Context, model.FindingListRequest) serror.SError); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(serror.SError)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetScanningList provides a mock function with given fields: _a0, _a1
+func (_m *IScanningUsecase) GetScanningList(_a0 context.Context, _a1 model.ScanningListRequest) ([]model.ScanningListResponse, serror.SError) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 []model.ScanningListResponse
+	if rf, ok := ret.Get(0).(func(context.Context, model.ScanningListRequest) []model.ScanningListResponse); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.ScanningListResponse)
+		}
+	}
+
+	var r1 serror.SError
+	if rf, ok := ret.Get(1).(func(context.Context, model.ScanningListRequest) serror.SError); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(serror.SError)
+		}
+	}
+
+	return r0, r1
+}
+
+// RevealFinding provides a mock function with given fields: _a0, _a1
+func (_m *IScanningUsecase) RevealFinding(_a0 context.Context, _a1 model.RevealFindingRequest) (model.RevealFindingResponse, serror.SError) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 model.RevealFindingResponse
+	if rf, ok := ret.Get(0).(func(context.Context, model.RevealFindingRequest) model.RevealFindingResponse); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Get(0).(model.RevealFindingResponse)
+	}
+
+	var r1 serror.SError
+	if rf, ok := ret.Get(1).(func(context.Context, model.RevealFindingRequest) serror.SError); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(serror.SError)
+		}
+	}
+
+	return r0, r1
+}
+
+// StartScanningInQueue provides a mock function with given fields: _a0
+func (_m *IScanningUsecase) StartScanningInQueue(_a0 context.Context) serror.SError {
+	ret := _m.Called(_a0)
+
+	var r0 serror.SError
+	if rf, ok := ret.Get(0).(func(context.Context) serror.SError); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(serror.SError)
+		}
+	}
+
+	return r0
+}
+
+// StartScanningPoller provides a mock function with given fields: _a0
+func (_m *IScanningUsecase) StartScanningPoller(_a0 context.Context) serror.SError {
+	ret := _m.Called(_a0)
+
+	var r0 serror.SError
+	if rf, ok := ret.Get(0).(func(context.Context) serror.SError); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(serror.SError)
+		}
+	}
+
+	return r0
+}
+
+// StartScanningReaper provides a mock function with given fields: _a0
+func (_m *IScanningUsecase) StartScanningReaper(_a0 context.Context) serror.SError {
+	ret := _m.Called(_a0)
+
+	var r0 serror.SError
+	if rf, ok := ret.Get(0).(func(context.Context) serror.SError); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(serror.SError)
+		}
+	}
+
+	return r0
+}
+
+// StartScanningScheduler provides a mock function with given fields: _a0
+func (_m *IScanningUsecase) StartScanningScheduler(_a0 context.Context) serror.SError {
+	ret := _m.Called(_a0)
+
+	var r0 serror.SError
+	if rf, ok := ret.Get(0).(func(context.Context) serror.SError); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(serror.SError)
+		}
+	}
+
+	return r0
+}
+
+// TriageFinding provides a mock function with given fields: _a0, _a1
+func (_m *IScanningUsecase) TriageFinding(_a0 context.Context, _a1 model.TriageFindingRequest) (model.FindingTriageResponse, serror.SError) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 model.FindingTriageResponse
+	if rf, ok := ret.Get(0).(func(context.Context, model.TriageFindingRequest) model.FindingTriageResponse); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Get(0).(model.FindingTriageResponse)
+	}
+
+	var r1 serror.SError
+	if rf, ok := ret.Get(1).(func(context.Context, model.TriageFindingRequest) serror.SError); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(serror.SError)
+		}
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewIScanningUsecase interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewIScanningUsecase creates a new instance of IScanningUsecase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewIScanningUsecase(t mockConstructorTestingTNewIScanningUsecase) *IScanningUsecase {
+	mock := &IScanningUsecase{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
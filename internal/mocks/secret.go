@@ -0,0 +1,88 @@
+// Code generated by mockery v2.14.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	model "repo-scanner/internal/model"
+
+	mock "github.com/stretchr/testify/mock"
+
+	serror "repo-scanner/internal/utils/serror"
+)
+
+// ISecretRepository is an autogenerated mock type for the ISecretRepository type
+type ISecretRepository struct {
+	mock.Mock
+}
+
+// Redact provides a mock function with given fields: scanning_id, findings
+func (_m *ISecretRepository) Redact(scanning_id int64, findings []model.Finding) ([]model.Finding, []model.FindingSecret, serror.SError) {
+	ret := _m.Called(scanning_id, findings)
+
+	var r0 []model.Finding
+	if rf, ok := ret.Get(0).(func(int64, []model.Finding) []model.Finding); ok {
+		r0 = rf(scanning_id, findings)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Finding)
+		}
+	}
+
+	var r1 []model.FindingSecret
+	if rf, ok := ret.Get(1).(func(int64, []model.Finding) []model.FindingSecret); ok {
+		r1 = rf(scanning_id, findings)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).([]model.FindingSecret)
+		}
+	}
+
+	var r2 serror.SError
+	if rf, ok := ret.Get(2).(func(int64, []model.Finding) serror.SError); ok {
+		r2 = rf(scanning_id, findings)
+	} else {
+		if ret.Get(2) != nil {
+			r2 = ret.Get(2).(serror.SError)
+		}
+	}
+
+	return r0, r1, r2
+}
+
+// Reveal provides a mock function with given fields: _a0
+func (_m *ISecretRepository) Reveal(_a0 model.FindingSecret) (string, serror.SError) {
+	ret := _m.Called(_a0)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(model.FindingSecret) string); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 serror.SError
+	if rf, ok := ret.Get(1).(func(model.FindingSecret) serror.SError); ok {
+		r1 = rf(_a0)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(serror.SError)
+		}
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewISecretRepository interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewISecretRepository creates a new instance of ISecretRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewISecretRepository(t mockConstructorTestingTNewISecretRepository) *ISecretRepository {
+	mock := &ISecretRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
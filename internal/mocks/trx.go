@@ -3,10 +3,12 @@
 package mocks
 
 import (
-	model "repo-scanner/internal/model"
+	context "context"
 
 	mock "github.com/stretchr/testify/mock"
 
+	model "repo-scanner/internal/model"
+
 	serror "repo-scanner/internal/utils/serror"
 )
 
@@ -15,13 +17,13 @@ type ITrxRepository struct {
 	mock.Mock
 }
 
-// Create provides a mock function with given fields:
-func (_m *ITrxRepository) Create() (*model.Trx, serror.SError) {
-	ret := _m.Called()
+// Create provides a mock function with given fields: _a0
+func (_m *ITrxRepository) Create(_a0 context.Context) (*model.Trx, serror.SError) {
+	ret := _m.Called(_a0)
 
 	var r0 *model.Trx
-	if rf, ok := ret.Get(0).(func() *model.Trx); ok {
-		r0 = rf()
+	if rf, ok := ret.Get(0).(func(context.Context) *model.Trx); ok {
+		r0 = rf(_a0)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*model.Trx)
@@ -29,8 +31,8 @@ func (_m *ITrxRepository) Create() (*model.Trx, serror.SError) {
 	}
 
 	var r1 serror.SError
-	if rf, ok := ret.Get(1).(func() serror.SError); ok {
-		r1 = rf()
+	if rf, ok := ret.Get(1).(func(context.Context) serror.SError); ok {
+		r1 = rf(_a0)
 	} else {
 		if ret.Get(1) != nil {
 			r1 = ret.Get(1).(serror.SError)
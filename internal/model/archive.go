@@ -0,0 +1,30 @@
+package model
+
+import "io"
+
+type (
+	// ArchiveOption limits archives to be extracted against zip bombs,
+	// MaxExtractedSize is total size in bytes of every extracted file
+	ArchiveOption struct {
+		Dir              string
+		MaxExtractedSize int64
+		MaxFiles         int
+	}
+
+	// UploadArchive is zip or tar.gz archive uploaded to be scanned, its format is told by Filename
+	UploadArchive struct {
+		Filename string
+		Size     int64
+		File     interface {
+			io.Reader
+			io.ReaderAt
+		}
+	}
+
+	// AddUploadScanningRequest queues scanning of uploaded archive under synthetic repository named Name,
+	// archive filename is used if it is empty
+	AddUploadScanningRequest struct {
+		Name    string
+		Archive UploadArchive
+	}
+)
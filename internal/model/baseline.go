@@ -0,0 +1,32 @@
+package model
+
+import "time"
+
+type (
+	// AddBaselineRequest snapshots fingerprints of findings of a successful scanning as new version of repository
+	// baseline, which becomes the active one
+	AddBaselineRequest struct {
+		RepositoryId int64  `json:"-"`
+		ScanningId   int64  `json:"scanning_id" validate:"required,min=1"`
+		CreatedBy    string `json:"created_by" validate:"required"`
+	}
+
+	// ActivateBaselineRequest switches active baseline of repository to an existing version, e.g. to roll it back
+	ActivateBaselineRequest struct {
+		RepositoryId int64  `json:"-"`
+		Version      int64  `json:"-"`
+		ActivatedBy  string `json:"activated_by" validate:"required"`
+	}
+
+	// BaselineResponse is a version of repository baseline, FingerprintCount is the number of findings it covers
+	BaselineResponse struct {
+		Id               int64     `json:"baseline_id" db:"baseline_id"`
+		RepositoryId     int64     `json:"repository_id" db:"repository_id"`
+		Version          int64     `json:"version" db:"version"`
+		ScanningId       int64     `json:"scanning_id" db:"scanning_id"`
+		FingerprintCount int64     `json:"fingerprint_count" db:"fingerprint_count"`
+		IsActive         bool      `json:"is_active" db:"is_active"`
+		CreatedBy        string    `json:"created_by" db:"created_by"`
+		CreatedAt        time.Time `json:"created_at" db:"created_at"`
+	}
+)
@@ -0,0 +1,95 @@
+package model
+
+type (
+	ScanEngineInfo struct {
+		Name         string   `json:"name"`
+		Version      string   `json:"version"`
+		Capabilities []string `json:"capabilities"`
+	}
+
+	// Finding keeps the same JSON shape as findings produced by grab/secret-scanner,
+	// Engine and Rule are the name of scan engine and rule that reported it, Severity is given by what is matched.
+	// LineContent is redacted to a preview before it is stored, SecretHash is keyed hash of its raw value.
+	// Fingerprint identifies the same finding across scannings of a repository, Occurrence tells whether
+	// it is new, recurring or fixed since previous scanning. Suppression is set once it is silenced in repository
+	// by .reposcanignore or inline comment, suppressed findings are kept so that they can be reviewed.
+	// BaselineVersion is the version of repository baseline which already has it
+	Finding struct {
+		ID              string
+		Engine          string
+		Rule            string
+		Severity        string
+		FilePath        string
+		Action          string
+		Description     string
+		Comment         string
+		RepositoryOwner string
+		RepositoryName  string
+		CommitHash      string
+		CommitMessage   string
+		CommitAuthor    string
+		FileURL         string
+		Line            uint64
+		LineContent     string
+		SecretHash      string
+		Fingerprint     string
+		Occurrence      string
+		Suppression     *FindingSuppression `json:",omitempty"`
+		BaselineVersion int64               `json:",omitempty"`
+		CommitURL       string
+		RepositoryURL   string
+		IsTestContext   bool
+	}
+
+	// FindingSuppression tells Source the finding is suppressed by, .reposcanignore or inline comment, and its Reason
+	FindingSuppression struct {
+		Source string
+		Reason string
+	}
+
+	// ScanTarget is the repository to be scanned at Ref, provider default branch if it is empty,
+	// LocalPath and Branch are set once it is checked out as a work tree.
+	// Only commits after SinceCommit are scanned if it is set, ChangedFiles are files changed by them.
+	// Diff mode scans changes from merge base of BaseRef and Ref, which is set as SinceCommit.
+	// Settings are scan settings of the repository
+	ScanTarget struct {
+		ScanningId   int64
+		Url          string
+		Mode         string
+		BaseRef      string
+		Ref          string
+		LocalPath    string
+		Branch       string
+		SinceCommit  string
+		ChangedFiles []string
+		Settings     RepositorySettings
+	}
+
+	// EntropyOption configures entropy engine, strings longer than MinLength
+	// are reported once their Shannon entropy exceeds threshold of their charset
+	EntropyOption struct {
+		Base64Threshold float64
+		HexThreshold    float64
+		MinLength       int
+		SkipExtensions  []string
+	}
+
+	// ScanResult keeps HeadCommit which Ref is resolved to and scanned, SinceCommit is empty once it is a full scan,
+	// otherwise only ChangedFiles are scanned. IgnoredFingerprints are fingerprints listed in .reposcanignore
+	// with reason they are suppressed by, which are matched once findings are fingerprinted.
+	// IgnoreFile suppresses findings which are not reported by the scan, e.g. carried over from previous scanning
+	ScanResult struct {
+		Engines             []ScanEngineInfo
+		Findings            []Finding
+		SinceCommit         string
+		HeadCommit          string
+		ChangedFiles        []string
+		IgnoredFingerprints map[string]string
+		IgnoreFile          FindingSuppressor
+	}
+
+	// FindingSuppressor marks findings suppressed by entries of .reposcanignore of scanned work tree
+	FindingSuppressor interface {
+		Suppress(findings []Finding)
+	}
+)
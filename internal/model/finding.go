@@ -0,0 +1,110 @@
+package model
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx/types"
+)
+
+type (
+	// FindingFingerprint is a finding tracked across scannings of a repository by its fingerprint,
+	// Occurrence is the one given by the last scanning which reported it
+	FindingFingerprint struct {
+		RepositoryId int64  `db:"repository_id"`
+		ScanningId   int64  `db:"scanning_id"`
+		Fingerprint  string `db:"fingerprint"`
+		Rule         string `db:"rule"`
+		FilePath     string `db:"file_path"`
+		Occurrence   string `db:"occurrence"`
+	}
+
+	// FindingListRequest filters findings of a scanning, FilePath matches paths starting with it.
+	// Findings triaged as false_positive or accepted_risk are left out unless TriageStatus is given, "all" for every one.
+	// Suppressed findings are left out as well unless Suppressed is "true" for suppressed ones only or "all",
+	// so are findings in baseline of repository unless Baselined is given the same way
+	FindingListRequest struct {
+		ScanningId   int64  `json:"-"`
+		Limit        int64  `json:"limit" validate:"numeric,min=1,max=100"` // limit item per page
+		Page         int64  `json:"page" validate:"numeric,min=1"`
+		Severity     string `json:"severity" validate:"omitempty,oneof=low medium high"`
+		Occurrence   string `json:"occurrence" validate:"omitempty,oneof=new recurring fixed"`
+		Engine       string `json:"engine"`
+		Rule         string `json:"rule"`
+		FilePath     string `json:"file_path"`
+		CommitHash   string `json:"commit_hash"`
+		CommitAuthor string `json:"commit_author"`
+		TriageStatus string `json:"triage_status" validate:"omitempty,oneof=all open confirmed false_positive accepted_risk resolved"`
+		Assignee     string `json:"assignee"`
+		Suppressed   string `json:"suppressed" validate:"omitempty,oneof=true false all"`
+		Baselined    string `json:"baselined" validate:"omitempty,oneof=true false all"`
+	}
+	// FindingListResponse keeps filterable columns of a finding, Finding is the whole one as it is reported.
+	// TriageStatus and Assignee are the ones of its fingerprint, SuppressionSource and SuppressionReason are set once
+	// it is suppressed in repository, BaselineVersion once it is in repository baseline
+	FindingListResponse struct {
+		ScanningId        int64          `json:"scanning_id" db:"scanning_id"`
+		RepositoryId      int64          `json:"repository_id" db:"repository_id"`
+		FindingId         string         `json:"finding_id" db:"finding_id"`
+		Engine            string         `json:"engine" db:"engine"`
+		Rule              string         `json:"rule" db:"rule"`
+		Severity          string         `json:"severity" db:"severity"`
+		FilePath          string         `json:"file_path" db:"file_path"`
+		Line              int64          `json:"line" db:"line"`
+		CommitHash        string         `json:"commit_hash" db:"commit_hash"`
+		CommitAuthor      string         `json:"commit_author" db:"commit_author"`
+		Fingerprint       string         `json:"fingerprint" db:"fingerprint"`
+		Occurrence        *string        `json:"occurrence" db:"occurrence"`
+		SuppressionSource *string        `json:"suppression_source" db:"suppression_source"`
+		SuppressionReason *string        `json:"suppression_reason" db:"suppression_reason"`
+		BaselineVersion   *int64         `json:"baseline_version" db:"baseline_version"`
+		TriageStatus      string         `json:"triage_status" db:"triage_status"`
+		Assignee          *string        `json:"assignee" db:"assignee"`
+		Finding           types.JSONText `json:"finding" db:"finding"`
+		CreatedAt         time.Time      `json:"created_at" db:"created_at"`
+	}
+
+	// TriageFindingRequest changes triage of a finding, which is applied to every finding of the same fingerprint.
+	// Assignee is left as it is if it is not given, empty to unassign. Comment is added to its thread if it is given
+	TriageFindingRequest struct {
+		ScanningId   int64   `json:"-"`
+		FindingId    string  `json:"-"`
+		RepositoryId int64   `json:"-"`
+		Fingerprint  string  `json:"-"`
+		Rule         string  `json:"-"`
+		FilePath     string  `json:"-"`
+		Status       string  `json:"triage_status" validate:"required,oneof=open confirmed false_positive accepted_risk resolved"`
+		Assignee     *string `json:"assignee"`
+		Comment      string  `json:"comment"`
+		TriagedBy    string  `json:"triaged_by" validate:"required"`
+	}
+	FindingTriageResponse struct {
+		RepositoryId int64      `json:"repository_id" db:"repository_id"`
+		Fingerprint  string     `json:"fingerprint" db:"fingerprint"`
+		TriageStatus string     `json:"triage_status" db:"triage_status"`
+		Assignee     *string    `json:"assignee" db:"assignee"`
+		TriagedBy    *string    `json:"triaged_by" db:"triaged_by"`
+		TriagedAt    *time.Time `json:"triaged_at" db:"triaged_at"`
+	}
+
+	// AddFindingCommentRequest adds comment to thread of a finding, which is shared by every finding of the same fingerprint
+	AddFindingCommentRequest struct {
+		ScanningId   int64  `json:"-"`
+		FindingId    string `json:"-"`
+		RepositoryId int64  `json:"-"`
+		Fingerprint  string `json:"-"`
+		Rule         string `json:"-"`
+		FilePath     string `json:"-"`
+		Comment      string `json:"comment" validate:"required"`
+		CreatedBy    string `json:"created_by" validate:"required"`
+	}
+	FindingComment struct {
+		Id           int64     `json:"comment_id" db:"comment_id"`
+		RepositoryId int64     `json:"repository_id" db:"repository_id"`
+		Fingerprint  string    `json:"fingerprint" db:"fingerprint"`
+		ScanningId   int64     `json:"scanning_id" db:"scanning_id"`
+		FindingId    string    `json:"finding_id" db:"finding_id"`
+		Comment      string    `json:"comment" db:"comment"`
+		CreatedBy    string    `json:"created_by" db:"created_by"`
+		CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	}
+)
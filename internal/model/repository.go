@@ -9,6 +9,8 @@ type (
 		Id         int64      `json:"repository_id" db:"repository_id" sqlq:"@{ primary: true; sortable: true; conds: $key; }"`
 		Name       string     `json:"repository_name" db:"repository_name" sqlq:"@{ sortable: true; conds: $key, $text; }"`
 		Url        string     `json:"repository_url" db:"repository_url" sqlq:"@{ sortable: false; }"`
+		DefaultRef *string    `json:"default_ref" db:"default_ref" sqlq:"@{ sortable: false; conds: $key, $nullable; }"`
+		IsUpload   bool       `json:"is_upload" db:"is_upload" sqlq:"@{ sortable: true; conds: $basic; }"`
 		IsActive   bool       `json:"is_active" db:"is_active" sqlq:"@{ sortable: true; conds: $basic; }"`
 		CreatedBy  string     `json:"created_by" db:"created_by" sqlq:"@{ sortable: true; conds: $key, $text; }"`
 		CreatedAt  time.Time  `json:"created_at" db:"created_at" sqlq:"@{ sortable: true; conds: $number; }"`
@@ -23,33 +25,87 @@ type (
 		Page  int64 `json:"page" validate:"numeric,min=1"`
 	}
 	RepositoryListResponse struct {
-		Id       int64  `json:"repository_id" db:"repository_id"`
-		Name     string `json:"repository_name" db:"repository_name"`
-		Url      string `json:"repository_url" db:"repository_url"`
-		IsActive bool   `json:"is_active" db:"is_active"`
+		Id                  int64      `json:"repository_id" db:"repository_id"`
+		Name                string     `json:"repository_name" db:"repository_name"`
+		Url                 string     `json:"repository_url" db:"repository_url"`
+		DefaultRef          *string    `json:"default_ref" db:"default_ref"`
+		IsUpload            bool       `json:"is_upload" db:"is_upload"`
+		IsActive            bool       `json:"is_active" db:"is_active"`
+		ScanSchedule        *string    `json:"scan_schedule" db:"scan_schedule"`
+		ScanTimezone        *string    `json:"scan_timezone" db:"scan_timezone"`
+		NextScheduledScanAt *time.Time `json:"next_scheduled_scan_at" db:"next_scheduled_scan_at"`
+		LastScheduledScanAt *time.Time `json:"last_scheduled_scan_at" db:"last_scheduled_scan_at"`
 	}
 
+	// AddRepositoryRequest registers new repository, DefaultRef is branch, tag or commit SHA
+	// to be scanned unless scan is triggered with other ref, provider default branch if it is empty.
+	// IsUpload marks synthetic repository of extracted archive which is only created by upload
 	AddRepositoryRequest struct {
-		Name string `json:"repository_name" validate:"required"`
-		Url  string `json:"repository_url" validate:"required"`
+		Name       string `json:"repository_name" validate:"required"`
+		Url        string `json:"repository_url" validate:"required"`
+		DefaultRef string `json:"default_ref"`
+		IsUpload   bool   `json:"-"`
 	}
 	AddRepositoryResponse struct {
-		Id       int64  `json:"repository_id" db:"repository_id"`
-		Name     string `json:"repository_name" db:"repository_name"`
-		Url      string `json:"repository_url" db:"repository_url"`
-		IsActive bool   `json:"is_active" db:"is_active"`
+		Id         int64   `json:"repository_id" db:"repository_id"`
+		Name       string  `json:"repository_name" db:"repository_name"`
+		Url        string  `json:"repository_url" db:"repository_url"`
+		DefaultRef *string `json:"default_ref" db:"default_ref"`
+		IsUpload   bool    `json:"is_upload" db:"is_upload"`
+		IsActive   bool    `json:"is_active" db:"is_active"`
 	}
 
+	// EditRepositoryRequest edits given fields only, empty DefaultRef resets it to provider default branch
 	EditRepositoryRequest struct {
-		Id       int64   `json:"_"`
-		Name     *string `json:"repository_name"`
-		Url      *string `json:"repository_url"`
-		IsActive *bool   `json:"is_active"`
+		Id         int64   `json:"_"`
+		Name       *string `json:"repository_name"`
+		Url        *string `json:"repository_url"`
+		DefaultRef *string `json:"default_ref"`
+		IsActive   *bool   `json:"is_active"`
 	}
 	EditRepositoryResponse struct {
-		Id       int64  `json:"repository_id" db:"repository_id"`
-		Name     string `json:"repository_name" db:"repository_name"`
-		Url      string `json:"repository_url" db:"repository_url"`
-		IsActive bool   `json:"is_active" db:"is_active"`
+		Id         int64   `json:"repository_id" db:"repository_id"`
+		Name       string  `json:"repository_name" db:"repository_name"`
+		Url        string  `json:"repository_url" db:"repository_url"`
+		DefaultRef *string `json:"default_ref" db:"default_ref"`
+		IsUpload   bool    `json:"is_upload" db:"is_upload"`
+		IsActive   bool    `json:"is_active" db:"is_active"`
+	}
+
+	// RepositorySettings tunes scanning of a repository on top of SKIP_EXT, SKIP_PATHS and SKIP_TEST_PATHS.
+	// SkipPaths are globs as in .reposcanignore, SkipExtensions are extensions with or without leading dot.
+	// Test contexts are skipped unless ScanTests, CommitDepth and Threads of grab engine are its defaults once they are 0.
+	// Priority is default priority of scannings of the repository triggered without one
+	RepositorySettings struct {
+		SkipPaths      []string `json:"skip_paths" validate:"dive,required"`
+		SkipExtensions []string `json:"skip_extensions" validate:"dive,required"`
+		ScanTests      bool     `json:"scan_tests"`
+		CommitDepth    int      `json:"commit_depth" validate:"min=0,max=100000"`
+		Threads        int      `json:"threads" validate:"min=0,max=64"`
+		Priority       int      `json:"priority" validate:"min=0,max=100"`
+	}
+
+	// RepositorySchedule scans repository every time ScanSchedule cron expression is due in ScanTimezone,
+	// empty ScanSchedule turns it off. NextScheduledScanAt is when scan is due next,
+	// LastScheduledScanAt is when the last one is queued
+	RepositorySchedule struct {
+		ScanSchedule        string     `json:"scan_schedule" db:"scan_schedule"`
+		ScanTimezone        string     `json:"scan_timezone" db:"scan_timezone"`
+		NextScheduledScanAt *time.Time `json:"next_scheduled_scan_at" db:"next_scheduled_scan_at"`
+		LastScheduledScanAt *time.Time `json:"last_scheduled_scan_at" db:"last_scheduled_scan_at"`
+	}
+	// EditRepositoryScheduleRequest replaces schedule, ScanTimezone is a location, e.g. "Asia/Jakarta",
+	// an offset, e.g. "+7", or "UTC", DefaultTimezone if it is empty
+	EditRepositoryScheduleRequest struct {
+		ScanSchedule string `json:"scan_schedule"`
+		ScanTimezone string `json:"scan_timezone"`
+	}
+	// ScheduledRepository is active repository whose scheduled scan is due at NextScheduledScanAt
+	ScheduledRepository struct {
+		Id                  int64     `db:"repository_id"`
+		DefaultRef          *string   `db:"default_ref"`
+		ScanSchedule        string    `db:"scan_schedule"`
+		ScanTimezone        string    `db:"scan_timezone"`
+		NextScheduledScanAt time.Time `db:"next_scheduled_scan_at"`
 	}
 )
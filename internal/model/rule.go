@@ -0,0 +1,73 @@
+package model
+
+import (
+	"time"
+)
+
+type (
+	Rule struct {
+		Id          int64      `json:"rule_id" db:"rule_id" sqlq:"@{ primary: true; sortable: true; conds: $key; }"`
+		Name        string     `json:"rule_name" db:"rule_name" sqlq:"@{ sortable: true; conds: $key, $text; }"`
+		Description string     `json:"description" db:"description" sqlq:"@{ sortable: false; }"`
+		Comment     string     `json:"comment" db:"comment" sqlq:"@{ sortable: false; }"`
+		Part        string     `json:"part" db:"part" sqlq:"@{ sortable: true; conds: $key; }"`
+		Pattern     string     `json:"pattern" db:"pattern" sqlq:"@{ sortable: false; }"`
+		IsActive    bool       `json:"is_active" db:"is_active" sqlq:"@{ sortable: true; conds: $basic; }"`
+		CreatedBy   string     `json:"created_by" db:"created_by" sqlq:"@{ sortable: true; conds: $key, $text; }"`
+		CreatedAt   time.Time  `json:"created_at" db:"created_at" sqlq:"@{ sortable: true; conds: $number; }"`
+		ModifiedBy  string     `json:"modified_by" db:"modified_by" sqlq:"@{ sortable: true; conds: $key, $text; }"`
+		ModifiedAt  time.Time  `json:"modified_at" db:"modified_at" sqlq:"@{ sortable: true; conds: $number; }"`
+		DeletedBy   *string    `json:"deleted_by" db:"deleted_by" sqlq:"@{ sortable: true; conds: $key, $text, $nullable; }"`
+		DeletedAt   *time.Time `json:"deleted_at" db:"deleted_at" sqlq:"@{ sortable: true; soft-del: true; conds: $number, $nullable; }"`
+	}
+
+	RuleListRequest struct {
+		Limit int64 `json:"limit" validate:"numeric,min=1,max=10"` // limit item per page
+		Page  int64 `json:"page" validate:"numeric,min=1"`
+	}
+	RuleListResponse struct {
+		Id          int64  `json:"rule_id" db:"rule_id"`
+		Name        string `json:"rule_name" db:"rule_name"`
+		Description string `json:"description" db:"description"`
+		Comment     string `json:"comment" db:"comment"`
+		Part        string `json:"part" db:"part"`
+		Pattern     string `json:"pattern" db:"pattern"`
+		IsActive    bool   `json:"is_active" db:"is_active"`
+	}
+
+	AddRuleRequest struct {
+		Name        string `json:"rule_name" validate:"required"`
+		Description string `json:"description" validate:"required"`
+		Comment     string `json:"comment"`
+		Part        string `json:"part" validate:"required,oneof=filename extension path content"`
+		Pattern     string `json:"pattern" validate:"required"`
+	}
+	AddRuleResponse struct {
+		Id          int64  `json:"rule_id" db:"rule_id"`
+		Name        string `json:"rule_name" db:"rule_name"`
+		Description string `json:"description" db:"description"`
+		Comment     string `json:"comment" db:"comment"`
+		Part        string `json:"part" db:"part"`
+		Pattern     string `json:"pattern" db:"pattern"`
+		IsActive    bool   `json:"is_active" db:"is_active"`
+	}
+
+	EditRuleRequest struct {
+		Id          int64   `json:"_"`
+		Name        *string `json:"rule_name"`
+		Description *string `json:"description"`
+		Comment     *string `json:"comment"`
+		Part        *string `json:"part" validate:"omitempty,oneof=filename extension path content"`
+		Pattern     *string `json:"pattern"`
+		IsActive    *bool   `json:"is_active"`
+	}
+	EditRuleResponse struct {
+		Id          int64  `json:"rule_id" db:"rule_id"`
+		Name        string `json:"rule_name" db:"rule_name"`
+		Description string `json:"description" db:"description"`
+		Comment     string `json:"comment" db:"comment"`
+		Part        string `json:"part" db:"part"`
+		Pattern     string `json:"pattern" db:"pattern"`
+		IsActive    bool   `json:"is_active" db:"is_active"`
+	}
+)
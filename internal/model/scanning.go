@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/jmoiron/sqlx/types"
+	"github.com/lib/pq"
 )
 
 type (
@@ -11,7 +12,14 @@ type (
 		Id           int64          `json:"scanning_id" db:"scanning_id" sqlq:"@{ primary: true; sortable: true; conds: $key; }"`
 		RepositoryId int64          `json:"repository_id" db:"repository_id" sqlq:"@{ foreign: true; sortable: true; conds: $key; }"`
 		Findings     types.JSONText `json:"findings" db:"findings"`
+		Engines      types.JSONText `json:"scan_engines" db:"scan_engines"`
 		Status       string         `json:"scanning_status" db:"scanning_status" sqlq:"@{ sortable: true; conds: $key; }"`
+		Mode         string         `json:"scan_mode" db:"scan_mode" sqlq:"@{ sortable: true; conds: $key; }"`
+		IsFullScan   bool           `json:"is_full_scan" db:"is_full_scan" sqlq:"@{ sortable: true; conds: $basic; }"`
+		BaseRef      *string        `json:"base_ref" db:"base_ref" sqlq:"@{ sortable: false; conds: $key, $nullable; }"`
+		Ref          *string        `json:"ref" db:"ref" sqlq:"@{ sortable: false; conds: $key, $nullable; }"`
+		SinceCommit  *string        `json:"since_commit" db:"since_commit" sqlq:"@{ sortable: false; conds: $key, $nullable; }"`
+		HeadCommit   *string        `json:"head_commit" db:"head_commit" sqlq:"@{ sortable: false; conds: $key, $nullable; }"`
 		QueuedAt     time.Time      `json:"queued_at" db:"queued_at" sqlq:"@{ sortable: true; conds: $number; }"`
 		ScanningAt   *time.Time     `json:"scanning_at" db:"scanning_at" sqlq:"@{ sortable: true; conds: $number, $nullable; }"`
 		FinishedAt   *time.Time     `json:"finished_at" db:"finished_at" sqlq:"@{ sortable: true; conds: $number, $nullable; }"`
@@ -28,26 +36,122 @@ type (
 		Limit  int64  `json:"limit" validate:"numeric,min=1,max=10"` // limit item per page
 		Page   int64  `json:"page" validate:"numeric,min=1"`
 		Sort   string `json:"sort" validate:"oneof=asc desc"`
-		Status string `json:"status" validate:"oneof=all queued in_progress success failure"`
+		Status string `json:"status" validate:"oneof=all queued in_progress success failure cancelled dead"`
 	}
 	ScanningListResponse struct {
-		Id         int64          `json:"scanning_id" db:"scanning_id"`
-		Name       string         `json:"repository_name" db:"repository_name"`
-		Url        string         `json:"repository_url" db:"repository_url"`
-		Findings   types.JSONText `json:"findings" db:"findings"`
-		Status     string         `json:"scanning_status" db:"scanning_status"`
-		QueuedAt   time.Time      `json:"queued_at" db:"queued_at"`
-		ScanningAt *time.Time     `json:"scanning_at" db:"scanning_at"`
-		FinishedAt *time.Time     `json:"finished_at" db:"finished_at"`
+		Id             int64          `json:"scanning_id" db:"scanning_id"`
+		RepoId         int64          `json:"repository_id" db:"repository_id"`
+		Name           string         `json:"repository_name" db:"repository_name"`
+		Url            string         `json:"repository_url" db:"repository_url"`
+		IsUpload       bool           `json:"-" db:"is_upload"`
+		Findings       types.JSONText `json:"findings" db:"findings"`
+		Engines        types.JSONText `json:"scan_engines" db:"scan_engines"`
+		Status         string         `json:"scanning_status" db:"scanning_status"`
+		Mode           string         `json:"scan_mode" db:"scan_mode"`
+		IsFullScan     bool           `json:"is_full_scan" db:"is_full_scan"`
+		BaseRef        *string        `json:"base_ref" db:"base_ref"`
+		Ref            *string        `json:"ref" db:"ref"`
+		SinceCommit    *string        `json:"since_commit" db:"since_commit"`
+		HeadCommit     *string        `json:"head_commit" db:"head_commit"`
+		QueuedAt       time.Time      `json:"queued_at" db:"queued_at"`
+		ScanningAt     *time.Time     `json:"scanning_at" db:"scanning_at"`
+		FinishedAt     *time.Time     `json:"finished_at" db:"finished_at"`
+		ClaimedBy      *string        `json:"claimed_by" db:"claimed_by"` // worker instance scanning it
+		LeaseExpiresAt *time.Time     `json:"lease_expires_at" db:"lease_expires_at"`
+		Attempts       int            `json:"attempts" db:"attempts"`
+		MaxAttempts    int            `json:"max_attempts" db:"max_attempts"`
+		NextAttemptAt  *time.Time     `json:"next_attempt_at" db:"next_attempt_at"`
+		LastError      *string        `json:"last_error" db:"last_error"`
+		LastErrorStack pq.StringArray `json:"last_error_stack" db:"last_error_stack"` // comment stack of last error
+		Priority       int            `json:"priority" db:"priority"`
 	}
 
+	// AddScanningRequest queues new scanning of repository, IsFullScan forces complete rescan
+	// instead of inspecting commits since last successful scanning only.
+	// Ref is branch, tag or commit SHA to be scanned, repository default ref is used if it is empty.
+	// Diff mode scans lines added from BaseRef to Ref only.
+	// Scanning failing for transient error is retried until it is attempted MaxAttempts times, zero for default.
+	// Scanning of higher Priority is claimed first, priority of repository settings is used if it is nil
+	AddScanningRequest struct {
+		RepoId      int64  `json:"-"`
+		Mode        string `json:"-"`
+		IsFullScan  bool   `json:"-"`
+		BaseRef     string `json:"-"`
+		Ref         string `json:"ref"`
+		MaxAttempts int    `json:"max_attempts" validate:"min=0,max=10"`
+		Priority    *int   `json:"priority" validate:"omitempty,min=0,max=100"`
+	}
+	AddDiffScanningRequest struct {
+		BaseRef     string `json:"base_ref" validate:"required"`
+		HeadRef     string `json:"head_ref" validate:"required"`
+		MaxAttempts int    `json:"max_attempts" validate:"min=0,max=10"`
+		Priority    *int   `json:"priority" validate:"omitempty,min=0,max=100"`
+	}
+
+	// ScanningResponse is scanning as stored, IsCoalesced tells triggered scanning is an identical one already queued
 	ScanningResponse struct {
-		Id         int64          `json:"scanning_id" db:"scanning_id"`
-		RepoId     int64          `json:"repository_id" db:"repository_id"`
-		Findings   types.JSONText `json:"findings" db:"findings"`
-		Status     string         `json:"scanning_status" db:"scanning_status"`
-		QueuedAt   time.Time      `json:"queued_at" db:"queued_at"`
-		ScanningAt *time.Time     `json:"scanning_at" db:"scanning_at"`
-		FinishedAt *time.Time     `json:"finished_at" db:"finished_at"`
+		Id          int64          `json:"scanning_id" db:"scanning_id"`
+		RepoId      int64          `json:"repository_id" db:"repository_id"`
+		Findings    types.JSONText `json:"findings" db:"findings"`
+		Engines     types.JSONText `json:"scan_engines" db:"scan_engines"`
+		Status      string         `json:"scanning_status" db:"scanning_status"`
+		Mode        string         `json:"scan_mode" db:"scan_mode"`
+		IsFullScan  bool           `json:"is_full_scan" db:"is_full_scan"`
+		BaseRef     *string        `json:"base_ref" db:"base_ref"`
+		Ref         *string        `json:"ref" db:"ref"`
+		SinceCommit *string        `json:"since_commit" db:"since_commit"`
+		HeadCommit  *string        `json:"head_commit" db:"head_commit"`
+		QueuedAt    time.Time      `json:"queued_at" db:"queued_at"`
+		ScanningAt  *time.Time     `json:"scanning_at" db:"scanning_at"`
+		FinishedAt  *time.Time     `json:"finished_at" db:"finished_at"`
+		MaxAttempts int            `json:"max_attempts" db:"max_attempts"`
+		Priority    int            `json:"priority" db:"priority"`
+		IsCoalesced bool           `json:"is_coalesced" db:"-"`
+	}
+
+	// EditScanningStatusRequest updates status of scanning, finishing scanning is rejected
+	// unless it is still claimed by ClaimedBy if it is given, e.g. its lease expired and it is claimed by another worker.
+	// Scanning to be retried is updated 'queued' again to be claimed after RetryDelay, LastError and LastErrorStack are kept
+	// on failed, dead and retried scanning
+	EditScanningStatusRequest struct {
+		Id             int64
+		Status         string
+		Findings       types.JSONText
+		Engines        types.JSONText
+		SinceCommit    string
+		HeadCommit     string
+		ClaimedBy      string
+		RetryDelay     time.Duration
+		LastError      string
+		LastErrorStack []string
+	}
+
+	// ReapedScanning is scanning whose lease expired, it is queued again or dead once attempted too many times
+	ReapedScanning struct {
+		Id        int64   `db:"scanning_id"`
+		RepoId    int64   `db:"repository_id"`
+		Status    string  `db:"scanning_status"`
+		Attempts  int     `db:"attempts"`
+		ClaimedBy *string `db:"claimed_by"`
+	}
+
+	// ScanningOption configures scanning queue, scanning lasting longer than Timeout fails,
+	// zero Timeout lets scanning run until it finishes. Up to Workers scannings run at once, none without Workers,
+	// each of them is claimed by worker of Instance, which tells this service apart from its other replicas.
+	// Claimed scanning is leased for Lease and renewed by heartbeat of its worker, scanning whose lease expires
+	// or failing for transient error is queued again unless it is attempted as many times as allowed, MaxAttempts by default.
+	// Retry is delayed by RetryBackoff doubled on every attempt up to RetryBackoffMax, with jitter.
+	// Queued scanning is raised one priority every PriorityAging it waits, zero PriorityAging keeps priorities as they are
+	// Idle workers look for scannings queued by other services every PollInterval
+	ScanningOption struct {
+		Timeout         time.Duration
+		Workers         int
+		Instance        string
+		Lease           time.Duration
+		MaxAttempts     int
+		RetryBackoff    time.Duration
+		RetryBackoffMax time.Duration
+		PriorityAging   time.Duration
+		PollInterval    time.Duration
 	}
 )
@@ -0,0 +1,44 @@
+package model
+
+import "time"
+
+type (
+	// SecretOption configures how secret values of findings are stored, HashKey keys SecretHash of findings.
+	// Raw values are kept only if KeepSecret is set, encrypted by EncryptionKey which is a 32 bytes AES-256 key
+	SecretOption struct {
+		HashKey       []byte
+		KeepSecret    bool
+		EncryptionKey []byte
+	}
+
+	// FindingSecret is encrypted raw value of a finding, kept apart from findings served by scanning results
+	FindingSecret struct {
+		ScanningId      int64  `db:"scanning_id"`
+		FindingId       string `db:"finding_id"`
+		SecretEncrypted string `db:"secret_encrypted"`
+	}
+
+	// CopyFindingSecretsRequest copies encrypted secrets of findings carried over from scanning FromScanningId
+	// to scanning ToScanningId, so that they can be revealed from the latter
+	CopyFindingSecretsRequest struct {
+		FromScanningId int64
+		ToScanningId   int64
+		FindingIds     []string
+	}
+
+	RevealFindingRequest struct {
+		ScanningId int64  `json:"-"`
+		FindingId  string `json:"-"`
+		Reason     string `json:"reason" validate:"required"`
+		RevealedBy string `json:"-"`
+	}
+
+	RevealFindingResponse struct {
+		ScanningId  int64     `json:"scanning_id" db:"scanning_id"`
+		FindingId   string    `json:"finding_id" db:"finding_id"`
+		LineContent string    `json:"line_content" db:"-"`
+		Reason      string    `json:"reason" db:"reason"`
+		RevealedBy  string    `json:"revealed_by" db:"revealed_by"`
+		RevealedAt  time.Time `json:"revealed_at" db:"revealed_at"`
+	}
+)
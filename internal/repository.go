@@ -1,10 +1,11 @@
 package internal
 
 import (
+	"context"
+	"time"
+
 	"repo-scanner/internal/model"
 	"repo-scanner/internal/utils/serror"
-
-	"github.com/jmoiron/sqlx/types"
 )
 
 type INumberHelperRepository interface {
@@ -20,42 +21,182 @@ type ITrx interface {
 
 type ITrxRepository interface {
 	/* Create database transaction */
-	Create() (*model.Trx, serror.SError)
+	Create(context.Context) (*model.Trx, serror.SError)
 }
 
 type RepositoryStore struct {
 	ScanningRepo   IScanningRepository
 	RepositoryRepo IRepositoryRepository
+	RuleRepo       IRuleRepository
+	ArchiveRepo    IArchiveRepository
+	SecretRepo     ISecretRepository
 }
 
 type IRepositoryRepository interface {
 	// Get list of git repositories
-	GetRepositoryList(model.RepositoryListRequest) ([]model.RepositoryListResponse, serror.SError)
+	GetRepositoryList(context.Context, model.RepositoryListRequest) ([]model.RepositoryListResponse, serror.SError)
 
 	// Get git repository detail by given repository id
-	GetRepositoryById(repo_id int64) (*model.Repository, serror.SError)
+	GetRepositoryById(ctx context.Context, repo_id int64) (*model.Repository, serror.SError)
 
 	// Insert new repository by given name and url
-	AddRepository(*model.Trx, model.AddRepositoryRequest) (model.AddRepositoryResponse, serror.SError)
+	AddRepository(context.Context, *model.Trx, model.AddRepositoryRequest) (model.AddRepositoryResponse, serror.SError)
 
 	// Update existing repository by given repository id.
 	// Required: repository Id.
 	// Optional: Name, Url and IsActive.
-	EditRepository(*model.Trx, model.EditRepositoryRequest) (model.EditRepositoryResponse, serror.SError)
+	EditRepository(context.Context, *model.Trx, model.EditRepositoryRequest) (model.EditRepositoryResponse, serror.SError)
+
+	// Lock repository by given repository id until end of given transaction, nothing is locked if there is no such repository
+	LockRepository(ctx context.Context, tx *model.Trx, repo_id int64) serror.SError
+
+	// Deactivate synthetic repository of uploaded archive once it has no unfinished scanning,
+	// path of its extracted archive is returned, nil if it is not deactivated
+	DeactivateUploadRepository(ctx context.Context, tx *model.Trx, repo_id int64) (*string, serror.SError)
 
 	// Delete existing repository by given repository id
-	DeleteRepository(*model.Trx, int64) serror.SError
+	DeleteRepository(context.Context, *model.Trx, int64) serror.SError
+
+	// Get scan settings by given repository id, nil if there is no such repository
+	GetRepositorySettings(ctx context.Context, repo_id int64) (*model.RepositorySettings, serror.SError)
+
+	// Replace scan settings by given repository id, nil if there is no such repository
+	EditRepositorySettings(ctx context.Context, tx *model.Trx, repo_id int64, settings model.RepositorySettings) (*model.RepositorySettings, serror.SError)
+
+	// Get scan schedule by given repository id, nil if there is no such repository
+	GetRepositorySchedule(ctx context.Context, repo_id int64) (*model.RepositorySchedule, serror.SError)
+
+	// Replace scan schedule and its next run by given repository id, nil if there is no such repository
+	EditRepositorySchedule(ctx context.Context, tx *model.Trx, repo_id int64, schedule model.RepositorySchedule) (*model.RepositorySchedule, serror.SError)
+
+	// Get active repositories whose scheduled scan is due by now
+	GetScheduledRepositories(ctx context.Context) ([]model.ScheduledRepository, serror.SError)
+
+	// Move schedule of repository still due at due_at to next_at, false if another replica moved it meanwhile
+	AdvanceRepositorySchedule(ctx context.Context, tx *model.Trx, repo_id int64, due_at time.Time, next_at time.Time) (bool, serror.SError)
 }
 
 type IScanningRepository interface {
 	// Get list of recently scanned
-	GetScanningList(model.ScanningListRequest) ([]model.ScanningListResponse, serror.SError)
+	GetScanningList(context.Context, model.ScanningListRequest) ([]model.ScanningListResponse, serror.SError)
+
+	// Get scanning detail by given scanning id, nil if there is none
+	GetScanningById(ctx context.Context, scanning_id int64) (*model.ScanningResponse, serror.SError)
+
+	// Get latest successful scanning having head commit by given repository id and ref, nil if there is none
+	GetLastSuccessfulScanning(context.Context, int64, string) (*model.ScanningResponse, serror.SError)
+
+	// Get latest successful standard scanning of given repository id and ref other than given scanning id, nil if there is none
+	GetPreviousScanning(ctx context.Context, repo_id int64, ref string, scanning_id int64) (*model.ScanningResponse, serror.SError)
 
 	// Insert new scanning by given active repository id
-	AddNewScanning(*model.Trx, int64) (model.ScanningResponse, serror.SError)
+	AddNewScanning(context.Context, *model.Trx, model.AddScanningRequest) (model.ScanningResponse, serror.SError)
+
+	// Update status of existing scanning by given scanning id, 'queued' status retries scanning after RetryDelay
+	// Required: scanning Id, status
+	// Optional: Findings, Engines, SinceCommit, HeadCommit, ClaimedBy, RetryDelay, LastError, LastErrorStack
+	EditScanningStatusById(context.Context, *model.Trx, model.EditScanningStatusRequest) (model.ScanningResponse, serror.SError)
+
+	// Atomically claim queued scanning of highest priority, oldest first, and update its status 'in_progress' on behalf of
+	// given worker instance, nil if queue is empty. Scannings claimed by other instances meanwhile are skipped.
+	// Claimed scanning is leased for given duration, waiting scanning is raised one priority every aging unless it is 0
+	ClaimScanning(ctx context.Context, claimed_by string, lease time.Duration, aging time.Duration) (*model.ScanningListResponse, serror.SError)
+
+	// Extend lease of scanning claimed by given worker instance, false if it is no longer in progress by the worker
+	RenewScanningLease(ctx context.Context, scanning_id int64, claimed_by string, lease time.Duration) (bool, serror.SError)
+
+	// Queue scannings whose lease expired again, those attempted as many times as allowed are dead instead
+	ReapExpiredScannings(ctx context.Context) ([]model.ReapedScanning, serror.SError)
+
+	// Mark queued or in progress scanning as cancelled by given scanning id, nil if it is already finished
+	CancelScanningById(context.Context, *model.Trx, int64) (*model.ScanningResponse, serror.SError)
+
+	// Insert findings of scanning by given scanning id, the same finding is inserted once
+	AddFindings(ctx context.Context, tx *model.Trx, scanning_id int64, findings []model.Finding) serror.SError
+
+	// Get list of findings of a scanning by given filters
+	GetFindingList(context.Context, model.FindingListRequest) ([]model.FindingListResponse, serror.SError)
+
+	// Get finding along with triage of its fingerprint by given scanning id and finding id, nil if there is none
+	GetFindingById(ctx context.Context, scanning_id int64, finding_id string) (*model.FindingListResponse, serror.SError)
+
+	// Upsert triage of finding fingerprint by given repository id and fingerprint
+	// Required: RepositoryId, Fingerprint, Status, TriagedBy
+	// Optional: Assignee
+	EditFindingTriage(context.Context, *model.Trx, model.TriageFindingRequest) (model.FindingTriageResponse, serror.SError)
+
+	// Insert comment into thread of finding fingerprint, fingerprint is tracked from now on if it is not yet
+	AddFindingComment(context.Context, *model.Trx, model.AddFindingCommentRequest) (model.FindingComment, serror.SError)
+
+	// Get comment thread of finding fingerprint by given repository id and fingerprint, oldest first
+	GetFindingComments(ctx context.Context, repo_id int64, fingerprint string) ([]model.FindingComment, serror.SError)
+
+	// Insert encrypted secrets of scanning findings, secret of the same finding is inserted once
+	AddFindingSecrets(context.Context, *model.Trx, []model.FindingSecret) serror.SError
+
+	// Copy encrypted secrets of findings from a scanning to another one, secret of the same finding is copied once
+	CopyFindingSecrets(context.Context, *model.Trx, model.CopyFindingSecretsRequest) serror.SError
+
+	// Upsert fingerprints of scanning findings by repository id and fingerprint, given fingerprints must be distinct
+	AddFindingFingerprints(context.Context, *model.Trx, []model.FindingFingerprint) serror.SError
+
+	// Get encrypted secret by given scanning id and finding id, nil if it is not kept
+	GetFindingSecret(ctx context.Context, scanning_id int64, finding_id string) (*model.FindingSecret, serror.SError)
+
+	// Insert audit record of revealed finding secret
+	AddFindingReveal(context.Context, *model.Trx, model.RevealFindingRequest) (model.RevealFindingResponse, serror.SError)
+
+	// Insert next version of repository baseline from findings of given scanning and make it the active one
+	AddBaseline(context.Context, *model.Trx, model.AddBaselineRequest) (model.BaselineResponse, serror.SError)
+
+	// Get every version of baseline by given repository id, latest first
+	GetBaselineList(ctx context.Context, repo_id int64) ([]model.BaselineResponse, serror.SError)
+
+	// Get active baseline by given repository id, nil if there is none
+	GetActiveBaseline(ctx context.Context, repo_id int64) (*model.BaselineResponse, serror.SError)
+
+	// Get finding fingerprints of baseline by given baseline id
+	GetBaselineFingerprints(ctx context.Context, baseline_id int64) ([]string, serror.SError)
+
+	// Make given version of repository baseline the active one, nil if there is no such version
+	ActivateBaseline(context.Context, *model.Trx, model.ActivateBaselineRequest) (*model.BaselineResponse, serror.SError)
+}
+
+type IRuleRepository interface {
+	// Get list of detection rules
+	GetRuleList(context.Context, model.RuleListRequest) ([]model.RuleListResponse, serror.SError)
+
+	// Get every enabled detection rule
+	GetActiveRules(context.Context) ([]model.RuleListResponse, serror.SError)
+
+	// Get detection rule detail by given rule id
+	GetRuleById(ctx context.Context, rule_id int64) (*model.Rule, serror.SError)
+
+	// Insert new detection rule
+	AddRule(context.Context, *model.Trx, model.AddRuleRequest) (model.AddRuleResponse, serror.SError)
+
+	// Update existing detection rule by given rule id.
+	// Required: rule Id.
+	// Optional: Name, Description, Comment, Part, Pattern and IsActive.
+	EditRule(context.Context, *model.Trx, model.EditRuleRequest) (model.EditRuleResponse, serror.SError)
+
+	// Delete existing detection rule by given rule id
+	DeleteRule(context.Context, *model.Trx, int64) serror.SError
+}
+
+type IArchiveRepository interface {
+	// Extract uploaded zip or tar.gz archive into a new directory and return its path
+	Extract(model.UploadArchive) (string, serror.SError)
+
+	// Remove directory of extracted archive
+	Remove(dir string) serror.SError
+}
+
+type ISecretRepository interface {
+	// Redact secret values of findings into preview and keyed hash,
+	// encrypted raw values are returned only if they are configured to be kept
+	Redact(scanning_id int64, findings []model.Finding) ([]model.Finding, []model.FindingSecret, serror.SError)
 
-	// Update status of existing scanning by given repository id
-	// Required: repository Id, status
-	// Optional: Findings
-	EditScanningStatusById(*model.Trx, int64, string, types.JSONText) (model.ScanningResponse, serror.SError)
+	// Decrypt raw secret value of a finding
+	Reveal(model.FindingSecret) (string, serror.SError)
 }
@@ -0,0 +1,271 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"repo-scanner/internal"
+	"repo-scanner/internal/model"
+	"repo-scanner/internal/utils/serror"
+)
+
+const (
+	formatZip   = "zip"
+	formatTarGz = "tar.gz"
+)
+
+type archiveRepository struct {
+	option model.ArchiveOption
+}
+
+// NewArchiveRepository creates repository which extracts uploaded archives under option.Dir
+func NewArchiveRepository(option model.ArchiveOption) internal.IArchiveRepository {
+	return archiveRepository{
+		option: option,
+	}
+}
+
+func (a archiveRepository) Extract(archive model.UploadArchive) (res string, errx serror.SError) {
+	format := archiveFormat(archive.Filename)
+	if format == "" {
+		errx = serror.Newi(http.StatusBadRequest, "Unsupported archive format|Unsupported archive format")
+		errx.AddCommentf("[repository][Extract] while detect format of %s", archive.Filename)
+		return
+	}
+
+	err := os.MkdirAll(a.option.Dir, 0755)
+	if err != nil {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[repository][Extract] while create upload directory %s", a.option.Dir)
+		return
+	}
+	res, err = ioutil.TempDir(a.option.Dir, "upload")
+	if err != nil {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[repository][Extract] while create extract directory")
+		return
+	}
+
+	ex := extractor{
+		dest:   res,
+		option: a.option,
+	}
+	switch format {
+	case formatZip:
+		errx = ex.zip(archive)
+	case formatTarGz:
+		errx = ex.tarGz(archive)
+	}
+	if errx != nil {
+		_ = os.RemoveAll(res)
+		errx.AddCommentf("[repository][Extract] while extract %s", archive.Filename)
+		return "", errx
+	}
+	return
+}
+
+func (a archiveRepository) Remove(dir string) (errx serror.SError) {
+	// Never remove anything outside of upload directory
+	if !isWithin(a.option.Dir, dir) {
+		errx = serror.Newf("Directory %s is not an extracted archive", dir)
+		errx.AddCommentf("[repository][Remove] while check upload directory %s", a.option.Dir)
+		return
+	}
+
+	err := os.RemoveAll(dir)
+	if err != nil {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[repository][Remove] while remove %s", dir)
+		return
+	}
+	return
+}
+
+// archiveFormat tells archive format by filename, empty if it is not supported
+func archiveFormat(filename string) string {
+	filename = strings.ToLower(filename)
+	switch {
+	case strings.HasSuffix(filename, ".zip"):
+		return formatZip
+	case strings.HasSuffix(filename, ".tar.gz"), strings.HasSuffix(filename, ".tgz"):
+		return formatTarGz
+	}
+	return ""
+}
+
+// isWithin tells whether path is inside of dir, not dir itself
+func isWithin(dir string, path string) bool {
+	rel, err := filepath.Rel(filepath.Clean(dir), filepath.Clean(path))
+	if err != nil {
+		return false
+	}
+	return rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) && !filepath.IsAbs(rel)
+}
+
+// extractor writes archive entries into dest, counting extracted files and bytes against option limits
+type extractor struct {
+	dest   string
+	option model.ArchiveOption
+	files  int
+	size   int64
+}
+
+func (e *extractor) zip(archive model.UploadArchive) (errx serror.SError) {
+	reader, err := zip.NewReader(archive.File, archive.Size)
+	if err != nil {
+		errx = serror.Newi(http.StatusBadRequest, "Invalid archive|Invalid archive")
+		errx.AddCommentf("[repository][zip] while read zip: %s", err.Error())
+		return
+	}
+
+	for _, file := range reader.File {
+		mode := file.Mode()
+		switch {
+		case mode.IsDir():
+			errx = e.mkdir(file.Name)
+		case mode.IsRegular():
+			var content io.ReadCloser
+			content, err = file.Open()
+			if err != nil {
+				errx = serror.Newi(http.StatusBadRequest, "Invalid archive|Invalid archive")
+				errx.AddCommentf("[repository][zip] while open %s: %s", file.Name, err.Error())
+				return
+			}
+			errx = e.write(file.Name, content)
+			content.Close()
+		default:
+			// symbolic links and devices are skipped, they may point outside of extracted tree
+			continue
+		}
+		if errx != nil {
+			errx.AddCommentf("[repository][zip] while extract %s", file.Name)
+			return
+		}
+	}
+	return
+}
+
+func (e *extractor) tarGz(archive model.UploadArchive) (errx serror.SError) {
+	gz, err := gzip.NewReader(archive.File)
+	if err != nil {
+		errx = serror.Newi(http.StatusBadRequest, "Invalid archive|Invalid archive")
+		errx.AddCommentf("[repository][tarGz] while read gzip: %s", err.Error())
+		return
+	}
+	defer gz.Close()
+
+	reader := tar.NewReader(gz)
+	for {
+		var header *tar.Header
+		header, err = reader.Next()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			errx = serror.Newi(http.StatusBadRequest, "Invalid archive|Invalid archive")
+			errx.AddCommentf("[repository][tarGz] while read tar: %s", err.Error())
+			return
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			errx = e.mkdir(header.Name)
+		case tar.TypeReg, tar.TypeRegA:
+			errx = e.write(header.Name, reader)
+		default:
+			// links and devices are skipped, they may point outside of extracted tree
+			continue
+		}
+		if errx != nil {
+			errx.AddCommentf("[repository][tarGz] while extract %s", header.Name)
+			return
+		}
+	}
+}
+
+// path returns where archive entry is extracted, entries escaping dest (zip slip) are rejected
+func (e *extractor) path(name string) (res string, errx serror.SError) {
+	name = filepath.FromSlash(strings.ReplaceAll(name, "\\", "/"))
+	res = filepath.Join(e.dest, name)
+	if filepath.IsAbs(name) || filepath.VolumeName(name) != "" || !isWithin(e.dest, res) {
+		errx = serror.Newi(http.StatusBadRequest, "Archive entry escapes extract directory|Archive entry escapes extract directory")
+		errx.AddCommentf("[repository][path] entry %s", name)
+		return "", errx
+	}
+	return
+}
+
+func (e *extractor) mkdir(name string) (errx serror.SError) {
+	// root entry, e.g. "./" of tarball made in source directory, is dest itself
+	if filepath.Clean(filepath.FromSlash(name)) == "." {
+		return
+	}
+
+	dir, errx := e.path(name)
+	if errx != nil {
+		return
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[repository][mkdir] while create %s", dir)
+		return
+	}
+	return
+}
+
+func (e *extractor) write(name string, content io.Reader) (errx serror.SError) {
+	e.files++
+	if e.option.MaxFiles > 0 && e.files > e.option.MaxFiles {
+		errx = serror.Newi(http.StatusBadRequest, "Archive has too many files|Archive has too many files")
+		errx.AddCommentf("[repository][write] more than %d files", e.option.MaxFiles)
+		return
+	}
+
+	path, errx := e.path(name)
+	if errx != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[repository][write] while create directory of %s", path)
+		return
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[repository][write] while create %s", path)
+		return
+	}
+	defer file.Close()
+
+	// Sizes declared by archive headers can lie, so extracted bytes are counted instead
+	var written int64
+	remaining := e.option.MaxExtractedSize - e.size
+	if e.option.MaxExtractedSize > 0 {
+		written, err = io.CopyN(file, content, remaining+1)
+	} else {
+		written, err = io.Copy(file, content)
+	}
+	e.size += written
+	if err != nil && err != io.EOF {
+		errx = serror.Newi(http.StatusBadRequest, "Invalid archive|Invalid archive")
+		errx.AddCommentf("[repository][write] while write %s: %s", path, err.Error())
+		return
+	}
+	if e.option.MaxExtractedSize > 0 && written > remaining {
+		errx = serror.Newi(http.StatusBadRequest, "Archive is too large once extracted|Archive is too large once extracted")
+		errx.AddCommentf("[repository][write] more than %s extracted", fmt.Sprint(e.option.MaxExtractedSize))
+		return
+	}
+	return
+}
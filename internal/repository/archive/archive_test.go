@@ -0,0 +1,213 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"repo-scanner/internal/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type entry struct {
+	name    string
+	content string
+	link    string
+}
+
+func newZip(t *testing.T, entries []entry) []byte {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for _, e := range entries {
+		header := &zip.FileHeader{Name: e.name, Method: zip.Deflate}
+		if e.link != "" {
+			header.SetMode(os.ModeSymlink | 0777)
+		}
+		f, err := w.CreateHeader(header)
+		if err != nil {
+			t.Fatal(err)
+		}
+		content := e.content
+		if e.link != "" {
+			content = e.link
+		}
+		if _, err = f.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func newTarGz(t *testing.T, entries []entry) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	w := tar.NewWriter(gz)
+	for _, e := range entries {
+		header := &tar.Header{Name: e.name, Mode: 0644, Size: int64(len(e.content)), Typeflag: tar.TypeReg}
+		switch {
+		case e.link != "":
+			header.Typeflag = tar.TypeSymlink
+			header.Linkname = e.link
+			header.Size = 0
+		case strings.HasSuffix(e.name, "/"):
+			header.Typeflag = tar.TypeDir
+			header.Mode = 0755
+		}
+		if err := w.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if header.Typeflag == tar.TypeReg {
+			if _, err := w.Write([]byte(e.content)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestExtract(t *testing.T) {
+	dir, err := ioutil.TempDir("", "reposcan-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	repo := NewArchiveRepository(model.ArchiveOption{
+		Dir:              filepath.Join(dir, "uploads"),
+		MaxExtractedSize: 64,
+		MaxFiles:         3,
+	})
+
+	tests := []struct {
+		name     string
+		filename string
+		content  []byte
+		want     map[string]string
+		wantErr  bool
+	}{
+		{
+			name:     "zip",
+			filename: "drop.zip",
+			content: newZip(t, []entry{
+				{name: "app.yml", content: "password: hunter2\n"},
+				{name: "config/.npmrc", content: "token\n"},
+				{name: "link", link: "/etc/passwd"},
+			}),
+			want: map[string]string{
+				"app.yml":       "password: hunter2\n",
+				"config/.npmrc": "token\n",
+			},
+		},
+		{
+			name:     "tar.gz",
+			filename: "drop.tar.gz",
+			content: newTarGz(t, []entry{
+				{name: "./"},
+				{name: "./src/"},
+				{name: "./src/main.go", content: "package main\n"},
+				{name: "./src/link", link: "../../../etc/passwd"},
+			}),
+			want: map[string]string{
+				"src/main.go": "package main\n",
+			},
+		},
+		{
+			name:     "zip slip",
+			filename: "evil.zip",
+			content:  newZip(t, []entry{{name: "../../evil.sh", content: "rm -rf /\n"}}),
+			wantErr:  true,
+		},
+		{
+			name:     "tar slip",
+			filename: "evil.tgz",
+			content:  newTarGz(t, []entry{{name: "src/../../evil.sh", content: "rm -rf /\n"}}),
+			wantErr:  true,
+		},
+		{
+			name:     "too large once extracted",
+			filename: "bomb.zip",
+			content:  newZip(t, []entry{{name: "zeros", content: strings.Repeat("0", 65)}}),
+			wantErr:  true,
+		},
+		{
+			name:     "too many files",
+			filename: "many.tar.gz",
+			content: newTarGz(t, []entry{
+				{name: "a", content: "a"},
+				{name: "b", content: "b"},
+				{name: "c", content: "c"},
+				{name: "d", content: "d"},
+			}),
+			wantErr: true,
+		},
+		{
+			name:     "unsupported format",
+			filename: "drop.rar",
+			content:  []byte("rar"),
+			wantErr:  true,
+		},
+		{
+			name:     "corrupted archive",
+			filename: "drop.zip",
+			content:  []byte("not a zip"),
+			wantErr:  true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			res, errx := repo.Extract(model.UploadArchive{
+				Filename: test.filename,
+				Size:     int64(len(test.content)),
+				File:     bytes.NewReader(test.content),
+			})
+			if test.wantErr {
+				assert.NotNil(t, errx)
+				assert.Equal(t, "", res)
+				_, err := os.Stat(filepath.Join(dir, "evil.sh"))
+				assert.True(t, os.IsNotExist(err))
+				return
+			}
+			assert.Nil(t, errx)
+
+			got := map[string]string{}
+			err := filepath.Walk(res, func(path string, info os.FileInfo, err error) error {
+				if err != nil || info.IsDir() {
+					return err
+				}
+				content, err := ioutil.ReadFile(path)
+				if err != nil {
+					return err
+				}
+				rel, _ := filepath.Rel(res, path)
+				got[filepath.ToSlash(rel)] = string(content)
+				return nil
+			})
+			assert.Nil(t, err)
+			assert.Equal(t, test.want, got)
+
+			assert.Nil(t, repo.Remove(res))
+			assert.NoDirExists(t, res)
+		})
+	}
+
+	// Nothing outside of upload directory can be removed
+	assert.NotNil(t, repo.Remove(dir))
+	assert.DirExists(t, dir)
+}
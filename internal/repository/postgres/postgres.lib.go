@@ -1,6 +1,7 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"reflect"
@@ -128,7 +129,7 @@ func (ox psql) InsertTable(name string, tx *model.Trx, args sqlq.TableData) (res
 	}
 
 	if auto {
-		tx, errx = ox.TrxRepo.Create()
+		tx, errx = ox.TrxRepo.Create(context.Background())
 		if errx != nil {
 			errx.AddComments("[repository][InsertTable] while Create transaction")
 			return resp, errx
@@ -224,7 +225,7 @@ func (ox psql) SetTable(name string, tx *model.Trx, args sqlq.TableData) (resp m
 	}
 
 	if auto {
-		tx, errx = ox.TrxRepo.Create()
+		tx, errx = ox.TrxRepo.Create(context.Background())
 		if errx != nil {
 			errx.AddComments("[repository][SetTable] while Create transaction")
 			return resp, errx
@@ -320,7 +321,7 @@ func (ox psql) SetV2Table(name string, tx *model.Trx, args sqlq.TableData) (resp
 	}
 
 	if auto {
-		tx, errx = ox.TrxRepo.Create()
+		tx, errx = ox.TrxRepo.Create(context.Background())
 		if errx != nil {
 			errx.AddComments("[repository][SetV2Table] while Create transaction")
 			return resp, errx
@@ -405,7 +406,7 @@ func (ox psql) InsertHeaderEAVTable(header string, detail string, tx *model.Trx,
 	}
 
 	if auto {
-		tx, errx = ox.TrxRepo.Create()
+		tx, errx = ox.TrxRepo.Create(context.Background())
 		if errx != nil {
 			errx.AddComments("[repository][InsertHeaderEAVTable] while Create transaction")
 			return resp, errx
@@ -524,7 +525,7 @@ func (ox psql) UpdateHeaderEAVTable(header string, detail string, tx *model.Trx,
 	}
 
 	if auto {
-		tx, errx = ox.TrxRepo.Create()
+		tx, errx = ox.TrxRepo.Create(context.Background())
 		if errx != nil {
 			errx.AddComments("[repository][UpdateHeaderEAVTable] while Create transaction")
 			return errx
@@ -588,7 +589,7 @@ func (ox psql) UpdateTable(name string, tx *model.Trx, args sqlq.TableData) (err
 	}
 
 	if auto {
-		tx, errx = ox.TrxRepo.Create()
+		tx, errx = ox.TrxRepo.Create(context.Background())
 		if errx != nil {
 			errx.AddComments("[repository][UpdateTable] while Create transaction")
 			return errx
@@ -814,7 +815,7 @@ func (ox psql) BatchUpdateTable(name string, tx *model.Trx, args sqlq.TableData)
 
 	var err error
 	if auto {
-		tx, errx = ox.TrxRepo.Create()
+		tx, errx = ox.TrxRepo.Create(context.Background())
 		if errx != nil {
 			errx.AddComments("[repository][BatchUpdateTable] while Create transaction")
 			return errx
@@ -872,7 +873,7 @@ func (ox psql) InsertEAVTable(name string, tx *model.Trx, args sqlq.EAVData) (re
 	}
 
 	if auto {
-		tx, errx = ox.TrxRepo.Create()
+		tx, errx = ox.TrxRepo.Create(context.Background())
 		if errx != nil {
 			errx.AddComments("[repository][InsertEAVTable] while Create transaction")
 			return resp, errx
@@ -968,7 +969,7 @@ func (ox psql) SetEAVTable(name string, tx *model.Trx, args sqlq.EAVData, dels m
 	}
 
 	if auto {
-		tx, errx = ox.TrxRepo.Create()
+		tx, errx = ox.TrxRepo.Create(context.Background())
 		if errx != nil {
 			errx.AddComments("[repository][SetEAVTable] while Create trasaction")
 			return resp, errx
@@ -6,7 +6,13 @@ const (
 			repository_id,
 			repository_name,
 			repository_url,
-			is_active
+			default_ref,
+			is_upload,
+			is_active,
+			scan_schedule,
+			scan_timezone,
+			next_scheduled_scan_at,
+			last_scheduled_scan_at
 		FROM
 			reposcan.repositories
 		WHERE
@@ -22,6 +28,8 @@ const (
 			repository_id,
 			repository_name,
 			repository_url,
+			default_ref,
+			is_upload,
 			is_active,
 			created_by,
 			created_at,
@@ -40,16 +48,20 @@ const (
 		INSERT INTO reposcan.repositories(
 			repository_name,
 			repository_url,
+			default_ref,
+			is_upload,
 			created_by,
 			created_at,
 			modified_by,
 			modified_at
 		)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		VALUES ($1, $2, NULLIF($3, ''), CASE WHEN $4 THEN '1'::BIT ELSE '0'::BIT END, $5, $6, $7, $8)
 		RETURNING
 			repository_id,
 			repository_name,
 			repository_url,
+			default_ref,
+			is_upload,
 			is_active
 	`
 
@@ -64,6 +76,8 @@ const (
 								CASE WHEN $7 THEN '1'::BIT
 									ELSE '0'::BIT END
 							ELSE is_active END,
+			default_ref = CASE WHEN $10 = true THEN NULLIF($11, '')
+							ELSE default_ref END,
 			modified_by = $8,
 			modified_at = $9
 		WHERE
@@ -72,9 +86,49 @@ const (
 			repository_id,
 			repository_name,
 			repository_url,
+			default_ref,
+			is_upload,
 			is_active
 	`
 
+	// Repository row is locked until its transaction ends, e.g. so that its baselines are numbered one by one
+	LockRepository = `
+		SELECT
+			repository_id
+		FROM
+			reposcan.repositories
+		WHERE
+			repository_id = $1
+		FOR UPDATE
+	`
+
+	// Synthetic repository of uploaded archive is deactivated once none of its scannings is queued or in progress,
+	// path of its extracted archive is returned to be removed
+	DeactivateUploadRepository = `
+		UPDATE reposcan.repositories r
+		SET
+			is_active = '0'::BIT,
+			modified_by = 'Automated',
+			modified_at = $2
+		WHERE
+			r.repository_id = $1
+		AND r.is_upload = '1'::BIT
+		AND r.is_active = '1'::BIT
+		AND r.deleted_by IS NULL
+		AND NOT EXISTS (
+			SELECT
+				1
+			FROM
+				reposcan.scannings s
+			WHERE
+				s.repository_id = r.repository_id
+			AND s.scanning_status IN ('queued'::reposcan.scanning_status, 'in_progress'::reposcan.scanning_status)
+			AND s.deleted_by IS NULL
+		)
+		RETURNING
+			r.repository_url
+	`
+
 	DeleteRepository = `
 		UPDATE reposcan.repositories
 		SET
@@ -84,4 +138,93 @@ const (
 		WHERE
 			repository_id = $1
 	`
+
+	GetRepositorySettings = `
+		SELECT
+			scan_settings
+		FROM
+			reposcan.repositories
+		WHERE
+			repository_id = $1
+		AND deleted_by IS NULL
+	`
+
+	UpdateRepositorySettings = `
+		UPDATE reposcan.repositories
+		SET
+			scan_settings = $2,
+			modified_by = $3,
+			modified_at = $4
+		WHERE
+			repository_id = $1
+		AND deleted_by IS NULL
+		RETURNING
+			scan_settings
+	`
+
+	GetRepositorySchedule = `
+		SELECT
+			COALESCE(scan_schedule, '') AS scan_schedule,
+			COALESCE(scan_timezone, '') AS scan_timezone,
+			next_scheduled_scan_at,
+			last_scheduled_scan_at
+		FROM
+			reposcan.repositories
+		WHERE
+			repository_id = $1
+		AND deleted_by IS NULL
+	`
+
+	// Schedule is turned off once its cron expression is empty
+	UpdateRepositorySchedule = `
+		UPDATE reposcan.repositories
+		SET
+			scan_schedule = NULLIF($2, ''),
+			scan_timezone = NULLIF($3, ''),
+			next_scheduled_scan_at = $4,
+			modified_by = $5,
+			modified_at = $6
+		WHERE
+			repository_id = $1
+		AND deleted_by IS NULL
+		RETURNING
+			COALESCE(scan_schedule, '') AS scan_schedule,
+			COALESCE(scan_timezone, '') AS scan_timezone,
+			next_scheduled_scan_at,
+			last_scheduled_scan_at
+	`
+
+	GetScheduledRepositories = `
+		SELECT
+			repository_id,
+			default_ref,
+			scan_schedule,
+			COALESCE(scan_timezone, '') AS scan_timezone,
+			next_scheduled_scan_at
+		FROM
+			reposcan.repositories
+		WHERE
+			scan_schedule IS NOT NULL
+		AND next_scheduled_scan_at <= $1
+		AND is_active = '1'::BIT
+		AND deleted_by IS NULL
+		ORDER BY
+			next_scheduled_scan_at ASC
+	`
+
+	// Schedule is moved forward only if it is still due at $2, replica which moves it queues the scan,
+	// others wait for its row lock and find it is no longer due
+	AdvanceRepositorySchedule = `
+		UPDATE reposcan.repositories
+		SET
+			next_scheduled_scan_at = $3,
+			last_scheduled_scan_at = $4
+		WHERE
+			repository_id = $1
+		AND next_scheduled_scan_at = $2
+		AND scan_schedule IS NOT NULL
+		AND deleted_by IS NULL
+		RETURNING
+			repository_id
+	`
 )
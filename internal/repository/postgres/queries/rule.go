@@ -0,0 +1,126 @@
+package queries
+
+const (
+	GetRuleList = `
+		SELECT
+			rule_id,
+			rule_name,
+			description,
+			comment,
+			part,
+			pattern,
+			is_active
+		FROM
+			reposcan.rules
+		WHERE
+			deleted_by IS NULL
+		ORDER BY
+			rule_id DESC
+		LIMIT $1
+		OFFSET $2
+	`
+
+	GetActiveRules = `
+		SELECT
+			rule_id,
+			rule_name,
+			description,
+			comment,
+			part,
+			pattern,
+			is_active
+		FROM
+			reposcan.rules
+		WHERE
+			is_active = '1'::BIT
+		AND deleted_by IS NULL
+		ORDER BY
+			rule_id ASC
+	`
+
+	GetRuleById = `
+		SELECT
+			rule_id,
+			rule_name,
+			description,
+			comment,
+			part,
+			pattern,
+			is_active,
+			created_by,
+			created_at,
+			modified_by,
+			modified_at,
+			deleted_by,
+			deleted_at
+		FROM
+			reposcan.rules
+		WHERE
+			rule_id = $1
+		AND deleted_by IS NULL
+	`
+
+	InsertNewRule = `
+		INSERT INTO reposcan.rules(
+			rule_name,
+			description,
+			comment,
+			part,
+			pattern,
+			created_by,
+			created_at,
+			modified_by,
+			modified_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING
+			rule_id,
+			rule_name,
+			description,
+			comment,
+			part,
+			pattern,
+			is_active
+	`
+
+	EditRule = `
+		UPDATE reposcan.rules
+		SET
+			rule_name = CASE WHEN $2 = true THEN $3
+							ELSE rule_name END,
+			description = CASE WHEN $4 = true THEN $5
+							ELSE description END,
+			comment = CASE WHEN $6 = true THEN $7
+							ELSE comment END,
+			part = CASE WHEN $8 = true THEN $9::reposcan.rule_part
+							ELSE part END,
+			pattern = CASE WHEN $10 = true THEN $11
+							ELSE pattern END,
+			is_active = CASE WHEN $12 = true THEN
+								CASE WHEN $13 THEN '1'::BIT
+									ELSE '0'::BIT END
+							ELSE is_active END,
+			modified_by = $14,
+			modified_at = $15
+		WHERE
+			rule_id = $1
+		RETURNING
+			rule_id,
+			rule_name,
+			description,
+			comment,
+			part,
+			pattern,
+			is_active
+	`
+
+	DeleteRule = `
+		UPDATE reposcan.rules
+		SET
+			is_active = '0'::BIT,
+			deleted_by = $2,
+			deleted_at = $3
+		WHERE
+			rule_id = $1
+	`
+)
@@ -4,13 +4,30 @@ const (
 	GetScanningList = `
 		SELECT 
 			s.scanning_id,
+			s.repository_id,
 			r.repository_name,
 			r.repository_url,
+			r.is_upload,
 			s.findings,
+			s.scan_engines,
 			s.scanning_status,
+			s.scan_mode,
+			s.is_full_scan,
+			s.base_ref,
+			s.ref,
+			s.since_commit,
+			s.head_commit,
 			s.queued_at,
 			s.scanning_at,
-			s.finished_at
+			s.finished_at,
+			s.claimed_by,
+			s.lease_expires_at,
+			s.attempts,
+			s.max_attempts,
+			s.next_attempt_at,
+			s.last_error,
+			s.last_error_stack,
+			s.priority
 		FROM
 			reposcan.repositories r
 		JOIN
@@ -29,21 +46,74 @@ const (
 	InsertNewScanning = `
 		INSERT INTO reposcan.scannings(
 			repository_id,
+			scan_mode,
+			is_full_scan,
+			base_ref,
+			ref,
 			queued_at,
 			created_by,
 			created_at,
 			modified_by,
-			modified_at
+			modified_at,
+			max_attempts,
+			priority
 		)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		VALUES ($1, $2::reposcan.scan_mode, CASE WHEN $3 THEN '1'::BIT ELSE '0'::BIT END,
+			NULLIF($4, ''), NULLIF($5, ''), $6, $7, $8, $9, $10, $11, COALESCE($12, 0))
+		ON CONFLICT (repository_id, scan_mode, is_full_scan, COALESCE(base_ref, ''), COALESCE(ref, ''))
+			WHERE scanning_status = 'queued'::reposcan.scanning_status AND attempts = 0 AND deleted_by IS NULL
+		DO NOTHING
 		RETURNING
 			scanning_id,
 			repository_id,
 			findings,
+			scan_engines,
 			scanning_status,
+			scan_mode,
+			is_full_scan,
+			base_ref,
+			ref,
+			since_commit,
+			head_commit,
 			queued_at,
 			scanning_at,
-			finished_at
+			finished_at,
+			max_attempts,
+			priority
+	`
+
+	// Identical scanning queued and never attempted yet is returned instead of a new one,
+	// it is raised to priority $6 if that is higher
+	UpdateQueuedDuplicateScanning = `
+		UPDATE reposcan.scannings
+		SET
+			priority = GREATEST(priority, COALESCE($6, 0))
+		WHERE
+			repository_id = $1
+		AND scan_mode = $2::reposcan.scan_mode
+		AND is_full_scan = CASE WHEN $3 THEN '1'::BIT ELSE '0'::BIT END
+		AND COALESCE(base_ref, '') = $4
+		AND COALESCE(ref, '') = $5
+		AND scanning_status = 'queued'::reposcan.scanning_status
+		AND attempts = 0
+		AND deleted_by IS NULL
+		RETURNING
+			scanning_id,
+			repository_id,
+			findings,
+			scan_engines,
+			scanning_status,
+			scan_mode,
+			is_full_scan,
+			base_ref,
+			ref,
+			since_commit,
+			head_commit,
+			queued_at,
+			scanning_at,
+			finished_at,
+			max_attempts,
+			priority
 	`
 
 	UpdateScanningInProgressById = `
@@ -55,33 +125,755 @@ const (
 			modified_at = $4
 		WHERE
 			scanning_id = $1
+		AND scanning_status = 'queued'::reposcan.scanning_status
 		RETURNING
 			scanning_id,
 			repository_id,
 			findings,
+			scan_engines,
 			scanning_status,
+			scan_mode,
+			is_full_scan,
+			base_ref,
+			ref,
+			since_commit,
+			head_commit,
 			queued_at,
 			scanning_at,
-			finished_at
+			finished_at,
+			max_attempts,
+			priority
+	`
+
+	// Queued scanning of highest priority, oldest first, is claimed and updated 'in_progress' at once, rows locked by
+	// other instances are skipped so that no scanning is claimed twice. Waiting scanning is raised one priority every
+	// $4 seconds unless it is 0. Claimed scanning is leased to its worker until $3,
+	// scanning to be retried is not claimed until its next attempt is due
+	ClaimScanning = `
+		WITH claimed AS (
+			SELECT
+				scanning_id
+			FROM
+				reposcan.scannings
+			WHERE
+				scanning_status = 'queued'::reposcan.scanning_status
+			AND (next_attempt_at IS NULL OR next_attempt_at <= $2)
+			AND deleted_by IS NULL
+			ORDER BY
+				priority + CASE WHEN $4::float8 > 0 THEN floor(extract(epoch FROM $2 - queued_at) / $4::float8)::int ELSE 0 END DESC,
+				queued_at ASC
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE reposcan.scannings s
+		SET
+			scanning_status = 'in_progress'::reposcan.scanning_status,
+			scanning_at = $2,
+			claimed_by = $1,
+			lease_expires_at = $3,
+			attempts = s.attempts + 1,
+			modified_by = 'Automated',
+			modified_at = $2
+		FROM
+			claimed,
+			reposcan.repositories r
+		WHERE
+			s.scanning_id = claimed.scanning_id
+		AND r.repository_id = s.repository_id
+		RETURNING
+			s.scanning_id,
+			s.repository_id,
+			r.repository_name,
+			r.repository_url,
+			s.findings,
+			s.scan_engines,
+			s.scanning_status,
+			s.scan_mode,
+			s.is_full_scan,
+			s.base_ref,
+			s.ref,
+			s.since_commit,
+			s.head_commit,
+			s.queued_at,
+			s.scanning_at,
+			s.finished_at,
+			s.claimed_by,
+			s.lease_expires_at,
+			s.attempts,
+			s.max_attempts,
+			s.next_attempt_at,
+			s.last_error,
+			s.last_error_stack,
+			s.priority
+	`
+
+	// Lease is renewed as long as scanning is in progress by the same worker
+	UpdateScanningLeaseById = `
+		UPDATE reposcan.scannings
+		SET
+			lease_expires_at = $3
+		WHERE
+			scanning_id = $1
+		AND claimed_by = $2
+		AND scanning_status = 'in_progress'::reposcan.scanning_status
+	`
+
+	// Scannings whose lease expires are queued again with last error $1 unless they have been attempted as many times as allowed,
+	// which are dead instead
+	UpdateExpiredScannings = `
+		WITH expired AS (
+			SELECT
+				scanning_id,
+				claimed_by
+			FROM
+				reposcan.scannings
+			WHERE
+				scanning_status = 'in_progress'::reposcan.scanning_status
+			AND lease_expires_at < $2
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE reposcan.scannings s
+		SET
+			scanning_status = CASE WHEN s.attempts < s.max_attempts
+				THEN 'queued'::reposcan.scanning_status ELSE 'dead'::reposcan.scanning_status END,
+			findings = CASE WHEN s.attempts < s.max_attempts THEN s.findings ELSE jsonb_build_object('reason', $1::text) END,
+			scanning_at = CASE WHEN s.attempts < s.max_attempts THEN NULL ELSE s.scanning_at END,
+			finished_at = CASE WHEN s.attempts < s.max_attempts THEN NULL ELSE $2::timestamp END,
+			claimed_by = CASE WHEN s.attempts < s.max_attempts THEN NULL ELSE s.claimed_by END,
+			next_attempt_at = NULL,
+			last_error = $1,
+			last_error_stack = NULL,
+			lease_expires_at = NULL,
+			modified_by = 'Automated',
+			modified_at = $2
+		FROM
+			expired
+		WHERE
+			s.scanning_id = expired.scanning_id
+		RETURNING
+			s.scanning_id,
+			s.repository_id,
+			s.scanning_status,
+			s.attempts,
+			expired.claimed_by
+	`
+
+	// Scanning failing for transient error is queued again to be claimed from $3 on, unless it is no longer claimed by $2
+	UpdateScanningRetryById = `
+		UPDATE reposcan.scannings
+		SET
+			scanning_status = 'queued'::reposcan.scanning_status,
+			scanning_at = NULL,
+			claimed_by = NULL,
+			lease_expires_at = NULL,
+			next_attempt_at = $3,
+			last_error = NULLIF($4, ''),
+			last_error_stack = $5,
+			modified_by = 'Automated',
+			modified_at = $6
+		WHERE
+			scanning_id = $1
+		AND scanning_status = 'in_progress'::reposcan.scanning_status
+		AND ($2 = '' OR claimed_by = $2)
+		RETURNING
+			scanning_id,
+			repository_id,
+			findings,
+			scan_engines,
+			scanning_status,
+			scan_mode,
+			is_full_scan,
+			base_ref,
+			ref,
+			since_commit,
+			head_commit,
+			queued_at,
+			scanning_at,
+			finished_at,
+			max_attempts,
+			priority
 	`
 
 	UpdateScanningFinishedById = `
 		UPDATE reposcan.scannings
 		SET
 			scanning_status = $2,
-			finished_at = $5,
+			finished_at = $6,
 			findings = $3,
-			modified_by = $4,
-			modified_at = $5
+			scan_engines = $4,
+			since_commit = NULLIF($7, ''),
+			head_commit = NULLIF($8, ''),
+			last_error = NULLIF($10, ''),
+			last_error_stack = $11,
+			lease_expires_at = NULL,
+			modified_by = $5,
+			modified_at = $6
+		WHERE
+			scanning_id = $1
+		AND scanning_status = 'in_progress'::reposcan.scanning_status
+		AND ($9 = '' OR claimed_by = $9)
+		RETURNING
+			scanning_id,
+			repository_id,
+			findings,
+			scan_engines,
+			scanning_status,
+			scan_mode,
+			is_full_scan,
+			base_ref,
+			ref,
+			since_commit,
+			head_commit,
+			queued_at,
+			scanning_at,
+			finished_at,
+			max_attempts,
+			priority
+	`
+
+	GetScanningById = `
+		SELECT
+			scanning_id,
+			repository_id,
+			findings,
+			scan_engines,
+			scanning_status,
+			scan_mode,
+			is_full_scan,
+			base_ref,
+			ref,
+			since_commit,
+			head_commit,
+			queued_at,
+			scanning_at,
+			finished_at,
+			max_attempts,
+			priority
+		FROM
+			reposcan.scannings
+		WHERE
+			scanning_id = $1
+		AND deleted_by IS NULL
+	`
+
+	CancelScanningById = `
+		UPDATE reposcan.scannings
+		SET
+			scanning_status = 'cancelled'::reposcan.scanning_status,
+			finished_at = $3,
+			modified_by = $2,
+			modified_at = $3
 		WHERE
 			scanning_id = $1
+		AND scanning_status IN ('queued'::reposcan.scanning_status, 'in_progress'::reposcan.scanning_status)
 		RETURNING
 			scanning_id,
 			repository_id,
 			findings,
+			scan_engines,
 			scanning_status,
+			scan_mode,
+			is_full_scan,
+			base_ref,
+			ref,
+			since_commit,
+			head_commit,
+			queued_at,
+			scanning_at,
+			finished_at,
+			max_attempts,
+			priority
+	`
+
+	GetLastSuccessfulScanning = `
+		SELECT
+			scanning_id,
+			repository_id,
+			findings,
+			scan_engines,
+			scanning_status,
+			scan_mode,
+			is_full_scan,
+			base_ref,
+			ref,
+			since_commit,
+			head_commit,
+			queued_at,
+			scanning_at,
+			finished_at
+		FROM
+			reposcan.scannings
+		WHERE
+			repository_id = $1
+		AND COALESCE(ref, '') = $2
+		AND scan_mode = 'standard'::reposcan.scan_mode
+		AND scanning_status = 'success'::reposcan.scanning_status
+		AND head_commit IS NOT NULL
+		AND deleted_by IS NULL
+		ORDER BY
+			finished_at DESC
+		LIMIT 1
+	`
+
+	GetPreviousScanning = `
+		SELECT
+			scanning_id,
+			repository_id,
+			findings,
+			scan_engines,
+			scanning_status,
+			scan_mode,
+			is_full_scan,
+			base_ref,
+			ref,
+			since_commit,
+			head_commit,
 			queued_at,
 			scanning_at,
 			finished_at
+		FROM
+			reposcan.scannings
+		WHERE
+			repository_id = $1
+		AND COALESCE(ref, '') = $2
+		AND scanning_id <> $3
+		AND scan_mode = 'standard'::reposcan.scan_mode
+		AND scanning_status = 'success'::reposcan.scanning_status
+		AND deleted_by IS NULL
+		ORDER BY
+			finished_at DESC
+		LIMIT 1
+	`
+
+	UpsertFindingFingerprints = `
+		INSERT INTO reposcan.finding_fingerprints AS fp(
+			repository_id,
+			fingerprint,
+			rule,
+			file_path,
+			occurrence,
+			first_scanning_id,
+			last_scanning_id,
+			first_seen_at,
+			last_seen_at
+		)
+		SELECT $1, fingerprint, rule, file_path, occurrence::reposcan.finding_occurrence, $2, $2, $7, $7
+		FROM
+			unnest($3::varchar[], $4::varchar[], $5::varchar[], $6::varchar[]) AS f(fingerprint, rule, file_path, occurrence)
+		ON CONFLICT (repository_id, fingerprint) DO UPDATE
+		SET
+			occurrence = EXCLUDED.occurrence,
+			last_scanning_id = EXCLUDED.last_scanning_id,
+			last_seen_at = EXCLUDED.last_seen_at,
+			triage_status = CASE
+				WHEN fp.triage_status = 'resolved'::reposcan.triage_status AND EXCLUDED.occurrence <> 'fixed'::reposcan.finding_occurrence
+				THEN 'open'::reposcan.triage_status
+				ELSE fp.triage_status END
+	`
+
+	InsertFindings = `
+		INSERT INTO reposcan.findings(
+			scanning_id,
+			finding_id,
+			engine,
+			rule,
+			severity,
+			file_path,
+			line,
+			commit_hash,
+			commit_author,
+			fingerprint,
+			occurrence,
+			suppression_source,
+			suppression_reason,
+			baseline_version,
+			finding,
+			created_at
+		)
+		SELECT $1, finding_id, engine, rule, severity::reposcan.finding_severity, file_path, line,
+			commit_hash, commit_author, fingerprint, NULLIF(occurrence, '')::reposcan.finding_occurrence,
+			NULLIF(suppression_source, ''), NULLIF(suppression_reason, ''), NULLIF(baseline_version, 0), finding, $16
+		FROM
+			unnest($2::varchar[], $3::varchar[], $4::varchar[], $5::varchar[], $6::varchar[], $7::bigint[],
+				$8::varchar[], $9::varchar[], $10::varchar[], $11::varchar[], $12::varchar[], $13::varchar[],
+				$14::int[], $15::jsonb[])
+			AS f(finding_id, engine, rule, severity, file_path, line, commit_hash, commit_author,
+				fingerprint, occurrence, suppression_source, suppression_reason, baseline_version, finding)
+		ON CONFLICT (scanning_id, finding_id) DO NOTHING
+	`
+
+	GetFindingList = `
+		SELECT
+			f.scanning_id,
+			s.repository_id,
+			f.finding_id,
+			f.engine,
+			f.rule,
+			f.severity,
+			f.file_path,
+			f.line,
+			f.commit_hash,
+			f.commit_author,
+			f.fingerprint,
+			f.occurrence,
+			f.suppression_source,
+			f.suppression_reason,
+			f.baseline_version,
+			COALESCE(fp.triage_status, 'open'::reposcan.triage_status) AS triage_status,
+			fp.assignee,
+			f.finding,
+			f.created_at
+		FROM
+			reposcan.findings f
+		JOIN
+			reposcan.scannings s
+		ON
+			s.scanning_id = f.scanning_id
+		LEFT JOIN
+			reposcan.finding_fingerprints fp
+		ON
+			fp.repository_id = s.repository_id
+		AND fp.fingerprint = f.fingerprint
+		WHERE
+			f.scanning_id = $1
+		AND ('' = $2 OR f.severity::text = $2)
+		AND ('' = $3 OR f.occurrence::text = $3)
+		AND ('' = $4 OR f.engine = $4)
+		AND ('' = $5 OR f.rule = $5)
+		AND left(f.file_path, length($6)) = $6
+		AND ('' = $7 OR f.commit_hash = $7)
+		AND ('' = $8 OR f.commit_author = $8)
+		AND (CASE $9
+			WHEN 'all' THEN true
+			WHEN '' THEN COALESCE(fp.triage_status::text, 'open') NOT IN ('false_positive', 'accepted_risk')
+			ELSE COALESCE(fp.triage_status::text, 'open') = $9 END)
+		AND ('' = $10 OR fp.assignee = $10)
+		AND (CASE $11
+			WHEN 'all' THEN true
+			WHEN 'true' THEN f.suppression_source IS NOT NULL
+			ELSE f.suppression_source IS NULL END)
+		AND (CASE $12
+			WHEN 'all' THEN true
+			WHEN 'true' THEN f.baseline_version IS NOT NULL
+			ELSE f.baseline_version IS NULL END)
+		ORDER BY
+			f.severity DESC,
+			f.file_path,
+			f.line,
+			f.finding_id
+		LIMIT $13
+		OFFSET $14
+	`
+
+	GetFindingById = `
+		SELECT
+			f.scanning_id,
+			s.repository_id,
+			f.finding_id,
+			f.engine,
+			f.rule,
+			f.severity,
+			f.file_path,
+			f.line,
+			f.commit_hash,
+			f.commit_author,
+			f.fingerprint,
+			f.occurrence,
+			f.suppression_source,
+			f.suppression_reason,
+			f.baseline_version,
+			COALESCE(fp.triage_status, 'open'::reposcan.triage_status) AS triage_status,
+			fp.assignee,
+			f.finding,
+			f.created_at
+		FROM
+			reposcan.findings f
+		JOIN
+			reposcan.scannings s
+		ON
+			s.scanning_id = f.scanning_id
+		LEFT JOIN
+			reposcan.finding_fingerprints fp
+		ON
+			fp.repository_id = s.repository_id
+		AND fp.fingerprint = f.fingerprint
+		WHERE
+			f.scanning_id = $1
+		AND f.finding_id = $2
+	`
+
+	UpsertFindingTriage = `
+		INSERT INTO reposcan.finding_fingerprints AS fp(
+			repository_id,
+			fingerprint,
+			rule,
+			file_path,
+			first_scanning_id,
+			last_scanning_id,
+			triage_status,
+			assignee,
+			triaged_by,
+			triaged_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $5, $6::reposcan.triage_status, NULLIF($8, ''), $9, $10)
+		ON CONFLICT (repository_id, fingerprint) DO UPDATE
+		SET
+			triage_status = EXCLUDED.triage_status,
+			assignee = CASE WHEN $7 THEN EXCLUDED.assignee ELSE fp.assignee END,
+			triaged_by = EXCLUDED.triaged_by,
+			triaged_at = EXCLUDED.triaged_at
+		RETURNING
+			repository_id,
+			fingerprint,
+			triage_status,
+			assignee,
+			triaged_by,
+			triaged_at
+	`
+
+	InsertFindingComment = `
+		WITH fingerprint AS (
+			INSERT INTO reposcan.finding_fingerprints(
+				repository_id,
+				fingerprint,
+				rule,
+				file_path,
+				first_scanning_id,
+				last_scanning_id
+			)
+			VALUES ($1, $2, $8, $9, $3, $3)
+			ON CONFLICT (repository_id, fingerprint) DO NOTHING
+		)
+		INSERT INTO reposcan.finding_comments(
+			repository_id,
+			fingerprint,
+			scanning_id,
+			finding_id,
+			comment,
+			created_by,
+			created_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING
+			comment_id,
+			repository_id,
+			fingerprint,
+			scanning_id,
+			finding_id,
+			comment,
+			created_by,
+			created_at
+	`
+
+	GetFindingComments = `
+		SELECT
+			comment_id,
+			repository_id,
+			fingerprint,
+			scanning_id,
+			finding_id,
+			comment,
+			created_by,
+			created_at
+		FROM
+			reposcan.finding_comments
+		WHERE
+			repository_id = $1
+		AND fingerprint = $2
+		ORDER BY
+			created_at,
+			comment_id
+	`
+
+	InsertFindingSecrets = `
+		INSERT INTO reposcan.finding_secrets(
+			scanning_id,
+			finding_id,
+			secret_encrypted,
+			created_at
+		)
+		SELECT $1, finding_id, secret_encrypted, $4
+		FROM
+			unnest($2::varchar[], $3::varchar[]) AS s(finding_id, secret_encrypted)
+		ON CONFLICT (scanning_id, finding_id) DO NOTHING
+	`
+
+	CopyFindingSecrets = `
+		INSERT INTO reposcan.finding_secrets(
+			scanning_id,
+			finding_id,
+			secret_encrypted,
+			created_at
+		)
+		SELECT $2, finding_id, secret_encrypted, $4
+		FROM
+			reposcan.finding_secrets
+		WHERE
+			scanning_id = $1
+		AND finding_id = ANY($3::varchar[])
+		ON CONFLICT (scanning_id, finding_id) DO NOTHING
+	`
+
+	GetFindingSecret = `
+		SELECT
+			scanning_id,
+			finding_id,
+			secret_encrypted
+		FROM
+			reposcan.finding_secrets
+		WHERE
+			scanning_id = $1
+		AND finding_id = $2
+	`
+
+	InsertFindingReveal = `
+		INSERT INTO reposcan.finding_reveals(
+			scanning_id,
+			finding_id,
+			reason,
+			revealed_by,
+			revealed_at
+		)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING
+			scanning_id,
+			finding_id,
+			reason,
+			revealed_by,
+			revealed_at
+	`
+
+	InsertBaseline = `
+		WITH baseline AS (
+			INSERT INTO reposcan.baselines(
+				repository_id,
+				version,
+				scanning_id,
+				created_by,
+				created_at
+			)
+			SELECT $1, COALESCE(MAX(version), 0) + 1, $2, $3, $4
+			FROM
+				reposcan.baselines
+			WHERE
+				repository_id = $1
+			RETURNING
+				baseline_id,
+				repository_id,
+				version,
+				scanning_id,
+				created_by,
+				created_at
+		), fingerprints AS (
+			INSERT INTO reposcan.baseline_fingerprints(
+				baseline_id,
+				fingerprint
+			)
+			SELECT DISTINCT b.baseline_id, f.fingerprint
+			FROM
+				baseline b
+			JOIN
+				reposcan.findings f
+			ON
+				f.scanning_id = b.scanning_id
+			WHERE
+				f.fingerprint <> ''
+			AND f.occurrence IS DISTINCT FROM 'fixed'::reposcan.finding_occurrence
+			RETURNING
+				fingerprint
+		), repository AS (
+			UPDATE reposcan.repositories
+			SET
+				baseline_id = (SELECT baseline_id FROM baseline),
+				modified_by = $3,
+				modified_at = $4
+			WHERE
+				repository_id = $1
+		)
+		SELECT
+			b.baseline_id,
+			b.repository_id,
+			b.version,
+			b.scanning_id,
+			(SELECT COUNT(*) FROM fingerprints) AS fingerprint_count,
+			true AS is_active,
+			b.created_by,
+			b.created_at
+		FROM
+			baseline b
+	`
+
+	GetBaselineList = `
+		SELECT
+			b.baseline_id,
+			b.repository_id,
+			b.version,
+			b.scanning_id,
+			(SELECT COUNT(*) FROM reposcan.baseline_fingerprints bf WHERE bf.baseline_id = b.baseline_id) AS fingerprint_count,
+			COALESCE(r.baseline_id = b.baseline_id, false) AS is_active,
+			b.created_by,
+			b.created_at
+		FROM
+			reposcan.baselines b
+		JOIN
+			reposcan.repositories r
+		ON
+			r.repository_id = b.repository_id
+		WHERE
+			b.repository_id = $1
+		ORDER BY
+			b.version DESC
+	`
+
+	GetActiveBaseline = `
+		SELECT
+			b.baseline_id,
+			b.repository_id,
+			b.version,
+			b.scanning_id,
+			(SELECT COUNT(*) FROM reposcan.baseline_fingerprints bf WHERE bf.baseline_id = b.baseline_id) AS fingerprint_count,
+			true AS is_active,
+			b.created_by,
+			b.created_at
+		FROM
+			reposcan.repositories r
+		JOIN
+			reposcan.baselines b
+		ON
+			b.baseline_id = r.baseline_id
+		WHERE
+			r.repository_id = $1
+	`
+
+	GetBaselineFingerprints = `
+		SELECT
+			fingerprint
+		FROM
+			reposcan.baseline_fingerprints
+		WHERE
+			baseline_id = $1
+	`
+
+	UpdateActiveBaseline = `
+		UPDATE reposcan.repositories r
+		SET
+			baseline_id = b.baseline_id,
+			modified_by = $3,
+			modified_at = $4
+		FROM
+			reposcan.baselines b
+		WHERE
+			r.repository_id = $1
+		AND b.repository_id = r.repository_id
+		AND b.version = $2
+		RETURNING
+			b.baseline_id,
+			b.repository_id,
+			b.version,
+			b.scanning_id,
+			(SELECT COUNT(*) FROM reposcan.baseline_fingerprints bf WHERE bf.baseline_id = b.baseline_id) AS fingerprint_count,
+			true AS is_active,
+			b.created_by,
+			b.created_at
 	`
 )
@@ -1,7 +1,9 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
 	"repo-scanner/internal"
 	"repo-scanner/internal/constants"
 	"repo-scanner/internal/model"
@@ -10,6 +12,9 @@ import (
 	"repo-scanner/internal/utils/serror"
 	"repo-scanner/internal/utils/sqlq"
 	"repo-scanner/internal/utils/uttime"
+	"time"
+
+	"github.com/jmoiron/sqlx/types"
 )
 
 type repositoryRepository struct {
@@ -29,8 +34,8 @@ func NewRepositoryRepository(db *database.DB, q sqlq.SQLQuery, trxRepo internal.
 	}
 }
 
-func (r repositoryRepository) GetRepositoryList(req model.RepositoryListRequest) (res []model.RepositoryListResponse, errx serror.SError) {
-	rows, err := r.DB.Queryx(queries.GetRepositoryList, req.Limit, (req.Page-1)*req.Limit)
+func (r repositoryRepository) GetRepositoryList(ctx context.Context, req model.RepositoryListRequest) (res []model.RepositoryListResponse, errx serror.SError) {
+	rows, err := r.DB.QueryxContext(ctx, queries.GetRepositoryList, req.Limit, (req.Page-1)*req.Limit)
 	if err != nil {
 		errx = serror.NewFromError(err)
 		errx.AddCommentf("[repository][GetRepositoryList] while get repository list")
@@ -50,9 +55,9 @@ func (r repositoryRepository) GetRepositoryList(req model.RepositoryListRequest)
 	return
 }
 
-func (r repositoryRepository) GetRepositoryById(repo_id int64) (res *model.Repository, errx serror.SError) {
+func (r repositoryRepository) GetRepositoryById(ctx context.Context, repo_id int64) (res *model.Repository, errx serror.SError) {
 	var repo model.Repository
-	err := r.DB.QueryRowx(queries.GetRepositoryById, repo_id).StructScan(&repo)
+	err := r.DB.QueryRowxContext(ctx, queries.GetRepositoryById, repo_id).StructScan(&repo)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return
@@ -65,23 +70,27 @@ func (r repositoryRepository) GetRepositoryById(repo_id int64) (res *model.Repos
 	return &repo, nil
 }
 
-func (r repositoryRepository) AddRepository(tx *model.Trx, req model.AddRepositoryRequest) (res model.AddRepositoryResponse, errx serror.SError) {
+func (r repositoryRepository) AddRepository(ctx context.Context, tx *model.Trx, req model.AddRepositoryRequest) (res model.AddRepositoryResponse, errx serror.SError) {
 	currentTime, _ := uttime.NowWithTimezone(constants.DefaultTimezone)
 
 	var err error
 	if tx != nil {
-		err = tx.QueryRowx(queries.InsertNewRepository,
+		err = tx.QueryRowxContext(ctx, queries.InsertNewRepository,
 			req.Name,
 			req.Url,
+			req.DefaultRef,
+			req.IsUpload,
 			"Anonymous", // suppose someone else to add new repo
 			currentTime,
 			"Anonymous", // suppose someone else to modify repo
 			currentTime,
 		).StructScan(&res)
 	} else {
-		err = r.psql.DB.QueryRowx(queries.InsertNewRepository,
+		err = r.psql.DB.QueryRowxContext(ctx, queries.InsertNewRepository,
 			req.Name,
 			req.Url,
+			req.DefaultRef,
+			req.IsUpload,
 			"Anonymous", // suppose someone else to add new repo
 			currentTime,
 			"Anonymous", // suppose someone else to modify repo
@@ -97,27 +106,29 @@ func (r repositoryRepository) AddRepository(tx *model.Trx, req model.AddReposito
 	return
 }
 
-func (r repositoryRepository) EditRepository(tx *model.Trx, req model.EditRepositoryRequest) (res model.EditRepositoryResponse, errx serror.SError) {
+func (r repositoryRepository) EditRepository(ctx context.Context, tx *model.Trx, req model.EditRepositoryRequest) (res model.EditRepositoryResponse, errx serror.SError) {
 	currentTime, _ := uttime.NowWithTimezone(constants.DefaultTimezone)
 
 	var err error
 	if tx != nil {
-		err = tx.QueryRowx(queries.EditRepository,
+		err = tx.QueryRowxContext(ctx, queries.EditRepository,
 			req.Id,
 			req.Name != nil, req.Name,
 			req.Url != nil, req.Url,
 			req.IsActive != nil, req.IsActive,
 			"Anonymous", // suppose someone else to modify repo
 			currentTime,
+			req.DefaultRef != nil, req.DefaultRef,
 		).StructScan(&res)
 	} else {
-		err = r.psql.DB.QueryRowx(queries.EditRepository,
+		err = r.psql.DB.QueryRowxContext(ctx, queries.EditRepository,
 			req.Id,
 			req.Name != nil, req.Name,
 			req.Url != nil, req.Url,
 			req.IsActive != nil, req.IsActive,
 			"Anonymous", // suppose someone else to modify repo
 			currentTime,
+			req.DefaultRef != nil, req.DefaultRef,
 		).StructScan(&res)
 	}
 
@@ -129,17 +140,58 @@ func (r repositoryRepository) EditRepository(tx *model.Trx, req model.EditReposi
 	return
 }
 
-func (r repositoryRepository) DeleteRepository(tx *model.Trx, repo_id int64) (errx serror.SError) {
+func (r repositoryRepository) LockRepository(ctx context.Context, tx *model.Trx, repo_id int64) (errx serror.SError) {
+	var (
+		id  int64
+		err error
+	)
+	if tx != nil {
+		err = tx.QueryRowxContext(ctx, queries.LockRepository, repo_id).Scan(&id)
+	} else {
+		err = r.psql.DB.QueryRowxContext(ctx, queries.LockRepository, repo_id).Scan(&id)
+	}
+	if err != nil && err != sql.ErrNoRows {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[repository][LockRepository] while lock repository id[%v]", repo_id)
+		return
+	}
+	return
+}
+
+func (r repositoryRepository) DeactivateUploadRepository(ctx context.Context, tx *model.Trx, repo_id int64) (res *string, errx serror.SError) {
+	currentTime, _ := uttime.NowWithTimezone(constants.DefaultTimezone)
+
+	var (
+		url string
+		err error
+	)
+	if tx != nil {
+		err = tx.QueryRowxContext(ctx, queries.DeactivateUploadRepository, repo_id, currentTime).Scan(&url)
+	} else {
+		err = r.psql.DB.QueryRowxContext(ctx, queries.DeactivateUploadRepository, repo_id, currentTime).Scan(&url)
+	}
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return
+		}
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[repository][DeactivateUploadRepository] while deactivate repository id[%v]", repo_id)
+		return
+	}
+	return &url, nil
+}
+
+func (r repositoryRepository) DeleteRepository(ctx context.Context, tx *model.Trx, repo_id int64) (errx serror.SError) {
 	currentTime, _ := uttime.NowWithTimezone(constants.DefaultTimezone)
 
 	var err error
 	if tx != nil {
-		_, err = tx.Exec(queries.DeleteRepository,
+		_, err = tx.ExecContext(ctx, queries.DeleteRepository,
 			repo_id,
 			"Anonymous", // suppose someone else to delete repo
 			currentTime)
 	} else {
-		_, err = r.psql.DB.Exec(queries.DeleteRepository,
+		_, err = r.psql.DB.ExecContext(ctx, queries.DeleteRepository,
 			repo_id,
 			"Anonymous", // suppose someone else to delete repo
 			currentTime)
@@ -152,3 +204,171 @@ func (r repositoryRepository) DeleteRepository(tx *model.Trx, repo_id int64) (er
 	}
 	return
 }
+
+func (r repositoryRepository) GetRepositorySettings(ctx context.Context, repo_id int64) (res *model.RepositorySettings, errx serror.SError) {
+	var settings types.JSONText
+	err := r.DB.QueryRowxContext(ctx, queries.GetRepositorySettings, repo_id).Scan(&settings)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return
+		}
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[repository][GetRepositorySettings] while get settings of repository id[%v]", repo_id)
+		return
+	}
+
+	res = &model.RepositorySettings{}
+	if err = settings.Unmarshal(res); err != nil {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[repository][GetRepositorySettings] while unmarshal settings of repository id[%v]", repo_id)
+		return nil, errx
+	}
+	return
+}
+
+func (r repositoryRepository) EditRepositorySettings(ctx context.Context, tx *model.Trx, repo_id int64, req model.RepositorySettings) (res *model.RepositorySettings, errx serror.SError) {
+	currentTime, _ := uttime.NowWithTimezone(constants.DefaultTimezone)
+
+	settings, err := json.Marshal(req)
+	if err != nil {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[repository][EditRepositorySettings] while marshal settings of repository id[%v]", repo_id)
+		return
+	}
+
+	var updated types.JSONText
+	if tx != nil {
+		err = tx.QueryRowxContext(ctx, queries.UpdateRepositorySettings,
+			repo_id,
+			types.JSONText(settings),
+			"Anonymous", // suppose someone else to modify repo
+			currentTime,
+		).Scan(&updated)
+	} else {
+		err = r.psql.DB.QueryRowxContext(ctx, queries.UpdateRepositorySettings,
+			repo_id,
+			types.JSONText(settings),
+			"Anonymous", // suppose someone else to modify repo
+			currentTime,
+		).Scan(&updated)
+	}
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return
+		}
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[repository][EditRepositorySettings] while update settings of repository id[%v]", repo_id)
+		return
+	}
+
+	res = &model.RepositorySettings{}
+	if err = updated.Unmarshal(res); err != nil {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[repository][EditRepositorySettings] while unmarshal settings of repository id[%v]", repo_id)
+		return nil, errx
+	}
+	return
+}
+
+func (r repositoryRepository) GetRepositorySchedule(ctx context.Context, repo_id int64) (res *model.RepositorySchedule, errx serror.SError) {
+	var schedule model.RepositorySchedule
+	err := r.DB.QueryRowxContext(ctx, queries.GetRepositorySchedule, repo_id).StructScan(&schedule)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return
+		}
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[repository][GetRepositorySchedule] while get schedule of repository id[%v]", repo_id)
+		return
+	}
+
+	return &schedule, nil
+}
+
+func (r repositoryRepository) EditRepositorySchedule(ctx context.Context, tx *model.Trx, repo_id int64, req model.RepositorySchedule) (res *model.RepositorySchedule, errx serror.SError) {
+	currentTime, _ := uttime.NowWithTimezone(constants.DefaultTimezone)
+
+	var (
+		schedule model.RepositorySchedule
+		err      error
+	)
+	if tx != nil {
+		err = tx.QueryRowxContext(ctx, queries.UpdateRepositorySchedule,
+			repo_id,
+			req.ScanSchedule,
+			req.ScanTimezone,
+			req.NextScheduledScanAt,
+			"Anonymous", // suppose someone else to modify repo
+			currentTime,
+		).StructScan(&schedule)
+	} else {
+		err = r.psql.DB.QueryRowxContext(ctx, queries.UpdateRepositorySchedule,
+			repo_id,
+			req.ScanSchedule,
+			req.ScanTimezone,
+			req.NextScheduledScanAt,
+			"Anonymous", // suppose someone else to modify repo
+			currentTime,
+		).StructScan(&schedule)
+	}
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return
+		}
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[repository][EditRepositorySchedule] while update schedule of repository id[%v]", repo_id)
+		return
+	}
+
+	return &schedule, nil
+}
+
+// GetScheduledRepositories gets active repositories whose scheduled scan is due by now, earliest due first
+func (r repositoryRepository) GetScheduledRepositories(ctx context.Context) (res []model.ScheduledRepository, errx serror.SError) {
+	currentTime, _ := uttime.NowWithTimezone(constants.DefaultTimezone)
+
+	rows, err := r.DB.QueryxContext(ctx, queries.GetScheduledRepositories, currentTime)
+	if err != nil {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[repository][GetScheduledRepositories] while get scheduled repositories")
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var repo model.ScheduledRepository
+		if err = rows.StructScan(&repo); err != nil {
+			errx = serror.NewFromError(err)
+			errx.AddCommentf("[repository][GetScheduledRepositories] while rows.StructScan")
+			return
+		}
+		res = append(res, repo)
+	}
+	return
+}
+
+// AdvanceRepositorySchedule moves schedule due at due_at to next_at and records it runs now,
+// false once it is not due at due_at anymore, e.g. another replica moved it
+func (r repositoryRepository) AdvanceRepositorySchedule(ctx context.Context, tx *model.Trx, repo_id int64,
+	due_at time.Time, next_at time.Time) (res bool, errx serror.SError) {
+	currentTime, _ := uttime.NowWithTimezone(constants.DefaultTimezone)
+
+	var (
+		id  int64
+		err error
+	)
+	if tx != nil {
+		err = tx.QueryRowxContext(ctx, queries.AdvanceRepositorySchedule, repo_id, due_at, next_at, currentTime).Scan(&id)
+	} else {
+		err = r.psql.DB.QueryRowxContext(ctx, queries.AdvanceRepositorySchedule, repo_id, due_at, next_at, currentTime).Scan(&id)
+	}
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[repository][AdvanceRepositorySchedule] while advance schedule of repository id[%v]", repo_id)
+		return
+	}
+	return true, nil
+}
@@ -1,6 +1,7 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
 	"io/ioutil"
 	"log"
@@ -37,7 +38,7 @@ func NewRepositoryMock() (internal.IRepositoryRepository, *sql.DB, sqlmock.Sqlmo
 	builder := sqlq.NewBuilder(opts)
 
 	sqlxDb := sqlx.NewDb(db, "sqlmock")
-	postDB := &database.DB{sqlxDb}
+	postDB := &database.DB{DB: sqlxDb}
 
 	trxRepo := NewTrxRepository(nil)
 
@@ -94,7 +95,7 @@ func TestGetRepositoryList(t *testing.T) {
 
 	for _, test := range tests {
 		test.mock()
-		got, err := repo.GetRepositoryList(test.requestBody)
+		got, err := repo.GetRepositoryList(context.Background(), test.requestBody)
 		if (err != nil) != test.wantErr {
 			t.Errorf("GetRepositoryList() error '%s'", err)
 			return
@@ -167,7 +168,7 @@ func TestGetRepositoryById(t *testing.T) {
 
 	for _, test := range tests {
 		test.mock()
-		got, err := repo.GetRepositoryById(test.requestBody)
+		got, err := repo.GetRepositoryById(context.Background(), test.requestBody)
 		if (err != nil) != test.wantErr {
 			t.Errorf("GetRepositoryById() error '%s'", err)
 			return
@@ -188,6 +189,7 @@ func TestAddRepository(t *testing.T) {
 	wayback := time.Date(1974, time.May, 19, 1, 2, 3, 4, time.UTC)
 	patch := monkey.Patch(time.Now, func() time.Time { return wayback })
 	defer patch.Unpatch()
+	defaultRef := "main"
 
 	tests := []struct {
 		name        string
@@ -205,17 +207,21 @@ func TestAddRepository(t *testing.T) {
 					"repository_id",
 					"repository_name",
 					"repository_url",
+					"default_ref",
 					"is_active",
 				}).AddRow(
 					3,
 					"JQuery",
 					"github.com/jquery/jquery",
+					"main",
 					true)
 
 				currentTime, _ := uttime.NowWithTimezone(constants.DefaultTimezone)
 				expectedQuery := mock.ExpectQuery(regexp.QuoteMeta(queries.InsertNewRepository)).WithArgs(
 					"JQuery",
 					"github.com/jquery/jquery",
+					"main",
+					false,
 					"Anonymous",
 					currentTime,
 					"Anonymous",
@@ -224,14 +230,16 @@ func TestAddRepository(t *testing.T) {
 				expectedQuery.WillReturnRows(rows)
 			},
 			requestBody: model.AddRepositoryRequest{
-				Name: "JQuery",
-				Url:  "github.com/jquery/jquery",
+				Name:       "JQuery",
+				Url:        "github.com/jquery/jquery",
+				DefaultRef: "main",
 			},
 			want: model.AddRepositoryResponse{
-				Id:       3,
-				Name:     "JQuery",
-				Url:      "github.com/jquery/jquery",
-				IsActive: true,
+				Id:         3,
+				Name:       "JQuery",
+				Url:        "github.com/jquery/jquery",
+				DefaultRef: &defaultRef,
+				IsActive:   true,
 			},
 			wantErr: false,
 		},
@@ -239,7 +247,7 @@ func TestAddRepository(t *testing.T) {
 
 	for _, test := range tests {
 		test.mock()
-		got, err := repo.AddRepository(nil, test.requestBody)
+		got, err := repo.AddRepository(context.Background(), nil, test.requestBody)
 		if (err != nil) != test.wantErr {
 			t.Errorf("AddRepository() error '%s'", err)
 			return
@@ -260,6 +268,7 @@ func TestEditRepository(t *testing.T) {
 	wayback := time.Date(1974, time.May, 19, 1, 2, 3, 4, time.UTC)
 	patch := monkey.Patch(time.Now, func() time.Time { return wayback })
 	defer patch.Unpatch()
+	defaultRef := "main"
 
 	tests := []struct {
 		name        string
@@ -277,11 +286,13 @@ func TestEditRepository(t *testing.T) {
 					"repository_id",
 					"repository_name",
 					"repository_url",
+					"default_ref",
 					"is_active",
 				}).AddRow(
 					3,
 					"JQuery",
 					"github.com/jquery/jquery",
+					"main",
 					true)
 
 				currentTime, _ := uttime.NowWithTimezone(constants.DefaultTimezone)
@@ -292,20 +303,23 @@ func TestEditRepository(t *testing.T) {
 					false, nil,
 					"Anonymous",
 					currentTime,
+					true, "main",
 				)
 				expectedQuery.WillReturnRows(rows)
 			},
 			requestBody: model.EditRepositoryRequest{
-				Id:       3,
-				Name:     nil,
-				Url:      nil,
-				IsActive: nil,
+				Id:         3,
+				Name:       nil,
+				Url:        nil,
+				DefaultRef: &defaultRef,
+				IsActive:   nil,
 			},
 			want: model.EditRepositoryResponse{
-				Id:       3,
-				Name:     "JQuery",
-				Url:      "github.com/jquery/jquery",
-				IsActive: true,
+				Id:         3,
+				Name:       "JQuery",
+				Url:        "github.com/jquery/jquery",
+				DefaultRef: &defaultRef,
+				IsActive:   true,
 			},
 			wantErr: false,
 		},
@@ -313,7 +327,7 @@ func TestEditRepository(t *testing.T) {
 
 	for _, test := range tests {
 		test.mock()
-		got, err := repo.EditRepository(nil, test.requestBody)
+		got, err := repo.EditRepository(context.Background(), nil, test.requestBody)
 		if (err != nil) != test.wantErr {
 			t.Errorf("EditRepository() error '%s'", err)
 			return
@@ -361,10 +375,317 @@ func TestEditRepository(t *testing.T) {
 
 	for _, test := range tests {
 		test.mock()
-		err := repo.DeleteRepository(nil, test.requestBody)
+		err := repo.DeleteRepository(context.Background(), nil, test.requestBody)
 		if (err != nil) != test.wantErr {
 			t.Errorf("EditRepository() error '%s'", err)
 			return
 		}
 	}
 }*/
+
+func TestGetRepositorySettings(t *testing.T) {
+	repo, db, mock := NewRepositoryMock()
+	defer func() {
+		db.Close()
+	}()
+
+	tests := []struct {
+		name    string
+		mock    func()
+		repoId  int64
+		want    *model.RepositorySettings
+		wantErr bool
+	}{
+		{
+			name: "OK",
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"scan_settings"}).
+					AddRow([]byte(`{"skip_paths":["docs/**"],"skip_extensions":["csv"],"scan_tests":true,"commit_depth":1000,"threads":4,"priority":50}`))
+				mock.ExpectQuery(regexp.QuoteMeta(queries.GetRepositorySettings)).WithArgs(3).WillReturnRows(rows)
+			},
+			repoId: 3,
+			want: &model.RepositorySettings{
+				SkipPaths:      []string{"docs/**"},
+				SkipExtensions: []string{"csv"},
+				ScanTests:      true,
+				CommitDepth:    1000,
+				Threads:        4,
+				Priority:       50,
+			},
+			wantErr: false,
+		},
+		{
+			name: "Default settings",
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"scan_settings"}).AddRow([]byte(`{}`))
+				mock.ExpectQuery(regexp.QuoteMeta(queries.GetRepositorySettings)).WithArgs(4).WillReturnRows(rows)
+			},
+			repoId:  4,
+			want:    &model.RepositorySettings{},
+			wantErr: false,
+		},
+		{
+			name: "Repository not found",
+			mock: func() {
+				mock.ExpectQuery(regexp.QuoteMeta(queries.GetRepositorySettings)).WithArgs(5).
+					WillReturnRows(sqlmock.NewRows([]string{"scan_settings"}))
+			},
+			repoId:  5,
+			want:    nil,
+			wantErr: false,
+		},
+	}
+
+	for _, test := range tests {
+		test.mock()
+		got, err := repo.GetRepositorySettings(context.Background(), test.repoId)
+		if (err != nil) != test.wantErr {
+			t.Errorf("GetRepositorySettings() error '%s'", err)
+			return
+		}
+
+		if err == nil {
+			assert.Equal(t, test.want, got)
+		}
+	}
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestEditRepositorySettings(t *testing.T) {
+	repo, db, mock := NewRepositoryMock()
+	defer func() {
+		db.Close()
+	}()
+
+	wayback := time.Date(1974, time.May, 19, 1, 2, 3, 4, time.UTC)
+	patch := monkey.Patch(time.Now, func() time.Time { return wayback })
+	defer patch.Unpatch()
+
+	currentTime, _ := uttime.NowWithTimezone(constants.DefaultTimezone)
+	settings := `{"skip_paths":["docs/**"],"skip_extensions":["csv"],"scan_tests":true,"commit_depth":0,"threads":0,"priority":10}`
+
+	mock.ExpectQuery(regexp.QuoteMeta(queries.UpdateRepositorySettings)).WithArgs(
+		3,
+		[]byte(settings),
+		"Anonymous",
+		currentTime,
+	).WillReturnRows(sqlmock.NewRows([]string{"scan_settings"}).AddRow([]byte(settings)))
+
+	got, err := repo.EditRepositorySettings(context.Background(), nil, 3, model.RepositorySettings{
+		SkipPaths:      []string{"docs/**"},
+		SkipExtensions: []string{"csv"},
+		ScanTests:      true,
+		Priority:       10,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &model.RepositorySettings{
+		SkipPaths:      []string{"docs/**"},
+		SkipExtensions: []string{"csv"},
+		ScanTests:      true,
+		Priority:       10,
+	}, got)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestGetRepositorySchedule(t *testing.T) {
+	repo, db, mock := NewRepositoryMock()
+	defer func() {
+		db.Close()
+	}()
+
+	nextAt := time.Date(2022, 12, 21, 2, 0, 0, 0, time.UTC)
+	rows := sqlmock.NewRows([]string{"scan_schedule", "scan_timezone", "next_scheduled_scan_at", "last_scheduled_scan_at"}).
+		AddRow("0 2 * * *", "Asia/Jakarta", nextAt, nil)
+	mock.ExpectQuery(regexp.QuoteMeta(queries.GetRepositorySchedule)).WithArgs(3).WillReturnRows(rows)
+	mock.ExpectQuery(regexp.QuoteMeta(queries.GetRepositorySchedule)).WithArgs(4).
+		WillReturnRows(sqlmock.NewRows([]string{"scan_schedule", "scan_timezone", "next_scheduled_scan_at", "last_scheduled_scan_at"}))
+
+	got, err := repo.GetRepositorySchedule(context.Background(), 3)
+	assert.Nil(t, err)
+	assert.Equal(t, &model.RepositorySchedule{
+		ScanSchedule:        "0 2 * * *",
+		ScanTimezone:        "Asia/Jakarta",
+		NextScheduledScanAt: &nextAt,
+	}, got)
+
+	// Repository not found
+	got, err = repo.GetRepositorySchedule(context.Background(), 4)
+	assert.Nil(t, err)
+	assert.Nil(t, got)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestEditRepositorySchedule(t *testing.T) {
+	repo, db, mock := NewRepositoryMock()
+	defer func() {
+		db.Close()
+	}()
+
+	wayback := time.Date(1974, time.May, 19, 1, 2, 3, 4, time.UTC)
+	patch := monkey.Patch(time.Now, func() time.Time { return wayback })
+	defer patch.Unpatch()
+
+	currentTime, _ := uttime.NowWithTimezone(constants.DefaultTimezone)
+	nextAt := time.Date(1974, time.May, 20, 2, 0, 0, 0, currentTime.Location())
+
+	mock.ExpectQuery(regexp.QuoteMeta(queries.UpdateRepositorySchedule)).WithArgs(
+		3,
+		"0 2 * * *",
+		"+7",
+		nextAt,
+		"Anonymous",
+		currentTime,
+	).WillReturnRows(sqlmock.NewRows([]string{"scan_schedule", "scan_timezone", "next_scheduled_scan_at", "last_scheduled_scan_at"}).
+		AddRow("0 2 * * *", "+7", nextAt, nil))
+
+	got, err := repo.EditRepositorySchedule(context.Background(), nil, 3, model.RepositorySchedule{
+		ScanSchedule:        "0 2 * * *",
+		ScanTimezone:        "+7",
+		NextScheduledScanAt: &nextAt,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &model.RepositorySchedule{
+		ScanSchedule:        "0 2 * * *",
+		ScanTimezone:        "+7",
+		NextScheduledScanAt: &nextAt,
+	}, got)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestGetScheduledRepositories(t *testing.T) {
+	repo, db, mock := NewRepositoryMock()
+	defer func() {
+		db.Close()
+	}()
+
+	wayback := time.Date(1974, time.May, 19, 1, 2, 3, 4, time.UTC)
+	patch := monkey.Patch(time.Now, func() time.Time { return wayback })
+	defer patch.Unpatch()
+
+	currentTime, _ := uttime.NowWithTimezone(constants.DefaultTimezone)
+	dueAt := time.Date(1974, time.May, 19, 8, 0, 0, 0, time.UTC)
+	defaultRef := "main"
+
+	rows := sqlmock.NewRows([]string{"repository_id", "default_ref", "scan_schedule", "scan_timezone", "next_scheduled_scan_at"}).
+		AddRow(3, "main", "0 8 * * *", "", dueAt).
+		AddRow(4, nil, "@hourly", "UTC", dueAt)
+	mock.ExpectQuery(regexp.QuoteMeta(queries.GetScheduledRepositories)).WithArgs(currentTime).WillReturnRows(rows)
+
+	got, err := repo.GetScheduledRepositories(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, []model.ScheduledRepository{
+		{Id: 3, DefaultRef: &defaultRef, ScanSchedule: "0 8 * * *", NextScheduledScanAt: dueAt},
+		{Id: 4, ScanSchedule: "@hourly", ScanTimezone: "UTC", NextScheduledScanAt: dueAt},
+	}, got)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestAdvanceRepositorySchedule(t *testing.T) {
+	repo, db, mock := NewRepositoryMock()
+	defer func() {
+		db.Close()
+	}()
+
+	wayback := time.Date(1974, time.May, 19, 1, 2, 3, 4, time.UTC)
+	patch := monkey.Patch(time.Now, func() time.Time { return wayback })
+	defer patch.Unpatch()
+
+	currentTime, _ := uttime.NowWithTimezone(constants.DefaultTimezone)
+	dueAt := time.Date(1974, time.May, 19, 8, 0, 0, 0, time.UTC)
+	nextAt := time.Date(1974, time.May, 20, 8, 0, 0, 0, currentTime.Location())
+
+	tests := []struct {
+		name    string
+		mock    func()
+		want    bool
+		wantErr bool
+	}{
+		{
+			name: "Advanced",
+			mock: func() {
+				mock.ExpectQuery(regexp.QuoteMeta(queries.AdvanceRepositorySchedule)).WithArgs(3, dueAt, nextAt, currentTime).
+					WillReturnRows(sqlmock.NewRows([]string{"repository_id"}).AddRow(3))
+			},
+			want:    true,
+			wantErr: false,
+		},
+		{
+			name: "Advanced by another replica",
+			mock: func() {
+				mock.ExpectQuery(regexp.QuoteMeta(queries.AdvanceRepositorySchedule)).WithArgs(3, dueAt, nextAt, currentTime).
+					WillReturnRows(sqlmock.NewRows([]string{"repository_id"}))
+			},
+			want:    false,
+			wantErr: false,
+		},
+		{
+			name: "Error",
+			mock: func() {
+				mock.ExpectQuery(regexp.QuoteMeta(queries.AdvanceRepositorySchedule)).WithArgs(3, dueAt, nextAt, currentTime).
+					WillReturnError(sql.ErrConnDone)
+			},
+			want:    false,
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		test.mock()
+		got, err := repo.AdvanceRepositorySchedule(context.Background(), nil, 3, dueAt, nextAt)
+		if (err != nil) != test.wantErr {
+			t.Errorf("AdvanceRepositorySchedule() %s error '%s'", test.name, err)
+			return
+		}
+		assert.Equal(t, test.want, got, test.name)
+	}
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestLockRepository(t *testing.T) {
+	repo, db, mock := NewRepositoryMock()
+	defer func() {
+		db.Close()
+	}()
+
+	mock.ExpectQuery(regexp.QuoteMeta(queries.LockRepository)).WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"repository_id"}).AddRow(3))
+	// Nothing is locked for unknown repository
+	mock.ExpectQuery(regexp.QuoteMeta(queries.LockRepository)).WithArgs(4).
+		WillReturnRows(sqlmock.NewRows([]string{"repository_id"}))
+	mock.ExpectQuery(regexp.QuoteMeta(queries.LockRepository)).WithArgs(5).
+		WillReturnError(sql.ErrConnDone)
+
+	assert.Nil(t, repo.LockRepository(context.Background(), nil, 3))
+	assert.Nil(t, repo.LockRepository(context.Background(), nil, 4))
+	assert.NotNil(t, repo.LockRepository(context.Background(), nil, 5))
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestDeactivateUploadRepository(t *testing.T) {
+	repo, db, mock := NewRepositoryMock()
+	defer func() {
+		db.Close()
+	}()
+
+	wayback := time.Date(1974, time.May, 19, 1, 2, 3, 4, time.UTC)
+	patch := monkey.Patch(time.Now, func() time.Time { return wayback })
+	defer patch.Unpatch()
+
+	currentTime, _ := uttime.NowWithTimezone(constants.DefaultTimezone)
+	dir := "/tmp/reposcan-uploads/upload1"
+
+	mock.ExpectQuery(regexp.QuoteMeta(queries.DeactivateUploadRepository)).WithArgs(5, currentTime).
+		WillReturnRows(sqlmock.NewRows([]string{"repository_url"}).AddRow(dir))
+	// Repository is not an upload or it still has unfinished scannings
+	mock.ExpectQuery(regexp.QuoteMeta(queries.DeactivateUploadRepository)).WithArgs(3, currentTime).
+		WillReturnRows(sqlmock.NewRows([]string{"repository_url"}))
+
+	got, err := repo.DeactivateUploadRepository(context.Background(), nil, 5)
+	assert.Nil(t, err)
+	assert.Equal(t, &dir, got)
+
+	got, err = repo.DeactivateUploadRepository(context.Background(), nil, 3)
+	assert.Nil(t, err)
+	assert.Nil(t, got)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
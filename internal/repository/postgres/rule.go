@@ -0,0 +1,179 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"repo-scanner/internal"
+	"repo-scanner/internal/constants"
+	"repo-scanner/internal/model"
+	"repo-scanner/internal/repository/database"
+	"repo-scanner/internal/repository/postgres/queries"
+	"repo-scanner/internal/utils/serror"
+	"repo-scanner/internal/utils/sqlq"
+	"repo-scanner/internal/utils/uttime"
+)
+
+type ruleRepository struct {
+	psql
+	Driver sqlq.SQLDriver
+}
+
+func NewRuleRepository(db *database.DB, q sqlq.SQLQuery, trxRepo internal.ITrxRepository) internal.IRuleRepository {
+	return &ruleRepository{
+		psql: psql{
+			TrxRepo: trxRepo,
+			DB:      db.DB,
+			Q:       q,
+		},
+		Driver: q.Driver(),
+	}
+}
+
+func (r ruleRepository) GetRuleList(ctx context.Context, req model.RuleListRequest) (res []model.RuleListResponse, errx serror.SError) {
+	rows, err := r.DB.QueryxContext(ctx, queries.GetRuleList, req.Limit, (req.Page-1)*req.Limit)
+	if err != nil {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[repository][GetRuleList] while get rule list")
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var r model.RuleListResponse
+		if err = rows.StructScan(&r); err != nil {
+			errx = serror.NewFromError(err)
+			errx.AddCommentf("[repository][GetRuleList] while rows.StructScan")
+			return
+		}
+		res = append(res, r)
+	}
+	return
+}
+
+func (r ruleRepository) GetActiveRules(ctx context.Context) (res []model.RuleListResponse, errx serror.SError) {
+	rows, err := r.DB.QueryxContext(ctx, queries.GetActiveRules)
+	if err != nil {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[repository][GetActiveRules] while get active rules")
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var r model.RuleListResponse
+		if err = rows.StructScan(&r); err != nil {
+			errx = serror.NewFromError(err)
+			errx.AddCommentf("[repository][GetActiveRules] while rows.StructScan")
+			return
+		}
+		res = append(res, r)
+	}
+	return
+}
+
+func (r ruleRepository) GetRuleById(ctx context.Context, rule_id int64) (res *model.Rule, errx serror.SError) {
+	var rule model.Rule
+	err := r.DB.QueryRowxContext(ctx, queries.GetRuleById, rule_id).StructScan(&rule)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return
+		}
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[repository][GetRuleById] while get rule by rule_id")
+		return
+	}
+
+	return &rule, nil
+}
+
+func (r ruleRepository) AddRule(ctx context.Context, tx *model.Trx, req model.AddRuleRequest) (res model.AddRuleResponse, errx serror.SError) {
+	currentTime, _ := uttime.NowWithTimezone(constants.DefaultTimezone)
+
+	var err error
+	if tx != nil {
+		err = tx.QueryRowxContext(ctx, queries.InsertNewRule,
+			req.Name,
+			req.Description,
+			req.Comment,
+			req.Part,
+			req.Pattern,
+			"Anonymous", // suppose someone else to add new rule
+			currentTime,
+			"Anonymous", // suppose someone else to modify rule
+			currentTime,
+		).StructScan(&res)
+	} else {
+		err = r.psql.DB.QueryRowxContext(ctx, queries.InsertNewRule,
+			req.Name,
+			req.Description,
+			req.Comment,
+			req.Part,
+			req.Pattern,
+			"Anonymous", // suppose someone else to add new rule
+			currentTime,
+			"Anonymous", // suppose someone else to modify rule
+			currentTime,
+		).StructScan(&res)
+	}
+
+	if err != nil {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[repository][AddRule] while add rule")
+		return
+	}
+	return
+}
+
+func (r ruleRepository) EditRule(ctx context.Context, tx *model.Trx, req model.EditRuleRequest) (res model.EditRuleResponse, errx serror.SError) {
+	currentTime, _ := uttime.NowWithTimezone(constants.DefaultTimezone)
+
+	args := []interface{}{
+		req.Id,
+		req.Name != nil, req.Name,
+		req.Description != nil, req.Description,
+		req.Comment != nil, req.Comment,
+		req.Part != nil, req.Part,
+		req.Pattern != nil, req.Pattern,
+		req.IsActive != nil, req.IsActive,
+		"Anonymous", // suppose someone else to modify rule
+		currentTime,
+	}
+
+	var err error
+	if tx != nil {
+		err = tx.QueryRowxContext(ctx, queries.EditRule, args...).StructScan(&res)
+	} else {
+		err = r.psql.DB.QueryRowxContext(ctx, queries.EditRule, args...).StructScan(&res)
+	}
+
+	if err != nil {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[repository][EditRule] while edit rule")
+		return
+	}
+	return
+}
+
+func (r ruleRepository) DeleteRule(ctx context.Context, tx *model.Trx, rule_id int64) (errx serror.SError) {
+	currentTime, _ := uttime.NowWithTimezone(constants.DefaultTimezone)
+
+	var err error
+	if tx != nil {
+		_, err = tx.ExecContext(ctx, queries.DeleteRule,
+			rule_id,
+			"Anonymous", // suppose someone else to delete rule
+			currentTime)
+	} else {
+		_, err = r.psql.DB.ExecContext(ctx, queries.DeleteRule,
+			rule_id,
+			"Anonymous", // suppose someone else to delete rule
+			currentTime)
+	}
+
+	if err != nil {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[repository][DeleteRule] while delete rule")
+		return
+	}
+	return
+}
@@ -0,0 +1,162 @@
+package postgres
+
+import (
+	"context"
+	"io/ioutil"
+	"log"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"repo-scanner/internal"
+	"repo-scanner/internal/constants"
+	"repo-scanner/internal/model"
+	"repo-scanner/internal/repository/database"
+	"repo-scanner/internal/repository/postgres/queries"
+	"repo-scanner/internal/utils/sqlq"
+	"repo-scanner/internal/utils/uttime"
+
+	"bou.ke/monkey"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func NewRuleMock() (internal.IRuleRepository, sqlmock.Sqlmock, func()) {
+	log.SetOutput(ioutil.Discard)
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	opts := sqlq.BuilderOption{
+		Driver: sqlq.DriverPostgreSQL,
+	}
+
+	builder := sqlq.NewBuilder(opts)
+
+	sqlxDb := sqlx.NewDb(db, "sqlmock")
+	postDB := &database.DB{DB: sqlxDb}
+
+	trxRepo := NewTrxRepository(nil)
+
+	repo := NewRuleRepository(postDB, builder, trxRepo)
+	return repo, mock, func() { db.Close() }
+}
+
+var ruleColumns = []string{
+	"rule_id",
+	"rule_name",
+	"description",
+	"comment",
+	"part",
+	"pattern",
+	"is_active",
+}
+
+func TestGetActiveRules(t *testing.T) {
+	repo, mock, closeDB := NewRuleMock()
+	defer closeDB()
+
+	rows := sqlmock.NewRows(ruleColumns).AddRow(
+		1,
+		"aws-access-key",
+		"AWS access key",
+		"",
+		"content",
+		"AKIA[0-9A-Z]{16}",
+		true)
+	mock.ExpectQuery(regexp.QuoteMeta(queries.GetActiveRules)).WillReturnRows(rows)
+
+	want := []model.RuleListResponse{
+		{
+			Id:          1,
+			Name:        "aws-access-key",
+			Description: "AWS access key",
+			Part:        "content",
+			Pattern:     "AKIA[0-9A-Z]{16}",
+			IsActive:    true,
+		},
+	}
+
+	got, err := repo.GetActiveRules(context.Background())
+	if err != nil {
+		t.Errorf("GetActiveRules() error '%s'", err)
+		return
+	}
+	assert.Equal(t, want, got)
+}
+
+func TestAddRule(t *testing.T) {
+	repo, mock, closeDB := NewRuleMock()
+	defer closeDB()
+
+	wayback := time.Date(1974, time.May, 19, 1, 2, 3, 4, time.UTC)
+	patch := monkey.Patch(time.Now, func() time.Time { return wayback })
+	defer patch.Unpatch()
+
+	tests := []struct {
+		name        string
+		mock        func()
+		requestBody model.AddRuleRequest
+		want        model.AddRuleResponse
+		wantErr     bool
+	}{
+		{
+			name: "OK",
+			mock: func() {
+				rows := sqlmock.NewRows(ruleColumns).AddRow(
+					1,
+					"aws-access-key",
+					"AWS access key",
+					"",
+					"content",
+					"AKIA[0-9A-Z]{16}",
+					true)
+
+				currentTime, _ := uttime.NowWithTimezone(constants.DefaultTimezone)
+				expectedQuery := mock.ExpectQuery(regexp.QuoteMeta(queries.InsertNewRule)).WithArgs(
+					"aws-access-key",
+					"AWS access key",
+					"",
+					"content",
+					"AKIA[0-9A-Z]{16}",
+					"Anonymous",
+					currentTime,
+					"Anonymous",
+					currentTime,
+				)
+				expectedQuery.WillReturnRows(rows)
+			},
+			requestBody: model.AddRuleRequest{
+				Name:        "aws-access-key",
+				Description: "AWS access key",
+				Part:        "content",
+				Pattern:     "AKIA[0-9A-Z]{16}",
+			},
+			want: model.AddRuleResponse{
+				Id:          1,
+				Name:        "aws-access-key",
+				Description: "AWS access key",
+				Part:        "content",
+				Pattern:     "AKIA[0-9A-Z]{16}",
+				IsActive:    true,
+			},
+			wantErr: false,
+		},
+	}
+
+	for _, test := range tests {
+		test.mock()
+		got, err := repo.AddRule(context.Background(), nil, test.requestBody)
+		if (err != nil) != test.wantErr {
+			t.Errorf("AddRule() error '%s'", err)
+			return
+		}
+
+		if err == nil {
+			assert.Equal(t, reflect.DeepEqual(got, test.want), true)
+		}
+	}
+}
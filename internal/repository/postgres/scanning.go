@@ -1,6 +1,9 @@
 package postgres
 
 import (
+	"context"
+	"database/sql"
+	"encoding/json"
 	"fmt"
 	"repo-scanner/internal"
 	"repo-scanner/internal/constants"
@@ -10,8 +13,9 @@ import (
 	"repo-scanner/internal/utils/serror"
 	"repo-scanner/internal/utils/sqlq"
 	"repo-scanner/internal/utils/uttime"
+	"time"
 
-	"github.com/jmoiron/sqlx/types"
+	"github.com/lib/pq"
 )
 
 type scanningRepository struct {
@@ -31,9 +35,9 @@ func NewScanningRepository(db *database.DB, q sqlq.SQLQuery, trxRepo internal.IT
 	}
 }
 
-func (s scanningRepository) GetScanningList(req model.ScanningListRequest) (res []model.ScanningListResponse, errx serror.SError) {
+func (s scanningRepository) GetScanningList(ctx context.Context, req model.ScanningListRequest) (res []model.ScanningListResponse, errx serror.SError) {
 	query := fmt.Sprintf(queries.GetScanningList, req.Sort)
-	rows, err := s.DB.Queryx(query,
+	rows, err := s.DB.QueryxContext(ctx, query,
 		req.Status,
 		req.Limit,
 		(req.Page-1)*req.Limit)
@@ -56,28 +60,96 @@ func (s scanningRepository) GetScanningList(req model.ScanningListRequest) (res
 	return
 }
 
-func (s scanningRepository) AddNewScanning(tx *model.Trx, repo_id int64) (res model.ScanningResponse, errx serror.SError) {
+func (s scanningRepository) GetScanningById(ctx context.Context, scanning_id int64) (res *model.ScanningResponse, errx serror.SError) {
+	var scanning model.ScanningResponse
+	err := s.DB.QueryRowxContext(ctx, queries.GetScanningById, scanning_id).StructScan(&scanning)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return
+		}
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[repository][GetScanningById] while get scanning")
+		return
+	}
+
+	return &scanning, nil
+}
+
+func (s scanningRepository) GetLastSuccessfulScanning(ctx context.Context, repo_id int64, ref string) (res *model.ScanningResponse, errx serror.SError) {
+	var scanning model.ScanningResponse
+	err := s.DB.QueryRowxContext(ctx, queries.GetLastSuccessfulScanning, repo_id, ref).StructScan(&scanning)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return
+		}
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[repository][GetLastSuccessfulScanning] while get last successful scanning")
+		return
+	}
+
+	return &scanning, nil
+}
+
+func (s scanningRepository) GetPreviousScanning(ctx context.Context, repo_id int64, ref string, scanning_id int64) (res *model.ScanningResponse, errx serror.SError) {
+	var scanning model.ScanningResponse
+	err := s.DB.QueryRowxContext(ctx, queries.GetPreviousScanning, repo_id, ref, scanning_id).StructScan(&scanning)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return
+		}
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[repository][GetPreviousScanning] while get previous scanning")
+		return
+	}
+
+	return &scanning, nil
+}
+
+// AddNewScanning queues new scanning unless an identical one is queued and not attempted yet, which is returned
+// with IsCoalesced instead
+func (s scanningRepository) AddNewScanning(ctx context.Context, tx *model.Trx, req model.AddScanningRequest) (res model.ScanningResponse, errx serror.SError) {
 	currentTime, _ := uttime.NowWithTimezone(constants.DefaultTimezone)
 
-	var err error
+	queryRowx := s.psql.DB.QueryRowxContext
 	if tx != nil {
-		err = tx.QueryRowx(queries.InsertNewScanning,
-			repo_id,
+		queryRowx = tx.QueryRowxContext
+	}
+
+	// Duplicate may be claimed between insert and its lookup, so inserting is tried again once
+	var err error
+	for try := 0; try < 2; try++ {
+		err = queryRowx(ctx, queries.InsertNewScanning,
+			req.RepoId,
+			req.Mode,
+			req.IsFullScan,
+			req.BaseRef,
+			req.Ref,
 			currentTime, // queued date
 			"Anonymous", // suppose someone else to trigger scanning
 			currentTime,
 			"Anonymous", // suppose someone else to modify scanning
 			currentTime,
+			req.MaxAttempts,
+			req.Priority,
 		).StructScan(&res)
-	} else {
-		err = s.psql.DB.QueryRowx(queries.InsertNewScanning,
-			repo_id,
-			currentTime, // queued date
-			"Anonymous", // suppose someone else to trigger scanning
-			currentTime,
-			"Anonymous", // suppose someone else to modify scanning
-			currentTime,
+		if err != sql.ErrNoRows {
+			break
+		}
+
+		err = queryRowx(ctx, queries.UpdateQueuedDuplicateScanning,
+			req.RepoId,
+			req.Mode,
+			req.IsFullScan,
+			req.BaseRef,
+			req.Ref,
+			req.Priority,
 		).StructScan(&res)
+		if err == nil {
+			res.IsCoalesced = true
+			break
+		} else if err != sql.ErrNoRows {
+			break
+		}
 	}
 
 	if err != nil {
@@ -88,55 +160,693 @@ func (s scanningRepository) AddNewScanning(tx *model.Trx, repo_id int64) (res mo
 	return
 }
 
-func (s scanningRepository) EditScanningStatusById(tx *model.Trx,
-	scanningId int64, scanningStatus string, findings types.JSONText) (res model.ScanningResponse, errx serror.SError) {
+func (s scanningRepository) EditScanningStatusById(ctx context.Context, tx *model.Trx,
+	req model.EditScanningStatusRequest) (res model.ScanningResponse, errx serror.SError) {
 	currentTime, _ := uttime.NowWithTimezone(constants.DefaultTimezone)
 
 	var err error
 	if tx != nil {
-		switch scanningStatus {
+		switch req.Status {
 		case constants.ScanningStatusInProgress:
-			err = tx.QueryRowx(queries.UpdateScanningInProgressById,
-				scanningId,
-				scanningStatus,
+			err = tx.QueryRowxContext(ctx, queries.UpdateScanningInProgressById,
+				req.Id,
+				req.Status,
 				"Automated", // suppose someone else to modify repo
 				currentTime,
 			).StructScan(&res)
 
-		case constants.ScanningStatusSuccess, constants.ScanningStatusFailure:
-			err = tx.QueryRowx(queries.UpdateScanningFinishedById,
-				scanningId,
-				scanningStatus,
-				findings,
+		case constants.ScanningStatusQueued:
+			err = tx.QueryRowxContext(ctx, queries.UpdateScanningRetryById,
+				req.Id,
+				req.ClaimedBy,
+				currentTime.Add(req.RetryDelay),
+				req.LastError,
+				pq.Array(req.LastErrorStack),
+				currentTime,
+			).StructScan(&res)
+
+		case constants.ScanningStatusSuccess, constants.ScanningStatusFailure, constants.ScanningStatusCancelled, constants.ScanningStatusDead:
+			err = tx.QueryRowxContext(ctx, queries.UpdateScanningFinishedById,
+				req.Id,
+				req.Status,
+				req.Findings,
+				req.Engines,
 				"Automated", // suppose someone else to modify repo
 				currentTime,
+				req.SinceCommit,
+				req.HeadCommit,
+				req.ClaimedBy,
+				req.LastError,
+				pq.Array(req.LastErrorStack),
 			).StructScan(&res)
 		}
 	} else {
-		switch scanningStatus {
+		switch req.Status {
 		case constants.ScanningStatusInProgress:
-			err = s.psql.DB.QueryRowx(queries.UpdateScanningInProgressById,
-				scanningId,
-				scanningStatus,
+			err = s.psql.DB.QueryRowxContext(ctx, queries.UpdateScanningInProgressById,
+				req.Id,
+				req.Status,
 				"Automated", // suppose someone else to modify repo
 				currentTime,
 			).StructScan(&res)
 
-		case constants.ScanningStatusSuccess, constants.ScanningStatusFailure:
-			err = s.psql.DB.QueryRowx(queries.UpdateScanningFinishedById,
-				scanningId,
-				scanningStatus,
-				findings,
+		case constants.ScanningStatusQueued:
+			err = s.psql.DB.QueryRowxContext(ctx, queries.UpdateScanningRetryById,
+				req.Id,
+				req.ClaimedBy,
+				currentTime.Add(req.RetryDelay),
+				req.LastError,
+				pq.Array(req.LastErrorStack),
+				currentTime,
+			).StructScan(&res)
+
+		case constants.ScanningStatusSuccess, constants.ScanningStatusFailure, constants.ScanningStatusCancelled, constants.ScanningStatusDead:
+			err = s.psql.DB.QueryRowxContext(ctx, queries.UpdateScanningFinishedById,
+				req.Id,
+				req.Status,
+				req.Findings,
+				req.Engines,
 				"Automated", // suppose someone else to modify repo
 				currentTime,
+				req.SinceCommit,
+				req.HeadCommit,
+				req.ClaimedBy,
+				req.LastError,
+				pq.Array(req.LastErrorStack),
 			).StructScan(&res)
 		}
 	}
 
 	if err != nil {
+		if err == sql.ErrNoRows {
+			// e.g. scanning is cancelled meanwhile
+			errx = serror.Newf("Scanning id[%v] cannot be %v from its current status", req.Id, req.Status)
+			errx.AddCommentf("[repository][EditScanningStatusById] while update scanning status")
+			return
+		}
 		errx = serror.NewFromError(err)
 		errx.AddCommentf("[repository][EditScanningStatusById] while update scanning status")
 		return
 	}
 	return
 }
+
+// ClaimScanning updates queued scanning of highest priority aged by aging 'in_progress' on behalf of claimed_by
+// and leases it for lease, nil once queue is empty
+func (s scanningRepository) ClaimScanning(ctx context.Context, claimed_by string, lease time.Duration,
+	aging time.Duration) (res *model.ScanningListResponse, errx serror.SError) {
+	currentTime, _ := uttime.NowWithTimezone(constants.DefaultTimezone)
+
+	var scanning model.ScanningListResponse
+	err := s.psql.DB.QueryRowxContext(ctx, queries.ClaimScanning, claimed_by, currentTime, currentTime.Add(lease),
+		aging.Seconds()).StructScan(&scanning)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return
+		}
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[repository][ClaimScanning] while claim scanning")
+		return
+	}
+
+	return &scanning, nil
+}
+
+// RenewScanningLease extends lease of scanning for lease from now, false if it is no longer in progress by claimed_by
+func (s scanningRepository) RenewScanningLease(ctx context.Context, scanning_id int64, claimed_by string, lease time.Duration) (res bool, errx serror.SError) {
+	currentTime, _ := uttime.NowWithTimezone(constants.DefaultTimezone)
+
+	result, err := s.psql.DB.ExecContext(ctx, queries.UpdateScanningLeaseById, scanning_id, claimed_by, currentTime.Add(lease))
+	if err != nil {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[repository][RenewScanningLease] while renew lease of scanning")
+		return
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[repository][RenewScanningLease] while get affected rows")
+		return
+	}
+
+	return affected > 0, nil
+}
+
+// ReapExpiredScannings queues scannings whose lease expired again, those attempted as many times as allowed are dead instead
+func (s scanningRepository) ReapExpiredScannings(ctx context.Context) (res []model.ReapedScanning, errx serror.SError) {
+	currentTime, _ := uttime.NowWithTimezone(constants.DefaultTimezone)
+
+	rows, err := s.psql.DB.QueryxContext(ctx, queries.UpdateExpiredScannings,
+		"Scanning is abandoned by its worker",
+		currentTime,
+	)
+	if err != nil {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[repository][ReapExpiredScannings] while reap expired scannings")
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var r model.ReapedScanning
+		if err = rows.StructScan(&r); err != nil {
+			errx = serror.NewFromError(err)
+			errx.AddCommentf("[repository][ReapExpiredScannings] while rows.StructScan")
+			return
+		}
+		res = append(res, r)
+	}
+	return
+}
+
+func (s scanningRepository) CancelScanningById(ctx context.Context, tx *model.Trx, scanning_id int64) (res *model.ScanningResponse, errx serror.SError) {
+	currentTime, _ := uttime.NowWithTimezone(constants.DefaultTimezone)
+
+	var scanning model.ScanningResponse
+	var err error
+	if tx != nil {
+		err = tx.QueryRowxContext(ctx, queries.CancelScanningById,
+			scanning_id,
+			"Anonymous",
+			currentTime,
+		).StructScan(&scanning)
+	} else {
+		err = s.psql.DB.QueryRowxContext(ctx, queries.CancelScanningById,
+			scanning_id,
+			"Anonymous",
+			currentTime,
+		).StructScan(&scanning)
+	}
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return
+		}
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[repository][CancelScanningById] while cancel scanning")
+		return
+	}
+
+	return &scanning, nil
+}
+
+func (s scanningRepository) AddFindings(ctx context.Context, tx *model.Trx, scanning_id int64, findings []model.Finding) (errx serror.SError) {
+	if len(findings) == 0 {
+		return
+	}
+	currentTime, _ := uttime.NowWithTimezone(constants.DefaultTimezone)
+
+	// Findings of a scanning are inserted at once, column by column
+	var (
+		findingIds    = make([]string, 0, len(findings))
+		engines       = make([]string, 0, len(findings))
+		rules         = make([]string, 0, len(findings))
+		severities    = make([]string, 0, len(findings))
+		paths         = make([]string, 0, len(findings))
+		lines         = make([]int64, 0, len(findings))
+		commitHashes  = make([]string, 0, len(findings))
+		commitAuthors = make([]string, 0, len(findings))
+		fingerprints  = make([]string, 0, len(findings))
+		occurrences   = make([]string, 0, len(findings))
+		suppressedBy  = make([]string, 0, len(findings))
+		suppressions  = make([]string, 0, len(findings))
+		baselines     = make([]int64, 0, len(findings))
+		details       = make([]string, 0, len(findings))
+	)
+	for _, finding := range findings {
+		detail, err := json.Marshal(finding)
+		if err != nil {
+			errx = serror.NewFromError(err)
+			errx.AddCommentf("[repository][AddFindings] while marshal finding %v", finding.ID)
+			return
+		}
+		findingIds = append(findingIds, finding.ID)
+		engines = append(engines, finding.Engine)
+		rules = append(rules, finding.Rule)
+		severities = append(severities, finding.Severity)
+		paths = append(paths, finding.FilePath)
+		lines = append(lines, int64(finding.Line))
+		commitHashes = append(commitHashes, finding.CommitHash)
+		commitAuthors = append(commitAuthors, finding.CommitAuthor)
+		fingerprints = append(fingerprints, finding.Fingerprint)
+		occurrences = append(occurrences, finding.Occurrence)
+		if finding.Suppression != nil {
+			suppressedBy = append(suppressedBy, finding.Suppression.Source)
+			suppressions = append(suppressions, finding.Suppression.Reason)
+		} else {
+			suppressedBy = append(suppressedBy, "")
+			suppressions = append(suppressions, "")
+		}
+		baselines = append(baselines, finding.BaselineVersion)
+		details = append(details, string(detail))
+	}
+	args := []interface{}{
+		scanning_id,
+		pq.Array(findingIds),
+		pq.Array(engines),
+		pq.Array(rules),
+		pq.Array(severities),
+		pq.Array(paths),
+		pq.Array(lines),
+		pq.Array(commitHashes),
+		pq.Array(commitAuthors),
+		pq.Array(fingerprints),
+		pq.Array(occurrences),
+		pq.Array(suppressedBy),
+		pq.Array(suppressions),
+		pq.Array(baselines),
+		pq.Array(details),
+		currentTime,
+	}
+
+	var err error
+	if tx != nil {
+		_, err = tx.ExecContext(ctx, queries.InsertFindings, args...)
+	} else {
+		_, err = s.psql.DB.ExecContext(ctx, queries.InsertFindings, args...)
+	}
+	if err != nil {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[repository][AddFindings] while insert findings")
+		return
+	}
+	return
+}
+
+func (s scanningRepository) GetFindingList(ctx context.Context, req model.FindingListRequest) (res []model.FindingListResponse, errx serror.SError) {
+	rows, err := s.DB.QueryxContext(ctx, queries.GetFindingList,
+		req.ScanningId,
+		req.Severity,
+		req.Occurrence,
+		req.Engine,
+		req.Rule,
+		req.FilePath,
+		req.CommitHash,
+		req.CommitAuthor,
+		req.TriageStatus,
+		req.Assignee,
+		req.Suppressed,
+		req.Baselined,
+		req.Limit,
+		(req.Page-1)*req.Limit)
+	if err != nil {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[repository][GetFindingList] while get finding list")
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var r model.FindingListResponse
+		if err = rows.StructScan(&r); err != nil {
+			errx = serror.NewFromError(err)
+			errx.AddCommentf("[repository][GetFindingList] while rows.StructScan")
+			return
+		}
+		res = append(res, r)
+	}
+	return
+}
+
+func (s scanningRepository) GetFindingById(ctx context.Context, scanning_id int64, finding_id string) (res *model.FindingListResponse, errx serror.SError) {
+	var finding model.FindingListResponse
+	err := s.DB.QueryRowxContext(ctx, queries.GetFindingById, scanning_id, finding_id).StructScan(&finding)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return
+		}
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[repository][GetFindingById] while get finding")
+		return
+	}
+
+	return &finding, nil
+}
+
+func (s scanningRepository) EditFindingTriage(ctx context.Context, tx *model.Trx, req model.TriageFindingRequest) (res model.FindingTriageResponse, errx serror.SError) {
+	currentTime, _ := uttime.NowWithTimezone(constants.DefaultTimezone)
+
+	var assignee string
+	if req.Assignee != nil {
+		assignee = *req.Assignee
+	}
+
+	var err error
+	if tx != nil {
+		err = tx.QueryRowxContext(ctx, queries.UpsertFindingTriage,
+			req.RepositoryId,
+			req.Fingerprint,
+			req.Rule,
+			req.FilePath,
+			req.ScanningId,
+			req.Status,
+			req.Assignee != nil,
+			assignee,
+			req.TriagedBy,
+			currentTime,
+		).StructScan(&res)
+	} else {
+		err = s.psql.DB.QueryRowxContext(ctx, queries.UpsertFindingTriage,
+			req.RepositoryId,
+			req.Fingerprint,
+			req.Rule,
+			req.FilePath,
+			req.ScanningId,
+			req.Status,
+			req.Assignee != nil,
+			assignee,
+			req.TriagedBy,
+			currentTime,
+		).StructScan(&res)
+	}
+	if err != nil {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[repository][EditFindingTriage] while upsert finding triage")
+		return
+	}
+	return
+}
+
+func (s scanningRepository) AddFindingComment(ctx context.Context, tx *model.Trx, req model.AddFindingCommentRequest) (res model.FindingComment, errx serror.SError) {
+	currentTime, _ := uttime.NowWithTimezone(constants.DefaultTimezone)
+
+	var err error
+	if tx != nil {
+		err = tx.QueryRowxContext(ctx, queries.InsertFindingComment,
+			req.RepositoryId,
+			req.Fingerprint,
+			req.ScanningId,
+			req.FindingId,
+			req.Comment,
+			req.CreatedBy,
+			currentTime,
+			req.Rule,
+			req.FilePath,
+		).StructScan(&res)
+	} else {
+		err = s.psql.DB.QueryRowxContext(ctx, queries.InsertFindingComment,
+			req.RepositoryId,
+			req.Fingerprint,
+			req.ScanningId,
+			req.FindingId,
+			req.Comment,
+			req.CreatedBy,
+			currentTime,
+			req.Rule,
+			req.FilePath,
+		).StructScan(&res)
+	}
+	if err != nil {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[repository][AddFindingComment] while insert finding comment")
+		return
+	}
+	return
+}
+
+func (s scanningRepository) GetFindingComments(ctx context.Context, repo_id int64, fingerprint string) (res []model.FindingComment, errx serror.SError) {
+	rows, err := s.DB.QueryxContext(ctx, queries.GetFindingComments, repo_id, fingerprint)
+	if err != nil {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[repository][GetFindingComments] while get finding comments")
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var r model.FindingComment
+		if err = rows.StructScan(&r); err != nil {
+			errx = serror.NewFromError(err)
+			errx.AddCommentf("[repository][GetFindingComments] while rows.StructScan")
+			return
+		}
+		res = append(res, r)
+	}
+	return
+}
+
+func (s scanningRepository) AddFindingSecrets(ctx context.Context, tx *model.Trx, secrets []model.FindingSecret) (errx serror.SError) {
+	if len(secrets) == 0 {
+		return
+	}
+	currentTime, _ := uttime.NowWithTimezone(constants.DefaultTimezone)
+
+	// Secrets of a scanning are inserted at once
+	findingIds := make([]string, 0, len(secrets))
+	encrypted := make([]string, 0, len(secrets))
+	for _, secret := range secrets {
+		findingIds = append(findingIds, secret.FindingId)
+		encrypted = append(encrypted, secret.SecretEncrypted)
+	}
+
+	var err error
+	if tx != nil {
+		_, err = tx.ExecContext(ctx, queries.InsertFindingSecrets,
+			secrets[0].ScanningId,
+			pq.Array(findingIds),
+			pq.Array(encrypted),
+			currentTime,
+		)
+	} else {
+		_, err = s.psql.DB.ExecContext(ctx, queries.InsertFindingSecrets,
+			secrets[0].ScanningId,
+			pq.Array(findingIds),
+			pq.Array(encrypted),
+			currentTime,
+		)
+	}
+	if err != nil {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[repository][AddFindingSecrets] while insert finding secrets")
+		return
+	}
+	return
+}
+
+func (s scanningRepository) CopyFindingSecrets(ctx context.Context, tx *model.Trx, req model.CopyFindingSecretsRequest) (errx serror.SError) {
+	if len(req.FindingIds) == 0 {
+		return
+	}
+	currentTime, _ := uttime.NowWithTimezone(constants.DefaultTimezone)
+
+	var err error
+	if tx != nil {
+		_, err = tx.ExecContext(ctx, queries.CopyFindingSecrets,
+			req.FromScanningId,
+			req.ToScanningId,
+			pq.Array(req.FindingIds),
+			currentTime,
+		)
+	} else {
+		_, err = s.psql.DB.ExecContext(ctx, queries.CopyFindingSecrets,
+			req.FromScanningId,
+			req.ToScanningId,
+			pq.Array(req.FindingIds),
+			currentTime,
+		)
+	}
+	if err != nil {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[repository][CopyFindingSecrets] while copy finding secrets of scanning id[%v]", req.FromScanningId)
+		return
+	}
+	return
+}
+
+func (s scanningRepository) AddFindingFingerprints(ctx context.Context, tx *model.Trx, fingerprints []model.FindingFingerprint) (errx serror.SError) {
+	if len(fingerprints) == 0 {
+		return
+	}
+	currentTime, _ := uttime.NowWithTimezone(constants.DefaultTimezone)
+
+	// Fingerprints of a scanning are upserted at once
+	hashes := make([]string, 0, len(fingerprints))
+	rules := make([]string, 0, len(fingerprints))
+	paths := make([]string, 0, len(fingerprints))
+	occurrences := make([]string, 0, len(fingerprints))
+	for _, fingerprint := range fingerprints {
+		hashes = append(hashes, fingerprint.Fingerprint)
+		rules = append(rules, fingerprint.Rule)
+		paths = append(paths, fingerprint.FilePath)
+		occurrences = append(occurrences, fingerprint.Occurrence)
+	}
+
+	var err error
+	if tx != nil {
+		_, err = tx.ExecContext(ctx, queries.UpsertFindingFingerprints,
+			fingerprints[0].RepositoryId,
+			fingerprints[0].ScanningId,
+			pq.Array(hashes),
+			pq.Array(rules),
+			pq.Array(paths),
+			pq.Array(occurrences),
+			currentTime,
+		)
+	} else {
+		_, err = s.psql.DB.ExecContext(ctx, queries.UpsertFindingFingerprints,
+			fingerprints[0].RepositoryId,
+			fingerprints[0].ScanningId,
+			pq.Array(hashes),
+			pq.Array(rules),
+			pq.Array(paths),
+			pq.Array(occurrences),
+			currentTime,
+		)
+	}
+	if err != nil {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[repository][AddFindingFingerprints] while upsert finding fingerprints")
+		return
+	}
+	return
+}
+
+func (s scanningRepository) GetFindingSecret(ctx context.Context, scanning_id int64, finding_id string) (res *model.FindingSecret, errx serror.SError) {
+	var secret model.FindingSecret
+	err := s.DB.QueryRowxContext(ctx, queries.GetFindingSecret, scanning_id, finding_id).StructScan(&secret)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return
+		}
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[repository][GetFindingSecret] while get finding secret")
+		return
+	}
+
+	return &secret, nil
+}
+
+func (s scanningRepository) AddFindingReveal(ctx context.Context, tx *model.Trx, req model.RevealFindingRequest) (res model.RevealFindingResponse, errx serror.SError) {
+	currentTime, _ := uttime.NowWithTimezone(constants.DefaultTimezone)
+
+	var err error
+	if tx != nil {
+		err = tx.QueryRowxContext(ctx, queries.InsertFindingReveal,
+			req.ScanningId,
+			req.FindingId,
+			req.Reason,
+			req.RevealedBy,
+			currentTime,
+		).StructScan(&res)
+	} else {
+		err = s.psql.DB.QueryRowxContext(ctx, queries.InsertFindingReveal,
+			req.ScanningId,
+			req.FindingId,
+			req.Reason,
+			req.RevealedBy,
+			currentTime,
+		).StructScan(&res)
+	}
+	if err != nil {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[repository][AddFindingReveal] while insert finding reveal")
+		return
+	}
+	return
+}
+
+func (s scanningRepository) AddBaseline(ctx context.Context, tx *model.Trx, req model.AddBaselineRequest) (res model.BaselineResponse, errx serror.SError) {
+	currentTime, _ := uttime.NowWithTimezone(constants.DefaultTimezone)
+
+	var err error
+	if tx != nil {
+		err = tx.QueryRowxContext(ctx, queries.InsertBaseline,
+			req.RepositoryId,
+			req.ScanningId,
+			req.CreatedBy,
+			currentTime,
+		).StructScan(&res)
+	} else {
+		err = s.psql.DB.QueryRowxContext(ctx, queries.InsertBaseline,
+			req.RepositoryId,
+			req.ScanningId,
+			req.CreatedBy,
+			currentTime,
+		).StructScan(&res)
+	}
+	if err != nil {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[repository][AddBaseline] while insert baseline of repository id[%v]", req.RepositoryId)
+		return
+	}
+	return
+}
+
+func (s scanningRepository) GetBaselineList(ctx context.Context, repo_id int64) (res []model.BaselineResponse, errx serror.SError) {
+	rows, err := s.DB.QueryxContext(ctx, queries.GetBaselineList, repo_id)
+	if err != nil {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[repository][GetBaselineList] while get baseline list")
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var r model.BaselineResponse
+		if err = rows.StructScan(&r); err != nil {
+			errx = serror.NewFromError(err)
+			errx.AddCommentf("[repository][GetBaselineList] while rows.StructScan")
+			return
+		}
+		res = append(res, r)
+	}
+	return
+}
+
+func (s scanningRepository) GetActiveBaseline(ctx context.Context, repo_id int64) (res *model.BaselineResponse, errx serror.SError) {
+	var baseline model.BaselineResponse
+	err := s.DB.QueryRowxContext(ctx, queries.GetActiveBaseline, repo_id).StructScan(&baseline)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return
+		}
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[repository][GetActiveBaseline] while get active baseline")
+		return
+	}
+
+	return &baseline, nil
+}
+
+func (s scanningRepository) GetBaselineFingerprints(ctx context.Context, baseline_id int64) (res []string, errx serror.SError) {
+	err := s.DB.SelectContext(ctx, &res, queries.GetBaselineFingerprints, baseline_id)
+	if err != nil {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[repository][GetBaselineFingerprints] while get fingerprints of baseline id[%v]", baseline_id)
+		return
+	}
+	return
+}
+
+func (s scanningRepository) ActivateBaseline(ctx context.Context, tx *model.Trx, req model.ActivateBaselineRequest) (res *model.BaselineResponse, errx serror.SError) {
+	currentTime, _ := uttime.NowWithTimezone(constants.DefaultTimezone)
+
+	var (
+		baseline model.BaselineResponse
+		err      error
+	)
+	if tx != nil {
+		err = tx.QueryRowxContext(ctx, queries.UpdateActiveBaseline,
+			req.RepositoryId,
+			req.Version,
+			req.ActivatedBy,
+			currentTime,
+		).StructScan(&baseline)
+	} else {
+		err = s.psql.DB.QueryRowxContext(ctx, queries.UpdateActiveBaseline,
+			req.RepositoryId,
+			req.Version,
+			req.ActivatedBy,
+			currentTime,
+		).StructScan(&baseline)
+	}
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return
+		}
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[repository][ActivateBaseline] while activate baseline version %v", req.Version)
+		return
+	}
+
+	return &baseline, nil
+}
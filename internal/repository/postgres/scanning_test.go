@@ -1,7 +1,9 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
 	"fmt"
 	"io/ioutil"
 	"log"
@@ -21,6 +23,7 @@ import (
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/jmoiron/sqlx"
 	"github.com/jmoiron/sqlx/types"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -38,7 +41,7 @@ func NewScanningMock() (internal.IScanningRepository, *sql.DB, sqlmock.Sqlmock)
 	builder := sqlq.NewBuilder(opts)
 
 	sqlxDb := sqlx.NewDb(db, "sqlmock")
-	postDB := &database.DB{sqlxDb}
+	postDB := &database.DB{DB: sqlxDb}
 
 	trxRepo := NewTrxRepository(nil)
 
@@ -74,19 +77,29 @@ func TestGetScanningList(t *testing.T) {
 
 				rows := sqlmock.NewRows([]string{
 					"scanning_id",
+					"repository_id",
 					"repository_name",
 					"repository_url",
 					"findings",
+					"scan_engines",
 					"scanning_status",
+					"is_full_scan",
+					"since_commit",
+					"head_commit",
 					"queued_at",
 					"scanning_at",
 					"finished_at",
 				}).AddRow(
 					10,
+					3,
 					"JQuery",
 					"github.com/jquery/jquery",
 					types.JSONText([]byte(`{}`)),
+					types.JSONText([]byte(`[]`)),
 					"queued",
+					true,
+					nil,
+					nil,
 					currentTime,
 					currentTime,
 					currentTime,
@@ -107,10 +120,13 @@ func TestGetScanningList(t *testing.T) {
 			want: []model.ScanningListResponse{
 				{
 					Id:         10,
+					RepoId:     3,
 					Name:       "JQuery",
 					Url:        "github.com/jquery/jquery",
 					Findings:   types.JSONText([]byte(`{}`)),
+					Engines:    types.JSONText([]byte(`[]`)),
 					Status:     "queued",
+					IsFullScan: true,
 					QueuedAt:   currentTime,
 					ScanningAt: &currentTime,
 					FinishedAt: &currentTime,
@@ -122,7 +138,7 @@ func TestGetScanningList(t *testing.T) {
 
 	for _, test := range tests {
 		test.mock()
-		got, err := repo.GetScanningList(test.requestBody)
+		got, err := repo.GetScanningList(context.Background(), test.requestBody)
 		if (err != nil) != test.wantErr {
 			t.Errorf("GetScanningList() error '%s'", err)
 			return
@@ -145,12 +161,13 @@ func TestAddNewScanning(t *testing.T) {
 	defer patch.Unpatch()
 
 	currentTime, _ := uttime.NowWithTimezone(constants.DefaultTimezone)
+	priority := 50
 
 	tests := []struct {
 		name        string
 		repo        internal.IScanningRepository
 		mock        func()
-		requestBody int64
+		requestBody model.AddScanningRequest
 		want        model.ScanningResponse
 		wantErr     bool
 	}{
@@ -162,7 +179,11 @@ func TestAddNewScanning(t *testing.T) {
 					"scanning_id",
 					"repository_id",
 					"findings",
+					"scan_engines",
 					"scanning_status",
+					"is_full_scan",
+					"since_commit",
+					"head_commit",
 					"queued_at",
 					"scanning_at",
 					"finished_at",
@@ -170,47 +191,140 @@ func TestAddNewScanning(t *testing.T) {
 					10,
 					3,
 					types.JSONText([]byte(`{}`)),
+					types.JSONText([]byte(`[]`)),
 					"queued",
+					true,
+					nil,
+					nil,
 					currentTime,
 					currentTime,
 					currentTime,
 				)
 				expectedQuery := mock.ExpectQuery(regexp.QuoteMeta(queries.InsertNewScanning)).WithArgs(
 					3,
+					"standard",
+					true,
+					"",
+					"",
 					currentTime,
 					"Anonymous",
 					currentTime,
 					"Anonymous",
 					currentTime,
+					3,
+					50,
 				)
 				expectedQuery.WillReturnRows(rows)
 			},
-			requestBody: 3,
+			requestBody: model.AddScanningRequest{
+				RepoId:      3,
+				Mode:        "standard",
+				IsFullScan:  true,
+				MaxAttempts: 3,
+				Priority:    &priority,
+			},
 			want: model.ScanningResponse{
 				Id:         10,
 				RepoId:     3,
 				Findings:   types.JSONText([]byte(`{}`)),
+				Engines:    types.JSONText([]byte(`[]`)),
 				Status:     "queued",
+				IsFullScan: true,
 				QueuedAt:   currentTime,
 				ScanningAt: &currentTime,
 				FinishedAt: &currentTime,
 			},
 			wantErr: false,
 		},
+		{
+			name: "Coalesced",
+			repo: repo,
+			mock: func() {
+				args := []driver.Value{3, "diff", false, "main", "feature"}
+				mock.ExpectQuery(regexp.QuoteMeta(queries.InsertNewScanning)).WithArgs(
+					append(args, currentTime, "Anonymous", currentTime, "Anonymous", currentTime, 3, 50)...,
+				).WillReturnRows(sqlmock.NewRows([]string{"scanning_id"}))
+
+				rows := sqlmock.NewRows([]string{
+					"scanning_id",
+					"repository_id",
+					"scanning_status",
+					"scan_mode",
+					"queued_at",
+					"priority",
+				}).AddRow(
+					9,
+					3,
+					"queued",
+					"diff",
+					currentTime,
+					50,
+				)
+				mock.ExpectQuery(regexp.QuoteMeta(queries.UpdateQueuedDuplicateScanning)).WithArgs(append(args, 50)...).
+					WillReturnRows(rows)
+			},
+			requestBody: model.AddScanningRequest{
+				RepoId:      3,
+				Mode:        "diff",
+				BaseRef:     "main",
+				Ref:         "feature",
+				MaxAttempts: 3,
+				Priority:    &priority,
+			},
+			want: model.ScanningResponse{
+				Id:          9,
+				RepoId:      3,
+				Status:      "queued",
+				Mode:        "diff",
+				QueuedAt:    currentTime,
+				Priority:    50,
+				IsCoalesced: true,
+			},
+			wantErr: false,
+		},
+		{
+			name: "Duplicate claimed meanwhile",
+			repo: repo,
+			mock: func() {
+				args := []driver.Value{4, "standard", false, "", ""}
+				insertArgs := append(args, currentTime, "Anonymous", currentTime, "Anonymous", currentTime, 3, 50)
+				mock.ExpectQuery(regexp.QuoteMeta(queries.InsertNewScanning)).WithArgs(insertArgs...).
+					WillReturnRows(sqlmock.NewRows([]string{"scanning_id"}))
+				mock.ExpectQuery(regexp.QuoteMeta(queries.UpdateQueuedDuplicateScanning)).WithArgs(append(args, 50)...).
+					WillReturnRows(sqlmock.NewRows([]string{"scanning_id"}))
+				mock.ExpectQuery(regexp.QuoteMeta(queries.InsertNewScanning)).WithArgs(insertArgs...).
+					WillReturnRows(sqlmock.NewRows([]string{"scanning_id", "repository_id", "scanning_status", "queued_at"}).
+						AddRow(11, 4, "queued", currentTime))
+			},
+			requestBody: model.AddScanningRequest{
+				RepoId:      4,
+				Mode:        "standard",
+				MaxAttempts: 3,
+				Priority:    &priority,
+			},
+			want: model.ScanningResponse{
+				Id:       11,
+				RepoId:   4,
+				Status:   "queued",
+				QueuedAt: currentTime,
+			},
+			wantErr: false,
+		},
 	}
 
 	for _, test := range tests {
 		test.mock()
-		got, err := repo.AddNewScanning(nil, test.requestBody)
+		got, err := repo.AddNewScanning(context.Background(), nil, test.requestBody)
 		if (err != nil) != test.wantErr {
-			t.Errorf("AddNewScanning() error '%s'", err)
+			t.Errorf("AddNewScanning() %s error '%s'", test.name, err)
 			return
 		}
 
 		if err == nil {
-			assert.Equal(t, reflect.DeepEqual(got, test.want), true)
+			assert.Equal(t, test.want, got, test.name)
 		}
 	}
+	assert.Nil(t, mock.ExpectationsWereMet())
 }
 
 func TestEditScanningStatusById(t *testing.T) {
@@ -224,16 +338,14 @@ func TestEditScanningStatusById(t *testing.T) {
 	defer patch.Unpatch()
 
 	currentTime, _ := uttime.NowWithTimezone(constants.DefaultTimezone)
-
+	sinceCommit, headCommit := "0a1b2c3d", "4e5f6a7b"
 	tests := []struct {
-		name          string
-		repo          internal.IScanningRepository
-		mock          func()
-		reqScanningId int64
-		reqStatus     string
-		reqFindings   types.JSONText
-		want          model.ScanningResponse
-		wantErr       bool
+		name    string
+		repo    internal.IScanningRepository
+		mock    func()
+		req     model.EditScanningStatusRequest
+		want    model.ScanningResponse
+		wantErr bool
 	}{
 		{
 			name: "OK",
@@ -243,7 +355,11 @@ func TestEditScanningStatusById(t *testing.T) {
 					"scanning_id",
 					"repository_id",
 					"findings",
+					"scan_engines",
 					"scanning_status",
+					"is_full_scan",
+					"since_commit",
+					"head_commit",
 					"queued_at",
 					"scanning_at",
 					"finished_at",
@@ -251,7 +367,11 @@ func TestEditScanningStatusById(t *testing.T) {
 					10,
 					3,
 					types.JSONText([]byte(`{}`)),
+					types.JSONText([]byte(`[]`)),
 					"in_progress",
+					false,
+					nil,
+					nil,
 					currentTime,
 					currentTime,
 					currentTime,
@@ -264,13 +384,15 @@ func TestEditScanningStatusById(t *testing.T) {
 				)
 				expectedQuery.WillReturnRows(rows)
 			},
-			reqScanningId: 10,
-			reqStatus:     "in_progress",
-			reqFindings:   types.JSONText([]byte(`{}`)),
+			req: model.EditScanningStatusRequest{
+				Id:     10,
+				Status: "in_progress",
+			},
 			want: model.ScanningResponse{
 				Id:         10,
 				RepoId:     3,
 				Findings:   types.JSONText([]byte(`{}`)),
+				Engines:    types.JSONText([]byte(`[]`)),
 				Status:     "in_progress",
 				QueuedAt:   currentTime,
 				ScanningAt: &currentTime,
@@ -286,7 +408,11 @@ func TestEditScanningStatusById(t *testing.T) {
 					"scanning_id",
 					"repository_id",
 					"findings",
+					"scan_engines",
 					"scanning_status",
+					"is_full_scan",
+					"since_commit",
+					"head_commit",
 					"queued_at",
 					"scanning_at",
 					"finished_at",
@@ -294,7 +420,11 @@ func TestEditScanningStatusById(t *testing.T) {
 					10,
 					3,
 					types.JSONText([]byte(`{"result":"success"}`)),
+					types.JSONText([]byte(`[{"name":"grab"}]`)),
 					"success",
+					false,
+					"0a1b2c3d",
+					"4e5f6a7b",
 					currentTime,
 					currentTime,
 					currentTime,
@@ -303,32 +433,286 @@ func TestEditScanningStatusById(t *testing.T) {
 					10,
 					"success",
 					types.JSONText([]byte(`{"result":"success"}`)),
+					types.JSONText([]byte(`[{"name":"grab"}]`)),
 					"Automated",
 					currentTime,
+					"0a1b2c3d",
+					"4e5f6a7b",
+					"scanner-1:42/2",
+					"",
+					pq.Array([]string(nil)),
+				)
+				expectedQuery.WillReturnRows(rows)
+			},
+			req: model.EditScanningStatusRequest{
+				Id:          10,
+				Status:      "success",
+				Findings:    types.JSONText([]byte(`{"result":"success"}`)),
+				Engines:     types.JSONText([]byte(`[{"name":"grab"}]`)),
+				SinceCommit: "0a1b2c3d",
+				HeadCommit:  "4e5f6a7b",
+				ClaimedBy:   "scanner-1:42/2",
+			},
+			want: model.ScanningResponse{
+				Id:          10,
+				RepoId:      3,
+				Findings:    types.JSONText([]byte(`{"result":"success"}`)),
+				Engines:     types.JSONText([]byte(`[{"name":"grab"}]`)),
+				Status:      "success",
+				SinceCommit: &sinceCommit,
+				HeadCommit:  &headCommit,
+				QueuedAt:    currentTime,
+				ScanningAt:  &currentTime,
+				FinishedAt:  &currentTime,
+			},
+			wantErr: false,
+		},
+		{
+			name: "Retry",
+			repo: repo,
+			mock: func() {
+				rows := sqlmock.NewRows([]string{
+					"scanning_id",
+					"repository_id",
+					"findings",
+					"scan_engines",
+					"scanning_status",
+					"is_full_scan",
+					"queued_at",
+					"max_attempts",
+				}).AddRow(
+					10,
+					3,
+					types.JSONText([]byte(`{}`)),
+					types.JSONText([]byte(`[]`)),
+					"queued",
+					false,
+					currentTime,
+					3,
+				)
+				expectedQuery := mock.ExpectQuery(regexp.QuoteMeta(queries.UpdateScanningRetryById)).WithArgs(
+					10,
+					"scanner-1:42/2",
+					currentTime.Add(time.Minute),
+					"connection refused",
+					pq.Array([]string{"[scanner][Scan] while clone repository"}),
+					currentTime,
 				)
 				expectedQuery.WillReturnRows(rows)
 			},
-			reqScanningId: 10,
-			reqStatus:     "success",
-			reqFindings:   types.JSONText([]byte(`{"result":"success"}`)),
+			req: model.EditScanningStatusRequest{
+				Id:             10,
+				Status:         "queued",
+				ClaimedBy:      "scanner-1:42/2",
+				RetryDelay:     time.Minute,
+				LastError:      "connection refused",
+				LastErrorStack: []string{"[scanner][Scan] while clone repository"},
+			},
 			want: model.ScanningResponse{
+				Id:          10,
+				RepoId:      3,
+				Findings:    types.JSONText([]byte(`{}`)),
+				Engines:     types.JSONText([]byte(`[]`)),
+				Status:      "queued",
+				QueuedAt:    currentTime,
+				MaxAttempts: 3,
+			},
+			wantErr: false,
+		},
+	}
+
+	for _, test := range tests {
+		test.mock()
+		got, err := repo.EditScanningStatusById(context.Background(), nil, test.req)
+		if (err != nil) != test.wantErr {
+			t.Errorf("EditScanningStatusById() error '%s'", err)
+			return
+		}
+
+		if err == nil {
+			assert.Equal(t, reflect.DeepEqual(got, test.want), true)
+		}
+	}
+}
+
+func TestGetLastSuccessfulScanning(t *testing.T) {
+	repo, db, mock := NewScanningMock()
+	defer func() {
+		db.Close()
+	}()
+
+	wayback := time.Date(1974, time.May, 19, 1, 2, 3, 4, time.UTC)
+	patch := monkey.Patch(time.Now, func() time.Time { return wayback })
+	defer patch.Unpatch()
+
+	currentTime, _ := uttime.NowWithTimezone(constants.DefaultTimezone)
+	headCommit := "4e5f6a7b"
+
+	tests := []struct {
+		name    string
+		repo    internal.IScanningRepository
+		mock    func()
+		repoId  int64
+		ref     string
+		want    *model.ScanningResponse
+		wantErr bool
+	}{
+		{
+			name: "OK",
+			repo: repo,
+			mock: func() {
+				rows := sqlmock.NewRows([]string{
+					"scanning_id",
+					"repository_id",
+					"findings",
+					"scan_engines",
+					"scanning_status",
+					"is_full_scan",
+					"since_commit",
+					"head_commit",
+					"queued_at",
+					"scanning_at",
+					"finished_at",
+				}).AddRow(
+					10,
+					3,
+					types.JSONText([]byte(`[]`)),
+					types.JSONText([]byte(`[{"name":"grab"}]`)),
+					"success",
+					true,
+					nil,
+					headCommit,
+					currentTime,
+					currentTime,
+					currentTime,
+				)
+				mock.ExpectQuery(regexp.QuoteMeta(queries.GetLastSuccessfulScanning)).WithArgs(3, "").WillReturnRows(rows)
+			},
+			repoId: 3,
+			want: &model.ScanningResponse{
 				Id:         10,
 				RepoId:     3,
-				Findings:   types.JSONText([]byte(`{"result":"success"}`)),
+				Findings:   types.JSONText([]byte(`[]`)),
+				Engines:    types.JSONText([]byte(`[{"name":"grab"}]`)),
 				Status:     "success",
+				IsFullScan: true,
+				HeadCommit: &headCommit,
 				QueuedAt:   currentTime,
 				ScanningAt: &currentTime,
 				FinishedAt: &currentTime,
 			},
 			wantErr: false,
 		},
+		{
+			name: "Not Found",
+			repo: repo,
+			mock: func() {
+				mock.ExpectQuery(regexp.QuoteMeta(queries.GetLastSuccessfulScanning)).WithArgs(4, "develop").WillReturnError(sql.ErrNoRows)
+			},
+			repoId:  4,
+			ref:     "develop",
+			want:    nil,
+			wantErr: false,
+		},
 	}
 
 	for _, test := range tests {
 		test.mock()
-		got, err := repo.EditScanningStatusById(nil, test.reqScanningId, test.reqStatus, test.reqFindings)
+		got, err := repo.GetLastSuccessfulScanning(context.Background(), test.repoId, test.ref)
 		if (err != nil) != test.wantErr {
-			t.Errorf("EditScanningStatusById() error '%s'", err)
+			t.Errorf("GetLastSuccessfulScanning() error '%s'", err)
+			return
+		}
+
+		if err == nil {
+			assert.Equal(t, reflect.DeepEqual(got, test.want), true)
+		}
+	}
+}
+
+func TestGetPreviousScanning(t *testing.T) {
+	repo, db, mock := NewScanningMock()
+	defer func() {
+		db.Close()
+	}()
+
+	wayback := time.Date(1974, time.May, 19, 1, 2, 3, 4, time.UTC)
+	patch := monkey.Patch(time.Now, func() time.Time { return wayback })
+	defer patch.Unpatch()
+
+	currentTime, _ := uttime.NowWithTimezone(constants.DefaultTimezone)
+
+	tests := []struct {
+		name       string
+		mock       func()
+		repoId     int64
+		ref        string
+		scanningId int64
+		want       *model.ScanningResponse
+		wantErr    bool
+	}{
+		{
+			name: "OK",
+			mock: func() {
+				rows := sqlmock.NewRows([]string{
+					"scanning_id",
+					"repository_id",
+					"findings",
+					"scan_engines",
+					"scanning_status",
+					"scan_mode",
+					"is_full_scan",
+					"queued_at",
+					"scanning_at",
+					"finished_at",
+				}).AddRow(
+					10,
+					3,
+					types.JSONText([]byte(`[{"Fingerprint":"5d41402a"}]`)),
+					types.JSONText([]byte(`[{"name":"native"}]`)),
+					"success",
+					"standard",
+					true,
+					currentTime,
+					currentTime,
+					currentTime,
+				)
+				mock.ExpectQuery(regexp.QuoteMeta(queries.GetPreviousScanning)).WithArgs(3, "", 11).WillReturnRows(rows)
+			},
+			repoId:     3,
+			scanningId: 11,
+			want: &model.ScanningResponse{
+				Id:         10,
+				RepoId:     3,
+				Findings:   types.JSONText([]byte(`[{"Fingerprint":"5d41402a"}]`)),
+				Engines:    types.JSONText([]byte(`[{"name":"native"}]`)),
+				Status:     "success",
+				Mode:       "standard",
+				IsFullScan: true,
+				QueuedAt:   currentTime,
+				ScanningAt: &currentTime,
+				FinishedAt: &currentTime,
+			},
+			wantErr: false,
+		},
+		{
+			name: "Not Found",
+			mock: func() {
+				mock.ExpectQuery(regexp.QuoteMeta(queries.GetPreviousScanning)).WithArgs(4, "develop", 12).WillReturnError(sql.ErrNoRows)
+			},
+			repoId:     4,
+			ref:        "develop",
+			scanningId: 12,
+			want:       nil,
+			wantErr:    false,
+		},
+	}
+
+	for _, test := range tests {
+		test.mock()
+		got, err := repo.GetPreviousScanning(context.Background(), test.repoId, test.ref, test.scanningId)
+		if (err != nil) != test.wantErr {
+			t.Errorf("GetPreviousScanning() error '%s'", err)
 			return
 		}
 
@@ -337,3 +721,968 @@ func TestEditScanningStatusById(t *testing.T) {
 		}
 	}
 }
+
+func TestClaimScanning(t *testing.T) {
+	repo, db, mock := NewScanningMock()
+	defer func() {
+		db.Close()
+	}()
+
+	wayback := time.Date(1974, time.May, 19, 1, 2, 3, 4, time.UTC)
+	patch := monkey.Patch(time.Now, func() time.Time { return wayback })
+	defer patch.Unpatch()
+
+	currentTime, _ := uttime.NowWithTimezone(constants.DefaultTimezone)
+	claimedBy := "scanner-1:42/2"
+	lease, aging := 2*time.Minute, 5*time.Minute
+	leaseExpiresAt := currentTime.Add(lease)
+
+	tests := []struct {
+		name      string
+		mock      func()
+		claimedBy string
+		want      *model.ScanningListResponse
+		wantErr   bool
+	}{
+		{
+			name: "OK",
+			mock: func() {
+				rows := sqlmock.NewRows([]string{
+					"scanning_id",
+					"repository_id",
+					"repository_name",
+					"repository_url",
+					"findings",
+					"scan_engines",
+					"scanning_status",
+					"scan_mode",
+					"is_full_scan",
+					"queued_at",
+					"scanning_at",
+					"claimed_by",
+					"lease_expires_at",
+					"attempts",
+					"priority",
+				}).AddRow(
+					10,
+					3,
+					"JQuery",
+					"github.com/jquery/jquery",
+					types.JSONText([]byte(`{}`)),
+					types.JSONText([]byte(`[]`)),
+					"in_progress",
+					"standard",
+					true,
+					currentTime,
+					currentTime,
+					claimedBy,
+					leaseExpiresAt,
+					1,
+					50,
+				)
+				mock.ExpectQuery(regexp.QuoteMeta(queries.ClaimScanning)).WithArgs(claimedBy, currentTime, leaseExpiresAt, float64(300)).WillReturnRows(rows)
+			},
+			claimedBy: claimedBy,
+			want: &model.ScanningListResponse{
+				Id:         10,
+				RepoId:     3,
+				Name:       "JQuery",
+				Url:        "github.com/jquery/jquery",
+				Findings:   types.JSONText([]byte(`{}`)),
+				Engines:    types.JSONText([]byte(`[]`)),
+				Status:     "in_progress",
+				Mode:       "standard",
+				IsFullScan: true,
+				QueuedAt:   currentTime,
+				ScanningAt: &currentTime,
+				ClaimedBy:      &claimedBy,
+				LeaseExpiresAt: &leaseExpiresAt,
+				Attempts:       1,
+				Priority:       50,
+			},
+			wantErr: false,
+		},
+		{
+			name: "Empty Queue",
+			mock: func() {
+				mock.ExpectQuery(regexp.QuoteMeta(queries.ClaimScanning)).WithArgs(claimedBy, currentTime, leaseExpiresAt, float64(300)).WillReturnError(sql.ErrNoRows)
+			},
+			claimedBy: claimedBy,
+			want:      nil,
+			wantErr:   false,
+		},
+		{
+			name: "Error",
+			mock: func() {
+				mock.ExpectQuery(regexp.QuoteMeta(queries.ClaimScanning)).WithArgs(claimedBy, currentTime, leaseExpiresAt, float64(300)).WillReturnError(sql.ErrConnDone)
+			},
+			claimedBy: claimedBy,
+			want:      nil,
+			wantErr:   true,
+		},
+	}
+
+	for _, test := range tests {
+		test.mock()
+		got, err := repo.ClaimScanning(context.Background(), test.claimedBy, lease, aging)
+		if (err != nil) != test.wantErr {
+			t.Errorf("ClaimScanning() error '%s'", err)
+			return
+		}
+
+		assert.Equal(t, test.want, got, test.name)
+	}
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestRenewScanningLease(t *testing.T) {
+	repo, db, mock := NewScanningMock()
+	defer func() {
+		db.Close()
+	}()
+
+	wayback := time.Date(1974, time.May, 19, 1, 2, 3, 4, time.UTC)
+	patch := monkey.Patch(time.Now, func() time.Time { return wayback })
+	defer patch.Unpatch()
+
+	currentTime, _ := uttime.NowWithTimezone(constants.DefaultTimezone)
+	lease := 2 * time.Minute
+
+	tests := []struct {
+		name       string
+		mock       func()
+		scanningId int64
+		want       bool
+		wantErr    bool
+	}{
+		{
+			name: "OK",
+			mock: func() {
+				mock.ExpectExec(regexp.QuoteMeta(queries.UpdateScanningLeaseById)).WithArgs(10, "scanner-1:42/2", currentTime.Add(lease)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			scanningId: 10,
+			want:       true,
+			wantErr:    false,
+		},
+		{
+			name: "Lost",
+			mock: func() {
+				mock.ExpectExec(regexp.QuoteMeta(queries.UpdateScanningLeaseById)).WithArgs(11, "scanner-1:42/2", currentTime.Add(lease)).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			scanningId: 11,
+			want:       false,
+			wantErr:    false,
+		},
+		{
+			name: "Error",
+			mock: func() {
+				mock.ExpectExec(regexp.QuoteMeta(queries.UpdateScanningLeaseById)).WithArgs(12, "scanner-1:42/2", currentTime.Add(lease)).
+					WillReturnError(sql.ErrConnDone)
+			},
+			scanningId: 12,
+			want:       false,
+			wantErr:    true,
+		},
+	}
+
+	for _, test := range tests {
+		test.mock()
+		got, err := repo.RenewScanningLease(context.Background(), test.scanningId, "scanner-1:42/2", lease)
+		if (err != nil) != test.wantErr {
+			t.Errorf("RenewScanningLease() error '%s'", err)
+			return
+		}
+
+		assert.Equal(t, test.want, got, test.name)
+	}
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestReapExpiredScannings(t *testing.T) {
+	repo, db, mock := NewScanningMock()
+	defer func() {
+		db.Close()
+	}()
+
+	wayback := time.Date(1974, time.May, 19, 1, 2, 3, 4, time.UTC)
+	patch := monkey.Patch(time.Now, func() time.Time { return wayback })
+	defer patch.Unpatch()
+
+	currentTime, _ := uttime.NowWithTimezone(constants.DefaultTimezone)
+	claimedBy := "scanner-1:42/2"
+
+	tests := []struct {
+		name    string
+		mock    func()
+		want    []model.ReapedScanning
+		wantErr bool
+	}{
+		{
+			name: "OK",
+			mock: func() {
+				rows := sqlmock.NewRows([]string{
+					"scanning_id",
+					"scanning_status",
+					"attempts",
+					"claimed_by",
+				}).
+					AddRow(10, "queued", 1, claimedBy).
+					AddRow(11, "dead", 3, claimedBy)
+				mock.ExpectQuery(regexp.QuoteMeta(queries.UpdateExpiredScannings)).WithArgs(
+					"Scanning is abandoned by its worker",
+					currentTime,
+				).WillReturnRows(rows)
+			},
+			want: []model.ReapedScanning{
+				{Id: 10, Status: "queued", Attempts: 1, ClaimedBy: &claimedBy},
+				{Id: 11, Status: "dead", Attempts: 3, ClaimedBy: &claimedBy},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Error",
+			mock: func() {
+				mock.ExpectQuery(regexp.QuoteMeta(queries.UpdateExpiredScannings)).WithArgs(
+					"Scanning is abandoned by its worker",
+					currentTime,
+				).WillReturnError(sql.ErrConnDone)
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		test.mock()
+		got, err := repo.ReapExpiredScannings(context.Background())
+		if (err != nil) != test.wantErr {
+			t.Errorf("ReapExpiredScannings() error '%s'", err)
+			return
+		}
+
+		assert.Equal(t, test.want, got, test.name)
+	}
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestCancelScanningById(t *testing.T) {
+	repo, db, mock := NewScanningMock()
+	defer func() {
+		db.Close()
+	}()
+
+	wayback := time.Date(1974, time.May, 19, 1, 2, 3, 4, time.UTC)
+	patch := monkey.Patch(time.Now, func() time.Time { return wayback })
+	defer patch.Unpatch()
+
+	currentTime, _ := uttime.NowWithTimezone(constants.DefaultTimezone)
+
+	tests := []struct {
+		name       string
+		repo       internal.IScanningRepository
+		mock       func()
+		scanningId int64
+		want       *model.ScanningResponse
+		wantErr    bool
+	}{
+		{
+			name: "OK",
+			repo: repo,
+			mock: func() {
+				rows := sqlmock.NewRows([]string{
+					"scanning_id",
+					"repository_id",
+					"findings",
+					"scan_engines",
+					"scanning_status",
+					"is_full_scan",
+					"since_commit",
+					"head_commit",
+					"queued_at",
+					"scanning_at",
+					"finished_at",
+				}).AddRow(
+					10,
+					3,
+					types.JSONText([]byte(`{}`)),
+					types.JSONText([]byte(`[]`)),
+					"cancelled",
+					false,
+					nil,
+					nil,
+					currentTime,
+					nil,
+					currentTime,
+				)
+				mock.ExpectQuery(regexp.QuoteMeta(queries.CancelScanningById)).WithArgs(10, "Anonymous", currentTime).WillReturnRows(rows)
+			},
+			scanningId: 10,
+			want: &model.ScanningResponse{
+				Id:         10,
+				RepoId:     3,
+				Findings:   types.JSONText([]byte(`{}`)),
+				Engines:    types.JSONText([]byte(`[]`)),
+				Status:     "cancelled",
+				QueuedAt:   currentTime,
+				FinishedAt: &currentTime,
+			},
+			wantErr: false,
+		},
+		{
+			name: "Already Finished",
+			repo: repo,
+			mock: func() {
+				mock.ExpectQuery(regexp.QuoteMeta(queries.CancelScanningById)).WithArgs(11, "Anonymous", currentTime).WillReturnError(sql.ErrNoRows)
+			},
+			scanningId: 11,
+			want:       nil,
+			wantErr:    false,
+		},
+	}
+
+	for _, test := range tests {
+		test.mock()
+		got, err := repo.CancelScanningById(context.Background(), nil, test.scanningId)
+		if (err != nil) != test.wantErr {
+			t.Errorf("CancelScanningById() error '%s'", err)
+			return
+		}
+
+		if err == nil {
+			assert.Equal(t, reflect.DeepEqual(got, test.want), true)
+		}
+	}
+}
+
+func TestAddFindingSecrets(t *testing.T) {
+	repo, db, mock := NewScanningMock()
+	defer func() {
+		db.Close()
+	}()
+
+	wayback := time.Date(1974, time.May, 19, 1, 2, 3, 4, time.UTC)
+	patch := monkey.Patch(time.Now, func() time.Time { return wayback })
+	defer patch.Unpatch()
+
+	currentTime, _ := uttime.NowWithTimezone(constants.DefaultTimezone)
+
+	tests := []struct {
+		name    string
+		mock    func()
+		secrets []model.FindingSecret
+		wantErr bool
+	}{
+		{
+			name: "OK",
+			mock: func() {
+				mock.ExpectExec(regexp.QuoteMeta(queries.InsertFindingSecrets)).WithArgs(
+					10,
+					`{"abc","def"}`,
+					`{"c2VjcmV0","b3RoZXI="}`,
+					currentTime,
+				).WillReturnResult(sqlmock.NewResult(0, 2))
+			},
+			secrets: []model.FindingSecret{
+				{ScanningId: 10, FindingId: "abc", SecretEncrypted: "c2VjcmV0"},
+				{ScanningId: 10, FindingId: "def", SecretEncrypted: "b3RoZXI="},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Nothing to insert",
+			mock:    func() {},
+			secrets: []model.FindingSecret{},
+			wantErr: false,
+		},
+	}
+
+	for _, test := range tests {
+		test.mock()
+		err := repo.AddFindingSecrets(context.Background(), nil, test.secrets)
+		if (err != nil) != test.wantErr {
+			t.Errorf("AddFindingSecrets() error '%s'", err)
+			return
+		}
+	}
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestCopyFindingSecrets(t *testing.T) {
+	repo, db, mock := NewScanningMock()
+	defer func() {
+		db.Close()
+	}()
+
+	wayback := time.Date(1974, time.May, 19, 1, 2, 3, 4, time.UTC)
+	patch := monkey.Patch(time.Now, func() time.Time { return wayback })
+	defer patch.Unpatch()
+
+	currentTime, _ := uttime.NowWithTimezone(constants.DefaultTimezone)
+
+	tests := []struct {
+		name    string
+		mock    func()
+		req     model.CopyFindingSecretsRequest
+		wantErr bool
+	}{
+		{
+			name: "OK",
+			mock: func() {
+				mock.ExpectExec(regexp.QuoteMeta(queries.CopyFindingSecrets)).WithArgs(
+					14,
+					15,
+					`{"abc","def"}`,
+					currentTime,
+				).WillReturnResult(sqlmock.NewResult(0, 2))
+			},
+			req:     model.CopyFindingSecretsRequest{FromScanningId: 14, ToScanningId: 15, FindingIds: []string{"abc", "def"}},
+			wantErr: false,
+		},
+		{
+			name:    "Nothing to copy",
+			mock:    func() {},
+			req:     model.CopyFindingSecretsRequest{FromScanningId: 14, ToScanningId: 15},
+			wantErr: false,
+		},
+		{
+			name: "Failed",
+			mock: func() {
+				mock.ExpectExec(regexp.QuoteMeta(queries.CopyFindingSecrets)).WithArgs(
+					14,
+					15,
+					`{"abc"}`,
+					currentTime,
+				).WillReturnError(sql.ErrConnDone)
+			},
+			req:     model.CopyFindingSecretsRequest{FromScanningId: 14, ToScanningId: 15, FindingIds: []string{"abc"}},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		test.mock()
+		err := repo.CopyFindingSecrets(context.Background(), nil, test.req)
+		if (err != nil) != test.wantErr {
+			t.Errorf("CopyFindingSecrets() error '%s'", err)
+			return
+		}
+	}
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestAddFindingFingerprints(t *testing.T) {
+	repo, db, mock := NewScanningMock()
+	defer func() {
+		db.Close()
+	}()
+
+	wayback := time.Date(1974, time.May, 19, 1, 2, 3, 4, time.UTC)
+	patch := monkey.Patch(time.Now, func() time.Time { return wayback })
+	defer patch.Unpatch()
+
+	currentTime, _ := uttime.NowWithTimezone(constants.DefaultTimezone)
+
+	tests := []struct {
+		name         string
+		mock         func()
+		fingerprints []model.FindingFingerprint
+		wantErr      bool
+	}{
+		{
+			name: "OK",
+			mock: func() {
+				mock.ExpectExec(regexp.QuoteMeta(queries.UpsertFindingFingerprints)).WithArgs(
+					3,
+					10,
+					`{"5d41402a","7d793037"}`,
+					`{"aws-key","password"}`,
+					`{"app.yml","db.yml"}`,
+					`{"new","fixed"}`,
+					currentTime,
+				).WillReturnResult(sqlmock.NewResult(0, 2))
+			},
+			fingerprints: []model.FindingFingerprint{
+				{RepositoryId: 3, ScanningId: 10, Fingerprint: "5d41402a", Rule: "aws-key", FilePath: "app.yml", Occurrence: "new"},
+				{RepositoryId: 3, ScanningId: 10, Fingerprint: "7d793037", Rule: "password", FilePath: "db.yml", Occurrence: "fixed"},
+			},
+			wantErr: false,
+		},
+		{
+			name:         "Nothing to upsert",
+			mock:         func() {},
+			fingerprints: nil,
+			wantErr:      false,
+		},
+	}
+
+	for _, test := range tests {
+		test.mock()
+		err := repo.AddFindingFingerprints(context.Background(), nil, test.fingerprints)
+		if (err != nil) != test.wantErr {
+			t.Errorf("AddFindingFingerprints() error '%s'", err)
+			return
+		}
+	}
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestAddFindings(t *testing.T) {
+	repo, db, mock := NewScanningMock()
+	defer func() {
+		db.Close()
+	}()
+
+	wayback := time.Date(1974, time.May, 19, 1, 2, 3, 4, time.UTC)
+	patch := monkey.Patch(time.Now, func() time.Time { return wayback })
+	defer patch.Unpatch()
+
+	currentTime, _ := uttime.NowWithTimezone(constants.DefaultTimezone)
+	findings := []model.Finding{
+		{ID: "abc", Engine: "native", Rule: "aws-key", Severity: "high", FilePath: "app.yml", Line: 3,
+			CommitHash: "0a1b2c3d", CommitAuthor: "dev", Fingerprint: "5d41402a", Occurrence: "new", BaselineVersion: 2},
+		{ID: "def", Engine: "entropy", Rule: "high-entropy-hex", Severity: "low", FilePath: "key.txt", Line: 1,
+			Suppression: &model.FindingSuppression{Source: "inline", Reason: "test fixture"}},
+	}
+
+	tests := []struct {
+		name     string
+		mock     func()
+		findings []model.Finding
+		wantErr  bool
+	}{
+		{
+			name: "OK",
+			mock: func() {
+				mock.ExpectExec(regexp.QuoteMeta(queries.InsertFindings)).WithArgs(
+					10,
+					`{"abc","def"}`,
+					`{"native","entropy"}`,
+					`{"aws-key","high-entropy-hex"}`,
+					`{"high","low"}`,
+					`{"app.yml","key.txt"}`,
+					`{3,1}`,
+					`{"0a1b2c3d",""}`,
+					`{"dev",""}`,
+					`{"5d41402a",""}`,
+					`{"new",""}`,
+					`{"","inline"}`,
+					`{"","test fixture"}`,
+					`{2,0}`,
+					sqlmock.AnyArg(), // whole findings as JSON
+					currentTime,
+				).WillReturnResult(sqlmock.NewResult(0, 2))
+			},
+			findings: findings,
+			wantErr:  false,
+		},
+		{
+			name:     "Nothing to insert",
+			mock:     func() {},
+			findings: []model.Finding{},
+			wantErr:  false,
+		},
+	}
+
+	for _, test := range tests {
+		test.mock()
+		err := repo.AddFindings(context.Background(), nil, 10, test.findings)
+		if (err != nil) != test.wantErr {
+			t.Errorf("AddFindings() error '%s'", err)
+			return
+		}
+	}
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestGetFindingList(t *testing.T) {
+	repo, db, mock := NewScanningMock()
+	defer func() {
+		db.Close()
+	}()
+
+	wayback := time.Date(1974, time.May, 19, 1, 2, 3, 4, time.UTC)
+	patch := monkey.Patch(time.Now, func() time.Time { return wayback })
+	defer patch.Unpatch()
+
+	currentTime, _ := uttime.NowWithTimezone(constants.DefaultTimezone)
+	occurrence := "recurring"
+	assignee := "alice"
+	suppressionSource := "ignore_file"
+	suppressionReason := "fake key of tests"
+
+	tests := []struct {
+		name        string
+		mock        func()
+		requestBody model.FindingListRequest
+		want        []model.FindingListResponse
+		wantErr     bool
+	}{
+		{
+			name: "OK",
+			mock: func() {
+				rows := sqlmock.NewRows([]string{
+					"scanning_id",
+					"repository_id",
+					"finding_id",
+					"engine",
+					"rule",
+					"severity",
+					"file_path",
+					"line",
+					"commit_hash",
+					"commit_author",
+					"fingerprint",
+					"occurrence",
+					"suppression_source",
+					"suppression_reason",
+					"baseline_version",
+					"triage_status",
+					"assignee",
+					"finding",
+					"created_at",
+				}).AddRow(
+					10,
+					3,
+					"abc",
+					"native",
+					"aws-key",
+					"high",
+					"config/app.yml",
+					3,
+					"0a1b2c3d",
+					"dev",
+					"5d41402a",
+					occurrence,
+					suppressionSource,
+					suppressionReason,
+					nil,
+					"confirmed",
+					assignee,
+					types.JSONText([]byte(`{"ID":"abc"}`)),
+					currentTime,
+				)
+				mock.ExpectQuery(regexp.QuoteMeta(queries.GetFindingList)).WithArgs(
+					10,
+					"high",
+					"",
+					"",
+					"",
+					"config/",
+					"",
+					"",
+					"",
+					"alice",
+					"true",
+					"all",
+					20,
+					20,
+				).WillReturnRows(rows)
+			},
+			requestBody: model.FindingListRequest{
+				ScanningId: 10,
+				Limit:      20,
+				Page:       2,
+				Severity:   "high",
+				FilePath:   "config/",
+				Assignee:   "alice",
+				Suppressed: "true",
+				Baselined:  "all",
+			},
+			want: []model.FindingListResponse{
+				{
+					ScanningId:        10,
+					RepositoryId:      3,
+					FindingId:         "abc",
+					Engine:            "native",
+					Rule:              "aws-key",
+					Severity:          "high",
+					FilePath:          "config/app.yml",
+					Line:              3,
+					CommitHash:        "0a1b2c3d",
+					CommitAuthor:      "dev",
+					Fingerprint:       "5d41402a",
+					Occurrence:        &occurrence,
+					SuppressionSource: &suppressionSource,
+					SuppressionReason: &suppressionReason,
+					TriageStatus:      "confirmed",
+					Assignee:          &assignee,
+					Finding:           types.JSONText([]byte(`{"ID":"abc"}`)),
+					CreatedAt:         currentTime,
+				},
+			},
+			wantErr: false,
+		},
+	}
+
+	for _, test := range tests {
+		test.mock()
+		got, err := repo.GetFindingList(context.Background(), test.requestBody)
+		if (err != nil) != test.wantErr {
+			t.Errorf("GetFindingList() error '%s'", err)
+			return
+		}
+
+		if err == nil {
+			assert.Equal(t, test.want, got)
+		}
+	}
+}
+
+func TestEditFindingTriage(t *testing.T) {
+	repo, db, mock := NewScanningMock()
+	defer func() {
+		db.Close()
+	}()
+
+	wayback := time.Date(1974, time.May, 19, 1, 2, 3, 4, time.UTC)
+	patch := monkey.Patch(time.Now, func() time.Time { return wayback })
+	defer patch.Unpatch()
+
+	currentTime, _ := uttime.NowWithTimezone(constants.DefaultTimezone)
+	assignee := "alice"
+	triagedBy := "bob"
+
+	tests := []struct {
+		name        string
+		mock        func()
+		requestBody model.TriageFindingRequest
+		want        model.FindingTriageResponse
+		wantErr     bool
+	}{
+		{
+			name: "OK",
+			mock: func() {
+				rows := sqlmock.NewRows([]string{
+					"repository_id",
+					"fingerprint",
+					"triage_status",
+					"assignee",
+					"triaged_by",
+					"triaged_at",
+				}).AddRow(3, "5d41402a", "false_positive", assignee, triagedBy, currentTime)
+				mock.ExpectQuery(regexp.QuoteMeta(queries.UpsertFindingTriage)).WithArgs(
+					3,
+					"5d41402a",
+					"aws-key",
+					"test/app.yml",
+					10,
+					"false_positive",
+					true,
+					"alice",
+					"bob",
+					currentTime,
+				).WillReturnRows(rows)
+			},
+			requestBody: model.TriageFindingRequest{
+				ScanningId:   10,
+				FindingId:    "abc",
+				RepositoryId: 3,
+				Fingerprint:  "5d41402a",
+				Rule:         "aws-key",
+				FilePath:     "test/app.yml",
+				Status:       "false_positive",
+				Assignee:     &assignee,
+				TriagedBy:    "bob",
+			},
+			want: model.FindingTriageResponse{
+				RepositoryId: 3,
+				Fingerprint:  "5d41402a",
+				TriageStatus: "false_positive",
+				Assignee:     &assignee,
+				TriagedBy:    &triagedBy,
+				TriagedAt:    &currentTime,
+			},
+			wantErr: false,
+		},
+		{
+			name: "Assignee is kept",
+			mock: func() {
+				mock.ExpectQuery(regexp.QuoteMeta(queries.UpsertFindingTriage)).WithArgs(
+					3,
+					"5d41402a",
+					"aws-key",
+					"test/app.yml",
+					10,
+					"confirmed",
+					false,
+					"",
+					"bob",
+					currentTime,
+				).WillReturnRows(sqlmock.NewRows([]string{
+					"repository_id",
+					"fingerprint",
+					"triage_status",
+					"assignee",
+					"triaged_by",
+					"triaged_at",
+				}).AddRow(3, "5d41402a", "confirmed", assignee, triagedBy, currentTime))
+			},
+			requestBody: model.TriageFindingRequest{
+				ScanningId:   10,
+				FindingId:    "abc",
+				RepositoryId: 3,
+				Fingerprint:  "5d41402a",
+				Rule:         "aws-key",
+				FilePath:     "test/app.yml",
+				Status:       "confirmed",
+				TriagedBy:    "bob",
+			},
+			want: model.FindingTriageResponse{
+				RepositoryId: 3,
+				Fingerprint:  "5d41402a",
+				TriageStatus: "confirmed",
+				Assignee:     &assignee,
+				TriagedBy:    &triagedBy,
+				TriagedAt:    &currentTime,
+			},
+			wantErr: false,
+		},
+	}
+
+	for _, test := range tests {
+		test.mock()
+		got, err := repo.EditFindingTriage(context.Background(), nil, test.requestBody)
+		if (err != nil) != test.wantErr {
+			t.Errorf("EditFindingTriage() error '%s'", err)
+			return
+		}
+
+		if err == nil {
+			assert.Equal(t, test.want, got)
+		}
+	}
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestAddBaseline(t *testing.T) {
+	repo, db, mock := NewScanningMock()
+	defer func() {
+		db.Close()
+	}()
+
+	wayback := time.Date(1974, time.May, 19, 1, 2, 3, 4, time.UTC)
+	patch := monkey.Patch(time.Now, func() time.Time { return wayback })
+	defer patch.Unpatch()
+
+	currentTime, _ := uttime.NowWithTimezone(constants.DefaultTimezone)
+	columns := []string{
+		"baseline_id",
+		"repository_id",
+		"version",
+		"scanning_id",
+		"fingerprint_count",
+		"is_active",
+		"created_by",
+		"created_at",
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(queries.InsertBaseline)).WithArgs(
+		3,
+		10,
+		"bob",
+		currentTime,
+	).WillReturnRows(sqlmock.NewRows(columns).AddRow(7, 3, 2, 10, 42, true, "bob", currentTime))
+
+	got, err := repo.AddBaseline(context.Background(), nil, model.AddBaselineRequest{
+		RepositoryId: 3,
+		ScanningId:   10,
+		CreatedBy:    "bob",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, model.BaselineResponse{
+		Id:               7,
+		RepositoryId:     3,
+		Version:          2,
+		ScanningId:       10,
+		FingerprintCount: 42,
+		IsActive:         true,
+		CreatedBy:        "bob",
+		CreatedAt:        currentTime,
+	}, got)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestActivateBaseline(t *testing.T) {
+	repo, db, mock := NewScanningMock()
+	defer func() {
+		db.Close()
+	}()
+
+	wayback := time.Date(1974, time.May, 19, 1, 2, 3, 4, time.UTC)
+	patch := monkey.Patch(time.Now, func() time.Time { return wayback })
+	defer patch.Unpatch()
+
+	currentTime, _ := uttime.NowWithTimezone(constants.DefaultTimezone)
+	columns := []string{
+		"baseline_id",
+		"repository_id",
+		"version",
+		"scanning_id",
+		"fingerprint_count",
+		"is_active",
+		"created_by",
+		"created_at",
+	}
+
+	tests := []struct {
+		name        string
+		mock        func()
+		requestBody model.ActivateBaselineRequest
+		want        *model.BaselineResponse
+		wantErr     bool
+	}{
+		{
+			name: "OK",
+			mock: func() {
+				mock.ExpectQuery(regexp.QuoteMeta(queries.UpdateActiveBaseline)).WithArgs(
+					3,
+					1,
+					"alice",
+					currentTime,
+				).WillReturnRows(sqlmock.NewRows(columns).AddRow(5, 3, 1, 8, 120, true, "bob", currentTime))
+			},
+			requestBody: model.ActivateBaselineRequest{RepositoryId: 3, Version: 1, ActivatedBy: "alice"},
+			want: &model.BaselineResponse{
+				Id:               5,
+				RepositoryId:     3,
+				Version:          1,
+				ScanningId:       8,
+				FingerprintCount: 120,
+				IsActive:         true,
+				CreatedBy:        "bob",
+				CreatedAt:        currentTime,
+			},
+			wantErr: false,
+		},
+		{
+			name: "Version not found",
+			mock: func() {
+				mock.ExpectQuery(regexp.QuoteMeta(queries.UpdateActiveBaseline)).WithArgs(
+					3,
+					9,
+					"alice",
+					currentTime,
+				).WillReturnRows(sqlmock.NewRows(columns))
+			},
+			requestBody: model.ActivateBaselineRequest{RepositoryId: 3, Version: 9, ActivatedBy: "alice"},
+			want:        nil,
+			wantErr:     false,
+		},
+	}
+
+	for _, test := range tests {
+		test.mock()
+		got, err := repo.ActivateBaseline(context.Background(), nil, test.requestBody)
+		if (err != nil) != test.wantErr {
+			t.Errorf("ActivateBaseline() error '%s'", err)
+			return
+		}
+
+		if err == nil {
+			assert.Equal(t, test.want, got)
+		}
+	}
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
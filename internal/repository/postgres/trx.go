@@ -1,6 +1,8 @@
 package postgres
 
 import (
+	"context"
+
 	"github.com/jmoiron/sqlx"
 
 	"repo-scanner/internal"
@@ -18,8 +20,8 @@ func NewTrxRepository(db *sqlx.DB) internal.ITrxRepository {
 	}
 }
 
-func (ox trx) Create() (trx *model.Trx, errx serror.SError) {
-	tx, err := ox.DB.Beginx()
+func (ox trx) Create(ctx context.Context) (trx *model.Trx, errx serror.SError) {
+	tx, err := ox.DB.BeginTxx(ctx, nil)
 	if err != nil {
 		errx = serror.NewFromErrorc(err, "Failed to create transaction")
 		return trx, errx
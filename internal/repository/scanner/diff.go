@@ -0,0 +1,160 @@
+package scanner
+
+import (
+	"strings"
+
+	"repo-scanner/internal/constants"
+	"repo-scanner/internal/model"
+	"repo-scanner/internal/utils/serror"
+
+	"github.com/grab/secret-scanner/scanner/signatures"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing/format/diff"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+// addedLines keeps line numbers added by a change per file path,
+// files which are created by the change are kept as a whole
+type addedLines struct {
+	Lines map[string]map[uint64]bool
+	Files map[string]bool
+}
+
+// Paths returns every file path which has added lines
+func (a addedLines) Paths() (res []string) {
+	res = []string{}
+	for path := range a.Lines {
+		res = append(res, path)
+	}
+	for path := range a.Files {
+		if _, ok := a.Lines[path]; !ok {
+			res = append(res, path)
+		}
+	}
+	return
+}
+
+// Filter keeps findings on added lines only, findings of whole file, e.g. filename signatures,
+// are kept if the file is created by the change
+func (a addedLines) Filter(findings []model.Finding) (res []model.Finding) {
+	res = []model.Finding{}
+	for _, finding := range findings {
+		path := strings.TrimPrefix(finding.FilePath, "/")
+		switch finding.Action {
+		case signatures.PartContent, constants.FindingActionEntropy:
+			if a.Lines[path][finding.Line] {
+				res = append(res, finding)
+			}
+		default:
+			if a.Files[path] {
+				res = append(res, finding)
+			}
+		}
+	}
+	return
+}
+
+// diffWorkspace finds merge base of base ref and HEAD of work tree, which is where the change
+// branched off, then collects lines added from there to HEAD
+func diffWorkspace(dir string, baseRef string) (base string, res *addedLines, errx serror.SError) {
+	repo, err := git.PlainOpen(dir)
+	if err != nil {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[scanner][diffWorkspace] while open %s", dir)
+		return
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[scanner][diffWorkspace] while resolve HEAD of %s", dir)
+		return
+	}
+	headCommit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[scanner][diffWorkspace] while get commit %s", head.Hash())
+		return
+	}
+
+	baseHash, err := resolveRef(repo, baseRef)
+	if err != nil {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[scanner][diffWorkspace] while resolve base ref %s", baseRef)
+		return
+	}
+	baseCommit, err := repo.CommitObject(*baseHash)
+	if err != nil {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[scanner][diffWorkspace] while get commit %s", baseHash)
+		return
+	}
+
+	bases, err := baseCommit.MergeBase(headCommit)
+	if err != nil || len(bases) == 0 {
+		errx = serror.Newf("Base ref %s and HEAD have no common ancestor", baseRef)
+		errx.AddCommentf("[scanner][diffWorkspace] while find merge base of %s and %s", baseHash, head.Hash())
+		return
+	}
+	base = bases[0].Hash.String()
+
+	var trees [2]*object.Tree
+	for idx, commit := range []*object.Commit{bases[0], headCommit} {
+		trees[idx], err = commit.Tree()
+		if err != nil {
+			errx = serror.NewFromError(err)
+			errx.AddCommentf("[scanner][diffWorkspace] while get tree of commit %s", commit.Hash)
+			return
+		}
+	}
+
+	changes, err := object.DiffTree(trees[0], trees[1])
+	if err != nil {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[scanner][diffWorkspace] while diff %s..%s", base, head.Hash())
+		return
+	}
+	patch, err := changes.Patch()
+	if err != nil {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[scanner][diffWorkspace] while make patch of %s..%s", base, head.Hash())
+		return
+	}
+
+	res = &addedLines{
+		Lines: map[string]map[uint64]bool{},
+		Files: map[string]bool{},
+	}
+	for _, filePatch := range patch.FilePatches() {
+		from, to := filePatch.Files()
+		if to == nil {
+			continue
+		}
+		if from == nil {
+			res.Files[to.Path()] = true
+		}
+
+		// Line numbers of HEAD version are counted by unchanged and added chunks
+		var line uint64
+		for _, chunk := range filePatch.Chunks() {
+			count := uint64(strings.Count(chunk.Content(), "\n"))
+			if !strings.HasSuffix(chunk.Content(), "\n") && chunk.Content() != "" {
+				count++
+			}
+
+			switch chunk.Type() {
+			case diff.Equal:
+				line += count
+			case diff.Add:
+				if res.Lines[to.Path()] == nil {
+					res.Lines[to.Path()] = map[uint64]bool{}
+				}
+				for idx := uint64(1); idx <= count; idx++ {
+					res.Lines[to.Path()][line+idx] = true
+				}
+				line += count
+			}
+		}
+	}
+	return
+}
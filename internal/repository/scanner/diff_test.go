@@ -0,0 +1,110 @@
+package scanner
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"repo-scanner/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+func TestDiffWorkspace(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir, err := ioutil.TempDir("", "reposcan-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	newLocalRepository(t, dir, map[string]string{
+		"app.yml":   "name: app\nport: 80\n",
+		"README.md": "app\n",
+	})
+	repo, err := git.PlainOpen(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := repo.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	commit := func(files map[string]string) {
+		for name, content := range files {
+			if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := tree.Add(name); err != nil {
+				t.Fatal(err)
+			}
+		}
+		_, err := tree.Commit("change", &git.CommitOptions{
+			Author: &object.Signature{Name: "reposcan", Email: "reposcan@localhost", When: time.Now()},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// feature branches off first commit, then main moves on
+	err = tree.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("feature"), Create: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	commit(map[string]string{
+		"app.yml": "name: app\npassword: hunter2\nport: 80\n",
+		".npmrc":  "token\n",
+	})
+	if err = tree.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("main")}); err != nil {
+		t.Fatal(err)
+	}
+	commit(map[string]string{"README.md": "app\nmore docs\n"})
+
+	ws, errx := checkoutWorkspace(context.Background(), model.ScanTarget{Url: "file://" + dir, Ref: "feature"})
+	if errx != nil {
+		t.Fatal(errx)
+	}
+	defer ws.Cleanup()
+
+	base, added, errx := diffWorkspace(ws.Dir, "main")
+	assert.Nil(t, errx)
+	assert.Equal(t, first.Hash().String(), base)
+	assert.Equal(t, map[string]map[uint64]bool{
+		"app.yml": {2: true},
+		".npmrc":  {1: true},
+	}, added.Lines)
+	assert.Equal(t, map[string]bool{".npmrc": true}, added.Files)
+	assert.ElementsMatch(t, []string{"app.yml", ".npmrc"}, added.Paths())
+
+	findings := added.Filter([]model.Finding{
+		{ID: "added line", FilePath: "app.yml", Line: 2, Action: "content"},
+		{ID: "old line", FilePath: "app.yml", Line: 1, Action: "content"},
+		{ID: "added file", FilePath: ".npmrc", Line: 1, Action: "filename"},
+		{ID: "modified file", FilePath: "app.yml", Line: 1, Action: "extension"},
+		{ID: "untouched file", FilePath: "README.md", Line: 2, Action: "content"},
+	})
+	var ids []string
+	for _, finding := range findings {
+		ids = append(ids, finding.ID)
+	}
+	assert.Equal(t, []string{"added line", "added file"}, ids)
+
+	_, _, errx = diffWorkspace(ws.Dir, "missing")
+	assert.NotNil(t, errx)
+}
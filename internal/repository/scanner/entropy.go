@@ -0,0 +1,146 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+
+	"repo-scanner/internal"
+	"repo-scanner/internal/constants"
+	"repo-scanner/internal/model"
+	"repo-scanner/internal/utils/serror"
+
+	"github.com/grab/secret-scanner/scanner/signatures"
+)
+
+const entropyScannerVersion = "v1.0.0"
+
+const (
+	base64Charset = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/=_-"
+	hexCharset    = "0123456789abcdefABCDEF"
+)
+
+type entropyScanner struct {
+	option model.EntropyOption
+	sigs   []signatures.Signature
+}
+
+// NewEntropyScanner creates engine which reports base64 and hex strings with high Shannon entropy,
+// such as random API tokens which have no fixed pattern to be matched by signatures
+func NewEntropyScanner(option model.EntropyOption) internal.IScanEngine {
+	return entropyScanner{
+		option: option,
+		sigs: []signatures.Signature{
+			newEntropySignature("base64", base64Charset, option.Base64Threshold, option.MinLength),
+			newEntropySignature("hex", hexCharset, option.HexThreshold, option.MinLength),
+		},
+	}
+}
+
+func (e entropyScanner) Info() model.ScanEngineInfo {
+	return model.ScanEngineInfo{
+		Name:    constants.ScanEngineEntropy,
+		Version: entropyScannerVersion,
+		Capabilities: []string{
+			constants.ScanEngineCapabilityWorktree,
+		},
+	}
+}
+
+func (e entropyScanner) Scan(ctx context.Context, target model.ScanTarget) (res []model.Finding, errx serror.SError) {
+	errx = walkWorktree(ctx, target, func(file signatures.MatchFile, isTestContext bool) {
+		if e.isSkippable(file) {
+			return
+		}
+		res = append(res, matchSignatures(target, file, isTestContext, e.sigs, func(sig signatures.Signature) string {
+			return sig.(entropySignature).name
+		})...)
+	})
+	if errx != nil {
+		errx.AddComments("[scanner][entropy][Scan] while walk work tree")
+		return
+	}
+	return
+}
+
+// isSkippable tells whether file has one of excluded extensions, e.g. lock files and minified assets
+func (e entropyScanner) isSkippable(file signatures.MatchFile) bool {
+	filename := strings.ToLower(file.Filename)
+	for _, ext := range e.option.SkipExtensions {
+		if ext == "" {
+			continue
+		}
+		if strings.HasSuffix(filename, "."+strings.TrimPrefix(strings.ToLower(ext), ".")) {
+			return true
+		}
+	}
+	return false
+}
+
+// entropySignature is grab compatible signature of strings made of charset with entropy above threshold
+type entropySignature struct {
+	name      string
+	charset   string
+	threshold float64
+	match     *regexp.Regexp
+}
+
+func newEntropySignature(charsetName string, charset string, threshold float64, minLength int) entropySignature {
+	return entropySignature{
+		name:      fmt.Sprintf("high-entropy-%s", charsetName),
+		charset:   charsetName,
+		threshold: threshold,
+		match:     regexp.MustCompile(fmt.Sprintf("[%s]{%d,}", regexp.QuoteMeta(charset), minLength)),
+	}
+}
+
+func (s entropySignature) Match(file signatures.MatchFile) (res []*signatures.MatchResult) {
+	for i, line := range strings.Split(file.ContentRaw, "\n") {
+		for _, word := range s.match.FindAllString(line, -1) {
+			if shannonEntropy(word) <= s.threshold {
+				continue
+			}
+			res = append(res, &signatures.MatchResult{
+				Filename:    file.Filename,
+				Path:        file.Path,
+				Extension:   file.Extension,
+				Line:        uint64(i + 1),
+				LineContent: word,
+			})
+		}
+	}
+	return
+}
+
+func (s entropySignature) Description() string {
+	return fmt.Sprintf("High entropy %s string", s.charset)
+}
+
+func (s entropySignature) Comment() string {
+	return fmt.Sprintf("Shannon entropy is above %v", s.threshold)
+}
+
+func (s entropySignature) Part() string {
+	return constants.FindingActionEntropy
+}
+
+// shannonEntropy calculates Shannon entropy of given string in bits per character
+func shannonEntropy(s string) (res float64) {
+	if s == "" {
+		return
+	}
+
+	freq := map[rune]float64{}
+	for _, c := range s {
+		freq[c]++
+	}
+
+	length := float64(len(s))
+	for _, count := range freq {
+		p := count / length
+		res -= p * math.Log2(p)
+	}
+	return
+}
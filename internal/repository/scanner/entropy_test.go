@@ -0,0 +1,74 @@
+package scanner
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"repo-scanner/internal/constants"
+	"repo-scanner/internal/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShannonEntropy(t *testing.T) {
+	assert.Equal(t, float64(0), shannonEntropy(""))
+	assert.Equal(t, float64(0), shannonEntropy("aaaaaaaa"))
+	assert.Equal(t, float64(1), shannonEntropy("abababab"))
+	assert.Equal(t, float64(4), shannonEntropy("0123456789abcdef"))
+}
+
+func TestEntropyScannerScan(t *testing.T) {
+	dir, err := ioutil.TempDir("", "reposcan-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"config/app.yml":    "name: my-awesome-application-name\ntoken: 9fK2xQ7pL3vN8rT1wY6zB4mC0dH5jS\n",
+		"config/deploy.env": "COMMIT=3f786850e387550fdab836ed7e6dc881de23001b\n",
+		"yarn.lock":         "integrity sha512-9fK2xQ7pL3vN8rT1wY6zB4mC0dH5jS\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	engine := NewEntropyScanner(model.EntropyOption{
+		Base64Threshold: constants.DefaultEntropyBase64Threshold,
+		HexThreshold:    constants.DefaultEntropyHexThreshold,
+		MinLength:       constants.DefaultEntropyMinLength,
+		SkipExtensions:  []string{"lock"},
+	})
+	got, errx := engine.Scan(context.Background(), model.ScanTarget{
+		Url:       "github.com/org/repo",
+		LocalPath: dir,
+		Branch:    "main",
+	})
+	assert.Nil(t, errx)
+
+	byRule := map[string]model.Finding{}
+	for _, f := range got {
+		byRule[f.Rule] = f
+	}
+	assert.Len(t, got, 2)
+
+	base64 := byRule["high-entropy-base64"]
+	assert.Equal(t, "config/app.yml", base64.FilePath)
+	assert.Equal(t, constants.FindingActionEntropy, base64.Action)
+	assert.Equal(t, uint64(2), base64.Line)
+	assert.Equal(t, "9fK2xQ7pL3vN8rT1wY6zB4mC0dH5jS", base64.LineContent)
+
+	hex := byRule["high-entropy-hex"]
+	assert.Equal(t, "config/deploy.env", hex.FilePath)
+	assert.Equal(t, constants.FindingActionEntropy, hex.Action)
+	assert.Equal(t, "3f786850e387550fdab836ed7e6dc881de23001b", hex.LineContent)
+}
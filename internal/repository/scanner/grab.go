@@ -1,17 +1,19 @@
 package scanner
 
 import (
-	"encoding/json"
+	"context"
 	"repo-scanner/internal"
+	"repo-scanner/internal/constants"
+	"repo-scanner/internal/model"
 	"repo-scanner/internal/utils/serror"
+	"repo-scanner/internal/utils/utgit"
 	"repo-scanner/internal/utils/utstring"
 	"strings"
 
 	log "github.com/sirupsen/logrus"
+	"gopkg.in/src-d/go-git.v4"
 
-	"github.com/grab/secret-scanner/scanner"
 	"github.com/grab/secret-scanner/scanner/gitprovider"
-	"github.com/grab/secret-scanner/scanner/options"
 	"github.com/grab/secret-scanner/scanner/session"
 )
 
@@ -21,7 +23,7 @@ type grabScanner struct {
 	bitbucket *gitprovider.BitbucketProvider
 }
 
-func NewGrabScanner(store internal.RepositoryStore) internal.IGrabScanner {
+func NewGrabScanner(store internal.RepositoryStore) internal.IScanEngine {
 	grabScanner := grabScanner{}
 	additionalParams := map[string]string{
 		gitprovider.BitbucketParamClientID:     utstring.Env(gitprovider.BitbucketParamClientID),
@@ -60,91 +62,153 @@ func NewGrabScanner(store internal.RepositoryStore) internal.IGrabScanner {
 	return grabScanner
 }
 
-func (g grabScanner) StartScanningSession(repo_url string) (res []byte, errx serror.SError) {
-	pathParts := strings.Split(repo_url, "/")
-
-	opt := options.Options{
-		BaseURL:          new(string),
-		CommitDepth:      new(int),
-		Debug:            new(bool),
-		EnvFilePath:      new(string),
-		GitProvider:      new(string),
-		Load:             new(string),
-		LocalPath:        new(string),
-		LogSecret:        new(bool),
-		Report:           new(string),
-		Repos:            new(string),
-		ScanTarget:       new(string),
-		Silent:           new(bool),
-		SkipTestContexts: new(bool),
-		State:            new(bool),
-		Threads:          new(int),
-		Token:            new(string),
-		UI:               new(bool),
-		UIHost:           new(string),
-		UIPort:           new(string),
+func (g grabScanner) Info() model.ScanEngineInfo {
+	return model.ScanEngineInfo{
+		Name:    constants.ScanEngineGrab,
+		Version: moduleVersion("github.com/grab/secret-scanner"),
+		Capabilities: []string{
+			constants.ScanEngineCapabilityRemote,
+			constants.ScanEngineCapabilitySignature,
+		},
 	}
-	*opt.CommitDepth = 500
-	*opt.Debug = false
-	*opt.LogSecret = true
-	*opt.Repos = strings.Join(pathParts[1:], "/")
-	*opt.Silent = true
-	*opt.SkipTestContexts = true
-	*opt.State = false
-
-	var gitProvider gitprovider.GitProvider
-	switch pathParts[0] {
+}
+
+func (g grabScanner) Scan(ctx context.Context, target model.ScanTarget) (res []model.Finding, errx serror.SError) {
+	pathParts := strings.Split(target.Url, "/")
+
+	req := grabSessionRequest{
+		Repos:            strings.Join(pathParts[1:], "/"),
+		CommitDepth:      500,
+		SkipTestContexts: !target.Settings.ScanTests,
+	}
+	if target.Settings.CommitDepth > 0 {
+		req.CommitDepth = target.Settings.CommitDepth
+	}
+	if target.Settings.Threads > 0 {
+		req.Threads = target.Settings.Threads
+	}
+
+	// Work tree checked out by registry is scanned as local repository, which is the only way
+	// to resume from a checkpoint on incremental scan and to scan other ref than default branch
+	isLocalScan := utgit.IsDirectClone(target.Url) || target.SinceCommit != "" || target.Ref != ""
+
+	if isLocalScan {
+		if _, err := git.PlainOpen(target.LocalPath); err != nil {
+			log.Warnf("Skip grab scanning of %s, it is not a git repository", target.Url)
+			return
+		}
+		req.LocalPath = target.LocalPath
+		req.Repos = ""
+		req.SinceCommit = target.SinceCommit
+	} else {
+		req.GitProvider, errx = g.providerName(pathParts[0])
+		if errx != nil {
+			errx.AddCommentf("[scanner][grab][Scan] while pick git provider of %s", target.Url)
+			return
+		}
+	}
+
+	// Session runs in a child process of its own, so sessions of several scannings run at once,
+	// it is killed once scanning is cancelled or timed out and returns only after the process exits
+	// so that work tree is not removed under it
+	var sess grabSessionResult
+	sess, errx = startGrabSession(ctx, req)
+	if errx != nil {
+		errx.AddCommentf("[scanner][grab][Scan] while scan %s", target.Url)
+		return
+	}
+
+	// Return result
+	if sess.Status != session.StatusFinished {
+		errx = serror.New("Scanning session is not finished")
+		errx.AddCommentf("[scanner][grab][Scan] session status[%v]", sess.Status)
+		return
+	}
+
+	res = make([]model.Finding, 0, len(sess.Findings))
+	for _, f := range sess.Findings {
+		res = append(res, model.Finding{
+			ID:              f.ID,
+			Engine:          constants.ScanEngineGrab,
+			Rule:            utstring.Slug(f.Description),
+			FilePath:        f.FilePath,
+			Action:          f.Action,
+			Description:     f.Description,
+			Comment:         f.Comment,
+			RepositoryOwner: f.RepositoryOwner,
+			RepositoryName:  f.RepositoryName,
+			CommitHash:      f.CommitHash,
+			CommitMessage:   f.CommitMessage,
+			CommitAuthor:    f.CommitAuthor,
+			FileURL:         f.FileURL,
+			Line:            f.Line,
+			LineContent:     f.LineContent,
+			CommitURL:       f.CommitURL,
+			RepositoryURL:   f.RepositoryURL,
+			IsTestContext:   f.IsTestContext,
+		})
+	}
+
+	// grab/secret-scanner has no browsable url for repository scanned locally
+	if isLocalScan {
+		for idx := range res {
+			res[idx].RepositoryURL = repositoryURL(target)
+			res[idx].FileURL = fileURL(target, res[idx].FilePath)
+			res[idx].CommitURL = commitURL(target, res[idx].CommitHash)
+		}
+	}
+	return
+}
+
+// providerName returns git provider of grab/secret-scanner hosting repository of given host
+func (g grabScanner) providerName(host string) (res string, errx serror.SError) {
+	switch host {
 	case "github.com":
 		if g.github == nil {
-			res = []byte(`{"reason":"Github is not available for now"}`)
 			errx = serror.New("Github is not available for now")
-			errx.AddCommentf("[repository][StartScanningSession] Github is not available for now")
+			errx.AddCommentf("[scanner][grab][providerName] Github is not available for now")
 			return
 		}
-		*opt.GitProvider = "github"
-		*opt.BaseURL = utstring.Env(gitprovider.GithubParamBaseURL)
-		*opt.Token = utstring.Env(gitprovider.GithubParamToken)
-		gitProvider = g.github
+		res = "github"
 	case "gitlab.com":
 		if g.gitlab == nil {
-			res = []byte(`{"reason":"Gitlab is not available for now"}`)
 			errx = serror.New("Gitlab is not available for now")
-			errx.AddCommentf("[repository][StartScanningSession] Gitlab is not available for now")
+			errx.AddCommentf("[scanner][grab][providerName] Gitlab is not available for now")
 			return
 		}
-		*opt.GitProvider = "gitlab"
-		*opt.BaseURL = utstring.Env(gitprovider.GitlabParamBaseURL)
-		*opt.Token = utstring.Env(gitprovider.GitlabParamToken)
-		gitProvider = g.gitlab
+		res = "gitlab"
 	case "bitbucket.org":
-		if g.gitlab == nil {
-			res = []byte(`{"reason":"Bitbucket is not available for now"}`)
+		if g.bitbucket == nil {
 			errx = serror.New("Bitbucket is not available for now")
-			errx.AddCommentf("[repository][StartScanningSession] Bitbucket is not available for now")
+			errx.AddCommentf("[scanner][grab][providerName] Bitbucket is not available for now")
 			return
 		}
-		*opt.GitProvider = "bitbucket"
-		*opt.BaseURL = utstring.Env(gitprovider.BitbucketParamBaseURL)
-		gitProvider = g.bitbucket
+		res = "bitbucket"
+	default:
+		errx = serror.Newf("Unsupported git provider %s", host)
+		errx.AddCommentf("[scanner][grab][providerName] unsupported git provider")
 	}
+	return
+}
 
-	// Initialize new scan session
-	sess := &session.Session{}
-	sess.Initialize(opt)
-
-	// Scan
-	scanner.Scan(sess, gitProvider)
-
-	// Return result
-	if sess.Stats.Status == session.StatusFinished {
-		sessionJSON, err := json.Marshal(sess.Findings)
-		if err != nil {
-			res = []byte(`{"reason":"Marshal struct failed"}`)
-			errx = serror.NewFromError(err)
-			errx.AddCommentf("[repository][StartScanningSession] while marshal struct")
-			return
+// provider returns initialized git provider of given name along with its base url and token
+func (g grabScanner) provider(name string) (res gitprovider.GitProvider, baseURL string, token string) {
+	switch name {
+	case "github":
+		if g.github != nil {
+			res = g.github
+		}
+		return res, utstring.Env(gitprovider.GithubParamBaseURL), utstring.Env(gitprovider.GithubParamToken)
+	case "gitlab":
+		if g.gitlab != nil {
+			res = g.gitlab
+		}
+		return res, utstring.Env(gitprovider.GitlabParamBaseURL), utstring.Env(gitprovider.GitlabParamToken)
+	case "bitbucket":
+		if g.bitbucket != nil {
+			res = g.bitbucket
 		}
-		return sessionJSON, nil
+		return res, utstring.Env(gitprovider.BitbucketParamBaseURL), ""
 	}
 	return
 }
@@ -0,0 +1,202 @@
+package scanner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"repo-scanner/internal"
+	"repo-scanner/internal/constants"
+	"repo-scanner/internal/utils/serror"
+
+	"github.com/grab/secret-scanner/scanner"
+	"github.com/grab/secret-scanner/scanner/findings"
+	"github.com/grab/secret-scanner/scanner/gitprovider"
+	"github.com/grab/secret-scanner/scanner/options"
+	"github.com/grab/secret-scanner/scanner/session"
+	"github.com/grab/secret-scanner/scanner/state"
+)
+
+// grabSessionOutputLimit is how much of output of a failed session is kept in its error
+const grabSessionOutputLimit = 1024
+
+type (
+	// grabSessionRequest is written to stdin of child process running a grab session,
+	// credentials of git provider are taken from environment of the child process
+	grabSessionRequest struct {
+		GitProvider      string `json:"git_provider"`
+		Repos            string `json:"repos"`
+		LocalPath        string `json:"local_path"`
+		CommitDepth      int    `json:"commit_depth"`
+		Threads          int    `json:"threads"`
+		SkipTestContexts bool   `json:"skip_test_contexts"`
+		SinceCommit      string `json:"since_commit"`
+	}
+
+	// grabSessionResult is written by child process to result file once its grab session ends
+	grabSessionResult struct {
+		Status   string              `json:"status"`
+		Findings []*findings.Finding `json:"findings"`
+	}
+)
+
+// grabSessionCommand returns child process of this executable which runs a grab session and writes its result to resultPath,
+// the process is killed once ctx is done
+var grabSessionCommand = func(ctx context.Context, resultPath string) (*exec.Cmd, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	return exec.CommandContext(ctx, executable, constants.GrabSessionCommand, resultPath), nil
+}
+
+// startGrabSession runs grab session in a child process and waits until it exits.
+// grab/secret-scanner changes working directory and cannot be interrupted, a process of its own
+// lets sessions of several scannings run at once and a cancelled or timed out one be killed
+func startGrabSession(ctx context.Context, req grabSessionRequest) (res grabSessionResult, errx serror.SError) {
+	// Checkpoint store and clone of remote repository are kept in a directory of the session, removed once it exits
+	dir, err := ioutil.TempDir("", "reposcan-grab")
+	if err != nil {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[scanner][startGrabSession] while create session directory")
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[scanner][startGrabSession] while encode request")
+		return
+	}
+
+	resultPath := filepath.Join(dir, "result.json")
+	cmd, err := grabSessionCommand(ctx, resultPath)
+	if err != nil {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[scanner][startGrabSession] while prepare session process")
+		return
+	}
+	var output bytes.Buffer
+	cmd.Stdin = bytes.NewReader(body)
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	cmd.Env = append(os.Environ(), "HOME="+dir, "TMPDIR="+dir)
+
+	err = cmd.Run()
+	if ctx.Err() != nil {
+		errx = serror.NewFromError(ctx.Err())
+		errx.AddCommentf("[scanner][startGrabSession] while run session, its process is killed")
+		return
+	}
+	if err != nil {
+		errx = serror.Newf("grab session failed: %v %s", err, tail(strings.TrimSpace(output.String()), grabSessionOutputLimit))
+		errx.AddCommentf("[scanner][startGrabSession] while run session")
+		return
+	}
+
+	raw, err := ioutil.ReadFile(resultPath)
+	if err != nil {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[scanner][startGrabSession] while read session result")
+		return
+	}
+	if err = json.Unmarshal(raw, &res); err != nil {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[scanner][startGrabSession] while decode session result")
+		return
+	}
+	return
+}
+
+// RunGrabSession runs grab session requested on in by parent process and writes its result to resultPath,
+// it is run by child process of the same executable with GrabSessionCommand argument
+func RunGrabSession(in io.Reader, resultPath string) (errx serror.SError) {
+	var req grabSessionRequest
+	if err := json.NewDecoder(in).Decode(&req); err != nil {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[scanner][RunGrabSession] while decode request")
+		return
+	}
+
+	opt := options.Options{
+		BaseURL:          new(string),
+		CommitDepth:      &req.CommitDepth,
+		Debug:            new(bool),
+		EnvFilePath:      new(string),
+		GitProvider:      &req.GitProvider,
+		Load:             new(string),
+		LocalPath:        &req.LocalPath,
+		LogSecret:        new(bool),
+		Report:           new(string),
+		Repos:            &req.Repos,
+		ScanTarget:       new(string),
+		Silent:           new(bool),
+		SkipTestContexts: &req.SkipTestContexts,
+		State:            new(bool),
+		Threads:          &req.Threads,
+		Token:            new(string),
+		UI:               new(bool),
+		UIHost:           new(string),
+		UIPort:           new(string),
+	}
+	*opt.LogSecret = true // raw secrets are hashed and redacted by scanning usecase before they are stored
+	*opt.Silent = true
+	*opt.State = req.LocalPath != "" && req.SinceCommit != ""
+
+	var gitProvider gitprovider.GitProvider
+	if req.LocalPath == "" {
+		gitProvider, *opt.BaseURL, *opt.Token = NewGrabScanner(internal.RepositoryStore{}).(grabScanner).provider(req.GitProvider)
+		if gitProvider == nil {
+			errx = serror.Newf("Git provider %s is not available for now", req.GitProvider)
+			errx.AddCommentf("[scanner][RunGrabSession] while initialize git provider")
+			return
+		}
+	}
+
+	// Initialize new scan session
+	sess := &session.Session{}
+	sess.Initialize(opt)
+
+	// Only commits after checkpoint are scanned, LocalGitScan looks it up by this key
+	if *opt.State {
+		localID := fmt.Sprintf("%s/%s", strings.Trim(*opt.LocalPath, "/"), strings.Trim(*opt.ScanTarget, "/"))
+		err := sess.StateStore.Save(state.Create(*opt.GitProvider, localID, req.SinceCommit, time.Now().String()))
+		if err != nil {
+			errx = serror.NewFromError(err)
+			errx.AddCommentf("[scanner][RunGrabSession] while save checkpoint %s", req.SinceCommit)
+			return
+		}
+	}
+
+	scanner.Scan(sess, gitProvider)
+
+	raw, err := json.Marshal(grabSessionResult{Status: sess.Stats.Status, Findings: sess.Findings})
+	if err != nil {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[scanner][RunGrabSession] while encode result")
+		return
+	}
+	if err = ioutil.WriteFile(resultPath, raw, 0600); err != nil {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[scanner][RunGrabSession] while write result")
+		return
+	}
+	return
+}
+
+// tail returns last n bytes of s
+func tail(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[len(s)-n:]
+}
@@ -0,0 +1,218 @@
+package scanner
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"repo-scanner/internal/constants"
+	"repo-scanner/internal/model"
+	"repo-scanner/internal/utils/serror"
+)
+
+// ignoreFile is parsed .reposcanignore at root of work tree, every entry keeps reason findings are suppressed by.
+// Fingerprints are given to be matched by caller since findings are fingerprinted after scanning
+type ignoreFile struct {
+	paths        []ignorePath
+	rules        map[string]string
+	fingerprints map[string]string
+}
+
+type ignorePath struct {
+	match  *regexp.Regexp
+	reason string
+}
+
+// loadIgnoreFile reads .reposcanignore at root of dir, nothing is ignored if there is no such file
+func loadIgnoreFile(dir string) (res ignoreFile, errx serror.SError) {
+	content, err := ioutil.ReadFile(filepath.Join(dir, constants.IgnoreFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return
+		}
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[scanner][loadIgnoreFile] while read %s", constants.IgnoreFileName)
+		return
+	}
+	return parseIgnoreFile(string(content)), nil
+}
+
+// parseIgnoreFile parses one entry per line, either a path glob, "rule:<id>" or "fingerprint:<hash>",
+// followed by an optional "# reason". Blank lines and lines starting with "#" are skipped
+func parseIgnoreFile(content string) (res ignoreFile) {
+	res.rules = map[string]string{}
+	res.fingerprints = map[string]string{}
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		entry, reason := line, ""
+		if idx := strings.Index(line, " #"); idx >= 0 {
+			entry, reason = strings.TrimSpace(line[:idx]), strings.TrimSpace(line[idx+2:])
+		}
+		if reason == "" {
+			reason = fmt.Sprintf("%s line %d: %s", constants.IgnoreFileName, lineNo, entry)
+		}
+
+		switch {
+		case strings.HasPrefix(entry, "rule:"):
+			res.rules[strings.TrimSpace(strings.TrimPrefix(entry, "rule:"))] = reason
+		case strings.HasPrefix(entry, "fingerprint:"):
+			res.fingerprints[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(entry, "fingerprint:")))] = reason
+		default:
+			res.paths = append(res.paths, ignorePath{
+				match:  globRegexp(strings.TrimPrefix(entry, "path:")),
+				reason: reason,
+			})
+		}
+	}
+	return
+}
+
+// Reason returns why finding is suppressed by path or rule entries, empty if it is not
+func (f ignoreFile) Reason(finding model.Finding) string {
+	if reason, ok := f.rules[finding.Rule]; ok {
+		return reason
+	}
+	for _, path := range f.paths {
+		if path.match.MatchString(finding.FilePath) {
+			return path.reason
+		}
+	}
+	return ""
+}
+
+// Suppress marks findings suppressed by path, rule or fingerprint entries, findings already suppressed are kept as they are.
+// Inline comments are not looked up since work tree may be removed already
+func (f ignoreFile) Suppress(findings []model.Finding) {
+	for idx := range findings {
+		finding := &findings[idx]
+		if finding.Suppression != nil {
+			continue
+		}
+		if reason := f.Reason(*finding); reason != "" {
+			finding.Suppression = &model.FindingSuppression{Source: constants.SuppressionSourceIgnoreFile, Reason: reason}
+		} else if reason, ok := f.fingerprints[finding.Fingerprint]; ok {
+			finding.Suppression = &model.FindingSuppression{Source: constants.SuppressionSourceIgnoreFile, Reason: reason}
+		}
+	}
+}
+
+// globRegexp converts gitignore like glob to regexp matching slash separated paths.
+// "*" and "?" never match "/", "**" matches any number of directories, pattern ending with "/" matches everything under it.
+// Pattern without "/" is matched against any path segment, otherwise it is anchored at root
+func globRegexp(glob string) *regexp.Regexp {
+	glob = strings.TrimSpace(glob)
+	anchored := strings.Contains(strings.TrimSuffix(glob, "/"), "/")
+	glob = strings.TrimPrefix(glob, "/")
+
+	var sb strings.Builder
+	sb.WriteString("^")
+	if !anchored {
+		sb.WriteString("(.*/)?")
+	}
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; c {
+		case '*':
+			if i+1 < len(glob) && glob[i+1] == '*' {
+				i++
+				if i+1 < len(glob) && glob[i+1] == '/' {
+					i++
+					sb.WriteString("(.*/)?")
+				} else {
+					sb.WriteString(".*")
+				}
+			} else {
+				sb.WriteString("[^/]*")
+			}
+		case '?':
+			sb.WriteString("[^/]")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	if strings.HasSuffix(glob, "/") {
+		sb.WriteString(".*")
+	} else {
+		sb.WriteString("(/.*)?")
+	}
+	sb.WriteString("$")
+	return regexp.MustCompile(sb.String())
+}
+
+// inlineIgnoreReason tells whether given line has reposcan:ignore comment and returns its reason,
+// which is text following the marker, e.g. "// reposcan:ignore test fixture"
+func inlineIgnoreReason(line string) (string, bool) {
+	idx := strings.Index(line, constants.IgnoreInlineMarker)
+	if idx < 0 {
+		return "", false
+	}
+
+	reason := line[idx+len(constants.IgnoreInlineMarker):]
+	for _, closer := range []string{"*/", "-->", "#}", "%>"} {
+		if end := strings.Index(reason, closer); end >= 0 {
+			reason = reason[:end]
+		}
+	}
+	reason = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(reason), ":-"))
+	if reason == "" {
+		reason = fmt.Sprintf("%s comment", constants.IgnoreInlineMarker)
+	}
+	return reason, true
+}
+
+// suppressFindings marks findings suppressed by ignore file or inline comment on their line of work tree in dir,
+// findings are kept so that suppressions can be reviewed
+func suppressFindings(dir string, ignore ignoreFile, findings []model.Finding) {
+	lines := map[string][]string{}
+	for idx := range findings {
+		finding := &findings[idx]
+		if finding.Suppression != nil {
+			continue
+		}
+		if reason := ignore.Reason(*finding); reason != "" {
+			finding.Suppression = &model.FindingSuppression{Source: constants.SuppressionSourceIgnoreFile, Reason: reason}
+			continue
+		}
+
+		line := finding.LineContent
+		if finding.Line > 0 {
+			fileLines, ok := lines[finding.FilePath]
+			if !ok {
+				fileLines = readWorktreeLines(dir, finding.FilePath)
+				lines[finding.FilePath] = fileLines
+			}
+			if int(finding.Line) <= len(fileLines) {
+				line = fileLines[finding.Line-1]
+			}
+		}
+		if reason, ok := inlineIgnoreReason(line); ok {
+			finding.Suppression = &model.FindingSuppression{Source: constants.SuppressionSourceInline, Reason: reason}
+		}
+	}
+}
+
+// readWorktreeLines returns lines of file at subPath of dir, nil if it cannot be read or is outside of dir
+func readWorktreeLines(dir string, subPath string) []string {
+	path := filepath.Join(dir, filepath.FromSlash(subPath))
+	if rel, err := filepath.Rel(dir, path); err != nil || strings.HasPrefix(rel, "..") {
+		return nil
+	}
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() || info.Size() > maxWorktreeFileSize {
+		return nil
+	}
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	return strings.Split(string(content), "\n")
+}
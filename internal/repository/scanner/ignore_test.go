@@ -0,0 +1,143 @@
+package scanner
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"repo-scanner/internal/constants"
+	"repo-scanner/internal/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGlobRegexp(t *testing.T) {
+	tests := []struct {
+		glob  string
+		path  string
+		match bool
+	}{
+		{"*.pem", "key.pem", true},
+		{"*.pem", "certs/dev/key.pem", true},
+		{"*.pem", "key.pem.txt", false},
+		{"fixtures/", "test/fixtures/aws.json", true},
+		{"fixtures/", "fixtures.go", false},
+		{"test/fixtures/**", "test/fixtures/aws/key.json", true},
+		{"test/fixtures/**", "src/test/fixtures/key.json", false},
+		{"/config/*.yml", "config/app.yml", true},
+		{"/config/*.yml", "config/dev/app.yml", false},
+		{"**/testdata/*.json", "testdata/a.json", true},
+		{"**/testdata/*.json", "pkg/x/testdata/a.json", true},
+		{"docs/example?.md", "docs/example1.md", true},
+		{"docs", "docs/example1.md", true},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.match, globRegexp(test.glob).MatchString(test.path), "%s ~ %s", test.glob, test.path)
+	}
+}
+
+func TestParseIgnoreFile(t *testing.T) {
+	ignore := parseIgnoreFile(`
+# fixtures of tests
+test/fixtures/**  # fake keys of tests
+rule:high-entropy-hex
+fingerprint:5D41402A # rotated already
+`)
+
+	assert.Equal(t, "fake keys of tests", ignore.Reason(model.Finding{Rule: "aws-key", FilePath: "test/fixtures/aws.json"}))
+	assert.Equal(t, ".reposcanignore line 4: rule:high-entropy-hex", ignore.Reason(model.Finding{Rule: "high-entropy-hex", FilePath: "main.go"}))
+	assert.Equal(t, "", ignore.Reason(model.Finding{Rule: "aws-key", FilePath: "main.go"}))
+	assert.Equal(t, map[string]string{"5d41402a": "rotated already"}, ignore.fingerprints)
+}
+
+func TestInlineIgnoreReason(t *testing.T) {
+	tests := []struct {
+		line   string
+		reason string
+		ok     bool
+	}{
+		{`key := "AKIA0000" // reposcan:ignore fake key of tests`, "fake key of tests", true},
+		{`key: AKIA0000 # reposcan:ignore`, "reposcan:ignore comment", true},
+		{`<key>AKIA0000</key> <!-- reposcan:ignore: sample -->`, "sample", true},
+		{`key = "AKIA0000" /* reposcan:ignore - rotated */`, "rotated", true},
+		{`key := "AKIA0000"`, "", false},
+	}
+	for _, test := range tests {
+		reason, ok := inlineIgnoreReason(test.line)
+		assert.Equal(t, test.ok, ok, test.line)
+		assert.Equal(t, test.reason, reason, test.line)
+	}
+}
+
+func TestSuppressFindings(t *testing.T) {
+	dir, err := ioutil.TempDir("", "reposcan-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		constants.IgnoreFileName: "test/fixtures/ # fixtures\n",
+		"config/app.yml":         "name: app\ntoken: 9fK2xQ7pL3vN8rT1wY6zB4mC0dH5jS # reposcan:ignore sample token\n",
+		"config/prod.yml":        "token: 9fK2xQ7pL3vN8rT1wY6zB4mC0dH5jS\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ignore, errx := loadIgnoreFile(dir)
+	assert.Nil(t, errx)
+
+	findings := []model.Finding{
+		{Rule: "high-entropy-base64", FilePath: "config/app.yml", Line: 2, LineContent: "9fK2xQ7pL3vN8rT1wY6zB4mC0dH5jS"},
+		{Rule: "high-entropy-base64", FilePath: "config/prod.yml", Line: 1, LineContent: "9fK2xQ7pL3vN8rT1wY6zB4mC0dH5jS"},
+		{Rule: "aws-key", FilePath: "test/fixtures/aws.json", Line: 1},
+		{Rule: "aws-key", FilePath: "../outside.txt", Line: 1},
+	}
+	suppressFindings(dir, ignore, findings)
+
+	assert.Equal(t, &model.FindingSuppression{Source: constants.SuppressionSourceInline, Reason: "sample token"}, findings[0].Suppression)
+	assert.Nil(t, findings[1].Suppression)
+	assert.Equal(t, &model.FindingSuppression{Source: constants.SuppressionSourceIgnoreFile, Reason: "fixtures"}, findings[2].Suppression)
+	assert.Nil(t, findings[3].Suppression)
+}
+
+func TestIgnoreFileSuppress(t *testing.T) {
+	ignore := parseIgnoreFile("test/fixtures/ # fixtures\nrule:npm-token # rotated\nfingerprint:ABC123 # revoked\n")
+
+	inline := &model.FindingSuppression{Source: constants.SuppressionSourceInline, Reason: "sample token"}
+	findings := []model.Finding{
+		{Rule: "aws-key", FilePath: "test/fixtures/aws.json"},
+		{Rule: "npm-token", FilePath: ".npmrc"},
+		{Rule: "password", FilePath: "db.yml", Fingerprint: "abc123"},
+		{Rule: "password", FilePath: "app.yml"},
+		{Rule: "npm-token", FilePath: "app.yml", Suppression: inline},
+	}
+	ignore.Suppress(findings)
+
+	assert.Equal(t, &model.FindingSuppression{Source: constants.SuppressionSourceIgnoreFile, Reason: "fixtures"}, findings[0].Suppression)
+	assert.Equal(t, &model.FindingSuppression{Source: constants.SuppressionSourceIgnoreFile, Reason: "rotated"}, findings[1].Suppression)
+	assert.Equal(t, &model.FindingSuppression{Source: constants.SuppressionSourceIgnoreFile, Reason: "revoked"}, findings[2].Suppression)
+	assert.Nil(t, findings[3].Suppression)
+	assert.Equal(t, inline, findings[4].Suppression)
+}
+
+func TestLoadIgnoreFileMissing(t *testing.T) {
+	dir, err := ioutil.TempDir("", "reposcan-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ignore, errx := loadIgnoreFile(dir)
+	assert.Nil(t, errx)
+	assert.Equal(t, "", ignore.Reason(model.Finding{Rule: "aws-key", FilePath: "main.go"}))
+	assert.Empty(t, ignore.fingerprints)
+}
@@ -0,0 +1,120 @@
+package scanner
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"repo-scanner/internal"
+	"repo-scanner/internal/constants"
+	"repo-scanner/internal/model"
+	"repo-scanner/internal/utils/serror"
+
+	"github.com/grab/secret-scanner/scanner/signatures"
+	log "github.com/sirupsen/logrus"
+)
+
+const nativeScannerVersion = "v1.0.0"
+
+type nativeScanner struct {
+	ruleRepository internal.IRuleRepository
+}
+
+// NewNativeScanner creates engine which matches work tree with detection rules kept in reposcan.rules
+func NewNativeScanner(store internal.RepositoryStore) internal.IScanEngine {
+	return nativeScanner{
+		ruleRepository: store.RuleRepo,
+	}
+}
+
+func (n nativeScanner) Info() model.ScanEngineInfo {
+	return model.ScanEngineInfo{
+		Name:    constants.ScanEngineNative,
+		Version: nativeScannerVersion,
+		Capabilities: []string{
+			constants.ScanEngineCapabilityWorktree,
+			constants.ScanEngineCapabilityCustomRules,
+		},
+	}
+}
+
+func (n nativeScanner) Scan(ctx context.Context, target model.ScanTarget) (res []model.Finding, errx serror.SError) {
+	var rules []model.RuleListResponse
+	rules, errx = n.ruleRepository.GetActiveRules(ctx)
+	if errx != nil {
+		errx.AddComments("[scanner][native][Scan] while get active rules")
+		return
+	}
+
+	sigs := []signatures.Signature{}
+	for _, rule := range rules {
+		match, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			log.Warnf("Skip rule id[%v] name[%v], invalid pattern: %v", rule.Id, rule.Name, err)
+			continue
+		}
+		sigs = append(sigs, ruleSignature{rule: rule, match: match})
+	}
+	if len(sigs) == 0 {
+		return
+	}
+
+	errx = walkWorktree(ctx, target, func(file signatures.MatchFile, isTestContext bool) {
+		res = append(res, matchSignatures(target, file, isTestContext, sigs, func(sig signatures.Signature) string {
+			return sig.(ruleSignature).rule.Name
+		})...)
+	})
+	if errx != nil {
+		errx.AddComments("[scanner][native][Scan] while walk work tree")
+		return
+	}
+	return
+}
+
+// ruleSignature is grab compatible signature of a detection rule
+type ruleSignature struct {
+	rule  model.RuleListResponse
+	match *regexp.Regexp
+}
+
+func (s ruleSignature) Match(file signatures.MatchFile) (res []*signatures.MatchResult) {
+	var haystack string
+	switch s.rule.Part {
+	case signatures.PartPath:
+		haystack = file.Path
+	case signatures.PartFilename:
+		haystack = file.Filename
+	case signatures.PartExtension:
+		haystack = file.Extension
+	case signatures.PartContent:
+		haystack = file.ContentRaw
+	default:
+		return nil
+	}
+
+	for _, loc := range s.match.FindAllStringIndex(haystack, -1) {
+		result := &signatures.MatchResult{
+			Filename:  file.Filename,
+			Path:      file.Path,
+			Extension: file.Extension,
+		}
+		if s.rule.Part == signatures.PartContent {
+			result.Line = uint64(strings.Count(haystack[:loc[0]], "\n") + 1)
+			result.LineContent = haystack[loc[0]:loc[1]]
+		}
+		res = append(res, result)
+	}
+	return
+}
+
+func (s ruleSignature) Description() string {
+	return s.rule.Description
+}
+
+func (s ruleSignature) Comment() string {
+	return s.rule.Comment
+}
+
+func (s ruleSignature) Part() string {
+	return s.rule.Part
+}
@@ -0,0 +1,187 @@
+package scanner
+
+import (
+	"context"
+	"runtime/debug"
+
+	"repo-scanner/internal"
+	"repo-scanner/internal/constants"
+	"repo-scanner/internal/model"
+	"repo-scanner/internal/utils/serror"
+	"repo-scanner/internal/utils/utgit"
+	"repo-scanner/internal/utils/utstring"
+
+	"github.com/grab/secret-scanner/scanner/signatures"
+	log "github.com/sirupsen/logrus"
+)
+
+type scanEngineRegistry struct {
+	engines []internal.IScanEngine
+}
+
+// NewScanEngineRegistry registers given engines in order, only engines listed in enabled are registered.
+// Every given engine is registered if enabled is empty.
+func NewScanEngineRegistry(enabled []string, engines ...internal.IScanEngine) (internal.IGrabScanner, serror.SError) {
+	registry := &scanEngineRegistry{}
+
+	registered := map[string]bool{}
+	for _, engine := range engines {
+		info := engine.Info()
+		if registered[info.Name] {
+			errx := serror.Newf("Scan engine %s is registered twice", info.Name)
+			errx.AddCommentf("[scanner][NewScanEngineRegistry] while register engine %s", info.Name)
+			return nil, errx
+		}
+		if len(enabled) > 0 && !utstring.ArrContains(enabled, info.Name) {
+			log.Infof("Scan engine %s@%s is disabled", info.Name, info.Version)
+			continue
+		}
+
+		registered[info.Name] = true
+		registry.engines = append(registry.engines, engine)
+		log.Infof("Scan engine %s@%s is registered", info.Name, info.Version)
+	}
+
+	if len(registry.engines) == 0 {
+		errx := serror.New("No scan engine is registered")
+		errx.AddCommentf("[scanner][NewScanEngineRegistry] enabled engines %v", enabled)
+		return nil, errx
+	}
+	return registry, nil
+}
+
+func (r scanEngineRegistry) GetScanEngines() (res []model.ScanEngineInfo) {
+	for _, engine := range r.engines {
+		res = append(res, engine.Info())
+	}
+	return
+}
+
+func (r scanEngineRegistry) StartScanningSession(ctx context.Context, target model.ScanTarget) (res model.ScanResult, errx serror.SError) {
+	res.Engines = []model.ScanEngineInfo{}
+	res.Findings = []model.Finding{}
+
+	// Check out work tree once for every engine which needs it,
+	// local and generic git repository is always checked out since no engine can fetch it from git provider,
+	// so is repository to be scanned incrementally, at given ref or by diff since history is needed
+	var diffLines *addedLines
+	if target.LocalPath == "" && (utgit.IsDirectClone(target.Url) || target.SinceCommit != "" || target.Ref != "" || r.needWorktree()) {
+		var ws workspace
+		ws, errx = checkoutWorkspace(ctx, target)
+		if errx != nil {
+			errx.AddCommentf("[scanner][StartScanningSession] while check out %s", target.Url)
+			return
+		}
+		defer ws.Cleanup()
+
+		target.LocalPath = ws.Dir
+		target.Branch = ws.Branch
+		res.HeadCommit = ws.Head
+
+		if target.Mode == constants.ScanModeDiff {
+			target.SinceCommit, diffLines, errx = diffWorkspace(ws.Dir, target.BaseRef)
+			if errx != nil {
+				errx.AddCommentf("[scanner][StartScanningSession] while diff %s from %s", target.Url, target.BaseRef)
+				return
+			}
+			target.ChangedFiles = diffLines.Paths()
+		} else if target.SinceCommit != "" {
+			var errs serror.SError
+			target.ChangedFiles, errs = changedFiles(ws.Dir, target.SinceCommit)
+			if errs != nil {
+				// e.g. history is rewritten, so the whole repository has to be scanned again
+				log.Warnf("Scan %s fully, commit %s is not found: %s", target.Url, target.SinceCommit, errs.Error())
+				target.SinceCommit = ""
+			}
+		}
+	} else {
+		target.SinceCommit = ""
+	}
+	res.SinceCommit = target.SinceCommit
+	if target.SinceCommit != "" {
+		res.ChangedFiles = target.ChangedFiles
+	}
+
+	for _, engine := range r.engines {
+		info := engine.Info()
+
+		// Stop before next engine once scanning is cancelled or timed out
+		if err := ctx.Err(); err != nil {
+			errx = serror.NewFromError(err)
+			errx.AddCommentf("[scanner][StartScanningSession] before scan by engine %s", info.Name)
+			return
+		}
+		res.Engines = append(res.Engines, info)
+
+		var findings []model.Finding
+		findings, errx = engine.Scan(ctx, target)
+		if errx != nil {
+			errx.AddCommentf("[scanner][StartScanningSession] while scan by engine %s", info.Name)
+			return
+		}
+
+		for idx := range findings {
+			findings[idx].Engine = info.Name
+			findings[idx].Severity = findingSeverity(findings[idx].Action)
+		}
+		res.Findings = append(res.Findings, findings...)
+	}
+
+	// Engines scanning remote repository or history do not walk work tree, so they are not aware of repository settings
+	res.Findings = newSkipMatcher(target.Settings).Filter(res.Findings)
+
+	// Diff scanning reports findings on lines added by the change only
+	if diffLines != nil {
+		res.Findings = diffLines.Filter(res.Findings)
+	}
+
+	// Suppressions are only known from work tree, remote repository scanned by provider API has none
+	if target.LocalPath != "" {
+		var ignore ignoreFile
+		ignore, errx = loadIgnoreFile(target.LocalPath)
+		if errx != nil {
+			errx.AddCommentf("[scanner][StartScanningSession] while load ignore file of %s", target.Url)
+			return
+		}
+		suppressFindings(target.LocalPath, ignore, res.Findings)
+		res.IgnoredFingerprints = ignore.fingerprints
+		res.IgnoreFile = ignore
+	}
+	return
+}
+
+func (r scanEngineRegistry) needWorktree() bool {
+	for _, engine := range r.engines {
+		if utstring.ArrContains(engine.Info().Capabilities, constants.ScanEngineCapabilityWorktree) {
+			return true
+		}
+	}
+	return false
+}
+
+// findingSeverity tells severity of finding by part of file it is matched at
+func findingSeverity(action string) string {
+	switch action {
+	case signatures.PartContent:
+		return constants.FindingSeverityHigh
+	case constants.FindingActionEntropy:
+		return constants.FindingSeverityLow
+	default:
+		return constants.FindingSeverityMedium
+	}
+}
+
+// moduleVersion returns version of given dependency module from build info
+func moduleVersion(path string) string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+
+	for _, dep := range info.Deps {
+		if dep.Path == path {
+			return dep.Version
+		}
+	}
+	return "unknown"
+}
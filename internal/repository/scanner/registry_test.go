@@ -0,0 +1,110 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"repo-scanner/internal"
+	"repo-scanner/internal/mocks"
+	"repo-scanner/internal/model"
+	"repo-scanner/internal/utils/serror"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newEngineMock(name string) *mocks.IScanEngine {
+	engine := new(mocks.IScanEngine)
+	engine.On("Info").Return(model.ScanEngineInfo{Name: name, Version: "v1"})
+	return engine
+}
+
+func TestNewScanEngineRegistry(t *testing.T) {
+	tests := []struct {
+		name    string
+		enabled []string
+		engines []string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:    "all",
+			engines: []string{"grab", "native"},
+			want:    []string{"grab", "native"},
+		},
+		{
+			name:    "enabled only",
+			enabled: []string{"native"},
+			engines: []string{"grab", "native"},
+			want:    []string{"native"},
+		},
+		{
+			name:    "duplicated",
+			engines: []string{"grab", "grab"},
+			wantErr: true,
+		},
+		{
+			name:    "nothing enabled",
+			enabled: []string{"unknown"},
+			engines: []string{"grab"},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		var engines []internal.IScanEngine
+		for _, name := range test.engines {
+			engines = append(engines, newEngineMock(name))
+		}
+
+		registry, err := NewScanEngineRegistry(test.enabled, engines...)
+		if (err != nil) != test.wantErr {
+			t.Errorf("%s: NewScanEngineRegistry() got error : %s", test.name, err)
+			continue
+		}
+		if test.wantErr {
+			continue
+		}
+
+		var got []string
+		for _, info := range registry.GetScanEngines() {
+			got = append(got, info.Name)
+		}
+		assert.Equal(t, test.want, got, test.name)
+	}
+}
+
+func TestStartScanningSession(t *testing.T) {
+	target := model.ScanTarget{ScanningId: 10, Url: "github.com/jquery/jquery"}
+
+	grab := newEngineMock("grab")
+	grab.On("Scan", mock.Anything, target).Return([]model.Finding{{ID: "a", Action: "content"}}, nil).Once()
+	native := newEngineMock("native")
+	native.On("Scan", mock.Anything, target).Return([]model.Finding{{ID: "b", Action: "filename"}, {ID: "c", Action: "entropy"}}, nil).Once()
+
+	registry, errx := NewScanEngineRegistry(nil, grab, native)
+	assert.Nil(t, errx)
+
+	res, errx := registry.StartScanningSession(context.Background(), target)
+	assert.Nil(t, errx)
+	assert.Equal(t, []model.Finding{
+		{ID: "a", Engine: "grab", Action: "content", Severity: "high"},
+		{ID: "b", Engine: "native", Action: "filename", Severity: "medium"},
+		{ID: "c", Engine: "native", Action: "entropy", Severity: "low"},
+	}, res.Findings)
+	assert.Len(t, res.Engines, 2)
+
+	// failure of an engine fails the whole session
+	grab.On("Scan", mock.Anything, mock.Anything).Return(nil, serror.NewFromError(errors.New("error"))).Once()
+	_, errx = registry.StartScanningSession(context.Background(), target)
+	assert.NotNil(t, errx)
+
+	// cancelled session does not start any engine
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, errx = registry.StartScanningSession(ctx, target)
+	assert.NotNil(t, errx)
+	grab.AssertNumberOfCalls(t, "Scan", 2)
+	native.AssertNumberOfCalls(t, "Scan", 1)
+}
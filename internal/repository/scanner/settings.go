@@ -0,0 +1,60 @@
+package scanner
+
+import (
+	"regexp"
+	"strings"
+
+	"repo-scanner/internal/model"
+)
+
+// skipMatcher tells whether a file is skipped by SkipPaths and SkipExtensions of repository settings,
+// it is applied on top of SKIP_EXT and SKIP_PATHS
+type skipMatcher struct {
+	paths      []*regexp.Regexp
+	extensions []string
+}
+
+func newSkipMatcher(settings model.RepositorySettings) (res skipMatcher) {
+	for _, glob := range settings.SkipPaths {
+		if glob = strings.TrimSpace(glob); glob != "" {
+			res.paths = append(res.paths, globRegexp(glob))
+		}
+	}
+	for _, ext := range settings.SkipExtensions {
+		if ext = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(ext), ".")); ext != "" {
+			res.extensions = append(res.extensions, "."+ext)
+		}
+	}
+	return
+}
+
+// Skips tells whether file at slash separated path is skipped
+func (m skipMatcher) Skips(path string) bool {
+	lower := strings.ToLower(path)
+	for _, ext := range m.extensions {
+		if strings.HasSuffix(lower, ext) {
+			return true
+		}
+	}
+	for _, match := range m.paths {
+		if match.MatchString(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// Filter returns findings whose file is not skipped, for engines which do not walk work tree by themselves
+func (m skipMatcher) Filter(findings []model.Finding) []model.Finding {
+	if len(m.paths) == 0 && len(m.extensions) == 0 {
+		return findings
+	}
+
+	res := make([]model.Finding, 0, len(findings))
+	for _, finding := range findings {
+		if !m.Skips(finding.FilePath) {
+			res = append(res, finding)
+		}
+	}
+	return res
+}
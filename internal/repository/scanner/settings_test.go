@@ -0,0 +1,67 @@
+package scanner
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"repo-scanner/internal/model"
+
+	"github.com/grab/secret-scanner/scanner/signatures"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSkipMatcher(t *testing.T) {
+	skips := newSkipMatcher(model.RepositorySettings{
+		SkipPaths:      []string{"docs/**", " ", "*.snap"},
+		SkipExtensions: []string{".CSV", "svg", ""},
+	})
+
+	assert.True(t, skips.Skips("docs/guide/setup.md"))
+	assert.True(t, skips.Skips("web/__snapshots__/app.snap"))
+	assert.True(t, skips.Skips("data/Users.csv"))
+	assert.True(t, skips.Skips("logo.svg"))
+	assert.False(t, skips.Skips("src/docs.go"))
+	assert.False(t, skips.Skips("config/app.yml"))
+
+	findings := []model.Finding{{FilePath: "docs/setup.md"}, {FilePath: "config/app.yml"}}
+	assert.Equal(t, []model.Finding{{FilePath: "config/app.yml"}}, skips.Filter(findings))
+	assert.Equal(t, findings, newSkipMatcher(model.RepositorySettings{}).Filter(findings))
+}
+
+func TestWalkWorktreeSettings(t *testing.T) {
+	dir, err := ioutil.TempDir("", "reposcan-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, name := range []string{"main.go", "docs/setup.md", "test/main_test.go"} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte("package main\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	walk := func(settings model.RepositorySettings) (res []string) {
+		errx := walkWorktree(context.Background(), model.ScanTarget{LocalPath: dir, Settings: settings},
+			func(file signatures.MatchFile, isTestContext bool) {
+				res = append(res, file.Path)
+			})
+		assert.Nil(t, errx)
+		sort.Strings(res)
+		return
+	}
+
+	assert.Equal(t, []string{"docs/setup.md", "main.go"}, walk(model.RepositorySettings{}))
+	assert.Equal(t, []string{"main.go", "test/main_test.go"}, walk(model.RepositorySettings{
+		SkipPaths: []string{"docs/"},
+		ScanTests: true,
+	}))
+}
@@ -0,0 +1,303 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"repo-scanner/internal/constants"
+	"repo-scanner/internal/model"
+	"repo-scanner/internal/utils/serror"
+	"repo-scanner/internal/utils/utgit"
+	"repo-scanner/internal/utils/utstring"
+
+	"github.com/grab/secret-scanner/scanner/gitprovider"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport/http"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport/ssh"
+)
+
+// workspace is a local work tree of scan target, Cleanup removes it once scanning is done
+type workspace struct {
+	Dir     string
+	Branch  string
+	Head    string
+	Cleanup func()
+}
+
+// checkoutWorkspace clones given target into a temporary work tree checked out at target ref,
+// local directory which is not a git repository is used as work tree as it is
+func checkoutWorkspace(ctx context.Context, target model.ScanTarget) (res workspace, errx serror.SError) {
+	cloneOpt := &git.CloneOptions{
+		Tags: git.NoTags,
+	}
+	if target.Ref != "" {
+		cloneOpt.Tags = git.AllTags
+	}
+
+	localPath, isLocal := utgit.LocalPath(target.Url)
+	switch {
+	case isLocal:
+		info, err := os.Stat(localPath)
+		if err != nil || !info.IsDir() {
+			errx = serror.Newf("Local repository %s is not a directory", localPath)
+			errx.AddCommentf("[scanner][checkoutWorkspace] while stat %s", localPath)
+			return
+		}
+
+		if _, err = git.PlainOpen(localPath); err == git.ErrRepositoryNotExists {
+			if target.Ref != "" {
+				errx = serror.Newf("Local directory %s is not a git repository, ref %s cannot be checked out", localPath, target.Ref)
+				errx.AddCommentf("[scanner][checkoutWorkspace] while open %s", localPath)
+				return
+			}
+			res.Dir = localPath
+			res.Cleanup = func() {}
+			return
+		}
+		cloneOpt.URL = localPath
+	case utgit.IsRemote(target.Url):
+		cloneOpt.URL = target.Url
+		cloneOpt.Auth, errx = remoteAuth(target.Url)
+		if errx != nil {
+			errx.AddCommentf("[scanner][checkoutWorkspace] while prepare credentials of %s", target.Url)
+			return
+		}
+	default:
+		cloneOpt.URL = fmt.Sprintf("https://%s.git", strings.TrimSuffix(target.Url, ".git"))
+		cloneOpt.Auth = providerAuth(target.Url)
+		if target.SinceCommit == "" && target.Ref == "" {
+			cloneOpt.Depth = 1
+		}
+	}
+
+	dir, err := ioutil.TempDir("", "reposcan")
+	if err != nil {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[scanner][checkoutWorkspace] while create temporary directory")
+		return
+	}
+	res.Dir = dir
+	res.Cleanup = func() {
+		_ = os.RemoveAll(dir)
+	}
+
+	repo, err := git.PlainCloneContext(ctx, dir, false, cloneOpt)
+	if err != nil {
+		res.Cleanup()
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[scanner][checkoutWorkspace] while clone %s", target.Url)
+		return
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		res.Cleanup()
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[scanner][checkoutWorkspace] while resolve HEAD of %s", target.Url)
+		return
+	}
+	res.Branch = head.Name().Short()
+	headHash := head.Hash()
+
+	if target.Ref != "" {
+		var hash *plumbing.Hash
+		hash, err = resolveRef(repo, target.Ref)
+		if err != nil {
+			res.Cleanup()
+			errx = serror.NewFromError(err)
+			errx.AddCommentf("[scanner][checkoutWorkspace] while resolve ref %s of %s", target.Ref, target.Url)
+			return
+		}
+
+		var tree *git.Worktree
+		tree, err = repo.Worktree()
+		if err == nil {
+			err = tree.Checkout(&git.CheckoutOptions{Hash: *hash, Force: true})
+		}
+		if err != nil {
+			res.Cleanup()
+			errx = serror.NewFromError(err)
+			errx.AddCommentf("[scanner][checkoutWorkspace] while check out ref %s of %s", target.Ref, target.Url)
+			return
+		}
+		res.Branch = target.Ref
+		headHash = *hash
+	}
+	res.Head = headHash.String()
+
+	// grab/secret-scanner lists files of cloned repository from master branch only
+	if utgit.IsDirectClone(target.Url) || target.SinceCommit != "" || target.Ref != "" {
+		master := plumbing.NewBranchReferenceName("master")
+		if _, err = repo.Reference(master, false); err == plumbing.ErrReferenceNotFound || target.Ref != "" {
+			err = repo.Storer.SetReference(plumbing.NewHashReference(master, headHash))
+		}
+		if err != nil {
+			res.Cleanup()
+			errx = serror.NewFromError(err)
+			errx.AddCommentf("[scanner][checkoutWorkspace] while set master branch of %s", target.Url)
+			return
+		}
+	}
+	return
+}
+
+// resolveRef returns commit which given branch, tag or full commit SHA points to,
+// branches other than the default one are only known as remote branches in a fresh clone
+func resolveRef(repo *git.Repository, ref string) (*plumbing.Hash, error) {
+	if tag, err := repo.Tag(ref); err == nil {
+		// annotated tag points to tag object instead of commit
+		if obj, err := repo.TagObject(tag.Hash()); err == nil {
+			commit, err := obj.Commit()
+			if err != nil {
+				return nil, err
+			}
+			return &commit.Hash, nil
+		}
+	}
+
+	for _, rev := range []string{ref, fmt.Sprintf("%s/%s", git.DefaultRemoteName, ref)} {
+		if hash, err := repo.ResolveRevision(plumbing.Revision(rev)); err == nil {
+			return hash, nil
+		}
+	}
+	return nil, fmt.Errorf("ref %s is not found", ref)
+}
+
+// changedFiles returns files which are added, modified or deleted from since commit to HEAD of work tree
+func changedFiles(dir string, since string) (res []string, errx serror.SError) {
+	repo, err := git.PlainOpen(dir)
+	if err != nil {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[scanner][changedFiles] while open %s", dir)
+		return
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[scanner][changedFiles] while resolve HEAD of %s", dir)
+		return
+	}
+
+	var trees [2]*object.Tree
+	for idx, hash := range []plumbing.Hash{plumbing.NewHash(since), head.Hash()} {
+		commit, err := repo.CommitObject(hash)
+		if err != nil {
+			errx = serror.NewFromError(err)
+			errx.AddCommentf("[scanner][changedFiles] while get commit %s", hash)
+			return
+		}
+		trees[idx], err = commit.Tree()
+		if err != nil {
+			errx = serror.NewFromError(err)
+			errx.AddCommentf("[scanner][changedFiles] while get tree of commit %s", hash)
+			return
+		}
+	}
+
+	changes, err := object.DiffTree(trees[0], trees[1])
+	if err != nil {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[scanner][changedFiles] while diff %s..%s", since, head.Hash())
+		return
+	}
+
+	res = []string{}
+	for _, change := range changes {
+		if change.To.Name != "" {
+			res = append(res, change.To.Name)
+		}
+		// Findings in deleted or renamed files are gone as well
+		if change.From.Name != "" && change.From.Name != change.To.Name {
+			res = append(res, change.From.Name)
+		}
+	}
+	return
+}
+
+// remoteAuth returns credentials of generic git remote, HTTPS basic auth is taken from
+// GIT_HTTP_USERNAME and GIT_HTTP_PASSWORD for hosts listed in GIT_HTTP_HOSTS only and never sent over plain http,
+// SSH key from GIT_SSH_KEY_PATH and GIT_SSH_KEY_PASSPHRASE
+func remoteAuth(repoUrl string) (auth transport.AuthMethod, errx serror.SError) {
+	endpoint, err := transport.NewEndpoint(repoUrl)
+	if err != nil {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[scanner][remoteAuth] while parse %s", repoUrl)
+		return
+	}
+
+	switch endpoint.Protocol {
+	case "https":
+		if !isGitHTTPHost(endpoint.Host) {
+			return
+		}
+		if username := utstring.Env(constants.GitHTTPUsername); username != "" {
+			auth = &http.BasicAuth{
+				Username: username,
+				Password: utstring.Env(constants.GitHTTPPassword),
+			}
+		}
+	case "ssh":
+		keyPath := utstring.Env(constants.GitSSHKeyPath)
+		if keyPath == "" {
+			return
+		}
+
+		var keys *ssh.PublicKeys
+		keys, err = ssh.NewPublicKeysFromFile(utstring.Chains(endpoint.User, "git"), keyPath, utstring.Env(constants.GitSSHKeyPassphrase))
+		if err != nil {
+			errx = serror.NewFromError(err)
+			errx.AddCommentf("[scanner][remoteAuth] while load SSH key %s", keyPath)
+			return
+		}
+
+		if knownHosts := utstring.Env(constants.GitSSHKnownHosts); knownHosts != "" {
+			keys.HostKeyCallback, err = ssh.NewKnownHostsCallback(knownHosts)
+			if err != nil {
+				errx = serror.NewFromError(err)
+				errx.AddCommentf("[scanner][remoteAuth] while load known hosts %s", knownHosts)
+				return
+			}
+		}
+		auth = keys
+	}
+	return
+}
+
+// isGitHTTPHost tells whether host is listed in GIT_HTTP_HOSTS to receive GIT_HTTP_USERNAME and GIT_HTTP_PASSWORD
+func isGitHTTPHost(host string) bool {
+	for _, allowed := range utstring.CleanSpit(utstring.Env(constants.GitHTTPHosts), ",") {
+		if allowed != "" && strings.EqualFold(allowed, host) {
+			return true
+		}
+	}
+	return false
+}
+
+// providerAuth returns credentials of git provider hosting given repository url
+func providerAuth(repoUrl string) transport.AuthMethod {
+	switch strings.Split(repoUrl, "/")[0] {
+	case "github.com":
+		if token := utstring.Env(gitprovider.GithubParamToken); token != "" {
+			return &http.BasicAuth{Username: "reposcan", Password: token}
+		}
+	case "gitlab.com":
+		if token := utstring.Env(gitprovider.GitlabParamToken); token != "" {
+			return &http.BasicAuth{Username: "reposcan", Password: token}
+		}
+	case "bitbucket.org":
+		if username := utstring.Env(gitprovider.BitbucketParamUsername); username != "" {
+			return &http.BasicAuth{
+				Username: username,
+				Password: utstring.Env(gitprovider.BitbucketParamPassword),
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,340 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"repo-scanner/internal/constants"
+	"repo-scanner/internal/model"
+	"repo-scanner/internal/utils/serror"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport/http"
+)
+
+// newLocalRepository creates git repository with single commit on main branch
+func newLocalRepository(t *testing.T, dir string, files map[string]string) {
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = repo.Storer.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, plumbing.NewBranchReferenceName("main")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tree, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tree.Add(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err = tree.Commit("init", &git.CommitOptions{
+		Author: &object.Signature{Name: "reposcan", Email: "reposcan@localhost", When: time.Now()},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCheckoutLocalWorkspace(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir, err := ioutil.TempDir("", "reposcan-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	plainDir := filepath.Join(dir, "plain")
+	repoDir := filepath.Join(dir, "repo")
+	for _, d := range []string{plainDir, repoDir} {
+		if err := os.MkdirAll(d, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	newLocalRepository(t, repoDir, map[string]string{"app.yml": "name: app\n"})
+
+	t.Run("plain directory", func(t *testing.T) {
+		ws, errx := checkoutWorkspace(context.Background(), model.ScanTarget{Url: plainDir})
+		assert.Nil(t, errx)
+		defer ws.Cleanup()
+		assert.Equal(t, plainDir, ws.Dir)
+	})
+
+	t.Run("git repository", func(t *testing.T) {
+		ws, errx := checkoutWorkspace(context.Background(), model.ScanTarget{Url: "file://" + repoDir})
+		assert.Nil(t, errx)
+		defer ws.Cleanup()
+		assert.NotEqual(t, repoDir, ws.Dir)
+		assert.Equal(t, "main", ws.Branch)
+		assert.FileExists(t, filepath.Join(ws.Dir, "app.yml"))
+
+		repo, err := git.PlainOpen(ws.Dir)
+		assert.Nil(t, err)
+		_, err = repo.Reference(plumbing.NewBranchReferenceName("master"), false)
+		assert.Nil(t, err)
+	})
+
+	t.Run("missing directory", func(t *testing.T) {
+		_, errx := checkoutWorkspace(context.Background(), model.ScanTarget{Url: filepath.Join(dir, "missing")})
+		assert.NotNil(t, errx)
+	})
+}
+
+func TestCheckoutRemoteWorkspace(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir, err := ioutil.TempDir("", "reposcan-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	repoDir := filepath.Join(dir, "repo")
+	if err := os.MkdirAll(repoDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	newLocalRepository(t, repoDir, map[string]string{"app.yml": "name: app\n"})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	daemon := exec.Command("git", "daemon", "--export-all", "--reuseaddr",
+		"--listen=127.0.0.1", fmt.Sprintf("--port=%d", port), "--base-path="+dir, dir)
+	if err := daemon.Start(); err != nil {
+		t.Skipf("git daemon is not available: %v", err)
+	}
+	defer daemon.Process.Kill()
+
+	url := fmt.Sprintf("git://127.0.0.1:%d/repo", port)
+	var (
+		ws   workspace
+		errx serror.SError
+	)
+	for i := 0; i < 50; i++ {
+		ws, errx = checkoutWorkspace(context.Background(), model.ScanTarget{Url: url})
+		if errx == nil {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	if errx != nil {
+		t.Skipf("git daemon is not reachable: %v", errx)
+	}
+	defer ws.Cleanup()
+
+	assert.Equal(t, "main", ws.Branch)
+	assert.FileExists(t, filepath.Join(ws.Dir, "app.yml"))
+
+	repo, err := git.PlainOpen(ws.Dir)
+	assert.Nil(t, err)
+	_, err = repo.Reference(plumbing.NewBranchReferenceName("master"), false)
+	assert.Nil(t, err)
+}
+
+func TestCheckoutWorkspaceRef(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir, err := ioutil.TempDir("", "reposcan-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	newLocalRepository(t, dir, map[string]string{"app.yml": "version: 1\n"})
+	repo, err := git.PlainOpen(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := repo.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature := &object.Signature{Name: "reposcan", Email: "reposcan@localhost", When: time.Now()}
+	if _, err = repo.CreateTag("v1", first.Hash(), &git.CreateTagOptions{Tagger: signature, Message: "v1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err = repo.Storer.SetReference(plumbing.NewHashReference(plumbing.NewBranchReferenceName("develop"), first.Hash())); err != nil {
+		t.Fatal(err)
+	}
+
+	// Second commit moves main ahead of v1
+	tree, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = ioutil.WriteFile(filepath.Join(dir, "app.yml"), []byte("version: 2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = tree.Add("app.yml"); err != nil {
+		t.Fatal(err)
+	}
+	second, err := tree.Commit("bump", &git.CommitOptions{Author: signature})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		ref     string
+		want    string
+		content string
+		wantErr bool
+	}{
+		{name: "default branch", ref: "", want: second.String(), content: "version: 2\n"},
+		{name: "branch", ref: "main", want: second.String(), content: "version: 2\n"},
+		{name: "other branch", ref: "develop", want: first.Hash().String(), content: "version: 1\n"},
+		{name: "annotated tag", ref: "v1", want: first.Hash().String(), content: "version: 1\n"},
+		{name: "commit", ref: first.Hash().String(), want: first.Hash().String(), content: "version: 1\n"},
+		{name: "unknown ref", ref: "missing", wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ws, errx := checkoutWorkspace(context.Background(), model.ScanTarget{Url: "file://" + dir, Ref: test.ref})
+			if test.wantErr {
+				assert.NotNil(t, errx)
+				return
+			}
+			assert.Nil(t, errx)
+			defer ws.Cleanup()
+			assert.Equal(t, test.want, ws.Head)
+
+			content, err := ioutil.ReadFile(filepath.Join(ws.Dir, "app.yml"))
+			assert.Nil(t, err)
+			assert.Equal(t, test.content, string(content))
+
+			// grab scans master branch, so it has to point to checked out ref
+			clone, err := git.PlainOpen(ws.Dir)
+			assert.Nil(t, err)
+			master, err := clone.Reference(plumbing.NewBranchReferenceName("master"), false)
+			assert.Nil(t, err)
+			assert.Equal(t, test.want, master.Hash().String())
+		})
+	}
+}
+
+func TestChangedFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "reposcan-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	newLocalRepository(t, dir, map[string]string{
+		"app.yml":  "version: 1\n",
+		"old.txt":  "obsolete\n",
+		"keep.txt": "untouched\n",
+	})
+	repo, err := git.PlainOpen(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := repo.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Second commit modifies, deletes and adds a file
+	tree, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = ioutil.WriteFile(filepath.Join(dir, "app.yml"), []byte("version: 2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err = ioutil.WriteFile(filepath.Join(dir, "new.txt"), []byte("fresh\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"app.yml", "new.txt"} {
+		if _, err = tree.Add(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err = tree.Remove("old.txt"); err != nil {
+		t.Fatal(err)
+	}
+	_, err = tree.Commit("change", &git.CommitOptions{
+		Author: &object.Signature{Name: "reposcan", Email: "reposcan@localhost", When: time.Now()},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, errx := changedFiles(dir, first.Hash().String())
+	assert.Nil(t, errx)
+	assert.ElementsMatch(t, []string{"app.yml", "new.txt", "old.txt"}, got)
+}
+
+func TestRemoteAuth(t *testing.T) {
+	t.Setenv(constants.GitHTTPUsername, "reposcan")
+	t.Setenv(constants.GitHTTPPassword, "s3cret")
+	t.Setenv(constants.GitHTTPHosts, "gitea.example.com, git.example.org")
+
+	testCases := []struct {
+		name string
+		url  string
+		want transport.AuthMethod
+	}{
+		{
+			name: "listed host",
+			url:  "https://gitea.example.com/org/repo.git",
+			want: &http.BasicAuth{Username: "reposcan", Password: "s3cret"},
+		},
+		{
+			name: "listed host with port",
+			url:  "https://GIT.example.org:8443/org/repo.git",
+			want: &http.BasicAuth{Username: "reposcan", Password: "s3cret"},
+		},
+		{
+			name: "unlisted host",
+			url:  "https://attacker.example/x.git",
+		},
+		{
+			name: "listed host over plain http",
+			url:  "http://gitea.example.com/org/repo.git",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			auth, errx := remoteAuth(tc.url)
+			assert.Nil(t, errx)
+			assert.Equal(t, tc.want, auth)
+		})
+	}
+
+	// No host receives credentials until it is listed
+	t.Setenv(constants.GitHTTPHosts, "")
+	auth, errx := remoteAuth("https://gitea.example.com/org/repo.git")
+	assert.Nil(t, errx)
+	assert.Nil(t, auth)
+}
@@ -0,0 +1,143 @@
+package scanner
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"repo-scanner/internal/model"
+	"repo-scanner/internal/utils/serror"
+	"repo-scanner/internal/utils/utgit"
+
+	"github.com/grab/secret-scanner/scanner/findings"
+	"github.com/grab/secret-scanner/scanner/signatures"
+)
+
+// maxWorktreeFileSize is the biggest file to be read from work tree, bigger ones are skipped
+const maxWorktreeFileSize = 2 << 20
+
+// walkWorktree calls fn with every file of target work tree which is not skippable by SKIP_EXT, SKIP_PATHS
+// and repository settings, test contexts by SKIP_TEST_PATHS are walked only if settings scan tests.
+// Only changed files are walked on incremental scan. Walking stops as soon as ctx is done.
+func walkWorktree(ctx context.Context, target model.ScanTarget, fn func(file signatures.MatchFile, isTestContext bool)) (errx serror.SError) {
+	dir := target.LocalPath
+	skips := newSkipMatcher(target.Settings)
+
+	var changed map[string]bool
+	if target.SinceCommit != "" {
+		changed = make(map[string]bool, len(target.ChangedFiles))
+		for _, path := range target.ChangedFiles {
+			changed[path] = true
+		}
+	}
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if info.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.Mode().IsRegular() || info.Size() > maxWorktreeFileSize {
+			return nil
+		}
+
+		subPath, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		matchFile := signatures.NewMatchFile(filepath.ToSlash(subPath), "")
+		if changed != nil && !changed[matchFile.Path] {
+			return nil
+		}
+		if matchFile.IsSkippable() || skips.Skips(matchFile.Path) {
+			return nil
+		}
+		isTestContext := matchFile.IsTestContext()
+		if isTestContext && !target.Settings.ScanTests {
+			return nil
+		}
+
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		fn(signatures.NewMatchFile(matchFile.Path, string(content)), isTestContext)
+		return nil
+	})
+	if err != nil {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[scanner][walkWorktree] while walk %s", dir)
+		return
+	}
+	return
+}
+
+// matchSignatures matches given file with every signature and returns findings in grab shape
+func matchSignatures(target model.ScanTarget, file signatures.MatchFile, isTestContext bool,
+	sigs []signatures.Signature, ruleFN func(sig signatures.Signature) string) (res []model.Finding) {
+	for _, sig := range sigs {
+		for _, match := range sig.Match(file) {
+			finding := model.Finding{
+				Rule:          ruleFN(sig),
+				FilePath:      file.Path,
+				Action:        sig.Part(),
+				Description:   sig.Description(),
+				Comment:       sig.Comment(),
+				RepositoryURL: repositoryURL(target),
+				FileURL:       fileURL(target, file.Path),
+				Line:          match.Line,
+				LineContent:   match.LineContent,
+				IsTestContext: isTestContext,
+			}
+			if len(finding.LineContent) > findings.MaxLineChar {
+				finding.LineContent = finding.LineContent[:findings.MaxLineChar]
+			}
+			finding.ID = findingHashID(finding)
+
+			res = append(res, finding)
+		}
+	}
+	return
+}
+
+// repositoryURL returns browsable url of target, local and generic git repository is returned as it is registered
+func repositoryURL(target model.ScanTarget) string {
+	if utgit.IsDirectClone(target.Url) {
+		return target.Url
+	}
+	return fmt.Sprintf("https://%s", target.Url)
+}
+
+// fileURL returns browsable url of file in target
+func fileURL(target model.ScanTarget, filePath string) string {
+	if utgit.IsDirectClone(target.Url) {
+		return fmt.Sprintf("%s/%s", strings.TrimSuffix(target.Url, "/"), filePath)
+	}
+	return fmt.Sprintf("https://%s/blob/%s/%s", target.Url, target.Branch, filePath)
+}
+
+// commitURL returns browsable url of commit in target, empty if there is none
+func commitURL(target model.ScanTarget, commitHash string) string {
+	if commitHash == "" || utgit.IsDirectClone(target.Url) {
+		return ""
+	}
+	return fmt.Sprintf("https://%s/commit/%s", target.Url, commitHash)
+}
+
+// findingHashID generates hash of finding the same way as grab/secret-scanner does
+func findingHashID(f model.Finding) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(
+		fmt.Sprintf("%s%s%v%s", f.FileURL, f.Action, f.Line, f.LineContent))))
+}
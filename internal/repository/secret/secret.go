@@ -0,0 +1,149 @@
+package secret
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"repo-scanner/internal"
+	"repo-scanner/internal/model"
+	"repo-scanner/internal/utils/serror"
+)
+
+// redactedMask replaces every redacted character of secret preview
+const redactedMask = "****"
+
+type secretRepository struct {
+	option model.SecretOption
+	aead   cipher.AEAD
+}
+
+// NewSecretRepository creates repository which redacts secret values of findings,
+// raw values are encrypted with AES-256-GCM if option keeps them
+func NewSecretRepository(option model.SecretOption) (internal.ISecretRepository, serror.SError) {
+	if len(option.HashKey) == 0 {
+		errx := serror.New("Hash key of finding secrets is required")
+		errx.AddCommentf("[repository][NewSecretRepository] while check hash key")
+		return nil, errx
+	}
+
+	repo := secretRepository{
+		option: option,
+	}
+	if option.KeepSecret {
+		block, err := aes.NewCipher(option.EncryptionKey)
+		if err != nil || len(option.EncryptionKey) != 32 {
+			errx := serror.New("Encryption key of finding secrets must be 32 bytes")
+			errx.AddCommentf("[repository][NewSecretRepository] while create cipher")
+			return nil, errx
+		}
+		repo.aead, err = cipher.NewGCM(block)
+		if err != nil {
+			errx := serror.NewFromError(err)
+			errx.AddCommentf("[repository][NewSecretRepository] while create GCM")
+			return nil, errx
+		}
+	}
+	return repo, nil
+}
+
+func (s secretRepository) Redact(scanning_id int64, findings []model.Finding) (res []model.Finding, secrets []model.FindingSecret, errx serror.SError) {
+	res = make([]model.Finding, 0, len(findings))
+	secrets = []model.FindingSecret{}
+
+	kept := map[string]bool{}
+	for _, finding := range findings {
+		if finding.LineContent == "" {
+			res = append(res, finding)
+			continue
+		}
+
+		raw := finding.LineContent
+		finding.LineContent = preview(raw)
+		finding.SecretHash = s.hash(raw)
+		res = append(res, finding)
+
+		// Engines may report the same finding, only one secret is kept per finding id
+		if !s.option.KeepSecret || kept[finding.ID] {
+			continue
+		}
+		var encrypted string
+		encrypted, errx = s.encrypt(raw, additionalData(scanning_id, finding.ID))
+		if errx != nil {
+			errx.AddCommentf("[repository][Redact] while encrypt secret of finding %s", finding.ID)
+			return nil, nil, errx
+		}
+		secrets = append(secrets, model.FindingSecret{
+			ScanningId:      scanning_id,
+			FindingId:       finding.ID,
+			SecretEncrypted: encrypted,
+		})
+		kept[finding.ID] = true
+	}
+	return
+}
+
+func (s secretRepository) Reveal(secret model.FindingSecret) (res string, errx serror.SError) {
+	if s.aead == nil {
+		errx = serror.Newi(http.StatusBadRequest, "Secrets are not kept|Secrets are not kept")
+		errx.AddCommentf("[repository][Reveal] no encryption key of finding %s", secret.FindingId)
+		return
+	}
+
+	data, err := base64.StdEncoding.DecodeString(secret.SecretEncrypted)
+	if err != nil || len(data) < s.aead.NonceSize() {
+		errx = serror.New("Encrypted secret is malformed")
+		errx.AddCommentf("[repository][Reveal] while decode secret of finding %s", secret.FindingId)
+		return
+	}
+
+	nonce, ciphertext := data[:s.aead.NonceSize()], data[s.aead.NonceSize():]
+	plaintext, err := s.aead.Open(nil, nonce, ciphertext, additionalData(secret.ScanningId, secret.FindingId))
+	if err != nil {
+		errx = serror.New("Encrypted secret cannot be decrypted")
+		errx.AddCommentf("[repository][Reveal] while decrypt secret of finding %s", secret.FindingId)
+		return
+	}
+	return string(plaintext), nil
+}
+
+func (s secretRepository) hash(raw string) string {
+	mac := hmac.New(sha256.New, s.option.HashKey)
+	mac.Write([]byte(raw))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s secretRepository) encrypt(raw string, aad []byte) (res string, errx serror.SError) {
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[repository][encrypt] while generate nonce")
+		return
+	}
+
+	sealed := s.aead.Seal(nonce, nonce, []byte(raw), aad)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// additionalData binds encrypted secret to its finding, so it cannot be revealed as secret of another one
+func additionalData(scanning_id int64, finding_id string) []byte {
+	return []byte(fmt.Sprintf("%d/%s", scanning_id, finding_id))
+}
+
+// preview keeps a few leading characters of raw value, a quarter of it at most, to tell findings apart
+func preview(raw string) string {
+	runes := []rune(strings.TrimSpace(raw))
+	keep := len(runes) / 4
+	if keep > 4 {
+		keep = 4
+	}
+	return string(runes[:keep]) + redactedMask
+}
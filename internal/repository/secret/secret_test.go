@@ -0,0 +1,89 @@
+package secret
+
+import (
+	"strings"
+	"testing"
+
+	"repo-scanner/internal/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSecretRepository(t *testing.T) {
+	tests := []struct {
+		name    string
+		option  model.SecretOption
+		wantErr bool
+	}{
+		{name: "hash only", option: model.SecretOption{HashKey: []byte("hash-key")}},
+		{name: "keep secret", option: model.SecretOption{HashKey: []byte("hash-key"), KeepSecret: true, EncryptionKey: make([]byte, 32)}},
+		{name: "no hash key", option: model.SecretOption{}, wantErr: true},
+		{name: "short encryption key", option: model.SecretOption{HashKey: []byte("hash-key"), KeepSecret: true, EncryptionKey: make([]byte, 16)}, wantErr: true},
+	}
+
+	for _, test := range tests {
+		_, errx := NewSecretRepository(test.option)
+		assert.Equal(t, test.wantErr, errx != nil, test.name)
+	}
+}
+
+func TestRedact(t *testing.T) {
+	findings := []model.Finding{
+		{ID: "a", FilePath: ".npmrc", Action: "content", LineContent: "_authToken=0a1b2c3d4e5f"},
+		{ID: "a", FilePath: ".npmrc", Action: "content", LineContent: "_authToken=0a1b2c3d4e5f"},
+		{ID: "b", FilePath: "id_rsa", Action: "filename"},
+	}
+
+	// Secrets are not kept by default
+	repo, errx := NewSecretRepository(model.SecretOption{HashKey: []byte("hash-key")})
+	assert.Nil(t, errx)
+	res, secrets, errx := repo.Redact(10, findings)
+	assert.Nil(t, errx)
+	assert.Empty(t, secrets)
+	assert.Len(t, res, 3)
+	assert.Equal(t, "_aut****", res[0].LineContent)
+	assert.Len(t, res[0].SecretHash, 64)
+	assert.Equal(t, res[0].SecretHash, res[1].SecretHash)
+	assert.Equal(t, "", res[2].LineContent)
+	assert.Equal(t, "", res[2].SecretHash)
+	assert.Equal(t, "_authToken=0a1b2c3d4e5f", findings[0].LineContent, "given findings are not modified")
+
+	// Hash depends on key
+	other, _ := NewSecretRepository(model.SecretOption{HashKey: []byte("other-key")})
+	otherRes, _, _ := other.Redact(10, findings)
+	assert.NotEqual(t, res[0].SecretHash, otherRes[0].SecretHash)
+
+	// Kept secrets are encrypted once per finding and revealed back
+	key := []byte(strings.Repeat("k", 32))
+	repo, errx = NewSecretRepository(model.SecretOption{HashKey: []byte("hash-key"), KeepSecret: true, EncryptionKey: key})
+	assert.Nil(t, errx)
+	res, secrets, errx = repo.Redact(10, findings)
+	assert.Nil(t, errx)
+	assert.Len(t, secrets, 1)
+	assert.Equal(t, int64(10), secrets[0].ScanningId)
+	assert.Equal(t, "a", secrets[0].FindingId)
+	assert.NotContains(t, secrets[0].SecretEncrypted, "0a1b2c3d")
+	assert.Equal(t, "_aut****", res[0].LineContent)
+
+	raw, errx := repo.Reveal(secrets[0])
+	assert.Nil(t, errx)
+	assert.Equal(t, "_authToken=0a1b2c3d4e5f", raw)
+
+	// Encrypted secret cannot be revealed as secret of another finding
+	moved := secrets[0]
+	moved.FindingId = "b"
+	_, errx = repo.Reveal(moved)
+	assert.NotNil(t, errx)
+
+	// Nor by another key
+	other, _ = NewSecretRepository(model.SecretOption{HashKey: []byte("hash-key"), KeepSecret: true, EncryptionKey: make([]byte, 32)})
+	_, errx = other.Reveal(secrets[0])
+	assert.NotNil(t, errx)
+}
+
+func TestPreview(t *testing.T) {
+	assert.Equal(t, "****", preview("abc"))
+	assert.Equal(t, "ab****", preview("abcdefgh"))
+	assert.Equal(t, "abcd****", preview("abcdefghijklmnopqrstuvwxyz"))
+	assert.Equal(t, "pa****", preview("  password  "))
+}
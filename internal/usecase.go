@@ -1,6 +1,8 @@
 package internal
 
 import (
+	"context"
+
 	"repo-scanner/internal/model"
 	"repo-scanner/internal/utils/serror"
 )
@@ -8,36 +10,115 @@ import (
 type UsecaseStore struct {
 	RepositoryUsecase IRepositoryUsecase
 	ScanningUsecase   IScanningUsecase
+	RuleUsecase       IRuleUsecase
 }
 
 type IRepositoryUsecase interface {
 	// Get list of git repositories
-	GetRepositoryList(model.RepositoryListRequest) ([]model.RepositoryListResponse, serror.SError)
+	GetRepositoryList(context.Context, model.RepositoryListRequest) ([]model.RepositoryListResponse, serror.SError)
 
 	// Create new repository by given name and url
-	AddRepository(model.AddRepositoryRequest) (model.AddRepositoryResponse, serror.SError)
+	AddRepository(context.Context, model.AddRepositoryRequest) (model.AddRepositoryResponse, serror.SError)
 
 	// Edit existing repository by given repository id.
 	// Required: repository Id.
 	// Optional: Name, Url and IsActive.
-	EditRepository(model.EditRepositoryRequest) (model.EditRepositoryResponse, serror.SError)
+	EditRepository(context.Context, model.EditRepositoryRequest) (model.EditRepositoryResponse, serror.SError)
 
 	// Delete existing repository by given repository id
-	DeleteRepository(int64) serror.SError
+	DeleteRepository(context.Context, int64) serror.SError
+
+	// Get scan settings of repository by given repository id
+	GetRepositorySettings(ctx context.Context, repo_id int64) (model.RepositorySettings, serror.SError)
+
+	// Replace scan settings of repository by given repository id
+	EditRepositorySettings(ctx context.Context, repo_id int64, settings model.RepositorySettings) (model.RepositorySettings, serror.SError)
+
+	// Get scan schedule of repository by given repository id
+	GetRepositorySchedule(ctx context.Context, repo_id int64) (model.RepositorySchedule, serror.SError)
+
+	// Replace scan schedule of repository by given repository id, empty cron expression turns it off
+	EditRepositorySchedule(ctx context.Context, repo_id int64, req model.EditRepositoryScheduleRequest) (model.RepositorySchedule, serror.SError)
 }
 
 type IScanningUsecase interface {
 	// Get list of recently scanned
-	GetScanningList(model.ScanningListRequest) ([]model.ScanningListResponse, serror.SError)
+	GetScanningList(context.Context, model.ScanningListRequest) ([]model.ScanningListResponse, serror.SError)
 
 	// Create new scanning by given active repository id
-	AddNewScanning(int64) (model.ScanningResponse, serror.SError)
+	AddNewScanning(context.Context, model.AddScanningRequest) (model.ScanningResponse, serror.SError)
+
+	// Extract uploaded archive and queue its scanning under a synthetic repository
+	AddUploadScanning(context.Context, model.AddUploadScanningRequest) (model.ScanningResponse, serror.SError)
+
+	// Get list of findings of a scanning by given filters
+	GetFindingList(context.Context, model.FindingListRequest) ([]model.FindingListResponse, serror.SError)
+
+	// Change triage of a finding, which carries over to findings of the same fingerprint in later scannings
+	TriageFinding(context.Context, model.TriageFindingRequest) (model.FindingTriageResponse, serror.SError)
+
+	// Add comment to thread of a finding, which is shared by findings of the same fingerprint
+	AddFindingComment(context.Context, model.AddFindingCommentRequest) (model.FindingComment, serror.SError)
+
+	// Get comment thread of a finding by given scanning id and finding id
+	GetFindingComments(ctx context.Context, scanning_id int64, finding_id string) ([]model.FindingComment, serror.SError)
+
+	// Cancel queued or in progress scanning by given scanning id
+	CancelScanning(ctx context.Context, scanning_id int64) (model.ScanningResponse, serror.SError)
+
+	// Reveal raw secret value of a finding kept encrypted, every reveal is audited
+	RevealFinding(context.Context, model.RevealFindingRequest) (model.RevealFindingResponse, serror.SError)
+
+	// Snapshot findings of a successful scanning as next version of repository baseline, which becomes the active one
+	AddBaseline(context.Context, model.AddBaselineRequest) (model.BaselineResponse, serror.SError)
+
+	// Get every version of baseline by given repository id, latest first
+	GetBaselineList(ctx context.Context, repo_id int64) ([]model.BaselineResponse, serror.SError)
+
+	// Make an existing version of repository baseline the active one, e.g. to roll it back
+	ActivateBaseline(context.Context, model.ActivateBaselineRequest) (model.BaselineResponse, serror.SError)
 
 	// Start scanning from queue
-	StartScanningInQueue() (errx serror.SError)
+	StartScanningInQueue(context.Context) (errx serror.SError)
+
+	// Look for scannings queued by other services periodically until context is done
+	StartScanningPoller(context.Context) (errx serror.SError)
+
+	// Queue scannings whose lease expired periodically again until context is done
+	StartScanningReaper(context.Context) (errx serror.SError)
+
+	// Queue scannings of repositories whose schedule is due periodically until context is done
+	StartScanningScheduler(context.Context) (errx serror.SError)
+}
+
+type IRuleUsecase interface {
+	// Get list of detection rules
+	GetRuleList(context.Context, model.RuleListRequest) ([]model.RuleListResponse, serror.SError)
+
+	// Create new detection rule
+	AddRule(context.Context, model.AddRuleRequest) (model.AddRuleResponse, serror.SError)
+
+	// Edit existing detection rule by given rule id.
+	// Required: rule Id.
+	// Optional: Name, Description, Comment, Part, Pattern and IsActive.
+	EditRule(context.Context, model.EditRuleRequest) (model.EditRuleResponse, serror.SError)
+
+	// Delete existing detection rule by given rule id
+	DeleteRule(context.Context, int64) serror.SError
 }
 
 type IGrabScanner interface {
-	// Start scanning session with git repository url
-	StartScanningSession(string) ([]byte, serror.SError)
+	// Start scanning session on given target with every registered scan engine
+	StartScanningSession(context.Context, model.ScanTarget) (model.ScanResult, serror.SError)
+
+	// Get name, version and capabilities of registered scan engines
+	GetScanEngines() []model.ScanEngineInfo
+}
+
+type IScanEngine interface {
+	// Get name, version and capabilities of scan engine
+	Info() model.ScanEngineInfo
+
+	// Scan given target and return typed findings
+	Scan(context.Context, model.ScanTarget) ([]model.Finding, serror.SError)
 }
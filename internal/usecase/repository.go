@@ -1,10 +1,14 @@
 package usecase
 
 import (
+	"context"
 	"net/http"
 	"repo-scanner/internal"
+	"repo-scanner/internal/constants"
 	"repo-scanner/internal/model"
 	"repo-scanner/internal/utils/serror"
+	"strings"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 )
@@ -21,8 +25,8 @@ func NewRepositoryUsecase(store internal.RepositoryStore, trxRepo internal.ITrxR
 	}
 }
 
-func (r repositoryUsecase) GetRepositoryList(req model.RepositoryListRequest) (res []model.RepositoryListResponse, errx serror.SError) {
-	res, errx = r.repositoryRepository.GetRepositoryList(req)
+func (r repositoryUsecase) GetRepositoryList(ctx context.Context, req model.RepositoryListRequest) (res []model.RepositoryListResponse, errx serror.SError) {
+	res, errx = r.repositoryRepository.GetRepositoryList(ctx, req)
 	if errx != nil {
 		errx.AddComments("[usecase][GetRepositoryList] while get repository list")
 		return
@@ -30,9 +34,9 @@ func (r repositoryUsecase) GetRepositoryList(req model.RepositoryListRequest) (r
 	return
 }
 
-func (r repositoryUsecase) AddRepository(req model.AddRepositoryRequest) (res model.AddRepositoryResponse, errx serror.SError) {
+func (r repositoryUsecase) AddRepository(ctx context.Context, req model.AddRepositoryRequest) (res model.AddRepositoryResponse, errx serror.SError) {
 	var tx *model.Trx
-	tx, errx = r.trxRepository.Create()
+	tx, errx = r.trxRepository.Create(ctx)
 	if errx != nil {
 		errx.AddComments("[usecase][AddRepository] while create new transaction")
 		return
@@ -46,7 +50,7 @@ func (r repositoryUsecase) AddRepository(req model.AddRepositoryRequest) (res mo
 		}
 	}()
 
-	res, errx = r.repositoryRepository.AddRepository(tx, req)
+	res, errx = r.repositoryRepository.AddRepository(ctx, tx, req)
 	if errx != nil {
 		errx.AddComments("[usecase][AddRepository] while add repository")
 		return
@@ -63,7 +67,7 @@ func (r repositoryUsecase) AddRepository(req model.AddRepositoryRequest) (res mo
 	return
 }
 
-func (r repositoryUsecase) EditRepository(req model.EditRepositoryRequest) (res model.EditRepositoryResponse, errx serror.SError) {
+func (r repositoryUsecase) EditRepository(ctx context.Context, req model.EditRepositoryRequest) (res model.EditRepositoryResponse, errx serror.SError) {
 	if req.Name == nil &&
 		req.Url == nil &&
 		req.IsActive == nil {
@@ -72,7 +76,7 @@ func (r repositoryUsecase) EditRepository(req model.EditRepositoryRequest) (res
 	}
 
 	var originalRepo *model.Repository
-	originalRepo, errx = r.repositoryRepository.GetRepositoryById(req.Id)
+	originalRepo, errx = r.repositoryRepository.GetRepositoryById(ctx, req.Id)
 	if errx != nil {
 		errx.AddCommentf("[usecase][EditRepository] while GetRepositoryById (repository_id: %v)", req.Id)
 		return
@@ -96,7 +100,7 @@ func (r repositoryUsecase) EditRepository(req model.EditRepositoryRequest) (res
 	}
 
 	var tx *model.Trx
-	tx, errx = r.trxRepository.Create()
+	tx, errx = r.trxRepository.Create(ctx)
 	if errx != nil {
 		errx.AddComments("[usecase][EditRepository] while create new transaction")
 		return
@@ -110,7 +114,7 @@ func (r repositoryUsecase) EditRepository(req model.EditRepositoryRequest) (res
 		}
 	}()
 
-	res, errx = r.repositoryRepository.EditRepository(tx, req)
+	res, errx = r.repositoryRepository.EditRepository(ctx, tx, req)
 	if errx != nil {
 		errx.AddCommentf("[usecase][EditRepository] while EditRepository (repository_id: %v)", req.Id)
 		return
@@ -126,9 +130,9 @@ func (r repositoryUsecase) EditRepository(req model.EditRepositoryRequest) (res
 	return
 }
 
-func (r repositoryUsecase) DeleteRepository(repo_id int64) (errx serror.SError) {
+func (r repositoryUsecase) DeleteRepository(ctx context.Context, repo_id int64) (errx serror.SError) {
 	var repo *model.Repository
-	repo, errx = r.repositoryRepository.GetRepositoryById(repo_id)
+	repo, errx = r.repositoryRepository.GetRepositoryById(ctx, repo_id)
 	if errx != nil {
 		errx.AddCommentf("[usecase][DeleteRepository] while GetRepositoryById (repository_id: %v)", repo_id)
 		return
@@ -138,7 +142,7 @@ func (r repositoryUsecase) DeleteRepository(repo_id int64) (errx serror.SError)
 	}
 
 	var tx *model.Trx
-	tx, errx = r.trxRepository.Create()
+	tx, errx = r.trxRepository.Create(ctx)
 	if errx != nil {
 		errx.AddComments("[usecase][DeleteRepository] while create new transaction")
 		return
@@ -152,7 +156,7 @@ func (r repositoryUsecase) DeleteRepository(repo_id int64) (errx serror.SError)
 		}
 	}()
 
-	errx = r.repositoryRepository.DeleteRepository(tx, repo_id)
+	errx = r.repositoryRepository.DeleteRepository(ctx, tx, repo_id)
 	if errx != nil {
 		errx.AddCommentf("[usecase][DeleteRepository] while DeleteRepository (repository_id: %v)", repo_id)
 		return
@@ -167,3 +171,91 @@ func (r repositoryUsecase) DeleteRepository(repo_id int64) (errx serror.SError)
 	}
 	return
 }
+
+func (r repositoryUsecase) GetRepositorySettings(ctx context.Context, repo_id int64) (res model.RepositorySettings, errx serror.SError) {
+	var settings *model.RepositorySettings
+	settings, errx = r.repositoryRepository.GetRepositorySettings(ctx, repo_id)
+	if errx != nil {
+		errx.AddCommentf("[usecase][GetRepositorySettings] while GetRepositorySettings (repository_id: %v)", repo_id)
+		return
+	} else if settings == nil {
+		errx = serror.Newi(http.StatusBadRequest, "Repository not found|Repository not found")
+		return
+	}
+	return *settings, nil
+}
+
+func (r repositoryUsecase) EditRepositorySettings(ctx context.Context, repo_id int64, req model.RepositorySettings) (res model.RepositorySettings, errx serror.SError) {
+	// Extensions are kept without leading dot, blank globs and extensions are dropped
+	settings := req
+	settings.SkipPaths = []string{}
+	for _, glob := range req.SkipPaths {
+		if glob = strings.TrimSpace(glob); glob != "" {
+			settings.SkipPaths = append(settings.SkipPaths, glob)
+		}
+	}
+	settings.SkipExtensions = []string{}
+	for _, ext := range req.SkipExtensions {
+		if ext = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(ext), ".")); ext != "" {
+			settings.SkipExtensions = append(settings.SkipExtensions, ext)
+		}
+	}
+
+	var updated *model.RepositorySettings
+	updated, errx = r.repositoryRepository.EditRepositorySettings(ctx, nil, repo_id, settings)
+	if errx != nil {
+		errx.AddCommentf("[usecase][EditRepositorySettings] while EditRepositorySettings (repository_id: %v)", repo_id)
+		return
+	} else if updated == nil {
+		errx = serror.Newi(http.StatusBadRequest, "Repository not found|Repository not found")
+		return
+	}
+	return *updated, nil
+}
+
+func (r repositoryUsecase) GetRepositorySchedule(ctx context.Context, repo_id int64) (res model.RepositorySchedule, errx serror.SError) {
+	var schedule *model.RepositorySchedule
+	schedule, errx = r.repositoryRepository.GetRepositorySchedule(ctx, repo_id)
+	if errx != nil {
+		errx.AddCommentf("[usecase][GetRepositorySchedule] while GetRepositorySchedule (repository_id: %v)", repo_id)
+		return
+	} else if schedule == nil {
+		errx = serror.Newi(http.StatusBadRequest, "Repository not found|Repository not found")
+		return
+	}
+	return *schedule, nil
+}
+
+func (r repositoryUsecase) EditRepositorySchedule(ctx context.Context, repo_id int64, req model.EditRepositoryScheduleRequest) (res model.RepositorySchedule, errx serror.SError) {
+	schedule := model.RepositorySchedule{
+		ScanSchedule: strings.TrimSpace(req.ScanSchedule),
+		ScanTimezone: strings.TrimSpace(req.ScanTimezone),
+	}
+
+	// Next run is computed in timezone of schedule, but stored in default one as other timestamps
+	if schedule.ScanSchedule != "" {
+		if schedule.ScanTimezone == "" {
+			schedule.ScanTimezone = constants.DefaultTimezone
+		}
+		var next time.Time
+		next, errx = nextScheduledScan(schedule.ScanSchedule, schedule.ScanTimezone)
+		if errx != nil {
+			errx.AddCommentf("[usecase][EditRepositorySchedule] while get next scheduled scan (repository_id: %v)", repo_id)
+			return
+		}
+		schedule.NextScheduledScanAt = &next
+	} else {
+		schedule.ScanTimezone = ""
+	}
+
+	var updated *model.RepositorySchedule
+	updated, errx = r.repositoryRepository.EditRepositorySchedule(ctx, nil, repo_id, schedule)
+	if errx != nil {
+		errx.AddCommentf("[usecase][EditRepositorySchedule] while EditRepositorySchedule (repository_id: %v)", repo_id)
+		return
+	} else if updated == nil {
+		errx = serror.Newi(http.StatusBadRequest, "Repository not found|Repository not found")
+		return
+	}
+	return *updated, nil
+}
@@ -1,8 +1,11 @@
 package usecase
 
 import (
+	"context"
 	"errors"
+	"net/http"
 	"testing"
+	"time"
 
 	"repo-scanner/internal/mocks"
 	"repo-scanner/internal/model"
@@ -35,7 +38,7 @@ func TestGetRepositoryList(t *testing.T) {
 					},
 				}
 
-				repoMock.On("GetRepositoryList", mock.Anything).Return(w, nil).Once()
+				repoMock.On("GetRepositoryList", mock.Anything, mock.Anything).Return(w, nil).Once()
 			},
 			args: model.RepositoryListRequest{
 				Limit: 1,
@@ -57,7 +60,7 @@ func TestGetRepositoryList(t *testing.T) {
 				e := errors.New("error")
 				w := serror.NewFromErrorc(e, "[usecase][GetRepositoryList] get repository list")
 
-				repoMock.On("GetRepositoryList", mock.Anything).
+				repoMock.On("GetRepositoryList", mock.Anything, mock.Anything).
 					Return([]model.RepositoryListResponse{}, w).Once()
 			},
 			want:    []model.RepositoryListResponse{},
@@ -70,7 +73,7 @@ func TestGetRepositoryList(t *testing.T) {
 
 		repoUsecase := repositoryUsecase{repositoryRepository: repoMock}
 
-		res, err := repoUsecase.GetRepositoryList(test.args)
+		res, err := repoUsecase.GetRepositoryList(context.Background(), test.args)
 
 		if (err != nil) != test.wantErr {
 			t.Errorf("GetRepositoryList() got error : %s", err)
@@ -117,7 +120,7 @@ func TestAddRepository(t *testing.T) {
 					DB: &sqlx.DB{},
 				}
 
-				repoMock.On("AddRepository", &tx, args).Return(w, nil).Once()
+				repoMock.On("AddRepository", mock.Anything, &tx, args).Return(w, nil).Once()
 				trxMock.On("Create", mock.Anything).Return(&tx, nil).Once()
 				txMock.On("Admit", mock.Anything).Return(nil).Once()
 			},
@@ -143,7 +146,7 @@ func TestAddRepository(t *testing.T) {
 			trxRepository:        trxMock,
 		}
 
-		res, err := repoUsecase.AddRepository(test.args)
+		res, err := repoUsecase.AddRepository(context.Background(), test.args)
 
 		if (err != nil) != test.wantErr {
 			t.Errorf("AddRepository() got error : %s", err)
@@ -200,8 +203,8 @@ func TestEditRepository(t *testing.T) {
 					DB: &sqlx.DB{},
 				}
 
-				repoMock.On("GetRepositoryById", mock.Anything).Return(&o, nil).Once()
-				repoMock.On("EditRepository", &tx, args).Return(w, nil).Once()
+				repoMock.On("GetRepositoryById", mock.Anything, mock.Anything).Return(&o, nil).Once()
+				repoMock.On("EditRepository", mock.Anything, &tx, args).Return(w, nil).Once()
 				trxMock.On("Create", mock.Anything).Return(&tx, nil).Once()
 				txMock.On("Admit", mock.Anything).Return(nil).Once()
 			},
@@ -229,7 +232,7 @@ func TestEditRepository(t *testing.T) {
 			trxRepository:        trxMock,
 		}
 
-		res, err := repoUsecase.EditRepository(test.args)
+		res, err := repoUsecase.EditRepository(context.Background(), test.args)
 
 		if (err != nil) != test.wantErr {
 			t.Errorf("EditRepository() got error : %s", err)
@@ -246,3 +249,90 @@ func TestEditRepository(t *testing.T) {
 		}
 	}
 }
+
+func TestEditRepositorySettings(t *testing.T) {
+	repoMock := new(mocks.IRepositoryRepository)
+
+	normalized := model.RepositorySettings{
+		SkipPaths:      []string{"docs/**"},
+		SkipExtensions: []string{"csv", "min.js"},
+		Threads:        4,
+	}
+	repoMock.On("EditRepositorySettings", mock.Anything, mock.Anything, int64(3), normalized).Return(&normalized, nil).Once()
+	repoMock.On("EditRepositorySettings", mock.Anything, mock.Anything, int64(4), model.RepositorySettings{
+		SkipPaths:      []string{},
+		SkipExtensions: []string{},
+	}).Return(nil, nil).Once()
+
+	repoUsecase := repositoryUsecase{
+		repositoryRepository: repoMock,
+	}
+
+	// Blank entries are dropped, extensions are kept lower case without leading dot
+	res, err := repoUsecase.EditRepositorySettings(context.Background(), 3, model.RepositorySettings{
+		SkipPaths:      []string{" docs/** ", ""},
+		SkipExtensions: []string{".CSV", "min.js", " "},
+		Threads:        4,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, normalized, res)
+
+	_, err = repoUsecase.EditRepositorySettings(context.Background(), 4, model.RepositorySettings{})
+	assert.NotNil(t, err)
+	assert.Equal(t, http.StatusBadRequest, err.Code())
+	repoMock.AssertExpectations(t)
+}
+
+func TestEditRepositorySchedule(t *testing.T) {
+	repoMock := new(mocks.IRepositoryRepository)
+
+	isSchedule := func(expr string, zone string) interface{} {
+		return mock.MatchedBy(func(schedule model.RepositorySchedule) bool {
+			return schedule.ScanSchedule == expr && schedule.ScanTimezone == zone &&
+				schedule.NextScheduledScanAt != nil && schedule.NextScheduledScanAt.After(time.Now())
+		})
+	}
+	repoMock.On("EditRepositorySchedule", mock.Anything, mock.Anything, int64(3), isSchedule("30 2 * * MON-FRI", "Asia/Jakarta")).
+		Return(&model.RepositorySchedule{ScanSchedule: "30 2 * * MON-FRI", ScanTimezone: "Asia/Jakarta"}, nil).Once()
+	repoMock.On("EditRepositorySchedule", mock.Anything, mock.Anything, int64(4), isSchedule("@daily", "+7")).
+		Return(&model.RepositorySchedule{ScanSchedule: "@daily", ScanTimezone: "+7"}, nil).Once()
+	// Empty schedule turns it off
+	repoMock.On("EditRepositorySchedule", mock.Anything, mock.Anything, int64(5), model.RepositorySchedule{}).
+		Return(&model.RepositorySchedule{}, nil).Once()
+	repoMock.On("EditRepositorySchedule", mock.Anything, mock.Anything, int64(6), model.RepositorySchedule{}).
+		Return(nil, nil).Once()
+
+	repoUsecase := repositoryUsecase{
+		repositoryRepository: repoMock,
+	}
+
+	res, err := repoUsecase.EditRepositorySchedule(context.Background(), 3, model.EditRepositoryScheduleRequest{
+		ScanSchedule: " 30 2 * * MON-FRI ",
+		ScanTimezone: "Asia/Jakarta",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "30 2 * * MON-FRI", res.ScanSchedule)
+
+	// Timezone defaults to DefaultTimezone
+	res, err = repoUsecase.EditRepositorySchedule(context.Background(), 4, model.EditRepositoryScheduleRequest{ScanSchedule: "@daily"})
+	assert.Nil(t, err)
+	assert.Equal(t, "+7", res.ScanTimezone)
+
+	_, err = repoUsecase.EditRepositorySchedule(context.Background(), 5, model.EditRepositoryScheduleRequest{ScanTimezone: "UTC"})
+	assert.Nil(t, err)
+
+	_, err = repoUsecase.EditRepositorySchedule(context.Background(), 6, model.EditRepositoryScheduleRequest{})
+	assert.NotNil(t, err)
+	assert.Equal(t, http.StatusBadRequest, err.Code())
+
+	for _, req := range []model.EditRepositoryScheduleRequest{
+		{ScanSchedule: "every day"},
+		{ScanSchedule: "0 2 * * *", ScanTimezone: "Mars/Olympus"},
+		{ScanSchedule: "0 0 31 2 *"},
+	} {
+		_, err = repoUsecase.EditRepositorySchedule(context.Background(), 7, req)
+		assert.NotNil(t, err)
+		assert.Equal(t, http.StatusBadRequest, err.Code())
+	}
+	repoMock.AssertExpectations(t)
+}
@@ -0,0 +1,121 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"repo-scanner/internal/utils/serror"
+
+	"gopkg.in/src-d/go-git.v4/plumbing/transport"
+)
+
+// Errors of git transport which are permanent, scanning fails at once since attempting it again gives the same result
+var permanentGitErrors = []error{
+	transport.ErrRepositoryNotFound,
+	transport.ErrEmptyRemoteRepository,
+	transport.ErrAuthenticationRequired,
+	transport.ErrAuthorizationFailed,
+	transport.ErrInvalidAuthMethod,
+}
+
+// Errors mentioning these are permanent as well, e.g. errors of grab session which reach the worker as text only
+var permanentScanErrors = []string{
+	"repository not found",
+	"404 not found",
+	"does not exist",
+	"authentication required",
+	"authorization failed",
+	"unauthorized",
+	"forbidden",
+	"permission denied",
+	"couldn't find remote ref",
+}
+
+// Errors mentioning these are transient, e.g. git provider is unreachable, overloaded or rate limits
+var retryableScanErrors = []string{
+	"timeout",
+	"timed out",
+	"connection refused",
+	"connection reset",
+	"broken pipe",
+	"unexpected eof",
+	"temporary failure",
+	"tls handshake",
+	"too many requests",
+	"rate limit",
+	"internal server error",
+	"bad gateway",
+	"service unavailable",
+	"gateway timeout",
+	"server misbehaving",
+}
+
+// retryableScanError tells whether scanning failing with errx may succeed once it is attempted again,
+// errors are permanent unless they are known to be transient
+func retryableScanError(errx serror.SError) bool {
+	if errx == nil {
+		return false
+	}
+
+	cause := errx.Cause()
+	if errors.Is(cause, context.Canceled) || errors.Is(cause, context.DeadlineExceeded) {
+		return false
+	}
+	for _, permanent := range permanentGitErrors {
+		if errors.Is(cause, permanent) {
+			return false
+		}
+	}
+	// Host which is unknown for sure stays so, while failing lookup of it may succeed later
+	var dnsErr *net.DNSError
+	if errors.As(cause, &dnsErr) {
+		return dnsErr.IsTimeout || dnsErr.IsTemporary || !dnsErr.IsNotFound
+	}
+	var netErr net.Error
+	if errors.As(cause, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	message := strings.ToLower(errx.Error())
+	for _, permanent := range permanentScanErrors {
+		if strings.Contains(message, permanent) {
+			return false
+		}
+	}
+	for _, retryable := range retryableScanErrors {
+		if strings.Contains(message, retryable) {
+			return true
+		}
+	}
+	return false
+}
+
+var backoffJitter = struct {
+	sync.Mutex
+	*rand.Rand
+}{Rand: rand.New(rand.NewSource(time.Now().UnixNano()))}
+
+// retryBackoff returns delay before next attempt of scanning attempted given times, base doubled on every attempt up to max.
+// Half of the delay is jittered by jitter in [0, 1) so that scannings failing together are not retried together
+func retryBackoff(base time.Duration, max time.Duration, attempts int, jitter float64) time.Duration {
+	delay := base
+	for i := 1; i < attempts && delay < max; i++ {
+		delay *= 2
+	}
+	if max > 0 && delay > max {
+		delay = max
+	}
+	return delay/2 + time.Duration(jitter*float64(delay/2))
+}
+
+// randomJitter returns jitter of retryBackoff
+func randomJitter() float64 {
+	backoffJitter.Lock()
+	defer backoffJitter.Unlock()
+	return backoffJitter.Float64()
+}
@@ -0,0 +1,72 @@
+package usecase
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"repo-scanner/internal/utils/serror"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport"
+)
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o deadline reached" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestRetryableScanError(t *testing.T) {
+	listTests := []struct {
+		name string
+		errx serror.SError
+		want bool
+	}{
+		{name: "nil", errx: nil, want: false},
+		{name: "connection refused", errx: serror.New("dial tcp 10.0.0.1:443: connect: connection refused"), want: true},
+		{name: "rate limited", errx: serror.New("429 Too Many Requests"), want: true},
+		{name: "provider unavailable", errx: serror.New("503 Service Unavailable"), want: true},
+		{name: "network timeout", errx: serror.NewFromError(timeoutError{}), want: true},
+		{name: "cancelled", errx: serror.NewFromError(context.Canceled), want: false},
+		{name: "timed out scanning", errx: serror.NewFromError(context.DeadlineExceeded), want: false},
+		{name: "repository not found", errx: serror.New("repository not found"), want: false},
+		{name: "authentication", errx: serror.New("authentication required"), want: false},
+		{name: "git repository not found", errx: serror.NewFromError(transport.ErrRepositoryNotFound), want: false},
+		{name: "git authentication", errx: serror.NewFromError(transport.ErrAuthenticationRequired), want: false},
+		{name: "unknown host", errx: serror.NewFromError(&net.DNSError{Err: "no such host", Name: "github.local", IsNotFound: true}), want: false},
+		{name: "host lookup failing", errx: serror.NewFromError(&net.DNSError{Err: "server misbehaving", Name: "github.com", IsTemporary: true}), want: true},
+		{name: "host lookup timed out", errx: serror.NewFromError(&net.DNSError{Err: "i/o timeout", Name: "github.com", IsTimeout: true}), want: true},
+		// Transient errors mentioning words of permanent ones are not permanent
+		{name: "truncated git response", errx: serror.New("invalid pkt-len found, connection reset by peer"), want: true},
+		{name: "unknown host timed out", errx: serror.New("lookup github.com: no such host, timed out"), want: true},
+		// Permanent error wins over transient one mentioned along
+		{name: "repository not found timed out", errx: serror.New("repository not found, timed out"), want: false},
+		{name: "unknown", errx: serror.New("exit status 128"), want: false},
+	}
+
+	for _, test := range listTests {
+		assert.Equal(t, test.want, retryableScanError(test.errx), test.name)
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	base, max := 30*time.Second, 5*time.Minute
+
+	// Delay is doubled on every attempt, half of it is jittered
+	assert.Equal(t, 15*time.Second, retryBackoff(base, max, 1, 0))
+	assert.Equal(t, 30*time.Second, retryBackoff(base, max, 1, 1))
+	assert.Equal(t, 45*time.Second, retryBackoff(base, max, 2, 0.5))
+	assert.Equal(t, 90*time.Second, retryBackoff(base, max, 3, 0.5))
+
+	// Delay never exceeds max
+	assert.Equal(t, 5*time.Minute, retryBackoff(base, max, 10, 1))
+	assert.Equal(t, 5*time.Minute, retryBackoff(base, max, 1000, 1))
+
+	// No backoff retries at once
+	assert.Equal(t, time.Duration(0), retryBackoff(0, max, 3, 0.5))
+
+	jitter := randomJitter()
+	assert.True(t, jitter >= 0 && jitter < 1)
+}
@@ -0,0 +1,194 @@
+package usecase
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"repo-scanner/internal"
+	"repo-scanner/internal/model"
+	"repo-scanner/internal/utils/serror"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type ruleUsecase struct {
+	ruleRepository internal.IRuleRepository
+	trxRepository  internal.ITrxRepository
+}
+
+func NewRuleUsecase(store internal.RepositoryStore, trxRepo internal.ITrxRepository) internal.IRuleUsecase {
+	return ruleUsecase{
+		ruleRepository: store.RuleRepo,
+		trxRepository:  trxRepo,
+	}
+}
+
+func (r ruleUsecase) GetRuleList(ctx context.Context, req model.RuleListRequest) (res []model.RuleListResponse, errx serror.SError) {
+	res, errx = r.ruleRepository.GetRuleList(ctx, req)
+	if errx != nil {
+		errx.AddComments("[usecase][GetRuleList] while get rule list")
+		return
+	}
+	return
+}
+
+func (r ruleUsecase) AddRule(ctx context.Context, req model.AddRuleRequest) (res model.AddRuleResponse, errx serror.SError) {
+	if _, err := regexp.Compile(req.Pattern); err != nil {
+		errx = serror.Newi(http.StatusBadRequest, "Invalid rule pattern|Invalid rule pattern")
+		errx.AddCommentf("[usecase][AddRule] while compile pattern: %v", err)
+		return
+	}
+
+	var tx *model.Trx
+	tx, errx = r.trxRepository.Create(ctx)
+	if errx != nil {
+		errx.AddComments("[usecase][AddRule] while create new transaction")
+		return
+	}
+	defer func() {
+		if errx != nil {
+			errs := tx.Abort()
+			if errs != nil {
+				log.Error("[usecase][AddRule] Failed to rollback")
+			}
+		}
+	}()
+
+	res, errx = r.ruleRepository.AddRule(ctx, tx, req)
+	if errx != nil {
+		errx.AddComments("[usecase][AddRule] while add rule")
+		return
+	}
+
+	if errx == nil {
+		err := tx.Admit()
+		if err != nil {
+			errx = serror.NewFromError(err)
+			errx.AddCommentf("[usecase][AddRule] Failed to commit transaction")
+			return
+		}
+	}
+	return
+}
+
+func (r ruleUsecase) EditRule(ctx context.Context, req model.EditRuleRequest) (res model.EditRuleResponse, errx serror.SError) {
+	if req.Name == nil &&
+		req.Description == nil &&
+		req.Comment == nil &&
+		req.Part == nil &&
+		req.Pattern == nil &&
+		req.IsActive == nil {
+		errx = serror.Newi(http.StatusNotAcceptable, "Nothing to update|Nothing to update")
+		return
+	}
+
+	if req.Pattern != nil {
+		if _, err := regexp.Compile(*req.Pattern); err != nil {
+			errx = serror.Newi(http.StatusBadRequest, "Invalid rule pattern|Invalid rule pattern")
+			errx.AddCommentf("[usecase][EditRule] while compile pattern: %v", err)
+			return
+		}
+	}
+
+	var originalRule *model.Rule
+	originalRule, errx = r.ruleRepository.GetRuleById(ctx, req.Id)
+	if errx != nil {
+		errx.AddCommentf("[usecase][EditRule] while GetRuleById (rule_id: %v)", req.Id)
+		return
+	} else if originalRule == nil {
+		errx = serror.Newi(http.StatusBadRequest, "Rule not found|Rule not found")
+		return
+	}
+
+	var is_duplicate = true
+	switch {
+	case req.Name != nil && originalRule.Name != *req.Name:
+		is_duplicate = false
+	case req.Description != nil && originalRule.Description != *req.Description:
+		is_duplicate = false
+	case req.Comment != nil && originalRule.Comment != *req.Comment:
+		is_duplicate = false
+	case req.Part != nil && originalRule.Part != *req.Part:
+		is_duplicate = false
+	case req.Pattern != nil && originalRule.Pattern != *req.Pattern:
+		is_duplicate = false
+	case req.IsActive != nil && originalRule.IsActive != *req.IsActive:
+		is_duplicate = false
+	}
+	if is_duplicate {
+		errx = serror.Newi(http.StatusNotAcceptable, "Nothing to update|Nothing to update")
+		return
+	}
+
+	var tx *model.Trx
+	tx, errx = r.trxRepository.Create(ctx)
+	if errx != nil {
+		errx.AddComments("[usecase][EditRule] while create new transaction")
+		return
+	}
+	defer func() {
+		if errx != nil {
+			errs := tx.Abort()
+			if errs != nil {
+				log.Error("[usecase][EditRule] Failed to rollback")
+			}
+		}
+	}()
+
+	res, errx = r.ruleRepository.EditRule(ctx, tx, req)
+	if errx != nil {
+		errx.AddCommentf("[usecase][EditRule] while EditRule (rule_id: %v)", req.Id)
+		return
+	}
+	if errx == nil {
+		err := tx.Admit()
+		if err != nil {
+			errx = serror.NewFromError(err)
+			errx.AddCommentf("[usecase][EditRule] Failed to commit transaction")
+			return
+		}
+	}
+	return
+}
+
+func (r ruleUsecase) DeleteRule(ctx context.Context, rule_id int64) (errx serror.SError) {
+	var rule *model.Rule
+	rule, errx = r.ruleRepository.GetRuleById(ctx, rule_id)
+	if errx != nil {
+		errx.AddCommentf("[usecase][DeleteRule] while GetRuleById (rule_id: %v)", rule_id)
+		return
+	} else if rule == nil {
+		errx = serror.Newi(http.StatusBadRequest, "Rule not found|Rule not found")
+		return
+	}
+
+	var tx *model.Trx
+	tx, errx = r.trxRepository.Create(ctx)
+	if errx != nil {
+		errx.AddComments("[usecase][DeleteRule] while create new transaction")
+		return
+	}
+	defer func() {
+		if errx != nil {
+			errs := tx.Abort()
+			if errs != nil {
+				log.Error("[usecase][DeleteRule] Failed to rollback")
+			}
+		}
+	}()
+
+	errx = r.ruleRepository.DeleteRule(ctx, tx, rule_id)
+	if errx != nil {
+		errx.AddCommentf("[usecase][DeleteRule] while DeleteRule (rule_id: %v)", rule_id)
+		return
+	}
+	if errx == nil {
+		err := tx.Admit()
+		if err != nil {
+			errx = serror.NewFromError(err)
+			errx.AddCommentf("[usecase][DeleteRule] Failed to commit transaction")
+			return
+		}
+	}
+	return
+}
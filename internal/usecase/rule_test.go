@@ -0,0 +1,217 @@
+package usecase
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"repo-scanner/internal/mocks"
+	"repo-scanner/internal/model"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAddRule(t *testing.T) {
+	ruleMock := new(mocks.IRuleRepository)
+	trxMock := new(mocks.ITrxRepository)
+
+	listTests := []struct {
+		name     string
+		mock     func()
+		args     model.AddRuleRequest
+		want     model.AddRuleResponse
+		wantErr  bool
+		wantCode int
+	}{
+		{
+			name: "ok",
+			mock: func() {
+				args := model.AddRuleRequest{
+					Name:        "aws-access-key",
+					Description: "AWS access key",
+					Part:        "content",
+					Pattern:     "AKIA[0-9A-Z]{16}",
+				}
+				w := model.AddRuleResponse{
+					Id:          1,
+					Name:        "aws-access-key",
+					Description: "AWS access key",
+					Part:        "content",
+					Pattern:     "AKIA[0-9A-Z]{16}",
+					IsActive:    true,
+				}
+				tx := model.Trx{
+					DB: &sqlx.DB{},
+				}
+
+				ruleMock.On("AddRule", mock.Anything, &tx, args).Return(w, nil).Once()
+				trxMock.On("Create", mock.Anything).Return(&tx, nil).Once()
+			},
+			args: model.AddRuleRequest{
+				Name:        "aws-access-key",
+				Description: "AWS access key",
+				Part:        "content",
+				Pattern:     "AKIA[0-9A-Z]{16}",
+			},
+			want: model.AddRuleResponse{
+				Id:          1,
+				Name:        "aws-access-key",
+				Description: "AWS access key",
+				Part:        "content",
+				Pattern:     "AKIA[0-9A-Z]{16}",
+				IsActive:    true,
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid pattern",
+			mock: func() {},
+			args: model.AddRuleRequest{
+				Name:        "broken",
+				Description: "Broken pattern",
+				Part:        "content",
+				Pattern:     "AKIA[0-9A-Z",
+			},
+			want:     model.AddRuleResponse{},
+			wantErr:  true,
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, test := range listTests {
+		test.mock()
+
+		ruleUsecase := ruleUsecase{
+			ruleRepository: ruleMock,
+			trxRepository:  trxMock,
+		}
+
+		res, err := ruleUsecase.AddRule(context.Background(), test.args)
+
+		if (err != nil) != test.wantErr {
+			t.Errorf("AddRule() got error : %s", err)
+		}
+
+		if test.wantErr {
+			assert.Equal(t, test.want, res)
+			assert.Equal(t, test.wantCode, err.Code())
+		}
+
+		if !test.wantErr {
+			assert.Equal(t, test.want, res)
+			assert.Equal(t, nil, err)
+		}
+	}
+}
+
+func TestEditRule(t *testing.T) {
+	ruleMock := new(mocks.IRuleRepository)
+	trxMock := new(mocks.ITrxRepository)
+
+	isActive := false
+	original := model.Rule{
+		Id:          1,
+		Name:        "aws-access-key",
+		Description: "AWS access key",
+		Part:        "content",
+		Pattern:     "AKIA[0-9A-Z]{16}",
+		IsActive:    true,
+	}
+
+	listTests := []struct {
+		name     string
+		mock     func()
+		args     model.EditRuleRequest
+		want     model.EditRuleResponse
+		wantErr  bool
+		wantCode int
+	}{
+		{
+			name: "ok",
+			mock: func() {
+				args := model.EditRuleRequest{
+					Id:       1,
+					IsActive: &isActive,
+				}
+				w := model.EditRuleResponse{
+					Id:          1,
+					Name:        "aws-access-key",
+					Description: "AWS access key",
+					Part:        "content",
+					Pattern:     "AKIA[0-9A-Z]{16}",
+					IsActive:    false,
+				}
+				tx := model.Trx{
+					DB: &sqlx.DB{},
+				}
+
+				ruleMock.On("GetRuleById", mock.Anything, int64(1)).Return(&original, nil).Once()
+				ruleMock.On("EditRule", mock.Anything, &tx, args).Return(w, nil).Once()
+				trxMock.On("Create", mock.Anything).Return(&tx, nil).Once()
+			},
+			args: model.EditRuleRequest{
+				Id:       1,
+				IsActive: &isActive,
+			},
+			want: model.EditRuleResponse{
+				Id:          1,
+				Name:        "aws-access-key",
+				Description: "AWS access key",
+				Part:        "content",
+				Pattern:     "AKIA[0-9A-Z]{16}",
+				IsActive:    false,
+			},
+			wantErr: false,
+		},
+		{
+			name: "nothing to update",
+			mock: func() {},
+			args: model.EditRuleRequest{
+				Id: 1,
+			},
+			want:     model.EditRuleResponse{},
+			wantErr:  true,
+			wantCode: http.StatusNotAcceptable,
+		},
+		{
+			name: "not found",
+			mock: func() {
+				ruleMock.On("GetRuleById", mock.Anything, int64(2)).Return(nil, nil).Once()
+			},
+			args: model.EditRuleRequest{
+				Id:       2,
+				IsActive: &isActive,
+			},
+			want:     model.EditRuleResponse{},
+			wantErr:  true,
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, test := range listTests {
+		test.mock()
+
+		ruleUsecase := ruleUsecase{
+			ruleRepository: ruleMock,
+			trxRepository:  trxMock,
+		}
+
+		res, err := ruleUsecase.EditRule(context.Background(), test.args)
+
+		if (err != nil) != test.wantErr {
+			t.Errorf("EditRule() got error : %s", err)
+		}
+
+		if test.wantErr {
+			assert.Equal(t, test.want, res)
+			assert.Equal(t, test.wantCode, err.Code())
+		}
+
+		if !test.wantErr {
+			assert.Equal(t, test.want, res)
+			assert.Equal(t, nil, err)
+		}
+	}
+}
@@ -1,12 +1,19 @@
 package usecase
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"repo-scanner/internal"
 	"repo-scanner/internal/constants"
 	"repo-scanner/internal/model"
 	"repo-scanner/internal/utils/serror"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/jmoiron/sqlx/types"
@@ -16,23 +23,39 @@ import (
 type scanningUsecase struct {
 	repositoryRepository internal.IRepositoryRepository
 	scanningRepository   internal.IScanningRepository
+	archiveRepository    internal.IArchiveRepository
+	secretRepository     internal.ISecretRepository
 	trxRepository        internal.ITrxRepository
 	grabScanner          internal.IGrabScanner
-	scanningInProgress   *bool
+	option               model.ScanningOption
+	workers              *scanWorkers
+	runningScans         *runningScans
 }
 
-func NewScanningUsecase(store internal.RepositoryStore, trxRepo internal.ITrxRepository, grabScanner internal.IGrabScanner) internal.IScanningUsecase {
+func NewScanningUsecase(store internal.RepositoryStore, trxRepo internal.ITrxRepository, grabScanner internal.IGrabScanner,
+	option model.ScanningOption) internal.IScanningUsecase {
+	// No pool is started without workers, e.g. by API only service, queued scannings are left to other services
+	if option.Workers < 0 {
+		option.Workers = 0
+	}
+	if option.MaxAttempts < 1 {
+		option.MaxAttempts = 1
+	}
 	return scanningUsecase{
 		repositoryRepository: store.RepositoryRepo,
 		scanningRepository:   store.ScanningRepo,
+		archiveRepository:    store.ArchiveRepo,
+		secretRepository:     store.SecretRepo,
 		trxRepository:        trxRepo,
 		grabScanner:          grabScanner,
-		scanningInProgress:   new(bool),
+		option:               option,
+		workers:              newScanWorkers(option.Workers),
+		runningScans:         newRunningScans(),
 	}
 }
 
-func (s scanningUsecase) GetScanningList(req model.ScanningListRequest) (res []model.ScanningListResponse, errx serror.SError) {
-	res, errx = s.scanningRepository.GetScanningList(req)
+func (s scanningUsecase) GetScanningList(ctx context.Context, req model.ScanningListRequest) (res []model.ScanningListResponse, errx serror.SError) {
+	res, errx = s.scanningRepository.GetScanningList(ctx, req)
 	if errx != nil {
 		errx.AddComments("[usecase][GetScanningList] while get scanning list")
 		return
@@ -41,11 +64,11 @@ func (s scanningUsecase) GetScanningList(req model.ScanningListRequest) (res []m
 	return
 }
 
-func (s scanningUsecase) AddNewScanning(repo_id int64) (res model.ScanningResponse, errx serror.SError) {
+func (s scanningUsecase) AddNewScanning(ctx context.Context, req model.AddScanningRequest) (res model.ScanningResponse, errx serror.SError) {
 	var repo *model.Repository
-	repo, errx = s.repositoryRepository.GetRepositoryById(repo_id)
+	repo, errx = s.repositoryRepository.GetRepositoryById(ctx, req.RepoId)
 	if errx != nil {
-		errx.AddCommentf("[usecase][AddNewScanning] while GetRepositoryById (repository_id: %v)", repo_id)
+		errx.AddCommentf("[usecase][AddNewScanning] while GetRepositoryById (repository_id: %v)", req.RepoId)
 		return
 	} else if repo == nil {
 		errx = serror.Newi(http.StatusBadRequest, "Repository not found|Repository not found")
@@ -55,8 +78,43 @@ func (s scanningUsecase) AddNewScanning(repo_id int64) (res model.ScanningRespon
 		return
 	}
 
+	switch req.Mode {
+	case constants.ScanModeDiff:
+		if req.BaseRef == "" || req.Ref == "" {
+			errx = serror.Newi(http.StatusBadRequest, "Base and head refs are required|Base and head refs are required")
+			return
+		}
+		req.IsFullScan = false
+	default:
+		req.Mode = constants.ScanModeStandard
+		req.BaseRef = ""
+
+		// Scan repository default ref unless other one is requested
+		if req.Ref == "" && repo.DefaultRef != nil {
+			req.Ref = *repo.DefaultRef
+		}
+	}
+	if req.MaxAttempts == 0 {
+		req.MaxAttempts = s.option.MaxAttempts
+	}
+
+	// Scanning is queued with priority of repository settings unless other one is requested
+	if req.Priority == nil {
+		var settings *model.RepositorySettings
+		settings, errx = s.repositoryRepository.GetRepositorySettings(ctx, req.RepoId)
+		if errx != nil {
+			errx.AddCommentf("[usecase][AddNewScanning] while GetRepositorySettings (repository_id: %v)", req.RepoId)
+			return
+		}
+		priority := 0
+		if settings != nil {
+			priority = settings.Priority
+		}
+		req.Priority = &priority
+	}
+
 	var tx *model.Trx
-	tx, errx = s.trxRepository.Create()
+	tx, errx = s.trxRepository.Create(ctx)
 	if errx != nil {
 		errx.AddComments("[usecase][AddNewScanning] while create new transaction")
 		return
@@ -70,7 +128,7 @@ func (s scanningUsecase) AddNewScanning(repo_id int64) (res model.ScanningRespon
 		}
 	}()
 
-	res, errx = s.scanningRepository.AddNewScanning(tx, repo_id)
+	res, errx = s.scanningRepository.AddNewScanning(ctx, tx, req)
 	if errx != nil {
 		errx.AddComments("[usecase][AddNewScanning] while add repository")
 		return
@@ -85,76 +143,1108 @@ func (s scanningUsecase) AddNewScanning(repo_id int64) (res model.ScanningRespon
 		}
 	}
 
-	if *s.scanningInProgress == false {
-		go s.StartScanningInQueue()
+	// Identical scanning is already queued, so nothing new to be consumed
+	if res.IsCoalesced {
+		log.Infof("Scanning of repository id[%v] is coalesced into queued scanning id[%v]", req.RepoId, res.Id)
+		return
 	}
+
+	// Queue outlives the request, so it must not be stopped once request is done
+	go s.StartScanningInQueue(context.Background())
 	return
 }
 
-func (s scanningUsecase) StartScanningInQueue() (errx serror.SError) {
-	*s.scanningInProgress = true
-	log.Info("Start scanning...")
-	for {
-		// Limit should be 1 avoiding racing condition occuring in microservice architect
-		req := model.ScanningListRequest{
-			Limit:  1,
-			Page:   1,
-			Sort:   "asc",
-			Status: "queued",
+func (s scanningUsecase) AddUploadScanning(ctx context.Context, req model.AddUploadScanningRequest) (res model.ScanningResponse, errx serror.SError) {
+	var dir string
+	dir, errx = s.archiveRepository.Extract(req.Archive)
+	if errx != nil {
+		errx.AddCommentf("[usecase][AddUploadScanning] while extract archive %s", req.Archive.Filename)
+		return
+	}
+
+	var tx *model.Trx
+	tx, errx = s.trxRepository.Create(ctx)
+	if errx != nil {
+		errx.AddComments("[usecase][AddUploadScanning] while create new transaction")
+		return
+	}
+	defer func() {
+		if errx != nil {
+			errs := tx.Abort()
+			if errs != nil {
+				log.Error("[usecase][AddUploadScanning] Failed to rollback")
+			}
+			errs = s.archiveRepository.Remove(dir)
+			if errs != nil {
+				log.Errorf("[usecase][AddUploadScanning] Failed to remove extracted archive %s", dir)
+			}
+		}
+	}()
+
+	// Extracted tree is scanned as local directory of a synthetic repository
+	name := req.Name
+	if name == "" {
+		name = req.Archive.Filename
+	}
+	var repo model.AddRepositoryResponse
+	repo, errx = s.repositoryRepository.AddRepository(ctx, tx, model.AddRepositoryRequest{
+		Name:     name,
+		Url:      dir,
+		IsUpload: true,
+	})
+	if errx != nil {
+		errx.AddComments("[usecase][AddUploadScanning] while add synthetic repository")
+		return
+	}
+
+	res, errx = s.scanningRepository.AddNewScanning(ctx, tx, model.AddScanningRequest{
+		RepoId:      repo.Id,
+		Mode:        constants.ScanModeStandard,
+		IsFullScan:  true,
+		MaxAttempts: s.option.MaxAttempts,
+	})
+	if errx != nil {
+		errx.AddComments("[usecase][AddUploadScanning] while add scanning")
+		return
+	}
+
+	err := tx.Admit()
+	if err != nil {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[usecase][AddUploadScanning] Failed to commit transaction")
+		return
+	}
+
+	// Queue outlives the request, so it must not be stopped once request is done
+	go s.StartScanningInQueue(context.Background())
+	return
+}
+
+// StartScanningInQueue consumes queued scannings by idle workers of the pool and returns once they drain the queue.
+// Workers already busy are told to check the queue again instead, so it is safe to be called on every new scanning
+func (s scanningUsecase) StartScanningInQueue(ctx context.Context) (errx serror.SError) {
+	workers := s.workers.start()
+	if len(workers) == 0 {
+		return
+	}
+	log.Infof("Start scanning by %d workers...", len(workers))
+
+	var wg sync.WaitGroup
+	errs := make([]serror.SError, len(workers))
+	for idx, worker := range workers {
+		wg.Add(1)
+		go func(idx int, worker int) {
+			defer wg.Done()
+			errs[idx] = s.runScanningWorker(ctx, worker)
+		}(idx, worker)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			log.Error(err)
+			if errx == nil {
+				errx = err
+			}
 		}
+	}
+	log.Info("Scanning done")
+	return
+}
 
-		var scanningQueue []model.ScanningListResponse
-		scanningQueue, errx = s.scanningRepository.GetScanningList(req)
+// runScanningWorker scans queued scannings one by one until queue is empty
+func (s scanningUsecase) runScanningWorker(ctx context.Context, worker int) (errx serror.SError) {
+	claimedBy := fmt.Sprintf("%s/%d", s.option.Instance, worker)
+	logger := log.WithFields(log.Fields{"instance": s.option.Instance, "worker": worker})
+	logger.Info("Worker started")
+	for {
+		var scanning *model.ScanningListResponse
+		scanning, errx = s.claimScanning(ctx, claimedBy)
 		if errx != nil {
-			errx.AddComments("[usecase][StartScanning] while get scanning list")
+			s.workers.release(worker)
+			errx.AddCommentf("[usecase][StartScanning] while worker %d claims scanning", worker)
 			return
 		}
-		if len(scanningQueue) == 0 {
-			break
+		if scanning == nil {
+			if s.workers.idle(worker) {
+				break
+			}
+			continue
+		}
+
+		s.scanQueuedScanning(ctx, logger, claimedBy, *scanning)
+
+		// Break time before checking any new ones from DB
+		time.Sleep(1 * time.Second)
+	}
+	logger.Info("Worker stopped, queue is empty")
+	return
+}
+
+// claimScanning picks the oldest queued scanning on behalf of worker and updates its status 'in_progress', nil once queue is empty.
+// Claim is atomic in DB, so that no scanning is picked twice by workers of this or other instances
+func (s scanningUsecase) claimScanning(ctx context.Context, claimedBy string) (res *model.ScanningListResponse, errx serror.SError) {
+	res, errx = s.scanningRepository.ClaimScanning(ctx, claimedBy, s.option.Lease, s.option.PriorityAging)
+	if errx != nil {
+		errx.AddCommentf("[usecase][claimScanning] while claim scanning by %s", claimedBy)
+		return
+	}
+	return
+}
+
+// scanQueuedScanning scans claimed scanning and updates its result, errors are logged since the worker goes on with next one
+func (s scanningUsecase) scanQueuedScanning(ctx context.Context, logger *log.Entry, claimedBy string, scanning model.ScanningListResponse) {
+	logger.Infof("Scanning id[%v]...", scanning.Id)
+
+	// Scanning can be cancelled as soon as it is picked up, it is stopped on cancel or once timed out
+	var (
+		scanCtx context.Context
+		cancel  context.CancelFunc
+	)
+	if s.option.Timeout > 0 {
+		scanCtx, cancel = context.WithTimeout(ctx, s.option.Timeout)
+	} else {
+		scanCtx, cancel = context.WithCancel(ctx)
+	}
+	s.runningScans.add(scanning.Id, cancel)
+
+	// Lease is kept until scanning is updated, it is stopped as well once lease is lost
+	stopHeartbeat, leaseLost := s.heartbeat(ctx, logger, claimedBy, scanning.Id, cancel)
+	defer stopHeartbeat()
+
+	// Scan commits since last successful scanning of the same ref only unless full scan is requested
+	target := model.ScanTarget{
+		ScanningId: scanning.Id,
+		Url:        scanning.Url,
+		Mode:       scanning.Mode,
+	}
+	if scanning.BaseRef != nil {
+		target.BaseRef = *scanning.BaseRef
+	}
+	if scanning.Ref != nil {
+		target.Ref = *scanning.Ref
+	}
+	// Repository is scanned with its default settings once they cannot be read
+	settings, errx := s.repositoryRepository.GetRepositorySettings(ctx, scanning.RepoId)
+	if errx != nil {
+		logger.Error(errx)
+		errx.AddComments("[usecase][StartScanning] while get settings of repository id[%v]",
+			fmt.Sprint(scanning.RepoId))
+	} else if settings != nil {
+		target.Settings = *settings
+	}
+	if !scanning.IsFullScan && target.Mode != constants.ScanModeDiff {
+		var lastScanning *model.ScanningResponse
+		lastScanning, errx = s.scanningRepository.GetLastSuccessfulScanning(ctx, scanning.RepoId, target.Ref)
+		if errx != nil {
+			logger.Error(errx)
+			errx.AddComments("[usecase][StartScanning] while get last successful scanning of repository id[%v]",
+				fmt.Sprint(scanning.RepoId))
+		} else if lastScanning != nil && lastScanning.HeadCommit != nil {
+			target.SinceCommit = *lastScanning.HeadCommit
 		}
+	}
 
-		for idx := 0; idx < len(scanningQueue); idx++ {
-			log.Infof("Scanning id[%v]...", scanningQueue[idx].Id)
-
-			// Update status 'in_progress' immediately without creating a DB transaction
-			_, errx = s.scanningRepository.EditScanningStatusById(nil,
-				scanningQueue[idx].Id, constants.ScanningStatusInProgress, types.JSONText([]byte(`{}`)))
-			if errx != nil {
-				log.Error(errx)
-				errx.AddComments("[usecase][StartScanning] while update scanning id[%v] status[%v]",
-					fmt.Sprint(scanningQueue[idx].Id), constants.ScanningStatusInProgress)
-				continue
-			}
-			log.Infof("Update scanning id[%v] status[%v] done",
-				scanningQueue[idx].Id, constants.ScanningStatusInProgress)
-
-			//Start scanning session by grab
-			var res []byte
-			var status string
-			res, errx = s.grabScanner.StartScanningSession(scanningQueue[idx].Url)
-			if errx != nil {
-				log.Error(errx)
-				status = constants.ScanningStatusFailure
+	// Start scanning session by registered scan engines
+	var result model.ScanResult
+	var findings []byte
+	var status string
+	var secrets []model.FindingSecret
+	var fingerprints []model.FindingFingerprint
+	var carried model.CopyFindingSecretsRequest
+	result, errx = s.grabScanner.StartScanningSession(scanCtx, target)
+	scanErr := scanCtx.Err()
+	s.runningScans.remove(scanning.Id)
+	cancel()
+
+	// Error of scanning is kept on it, scanning failing for transient error is retried until it runs out of attempts
+	var lastErr serror.SError
+	var retryDelay time.Duration
+	switch {
+	case scanErr == context.Canceled:
+		status = constants.ScanningStatusCancelled
+		findings, _ = json.Marshal(map[string]string{"reason": "Scanning is cancelled"})
+	case scanErr == context.DeadlineExceeded:
+		lastErr = serror.Newf("scanning timed out after %v", s.option.Timeout)
+		lastErr.AddCommentf("[usecase][StartScanning] while scan scanning id[%v]", scanning.Id)
+		status = constants.ScanningStatusFailure
+		findings, _ = json.Marshal(map[string]string{
+			"reason": fmt.Sprintf("Scanning exceeds maximum duration of %v", s.option.Timeout)})
+	case errx != nil:
+		logger.Error(errx)
+		lastErr = errx
+		status = constants.ScanningStatusFailure
+		findings, _ = json.Marshal(map[string]string{"reason": errx.Error()})
+		if retryableScanError(errx) {
+			if scanning.Attempts < scanning.MaxAttempts {
+				status = constants.ScanningStatusQueued
+				retryDelay = retryBackoff(s.option.RetryBackoff, s.option.RetryBackoffMax, scanning.Attempts, randomJitter())
 			} else {
-				status = constants.ScanningStatusSuccess
+				status = constants.ScanningStatusDead
+			}
+		}
+	default:
+		status = constants.ScanningStatusSuccess
+		result.Findings, secrets, errx = s.redactFindings(scanning.Id, result.Findings)
+		if errx != nil {
+			logger.Error(errx)
+			lastErr = errx
+			status = constants.ScanningStatusFailure
+			findings, _ = json.Marshal(map[string]string{"reason": "Findings cannot be redacted"})
+			break
+		}
+		result.Findings, fingerprints, carried, errx = s.trackFindings(ctx, scanning, target.Ref, result)
+		if errx != nil {
+			logger.Error(errx)
+			lastErr = errx
+			status = constants.ScanningStatusFailure
+			findings, _ = json.Marshal(map[string]string{"reason": "Findings cannot be tracked"})
+			break
+		}
+		result.Findings, errx = s.baselineFindings(ctx, scanning.RepoId, result.Findings)
+		if errx != nil {
+			logger.Error(errx)
+			lastErr = errx
+			status = constants.ScanningStatusFailure
+			findings, _ = json.Marshal(map[string]string{"reason": "Findings cannot be compared with baseline"})
+			break
+		}
+		findings, _ = json.Marshal(result.Findings)
+	}
+	engines, _ := json.Marshal(result.Engines)
+
+	req := model.EditScanningStatusRequest{
+		Id:          scanning.Id,
+		Status:      status,
+		Findings:    types.JSONText(findings),
+		Engines:     types.JSONText(engines),
+		SinceCommit: result.SinceCommit,
+		HeadCommit:  result.HeadCommit,
+		ClaimedBy:   claimedBy,
+		RetryDelay:  retryDelay,
+	}
+	if lastErr != nil {
+		req.LastError = lastErr.Error()
+		req.LastErrorStack = lastErr.CommentStack()
+	}
+	// Scanning whose lease is lost is reaped and may be claimed by another worker already, it is left to that worker
+	if leaseLost() {
+		logger.Warnf("Lease of scanning id[%v] is lost, result of status[%v] is discarded", scanning.Id, status)
+		return
+	}
+	if status == constants.ScanningStatusSuccess {
+		errx = s.finishScanning(ctx, req, result.Findings, secrets, fingerprints, carried)
+	} else {
+		// Update status 'failure/cancelled/dead' or queue it again immediately without creating a DB transaction
+		_, errx = s.scanningRepository.EditScanningStatusById(ctx, nil, req)
+	}
+	if errx != nil {
+		logger.Error(errx)
+		errx.AddComments("[usecase][StartScanning] while update scanning id[%v] status[%v]",
+			fmt.Sprint(scanning.Id), status)
+		return
+	}
+	logger.Infof("Update scanning id[%v] status[%v] done", scanning.Id, status)
+
+	// Extracted archive is not needed anymore once its scanning is finished
+	if scanning.IsUpload && status != constants.ScanningStatusQueued {
+		if errx = s.releaseUpload(ctx, scanning.RepoId); errx != nil {
+			logger.Error(errx)
+		}
+	}
+
+	if status == constants.ScanningStatusQueued {
+		logger.Infof("Scanning id[%v] is retried in %v, attempt %d of %d failed", scanning.Id, retryDelay,
+			scanning.Attempts, scanning.MaxAttempts)
+		// Queue outlives the worker, so it must not be stopped once worker is done
+		time.AfterFunc(retryDelay, func() {
+			s.StartScanningInQueue(context.Background())
+		})
+	}
+}
+
+// releaseUpload deactivates synthetic repository of uploaded archive and removes the extracted archive once none of
+// its scannings is queued or in progress, nothing is done for other repositories
+func (s scanningUsecase) releaseUpload(ctx context.Context, repo_id int64) (errx serror.SError) {
+	var dir *string
+	dir, errx = s.repositoryRepository.DeactivateUploadRepository(ctx, nil, repo_id)
+	if errx != nil {
+		errx.AddCommentf("[usecase][releaseUpload] while deactivate repository id[%v]", repo_id)
+		return
+	} else if dir == nil {
+		return
+	}
+
+	errx = s.archiveRepository.Remove(*dir)
+	if errx != nil {
+		errx.AddCommentf("[usecase][releaseUpload] while remove extracted archive of repository id[%v]", repo_id)
+		return
+	}
+	log.Infof("Extracted archive of repository id[%v] is removed", repo_id)
+	return
+}
+
+// heartbeat renews lease of scanning claimed by worker every third of lease until returned stop is called.
+// Scanning is stopped by cancel once its lease is lost, e.g. it is reaped while renewing fails, and returned lost
+// reports it from then on
+func (s scanningUsecase) heartbeat(ctx context.Context, logger *log.Entry, claimedBy string, scanning_id int64,
+	cancel context.CancelFunc) (stop func(), lost func() bool) {
+	var isLost int32
+	lost = func() bool { return atomic.LoadInt32(&isLost) == 1 }
+	if s.option.Lease <= 0 {
+		return func() {}, lost
+	}
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(s.option.Lease / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				renewed, errx := s.scanningRepository.RenewScanningLease(ctx, scanning_id, claimedBy, s.option.Lease)
+				if errx != nil {
+					// Lease may still be renewed by next beat before it expires
+					errx.AddCommentf("[usecase][heartbeat] while renew lease of scanning id[%v]", scanning_id)
+					logger.Error(errx)
+					continue
+				}
+				if !renewed {
+					logger.Warnf("Lease of scanning id[%v] is lost, scanning is stopped", scanning_id)
+					atomic.StoreInt32(&isLost, 1)
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+	return func() { close(done) }, lost
+}
+
+// StartScanningPoller wakes idle workers up every PollInterval until ctx is done, so that scannings queued
+// by other services, e.g. API ones which run no worker, are consumed
+func (s scanningUsecase) StartScanningPoller(ctx context.Context) (errx serror.SError) {
+	ticker := time.NewTicker(s.option.PollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// Queue outlives the poller, so it must not be stopped once poller is done
+			go s.StartScanningInQueue(context.Background())
+		}
+	}
+}
+
+// StartScanningReaper recovers scannings whose lease expired every lease until ctx is done,
+// scannings queued again are consumed by workers of this service
+func (s scanningUsecase) StartScanningReaper(ctx context.Context) (errx serror.SError) {
+	if s.option.Lease <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.option.Lease)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if errs := s.reapScannings(ctx); errs != nil {
+				log.Error(errs)
 			}
+		}
+	}
+}
+
+// reapScannings queues scannings whose lease expired again, they are dead once they are attempted too many times
+func (s scanningUsecase) reapScannings(ctx context.Context) (errx serror.SError) {
+	var reaped []model.ReapedScanning
+	reaped, errx = s.scanningRepository.ReapExpiredScannings(ctx)
+	if errx != nil {
+		errx.AddComments("[usecase][reapScannings] while reap expired scannings")
+		return
+	}
+
+	requeued := false
+	for _, scanning := range reaped {
+		claimedBy := "unknown worker"
+		if scanning.ClaimedBy != nil {
+			claimedBy = *scanning.ClaimedBy
+		}
+		log.Warnf("Lease of scanning id[%v] claimed by %v expired after %d attempts, update status[%v] done",
+			scanning.Id, claimedBy, scanning.Attempts, scanning.Status)
+		if scanning.Status == constants.ScanningStatusQueued {
+			requeued = true
+		} else if errs := s.releaseUpload(ctx, scanning.RepoId); errs != nil {
+			log.Error(errs)
+		}
+	}
 
-			// Update status 'success/failure' immediately without creating a DB transaction
-			_, errx = s.scanningRepository.EditScanningStatusById(nil,
-				scanningQueue[idx].Id, status, types.JSONText(res))
-			if errx != nil {
-				log.Error(errx)
-				errx.AddComments("[usecase][StartScanning] while update scanning id[%v] status[%v]",
-					fmt.Sprint(scanningQueue[idx].Id), status)
-				continue
+	// Queue outlives the reaper, so it must not be stopped once reaper is done
+	if requeued {
+		go s.StartScanningInQueue(context.Background())
+	}
+	return
+}
+
+// StartScanningScheduler queues scannings of repositories whose schedule is due every scheduleInterval until ctx is done,
+// queued scannings are consumed by workers of this service
+func (s scanningUsecase) StartScanningScheduler(ctx context.Context) (errx serror.SError) {
+	ticker := time.NewTicker(scheduleInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if errs := s.scheduleScannings(ctx); errs != nil {
+				log.Error(errs)
 			}
-			log.Infof("Update scanning id[%v] status[%v] done", scanningQueue[idx].Id, status)
 		}
+	}
+}
 
-		// Break time before checking any new ones from DB
-		time.Sleep(1 * time.Second)
+// scheduleScannings queues scanning of every repository whose schedule is due, runs missed while no service
+// was up are queued once
+func (s scanningUsecase) scheduleScannings(ctx context.Context) (errx serror.SError) {
+	var repos []model.ScheduledRepository
+	repos, errx = s.repositoryRepository.GetScheduledRepositories(ctx)
+	if errx != nil {
+		errx.AddComments("[usecase][scheduleScannings] while get scheduled repositories")
+		return
+	}
+
+	queued := false
+	for _, repo := range repos {
+		ok, errs := s.scheduleScanning(ctx, repo)
+		if errs != nil {
+			log.Error(errs)
+			continue
+		}
+		if ok {
+			log.Infof("Scheduled scanning of repository id[%v] due at %v is queued", repo.Id, repo.NextScheduledScanAt)
+			queued = true
+		}
+	}
+
+	// Queue outlives the scheduler, so it must not be stopped once scheduler is done
+	if queued {
+		go s.StartScanningInQueue(context.Background())
+	}
+	return
+}
+
+// scheduleScanning queues scanning of repository and moves its schedule to the next run in one transaction,
+// false if another replica queued it meanwhile
+func (s scanningUsecase) scheduleScanning(ctx context.Context, repo model.ScheduledRepository) (res bool, errx serror.SError) {
+	zone := repo.ScanTimezone
+	if zone == "" {
+		zone = constants.DefaultTimezone
+	}
+	var next time.Time
+	next, errx = nextScheduledScan(repo.ScanSchedule, zone)
+	if errx != nil {
+		errx.AddCommentf("[usecase][scheduleScanning] while get next run of repository id[%v]", repo.Id)
+		return
+	}
+
+	// Scheduled scanning is queued as triggered one without request body
+	req := model.AddScanningRequest{
+		RepoId:      repo.Id,
+		Mode:        constants.ScanModeStandard,
+		MaxAttempts: s.option.MaxAttempts,
+	}
+	if repo.DefaultRef != nil {
+		req.Ref = *repo.DefaultRef
+	}
+	var settings *model.RepositorySettings
+	settings, errx = s.repositoryRepository.GetRepositorySettings(ctx, repo.Id)
+	if errx != nil {
+		errx.AddCommentf("[usecase][scheduleScanning] while GetRepositorySettings (repository_id: %v)", repo.Id)
+		return
+	}
+	priority := 0
+	if settings != nil {
+		priority = settings.Priority
+	}
+	req.Priority = &priority
+
+	var tx *model.Trx
+	tx, errx = s.trxRepository.Create(ctx)
+	if errx != nil {
+		errx.AddComments("[usecase][scheduleScanning] while create new transaction")
+		return
+	}
+	defer func() {
+		if errx != nil || !res {
+			errs := tx.Abort()
+			if errs != nil {
+				log.Error("[usecase][scheduleScanning] Failed to rollback")
+			}
+		}
+	}()
+
+	res, errx = s.repositoryRepository.AdvanceRepositorySchedule(ctx, tx, repo.Id, repo.NextScheduledScanAt, next)
+	if errx != nil {
+		errx.AddCommentf("[usecase][scheduleScanning] while AdvanceRepositorySchedule (repository_id: %v)", repo.Id)
+		return false, errx
+	} else if !res {
+		return
+	}
+
+	_, errx = s.scanningRepository.AddNewScanning(ctx, tx, req)
+	if errx != nil {
+		errx.AddCommentf("[usecase][scheduleScanning] while add scanning of repository id[%v]", repo.Id)
+		return false, errx
+	}
+
+	err := tx.Admit()
+	if err != nil {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[usecase][scheduleScanning] Failed to commit transaction")
+		return false, errx
+	}
+	return
+}
+
+func (s scanningUsecase) GetFindingList(ctx context.Context, req model.FindingListRequest) (res []model.FindingListResponse, errx serror.SError) {
+	var scanning *model.ScanningResponse
+	scanning, errx = s.scanningRepository.GetScanningById(ctx, req.ScanningId)
+	if errx != nil {
+		errx.AddCommentf("[usecase][GetFindingList] while GetScanningById (scanning_id: %v)", req.ScanningId)
+		return
+	} else if scanning == nil {
+		errx = serror.Newi(http.StatusBadRequest, "Scanning not found|Scanning not found")
+		return
+	}
+
+	res, errx = s.scanningRepository.GetFindingList(ctx, req)
+	if errx != nil {
+		errx.AddCommentf("[usecase][GetFindingList] while get finding list of scanning id[%v]", req.ScanningId)
+		return
+	}
+	return
+}
+
+func (s scanningUsecase) TriageFinding(ctx context.Context, req model.TriageFindingRequest) (res model.FindingTriageResponse, errx serror.SError) {
+	var finding *model.FindingListResponse
+	finding, errx = s.getTrackedFinding(ctx, req.ScanningId, req.FindingId)
+	if errx != nil {
+		errx.AddComments("[usecase][TriageFinding] while get tracked finding")
+		return
+	}
+	req.RepositoryId = finding.RepositoryId
+	req.Fingerprint = finding.Fingerprint
+	req.Rule = finding.Rule
+	req.FilePath = finding.FilePath
+
+	var tx *model.Trx
+	tx, errx = s.trxRepository.Create(ctx)
+	if errx != nil {
+		errx.AddComments("[usecase][TriageFinding] while create new transaction")
+		return
+	}
+	defer func() {
+		if errx != nil {
+			errs := tx.Abort()
+			if errs != nil {
+				log.Error("[usecase][TriageFinding] Failed to rollback")
+			}
+		}
+	}()
+
+	res, errx = s.scanningRepository.EditFindingTriage(ctx, tx, req)
+	if errx != nil {
+		errx.AddCommentf("[usecase][TriageFinding] while edit triage of finding %v", req.FindingId)
+		return
+	}
+
+	// Comment explaining the decision is kept in the thread of the finding
+	if req.Comment != "" {
+		_, errx = s.scanningRepository.AddFindingComment(ctx, tx, model.AddFindingCommentRequest{
+			ScanningId:   req.ScanningId,
+			FindingId:    req.FindingId,
+			RepositoryId: req.RepositoryId,
+			Fingerprint:  req.Fingerprint,
+			Rule:         req.Rule,
+			FilePath:     req.FilePath,
+			Comment:      req.Comment,
+			CreatedBy:    req.TriagedBy,
+		})
+		if errx != nil {
+			errx.AddCommentf("[usecase][TriageFinding] while add comment to finding %v", req.FindingId)
+			return
+		}
+	}
+
+	err := tx.Admit()
+	if err != nil {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[usecase][TriageFinding] Failed to commit transaction")
+		return
+	}
+	log.Infof("Finding id[%v] of scanning id[%v] is triaged as %v by %v", req.FindingId, req.ScanningId, req.Status, req.TriagedBy)
+	return
+}
+
+func (s scanningUsecase) AddFindingComment(ctx context.Context, req model.AddFindingCommentRequest) (res model.FindingComment, errx serror.SError) {
+	var finding *model.FindingListResponse
+	finding, errx = s.getTrackedFinding(ctx, req.ScanningId, req.FindingId)
+	if errx != nil {
+		errx.AddComments("[usecase][AddFindingComment] while get tracked finding")
+		return
+	}
+	req.RepositoryId = finding.RepositoryId
+	req.Fingerprint = finding.Fingerprint
+	req.Rule = finding.Rule
+	req.FilePath = finding.FilePath
+
+	res, errx = s.scanningRepository.AddFindingComment(ctx, nil, req)
+	if errx != nil {
+		errx.AddCommentf("[usecase][AddFindingComment] while add comment to finding %v", req.FindingId)
+		return
+	}
+	return
+}
+
+func (s scanningUsecase) GetFindingComments(ctx context.Context, scanning_id int64, finding_id string) (res []model.FindingComment, errx serror.SError) {
+	var finding *model.FindingListResponse
+	finding, errx = s.getTrackedFinding(ctx, scanning_id, finding_id)
+	if errx != nil {
+		errx.AddComments("[usecase][GetFindingComments] while get tracked finding")
+		return
+	}
+
+	res, errx = s.scanningRepository.GetFindingComments(ctx, finding.RepositoryId, finding.Fingerprint)
+	if errx != nil {
+		errx.AddCommentf("[usecase][GetFindingComments] while get comments of finding %v", finding_id)
+		return
+	}
+	return
+}
+
+// getTrackedFinding returns finding by given scanning id and finding id, which must have fingerprint to be triaged
+func (s scanningUsecase) getTrackedFinding(ctx context.Context, scanning_id int64, finding_id string) (res *model.FindingListResponse, errx serror.SError) {
+	res, errx = s.scanningRepository.GetFindingById(ctx, scanning_id, finding_id)
+	if errx != nil {
+		errx.AddCommentf("[usecase][getTrackedFinding] while GetFindingById (scanning_id: %v, finding_id: %v)", scanning_id, finding_id)
+		return
+	} else if res == nil {
+		errx = serror.Newi(http.StatusBadRequest, "Finding not found|Finding not found")
+		return
+	} else if res.Fingerprint == "" {
+		// e.g. finding is stored before fingerprints were introduced
+		errx = serror.Newi(http.StatusBadRequest, "Finding has no fingerprint|Finding has no fingerprint")
+		return
+	}
+	return
+}
+
+func (s scanningUsecase) AddBaseline(ctx context.Context, req model.AddBaselineRequest) (res model.BaselineResponse, errx serror.SError) {
+	var scanning *model.ScanningResponse
+	scanning, errx = s.scanningRepository.GetScanningById(ctx, req.ScanningId)
+	if errx != nil {
+		errx.AddCommentf("[usecase][AddBaseline] while GetScanningById (scanning_id: %v)", req.ScanningId)
+		return
+	} else if scanning == nil || scanning.RepoId != req.RepositoryId {
+		errx = serror.Newi(http.StatusBadRequest, "Scanning not found|Scanning not found")
+		return
+	} else if scanning.Status != constants.ScanningStatusSuccess || scanning.Mode != constants.ScanModeStandard {
+		// Diff scanning covers changed lines only, so it cannot tell what the repository already has
+		errx = serror.Newi(http.StatusBadRequest, "Only successful standard scanning can be a baseline|Only successful standard scanning can be a baseline")
+		return
+	}
+
+	var tx *model.Trx
+	tx, errx = s.trxRepository.Create(ctx)
+	if errx != nil {
+		errx.AddComments("[usecase][AddBaseline] while create new transaction")
+		return
+	}
+	defer func() {
+		if errx != nil {
+			errs := tx.Abort()
+			if errs != nil {
+				log.Error("[usecase][AddBaseline] Failed to rollback")
+			}
+		}
+	}()
+
+	// Version of baseline follows the latest one, so baselines of the same repository are added one by one
+	errx = s.repositoryRepository.LockRepository(ctx, tx, req.RepositoryId)
+	if errx != nil {
+		errx.AddCommentf("[usecase][AddBaseline] while lock repository id[%v]", req.RepositoryId)
+		return
+	}
+
+	res, errx = s.scanningRepository.AddBaseline(ctx, tx, req)
+	if errx != nil {
+		errx.AddCommentf("[usecase][AddBaseline] while add baseline of repository id[%v]", req.RepositoryId)
+		return
+	}
+
+	err := tx.Admit()
+	if err != nil {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[usecase][AddBaseline] Failed to commit transaction")
+		return
+	}
+	return
+}
+
+func (s scanningUsecase) GetBaselineList(ctx context.Context, repo_id int64) (res []model.BaselineResponse, errx serror.SError) {
+	res, errx = s.scanningRepository.GetBaselineList(ctx, repo_id)
+	if errx != nil {
+		errx.AddCommentf("[usecase][GetBaselineList] while get baseline list of repository id[%v]", repo_id)
+		return
+	}
+	return
+}
+
+func (s scanningUsecase) ActivateBaseline(ctx context.Context, req model.ActivateBaselineRequest) (res model.BaselineResponse, errx serror.SError) {
+	var baseline *model.BaselineResponse
+	baseline, errx = s.scanningRepository.ActivateBaseline(ctx, nil, req)
+	if errx != nil {
+		errx.AddCommentf("[usecase][ActivateBaseline] while activate baseline version %v of repository id[%v]", req.Version, req.RepositoryId)
+		return
+	} else if baseline == nil {
+		errx = serror.Newi(http.StatusBadRequest, "Baseline not found|Baseline not found")
+		return
+	}
+	return *baseline, nil
+}
+
+// baselineFindings marks findings whose fingerprint is in active baseline of repository by its version,
+// they are kept in scanning result but left out of findings list
+func (s scanningUsecase) baselineFindings(ctx context.Context, repo_id int64, findings []model.Finding) (res []model.Finding, errx serror.SError) {
+	res = findings
+
+	var baseline *model.BaselineResponse
+	baseline, errx = s.scanningRepository.GetActiveBaseline(ctx, repo_id)
+	if errx != nil {
+		errx.AddCommentf("[usecase][baselineFindings] while get active baseline of repository id[%v]", repo_id)
+		return
+	} else if baseline == nil {
+		return
+	}
+
+	var fingerprints []string
+	fingerprints, errx = s.scanningRepository.GetBaselineFingerprints(ctx, baseline.Id)
+	if errx != nil {
+		errx.AddCommentf("[usecase][baselineFindings] while get fingerprints of baseline id[%v]", baseline.Id)
+		return
+	}
+	known := make(map[string]bool, len(fingerprints))
+	for _, fingerprint := range fingerprints {
+		known[fingerprint] = true
+	}
+	for idx := range res {
+		if known[res[idx].Fingerprint] {
+			res[idx].BaselineVersion = baseline.Version
+		}
+	}
+	return
+}
+
+func (s scanningUsecase) CancelScanning(ctx context.Context, scanning_id int64) (res model.ScanningResponse, errx serror.SError) {
+	var scanning *model.ScanningResponse
+	scanning, errx = s.scanningRepository.GetScanningById(ctx, scanning_id)
+	if errx != nil {
+		errx.AddCommentf("[usecase][CancelScanning] while GetScanningById (scanning_id: %v)", scanning_id)
+		return
+	} else if scanning == nil {
+		errx = serror.Newi(http.StatusBadRequest, "Scanning not found|Scanning not found")
+		return
+	} else if scanning.Status != constants.ScanningStatusQueued && scanning.Status != constants.ScanningStatusInProgress {
+		errx = serror.Newi(http.StatusBadRequest, "Scanning is already finished|Scanning is already finished")
+		return
+	}
+
+	// Scanning running here is stopped, its status is updated once its session returns
+	if s.runningScans.cancel(scanning_id) {
+		return *scanning, nil
+	}
+
+	// Otherwise it is still queued or running elsewhere, whose result is discarded
+	scanning, errx = s.scanningRepository.CancelScanningById(ctx, nil, scanning_id)
+	if errx != nil {
+		errx.AddCommentf("[usecase][CancelScanning] while CancelScanningById (scanning_id: %v)", scanning_id)
+		return
+	} else if scanning == nil {
+		errx = serror.Newi(http.StatusBadRequest, "Scanning is already finished|Scanning is already finished")
+		return
+	}
+
+	// Extracted archive of cancelled upload is not needed anymore
+	if errs := s.releaseUpload(ctx, scanning.RepoId); errs != nil {
+		log.Error(errs)
+	}
+	return *scanning, nil
+}
+
+func (s scanningUsecase) RevealFinding(ctx context.Context, req model.RevealFindingRequest) (res model.RevealFindingResponse, errx serror.SError) {
+	var secret *model.FindingSecret
+	secret, errx = s.scanningRepository.GetFindingSecret(ctx, req.ScanningId, req.FindingId)
+	if errx != nil {
+		errx.AddCommentf("[usecase][RevealFinding] while GetFindingSecret (scanning_id: %v, finding_id: %v)", req.ScanningId, req.FindingId)
+		return
+	} else if secret == nil {
+		errx = serror.Newi(http.StatusBadRequest, "Secret of finding is not kept|Secret of finding is not kept")
+		return
+	}
+
+	var raw string
+	raw, errx = s.secretRepository.Reveal(*secret)
+	if errx != nil {
+		errx.AddCommentf("[usecase][RevealFinding] while reveal secret of finding %v", req.FindingId)
+		return
+	}
+
+	// Secret is never returned unless its reveal is audited
+	res, errx = s.scanningRepository.AddFindingReveal(ctx, nil, req)
+	if errx != nil {
+		errx.AddCommentf("[usecase][RevealFinding] while audit reveal of finding %v", req.FindingId)
+		return model.RevealFindingResponse{}, errx
+	}
+	log.Infof("Finding id[%v] of scanning id[%v] is revealed by %v", req.FindingId, req.ScanningId, req.RevealedBy)
+
+	res.LineContent = raw
+	return
+}
+
+// finishScanning updates status 'success' of scanning along with its findings, their secrets and fingerprints
+// in one transaction, so none of them are kept once scanning cannot be finished, e.g. it is cancelled meanwhile.
+// Secrets of carried over findings are copied from the scanning they are carried over from
+func (s scanningUsecase) finishScanning(ctx context.Context, req model.EditScanningStatusRequest, findings []model.Finding,
+	secrets []model.FindingSecret, fingerprints []model.FindingFingerprint, carried model.CopyFindingSecretsRequest) (errx serror.SError) {
+	var tx *model.Trx
+	tx, errx = s.trxRepository.Create(ctx)
+	if errx != nil {
+		errx.AddComments("[usecase][finishScanning] while create new transaction")
+		return
+	}
+	defer func() {
+		if errx != nil {
+			errs := tx.Abort()
+			if errs != nil {
+				log.Error("[usecase][finishScanning] Failed to rollback")
+			}
+		}
+	}()
+
+	if len(secrets) > 0 {
+		errx = s.scanningRepository.AddFindingSecrets(ctx, tx, secrets)
+		if errx != nil {
+			errx.AddCommentf("[usecase][finishScanning] while add finding secrets of scanning id[%v]", req.Id)
+			return
+		}
+	}
+
+	if len(carried.FindingIds) > 0 {
+		errx = s.scanningRepository.CopyFindingSecrets(ctx, tx, carried)
+		if errx != nil {
+			errx.AddCommentf("[usecase][finishScanning] while copy carried over finding secrets of scanning id[%v]", req.Id)
+			return
+		}
+	}
+
+	if len(fingerprints) > 0 {
+		errx = s.scanningRepository.AddFindingFingerprints(ctx, tx, fingerprints)
+		if errx != nil {
+			errx.AddCommentf("[usecase][finishScanning] while add finding fingerprints of scanning id[%v]", req.Id)
+			return
+		}
+	}
+
+	errx = s.scanningRepository.AddFindings(ctx, tx, req.Id, findings)
+	if errx != nil {
+		errx.AddCommentf("[usecase][finishScanning] while add findings of scanning id[%v]", req.Id)
+		return
+	}
+
+	_, errx = s.scanningRepository.EditScanningStatusById(ctx, tx, req)
+	if errx != nil {
+		errx.AddCommentf("[usecase][finishScanning] while update scanning id[%v] status[%v]", req.Id, req.Status)
+		return
+	}
+
+	err := tx.Admit()
+	if err != nil {
+		errx = serror.NewFromError(err)
+		errx.AddCommentf("[usecase][finishScanning] Failed to commit transaction")
+		return
+	}
+	return
+}
+
+// redactFindings replaces secret values of findings with preview and keyed hash before they are stored,
+// encrypted values are returned to be stored apart by finishScanning if they are configured to be kept
+func (s scanningUsecase) redactFindings(scanning_id int64, findings []model.Finding) (res []model.Finding,
+	secrets []model.FindingSecret, errx serror.SError) {
+	res, secrets, errx = s.secretRepository.Redact(scanning_id, findings)
+	if errx != nil {
+		errx.AddCommentf("[usecase][redactFindings] while redact findings of scanning id[%v]", scanning_id)
+		return
+	}
+	return
+}
+
+// trackFindings fingerprints redacted findings and marks them new or recurring against previous scanning
+// of the same repository and ref, findings of previous scanning which are gone are appended as fixed.
+// Findings of diff scanning are fingerprinted only, since they do not cover the whole ref.
+// Findings whose fingerprint is listed in .reposcanignore are suppressed once they are fingerprinted.
+// Fingerprints to be stored and carried over findings whose secrets are copied are returned for finishScanning
+func (s scanningUsecase) trackFindings(ctx context.Context, scanning model.ScanningListResponse, ref string,
+	result model.ScanResult) (res []model.Finding, fingerprints []model.FindingFingerprint,
+	carried model.CopyFindingSecretsRequest, errx serror.SError) {
+	res = result.Findings
+	for idx := range res {
+		res[idx].Fingerprint = fingerprintFinding(res[idx])
+		if reason, ok := result.IgnoredFingerprints[res[idx].Fingerprint]; ok && res[idx].Suppression == nil {
+			res[idx].Suppression = &model.FindingSuppression{Source: constants.SuppressionSourceIgnoreFile, Reason: reason}
+		}
+	}
+	if scanning.Mode == constants.ScanModeDiff {
+		return
+	}
+
+	var previous *model.ScanningResponse
+	previous, errx = s.scanningRepository.GetPreviousScanning(ctx, scanning.RepoId, ref, scanning.Id)
+	if errx != nil {
+		errx.AddCommentf("[usecase][trackFindings] while get previous scanning of repository id[%v]", scanning.RepoId)
+		return
+	}
+
+	// Findings stored before fingerprints were introduced have none, so they cannot be tracked
+	var previousFindings []model.Finding
+	previousFingerprints := map[string]bool{}
+	if previous != nil && json.Unmarshal(previous.Findings, &previousFindings) == nil {
+		for _, finding := range previousFindings {
+			if finding.Fingerprint != "" && finding.Occurrence != constants.FindingOccurrenceFixed {
+				previousFingerprints[finding.Fingerprint] = true
+			}
+		}
+	}
+
+	current := map[string]bool{}
+	for idx := range res {
+		current[res[idx].Fingerprint] = true
+		res[idx].Occurrence = constants.FindingOccurrenceNew
+		if previousFingerprints[res[idx].Fingerprint] {
+			res[idx].Occurrence = constants.FindingOccurrenceRecurring
+		}
+	}
+
+	// Incremental scanning skips files unchanged since previous scanning, so their findings are still there
+	var changed map[string]bool
+	if result.SinceCommit != "" {
+		changed = make(map[string]bool, len(result.ChangedFiles))
+		for _, path := range result.ChangedFiles {
+			changed[path] = true
+		}
+	}
+	fixed := map[string]bool{}
+	for _, finding := range previousFindings {
+		if !previousFingerprints[finding.Fingerprint] || current[finding.Fingerprint] {
+			continue
+		}
+		if changed != nil && !changed[finding.FilePath] {
+			// Baseline and .reposcanignore may have changed since previous scanning, inline comment has not
+			// since its file is unchanged, so the finding is compared with baseline and suppressed again
+			finding.Occurrence = constants.FindingOccurrenceRecurring
+			finding.BaselineVersion = 0
+			if finding.Suppression != nil && finding.Suppression.Source != constants.SuppressionSourceInline {
+				finding.Suppression = nil
+			}
+			res = append(res, finding)
+			if result.IgnoreFile != nil {
+				result.IgnoreFile.Suppress(res[len(res)-1:])
+			}
+			carried.FindingIds = append(carried.FindingIds, finding.ID)
+			continue
+		}
+		if !fixed[finding.Fingerprint] {
+			fixed[finding.Fingerprint] = true
+			finding.Occurrence = constants.FindingOccurrenceFixed
+			res = append(res, finding)
+		}
+	}
+
+	if len(carried.FindingIds) > 0 {
+		carried.FromScanningId = previous.Id
+		carried.ToScanningId = scanning.Id
+	}
+
+	// The same finding may be reported more than once, e.g. by several commits, it is tracked once
+	tracked := map[string]bool{}
+	for _, finding := range res {
+		if tracked[finding.Fingerprint] {
+			continue
+		}
+		tracked[finding.Fingerprint] = true
+		fingerprints = append(fingerprints, model.FindingFingerprint{
+			RepositoryId: scanning.RepoId,
+			ScanningId:   scanning.Id,
+			Fingerprint:  finding.Fingerprint,
+			Rule:         finding.Rule,
+			FilePath:     finding.FilePath,
+			Occurrence:   finding.Occurrence,
+		})
 	}
-	log.Info("Scanning done")
-	*s.scanningInProgress = false
 	return
 }
+
+// fingerprintFinding hashes rule, file path, whitespace normalized line content and secret hash of a redacted finding,
+// so the same finding keeps its fingerprint when lines around it move or it is reported by another commit
+func fingerprintFinding(finding model.Finding) string {
+	hash := sha256.New()
+	for _, part := range []string{
+		finding.Rule,
+		finding.FilePath,
+		strings.Join(strings.Fields(finding.LineContent), " "),
+		finding.SecretHash,
+	} {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// runningScans keeps cancel function of every scanning running in this process by scanning id
+type runningScans struct {
+	mu      sync.Mutex
+	cancels map[int64]context.CancelFunc
+}
+
+func newRunningScans() *runningScans {
+	return &runningScans{
+		cancels: map[int64]context.CancelFunc{},
+	}
+}
+
+func (r *runningScans) add(scanning_id int64, cancel context.CancelFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cancels[scanning_id] = cancel
+}
+
+func (r *runningScans) remove(scanning_id int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.cancels, scanning_id)
+}
+
+// cancel stops scanning by given id, false if it is not running in this process
+func (r *runningScans) cancel(scanning_id int64) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cancel, ok := r.cancels[scanning_id]
+	if ok {
+		cancel()
+	}
+	return ok
+}
@@ -1,8 +1,16 @@
 package usecase
 
 import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"repo-scanner/internal"
 	"repo-scanner/internal/mocks"
 	"repo-scanner/internal/model"
+	"repo-scanner/internal/utils/serror"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -39,7 +47,7 @@ func TestGetScanningList(t *testing.T) {
 					},
 				}
 
-				scanMock.On("GetScanningList", mock.Anything).Return(w, nil).Once()
+				scanMock.On("GetScanningList", mock.Anything, mock.Anything).Return(w, nil).Once()
 			},
 			args: model.ScanningListRequest{
 				Limit:  1,
@@ -71,7 +79,7 @@ func TestGetScanningList(t *testing.T) {
 			scanningRepository:   scanMock,
 		}
 
-		res, err := scanUsecase.GetScanningList(test.args)
+		res, err := scanUsecase.GetScanningList(context.Background(), test.args)
 
 		if (err != nil) != test.wantErr {
 			t.Errorf("GetScanningList() got error : %s", err)
@@ -93,11 +101,14 @@ func TestAddNewScanning(t *testing.T) {
 	scanMock := new(mocks.IScanningRepository)
 	trxMock := new(mocks.ITrxRepository)
 	txMock := new(mocks.ITrx)
+	defaultRef := "develop"
+	baseRef, headRef := "main", "feature"
+	defaultPriority, repoPriority, urgentPriority := 0, 20, 90
 
 	listTests := []struct {
 		name    string
 		mock    func()
-		args    int64
+		args    model.AddScanningRequest
 		want    model.ScanningResponse
 		wantErr bool
 	}{
@@ -120,12 +131,17 @@ func TestAddNewScanning(t *testing.T) {
 					DB: &sqlx.DB{},
 				}
 
-				repoMock.On("GetRepositoryById", mock.Anything).Return(&r, nil).Once()
-				scanMock.On("AddNewScanning", &tx, int64(3)).Return(w, nil).Once()
+				repoMock.On("GetRepositoryById", mock.Anything, mock.Anything).Return(&r, nil).Once()
+				repoMock.On("GetRepositorySettings", mock.Anything, int64(3)).Return(nil, nil).Once()
+				scanMock.On("AddNewScanning", mock.Anything, &tx, model.AddScanningRequest{
+					RepoId:   3,
+					Mode:     "standard",
+					Priority: &defaultPriority,
+				}).Return(w, nil).Once()
 				trxMock.On("Create", mock.Anything).Return(&tx, nil).Once()
 				txMock.On("Admit", mock.Anything).Return(nil).Once()
 			},
-			args: 3,
+			args: model.AddScanningRequest{RepoId: 3},
 			want: model.ScanningResponse{
 				Id:         10,
 				RepoId:     3,
@@ -137,18 +153,168 @@ func TestAddNewScanning(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "default ref",
+			mock: func() {
+				ref := "develop"
+				w := model.ScanningResponse{
+					Id:     11,
+					RepoId: 4,
+					Status: "queued",
+					Ref:    &ref,
+				}
+				r := model.Repository{
+					IsActive:   true,
+					DefaultRef: &ref,
+				}
+				tx := model.Trx{
+					DB: &sqlx.DB{},
+				}
+
+				repoMock.On("GetRepositoryById", mock.Anything, int64(4)).Return(&r, nil).Once()
+				// Scanning is queued with priority of repository settings
+				repoMock.On("GetRepositorySettings", mock.Anything, int64(4)).Return(&model.RepositorySettings{Priority: 20}, nil).Once()
+				scanMock.On("AddNewScanning", mock.Anything, &tx, model.AddScanningRequest{
+					RepoId:   4,
+					Mode:     "standard",
+					Ref:      "develop",
+					Priority: &repoPriority,
+				}).Return(w, nil).Once()
+				trxMock.On("Create", mock.Anything).Return(&tx, nil).Once()
+				txMock.On("Admit", mock.Anything).Return(nil).Once()
+			},
+			args: model.AddScanningRequest{RepoId: 4},
+			want: model.ScanningResponse{
+				Id:     11,
+				RepoId: 4,
+				Status: "queued",
+				Ref:    &defaultRef,
+			},
+			wantErr: false,
+		},
+		{
+			name: "diff",
+			mock: func() {
+				baseRef, headRef := "main", "feature"
+				w := model.ScanningResponse{
+					Id:      12,
+					RepoId:  4,
+					Status:  "queued",
+					Mode:    "diff",
+					BaseRef: &baseRef,
+					Ref:     &headRef,
+				}
+				r := model.Repository{
+					IsActive:   true,
+					DefaultRef: &defaultRef,
+				}
+				tx := model.Trx{
+					DB: &sqlx.DB{},
+				}
+
+				repoMock.On("GetRepositoryById", mock.Anything, int64(4)).Return(&r, nil).Once()
+				scanMock.On("AddNewScanning", mock.Anything, &tx, model.AddScanningRequest{
+					RepoId:   4,
+					Mode:     "diff",
+					BaseRef:  "main",
+					Ref:      "feature",
+					Priority: &urgentPriority,
+				}).Return(w, nil).Once()
+				trxMock.On("Create", mock.Anything).Return(&tx, nil).Once()
+				txMock.On("Admit", mock.Anything).Return(nil).Once()
+			},
+			args: model.AddScanningRequest{RepoId: 4, Mode: "diff", IsFullScan: true, BaseRef: "main", Ref: "feature", Priority: &urgentPriority},
+			want: model.ScanningResponse{
+				Id:      12,
+				RepoId:  4,
+				Status:  "queued",
+				Mode:    "diff",
+				BaseRef: &baseRef,
+				Ref:     &headRef,
+			},
+			wantErr: false,
+		},
+		{
+			name: "coalesced",
+			mock: func() {
+				w := model.ScanningResponse{
+					Id:          9,
+					RepoId:      4,
+					Status:      "queued",
+					Ref:         &defaultRef,
+					Priority:    20,
+					IsCoalesced: true,
+				}
+				r := model.Repository{
+					IsActive:   true,
+					DefaultRef: &defaultRef,
+				}
+				tx := model.Trx{
+					DB: &sqlx.DB{},
+				}
+
+				repoMock.On("GetRepositoryById", mock.Anything, int64(4)).Return(&r, nil).Once()
+				repoMock.On("GetRepositorySettings", mock.Anything, int64(4)).Return(&model.RepositorySettings{Priority: 20}, nil).Once()
+				// Identical scanning is already queued
+				scanMock.On("AddNewScanning", mock.Anything, &tx, model.AddScanningRequest{
+					RepoId:   4,
+					Mode:     "standard",
+					Ref:      "develop",
+					Priority: &repoPriority,
+				}).Return(w, nil).Once()
+				trxMock.On("Create", mock.Anything).Return(&tx, nil).Once()
+			},
+			args: model.AddScanningRequest{RepoId: 4},
+			want: model.ScanningResponse{
+				Id:          9,
+				RepoId:      4,
+				Status:      "queued",
+				Ref:         &defaultRef,
+				Priority:    20,
+				IsCoalesced: true,
+			},
+			wantErr: false,
+		},
+		{
+			name: "diff without head ref",
+			mock: func() {
+				r := model.Repository{
+					IsActive:   true,
+					DefaultRef: &defaultRef,
+				}
+				repoMock.On("GetRepositoryById", mock.Anything, int64(4)).Return(&r, nil).Once()
+			},
+			args:    model.AddScanningRequest{RepoId: 4, Mode: "diff", BaseRef: "main"},
+			want:    model.ScanningResponse{},
+			wantErr: true,
+		},
+		{
+			name: "settings error",
+			mock: func() {
+				r := model.Repository{
+					IsActive: true,
+				}
+				repoMock.On("GetRepositoryById", mock.Anything, int64(5)).Return(&r, nil).Once()
+				repoMock.On("GetRepositorySettings", mock.Anything, int64(5)).Return(nil, serror.New("connection refused")).Once()
+			},
+			args:    model.AddScanningRequest{RepoId: 5},
+			want:    model.ScanningResponse{},
+			wantErr: true,
+		},
 	}
 
 	for _, test := range listTests {
 		test.mock()
 
+		// Pool without workers never consumes the queue
 		scanUsecase := scanningUsecase{
 			repositoryRepository: repoMock,
 			scanningRepository:   scanMock,
 			trxRepository:        trxMock,
+			workers:              newScanWorkers(0),
 		}
 
-		res, err := scanUsecase.AddNewScanning(test.args)
+		res, err := scanUsecase.AddNewScanning(context.Background(), test.args)
 
 		if (err != nil) != test.wantErr {
 			t.Errorf("AddNewScanning() got error : %s", err)
@@ -165,10 +331,244 @@ func TestAddNewScanning(t *testing.T) {
 	}
 }
 
-func TestStartScanningInQueue(t *testing.T) {
+func TestAddUploadScanning(t *testing.T) {
+	repoMock := new(mocks.IRepositoryRepository)
+	scanMock := new(mocks.IScanningRepository)
+	archiveMock := new(mocks.IArchiveRepository)
+	trxMock := new(mocks.ITrxRepository)
+
+	archive := model.UploadArchive{
+		Filename: "drop.zip",
+		Size:     3,
+		File:     strings.NewReader("zip"),
+	}
+
+	listTests := []struct {
+		name    string
+		mock    func()
+		args    model.AddUploadScanningRequest
+		want    model.ScanningResponse
+		wantErr bool
+	}{
+		{
+			name: "ok",
+			mock: func() {
+				tx := model.Trx{
+					DB: &sqlx.DB{},
+				}
+
+				archiveMock.On("Extract", archive).Return("/tmp/reposcan-uploads/upload1", nil).Once()
+				trxMock.On("Create", mock.Anything).Return(&tx, nil).Once()
+				repoMock.On("AddRepository", mock.Anything, &tx, model.AddRepositoryRequest{
+					Name:     "drop.zip",
+					Url:      "/tmp/reposcan-uploads/upload1",
+					IsUpload: true,
+				}).Return(model.AddRepositoryResponse{Id: 5, IsUpload: true}, nil).Once()
+				scanMock.On("AddNewScanning", mock.Anything, &tx, model.AddScanningRequest{
+					RepoId:     5,
+					Mode:       "standard",
+					IsFullScan: true,
+				}).Return(model.ScanningResponse{Id: 20, RepoId: 5, Status: "queued"}, nil).Once()
+			},
+			args: model.AddUploadScanningRequest{Archive: archive},
+			want: model.ScanningResponse{Id: 20, RepoId: 5, Status: "queued"},
+		},
+		{
+			name: "unsafe archive",
+			mock: func() {
+				archiveMock.On("Extract", archive).Return("", serror.Newi(400, "Archive entry escapes extract directory")).Once()
+			},
+			args:    model.AddUploadScanningRequest{Name: "vendor drop", Archive: archive},
+			want:    model.ScanningResponse{},
+			wantErr: true,
+		},
+		{
+			name: "extracted archive is removed on failure",
+			mock: func() {
+				tx := model.Trx{
+					DB: &sqlx.DB{},
+				}
+
+				archiveMock.On("Extract", archive).Return("/tmp/reposcan-uploads/upload2", nil).Once()
+				trxMock.On("Create", mock.Anything).Return(&tx, nil).Once()
+				repoMock.On("AddRepository", mock.Anything, &tx, model.AddRepositoryRequest{
+					Name:     "vendor drop",
+					Url:      "/tmp/reposcan-uploads/upload2",
+					IsUpload: true,
+				}).Return(model.AddRepositoryResponse{}, serror.New("connection refused")).Once()
+				archiveMock.On("Remove", "/tmp/reposcan-uploads/upload2").Return(nil).Once()
+			},
+			args:    model.AddUploadScanningRequest{Name: "vendor drop", Archive: archive},
+			want:    model.ScanningResponse{},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range listTests {
+		test.mock()
+
+		// Pool without workers never consumes the queue
+		scanUsecase := scanningUsecase{
+			repositoryRepository: repoMock,
+			scanningRepository:   scanMock,
+			archiveRepository:    archiveMock,
+			trxRepository:        trxMock,
+			workers:              newScanWorkers(0),
+		}
+
+		res, err := scanUsecase.AddUploadScanning(context.Background(), test.args)
+
+		if (err != nil) != test.wantErr {
+			t.Errorf("AddUploadScanning() got error : %s", err)
+		}
+		assert.Equal(t, test.want, res)
+	}
+	archiveMock.AssertExpectations(t)
+}
+
+func TestScanningUsecaseWithoutWorkers(t *testing.T) {
+	repoMock := new(mocks.IRepositoryRepository)
+	scanMock := new(mocks.IScanningRepository)
+	archiveMock := new(mocks.IArchiveRepository)
+	trxMock := new(mocks.ITrxRepository)
+	grabMock := new(mocks.IGrabScanner)
+	tx := model.Trx{
+		DB: &sqlx.DB{},
+	}
+	archive := model.UploadArchive{
+		Filename: "drop.zip",
+		Size:     3,
+		File:     strings.NewReader("zip"),
+	}
+
+	repoMock.On("GetRepositoryById", mock.Anything, int64(3)).Return(&model.Repository{IsActive: true}, nil).Once()
+	repoMock.On("GetRepositorySettings", mock.Anything, int64(3)).Return(nil, nil).Once()
+	archiveMock.On("Extract", archive).Return("/tmp/reposcan-uploads/upload1", nil).Once()
+	repoMock.On("AddRepository", mock.Anything, &tx, mock.Anything).Return(model.AddRepositoryResponse{Id: 5, IsUpload: true}, nil).Once()
+	trxMock.On("Create", mock.Anything).Return(&tx, nil).Twice()
+	scanMock.On("AddNewScanning", mock.Anything, &tx, mock.Anything).Return(model.ScanningResponse{Id: 10, Status: "queued"}, nil).Once()
+	scanMock.On("AddNewScanning", mock.Anything, &tx, mock.Anything).Return(model.ScanningResponse{Id: 20, Status: "queued"}, nil).Once()
+
+	// API only service queues scannings for workers of other services
+	scanUsecase := NewScanningUsecase(internal.RepositoryStore{
+		RepositoryRepo: repoMock,
+		ScanningRepo:   scanMock,
+		ArchiveRepo:    archiveMock,
+	}, trxMock, grabMock, model.ScanningOption{Workers: 0, MaxAttempts: 3})
+
+	_, err := scanUsecase.AddNewScanning(context.Background(), model.AddScanningRequest{RepoId: 3})
+	assert.Nil(t, err)
+	_, err = scanUsecase.AddUploadScanning(context.Background(), model.AddUploadScanningRequest{Archive: archive})
+	assert.Nil(t, err)
+	assert.Nil(t, scanUsecase.StartScanningInQueue(context.Background()))
+
+	// Queue is kicked asynchronously, which must claim nothing
+	time.Sleep(50 * time.Millisecond)
+	scanMock.AssertNotCalled(t, "ClaimScanning", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	grabMock.AssertNotCalled(t, "StartScanningSession", mock.Anything, mock.Anything)
+	scanMock.AssertExpectations(t)
+	repoMock.AssertExpectations(t)
+}
+
+func TestStartScanningInQueueLeaseLost(t *testing.T) {
+	repoMock := new(mocks.IRepositoryRepository)
+	scanMock := new(mocks.IScanningRepository)
+	grabMock := new(mocks.IGrabScanner)
+	lease := 30 * time.Millisecond
+
+	scanning := model.ScanningListResponse{
+		Id:         16,
+		RepoId:     3,
+		Url:        "github.com/jquery/jquery",
+		Status:     "in_progress",
+		IsFullScan: true,
+		IsUpload:   true,
+	}
+	scanMock.On("ClaimScanning", mock.Anything, "scanner-1/1", lease, time.Duration(0)).Return(&scanning, nil).Once()
+	repoMock.On("GetRepositorySettings", mock.Anything, int64(3)).Return(nil, nil).Once()
+	// Lease is renewed once, then it is reaped and claimed by another worker
+	scanMock.On("RenewScanningLease", mock.Anything, int64(16), "scanner-1/1", lease).Return(true, nil).Once()
+	scanMock.On("RenewScanningLease", mock.Anything, int64(16), "scanner-1/1", lease).Return(false, nil).Once()
+	grabMock.On("StartScanningSession", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		select {
+		case <-args.Get(0).(context.Context).Done():
+		case <-time.After(5 * time.Second):
+			t.Error("scanning is not stopped once its lease is lost")
+		}
+	}).Return(model.ScanResult{}, serror.New("context canceled")).Once()
+	scanMock.On("ClaimScanning", mock.Anything, "scanner-1/1", lease, time.Duration(0)).Return(nil, nil).Once()
+
+	scanUsecase := scanningUsecase{
+		repositoryRepository: repoMock,
+		scanningRepository:   scanMock,
+		grabScanner:          grabMock,
+		option:               model.ScanningOption{Instance: "scanner-1", Lease: lease},
+		workers:              newScanWorkers(1),
+		runningScans:         newRunningScans(),
+	}
+
+	err := scanUsecase.StartScanningInQueue(context.Background())
+	assert.Nil(t, err)
+	repoMock.AssertExpectations(t)
+	scanMock.AssertExpectations(t)
+	grabMock.AssertExpectations(t)
+	// Result is discarded and upload is kept since scanning is no longer claimed by the worker
+	scanMock.AssertNotCalled(t, "EditScanningStatusById", mock.Anything, mock.Anything, mock.Anything)
+	repoMock.AssertNotCalled(t, "DeactivateUploadRepository", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestStartScanningInQueueUpload(t *testing.T) {
 	repoMock := new(mocks.IRepositoryRepository)
 	scanMock := new(mocks.IScanningRepository)
+	archiveMock := new(mocks.IArchiveRepository)
 	grabMock := new(mocks.IGrabScanner)
+	dir := "/tmp/reposcan-uploads/upload1"
+
+	scanning := model.ScanningListResponse{
+		Id:          17,
+		RepoId:      5,
+		Url:         dir,
+		IsUpload:    true,
+		Status:      "in_progress",
+		IsFullScan:  true,
+		Attempts:    1,
+		MaxAttempts: 3,
+	}
+	scanMock.On("ClaimScanning", mock.Anything, "scanner-1/1", time.Duration(0), time.Duration(0)).Return(&scanning, nil).Once()
+	repoMock.On("GetRepositorySettings", mock.Anything, int64(5)).Return(nil, nil).Once()
+	grabMock.On("StartScanningSession", mock.Anything, mock.Anything).Return(model.ScanResult{}, serror.New("exit status 128")).Once()
+	scanMock.On("EditScanningStatusById", mock.Anything, mock.Anything, mock.MatchedBy(func(req model.EditScanningStatusRequest) bool {
+		return req.Id == 17 && req.Status == "failure"
+	})).Return(model.ScanningResponse{Id: 17, RepoId: 5, Status: "failure"}, nil).Once()
+	// Upload repository is deactivated and its extracted archive is removed once its scanning is finished
+	repoMock.On("DeactivateUploadRepository", mock.Anything, mock.Anything, int64(5)).Return(&dir, nil).Once()
+	archiveMock.On("Remove", dir).Return(nil).Once()
+	scanMock.On("ClaimScanning", mock.Anything, "scanner-1/1", time.Duration(0), time.Duration(0)).Return(nil, nil).Once()
+
+	scanUsecase := scanningUsecase{
+		repositoryRepository: repoMock,
+		scanningRepository:   scanMock,
+		archiveRepository:    archiveMock,
+		grabScanner:          grabMock,
+		option:               model.ScanningOption{Instance: "scanner-1"},
+		workers:              newScanWorkers(1),
+		runningScans:         newRunningScans(),
+	}
+
+	err := scanUsecase.StartScanningInQueue(context.Background())
+	assert.Nil(t, err)
+	repoMock.AssertExpectations(t)
+	scanMock.AssertExpectations(t)
+	archiveMock.AssertExpectations(t)
+	grabMock.AssertExpectations(t)
+}
+
+func TestReapScannings(t *testing.T) {
+	scanMock := new(mocks.IScanningRepository)
+	repoMock := new(mocks.IRepositoryRepository)
+	archiveMock := new(mocks.IArchiveRepository)
+	dir := "/tmp/reposcan-uploads/upload1"
+	claimedBy := "scanner-2:7/1"
 
 	listTests := []struct {
 		name    string
@@ -178,29 +578,1320 @@ func TestStartScanningInQueue(t *testing.T) {
 		{
 			name: "ok",
 			mock: func() {
-				scanMock.On("GetScanningList", mock.Anything).Return([]model.ScanningListResponse{}, nil).Once()
+				scanMock.On("ReapExpiredScannings", mock.Anything).Return([]model.ReapedScanning{
+					{Id: 10, RepoId: 3, Status: "queued", Attempts: 1, ClaimedBy: &claimedBy},
+					{Id: 11, RepoId: 3, Status: "dead", Attempts: 3, ClaimedBy: &claimedBy},
+					{Id: 12, RepoId: 5, Status: "dead", Attempts: 3, ClaimedBy: &claimedBy},
+				}, nil).Once()
+				// Extracted archive of dead upload scanning is removed
+				repoMock.On("DeactivateUploadRepository", mock.Anything, mock.Anything, int64(3)).Return(nil, nil).Once()
+				repoMock.On("DeactivateUploadRepository", mock.Anything, mock.Anything, int64(5)).Return(&dir, nil).Once()
+				archiveMock.On("Remove", dir).Return(nil).Once()
+			},
+			wantErr: false,
+		},
+		{
+			name: "nothing expired",
+			mock: func() {
+				scanMock.On("ReapExpiredScannings", mock.Anything).Return(nil, nil).Once()
 			},
 			wantErr: false,
 		},
+		{
+			name: "error",
+			mock: func() {
+				scanMock.On("ReapExpiredScannings", mock.Anything).Return(nil, serror.New("connection refused")).Once()
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, test := range listTests {
 		test.mock()
 
+		// Pool without workers never consumes the queue
 		scanUsecase := scanningUsecase{
 			repositoryRepository: repoMock,
 			scanningRepository:   scanMock,
-			grabScanner:          grabMock,
+			archiveRepository:    archiveMock,
+			workers:              newScanWorkers(0),
 		}
 
-		err := scanUsecase.StartScanningInQueue()
+		err := scanUsecase.reapScannings(context.Background())
+		scanMock.AssertExpectations(t)
+		repoMock.AssertExpectations(t)
+		archiveMock.AssertExpectations(t)
 
 		if (err != nil) != test.wantErr {
-			t.Errorf("StartScanningInQueue() got error : %s", err)
+			t.Errorf("reapScannings() got error : %s", err)
 		}
+	}
+}
 
-		if test.wantErr {
-			assert.Error(t, err.Cause())
+func TestScheduleScannings(t *testing.T) {
+	repoMock := new(mocks.IRepositoryRepository)
+	scanMock := new(mocks.IScanningRepository)
+	trxMock := new(mocks.ITrxRepository)
+	defaultRef := "main"
+	dueAt := time.Date(2022, 12, 20, 2, 0, 0, 0, time.UTC)
+	priority := 20
+	tx := model.Trx{
+		DB: &sqlx.DB{},
+	}
+	isNext := mock.MatchedBy(func(next time.Time) bool {
+		return next.After(time.Now())
+	})
+
+	listTests := []struct {
+		name    string
+		mock    func()
+		wantErr bool
+	}{
+		{
+			name: "ok",
+			mock: func() {
+				repoMock.On("GetScheduledRepositories", mock.Anything).Return([]model.ScheduledRepository{
+					{Id: 3, DefaultRef: &defaultRef, ScanSchedule: "0 2 * * *", ScanTimezone: "Asia/Jakarta", NextScheduledScanAt: dueAt},
+					{Id: 4, ScanSchedule: "@hourly", NextScheduledScanAt: dueAt},
+				}, nil).Once()
+				repoMock.On("GetRepositorySettings", mock.Anything, int64(3)).Return(&model.RepositorySettings{Priority: 20}, nil).Once()
+				repoMock.On("GetRepositorySettings", mock.Anything, int64(4)).Return(nil, nil).Once()
+				trxMock.On("Create", mock.Anything).Return(&tx, nil).Twice()
+				repoMock.On("AdvanceRepositorySchedule", mock.Anything, &tx, int64(3), dueAt, isNext).Return(true, nil).Once()
+				// Another replica queued it meanwhile
+				repoMock.On("AdvanceRepositorySchedule", mock.Anything, &tx, int64(4), dueAt, isNext).Return(false, nil).Once()
+				scanMock.On("AddNewScanning", mock.Anything, &tx, model.AddScanningRequest{
+					RepoId:      3,
+					Mode:        "standard",
+					Ref:         "main",
+					MaxAttempts: 3,
+					Priority:    &priority,
+				}).Return(model.ScanningResponse{Id: 10, RepoId: 3, Status: "queued"}, nil).Once()
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid schedule is skipped",
+			mock: func() {
+				repoMock.On("GetScheduledRepositories", mock.Anything).Return([]model.ScheduledRepository{
+					{Id: 5, ScanSchedule: "0 2 30 2 *", NextScheduledScanAt: dueAt},
+				}, nil).Once()
+			},
+			wantErr: false,
+		},
+		{
+			name: "add scanning error",
+			mock: func() {
+				repoMock.On("GetScheduledRepositories", mock.Anything).Return([]model.ScheduledRepository{
+					{Id: 6, ScanSchedule: "*/15 * * * *", NextScheduledScanAt: dueAt},
+				}, nil).Once()
+				repoMock.On("GetRepositorySettings", mock.Anything, int64(6)).Return(nil, nil).Once()
+				trxMock.On("Create", mock.Anything).Return(&tx, nil).Once()
+				repoMock.On("AdvanceRepositorySchedule", mock.Anything, &tx, int64(6), dueAt, isNext).Return(true, nil).Once()
+				scanMock.On("AddNewScanning", mock.Anything, &tx, mock.Anything).Return(model.ScanningResponse{}, serror.New("connection refused")).Once()
+			},
+			wantErr: false,
+		},
+		{
+			name: "error",
+			mock: func() {
+				repoMock.On("GetScheduledRepositories", mock.Anything).Return(nil, serror.New("connection refused")).Once()
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range listTests {
+		test.mock()
+
+		// Pool without workers never consumes the queue
+		scanUsecase := scanningUsecase{
+			repositoryRepository: repoMock,
+			scanningRepository:   scanMock,
+			trxRepository:        trxMock,
+			workers:              newScanWorkers(0),
+			option:               model.ScanningOption{MaxAttempts: 3},
+		}
+
+		err := scanUsecase.scheduleScannings(context.Background())
+		repoMock.AssertExpectations(t)
+		scanMock.AssertExpectations(t)
+		trxMock.AssertExpectations(t)
+
+		if (err != nil) != test.wantErr {
+			t.Errorf("scheduleScannings() %s got error : %s", test.name, err)
+		}
+	}
+}
+
+func TestGetFindingList(t *testing.T) {
+	scanMock := new(mocks.IScanningRepository)
+
+	listTests := []struct {
+		name    string
+		mock    func()
+		args    model.FindingListRequest
+		want    []model.FindingListResponse
+		wantErr bool
+	}{
+		{
+			name: "ok",
+			mock: func() {
+				req := model.FindingListRequest{ScanningId: 10, Limit: 10, Page: 1, Severity: "high"}
+				scanMock.On("GetScanningById", mock.Anything, int64(10)).Return(&model.ScanningResponse{Id: 10, Status: "success"}, nil).Once()
+				scanMock.On("GetFindingList", mock.Anything, req).Return([]model.FindingListResponse{
+					{ScanningId: 10, FindingId: "abc", Rule: "aws-key", Severity: "high"},
+				}, nil).Once()
+			},
+			args: model.FindingListRequest{ScanningId: 10, Limit: 10, Page: 1, Severity: "high"},
+			want: []model.FindingListResponse{
+				{ScanningId: 10, FindingId: "abc", Rule: "aws-key", Severity: "high"},
+			},
+		},
+		{
+			name: "scanning not found",
+			mock: func() {
+				scanMock.On("GetScanningById", mock.Anything, int64(11)).Return(nil, nil).Once()
+			},
+			args:    model.FindingListRequest{ScanningId: 11, Limit: 10, Page: 1},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range listTests {
+		test.mock()
+
+		scanUsecase := scanningUsecase{
+			scanningRepository: scanMock,
+		}
+
+		res, err := scanUsecase.GetFindingList(context.Background(), test.args)
+
+		if (err != nil) != test.wantErr {
+			t.Errorf("GetFindingList() got error : %s", err)
 		}
+		assert.Equal(t, test.want, res)
+	}
+	scanMock.AssertExpectations(t)
+}
+
+func TestTriageFinding(t *testing.T) {
+	scanMock := new(mocks.IScanningRepository)
+	trxMock := new(mocks.ITrxRepository)
+	assignee := "alice"
+	tx := model.Trx{
+		DB: &sqlx.DB{},
 	}
+	finding := model.FindingListResponse{
+		ScanningId:   10,
+		RepositoryId: 3,
+		FindingId:    "abc",
+		Rule:         "aws-key",
+		FilePath:     "test/app.yml",
+		Fingerprint:  "5d41402a",
+		TriageStatus: "open",
+	}
+
+	listTests := []struct {
+		name    string
+		mock    func()
+		args    model.TriageFindingRequest
+		want    model.FindingTriageResponse
+		wantErr bool
+	}{
+		{
+			name: "false positive with comment",
+			mock: func() {
+				req := model.TriageFindingRequest{
+					ScanningId:   10,
+					FindingId:    "abc",
+					RepositoryId: 3,
+					Fingerprint:  "5d41402a",
+					Rule:         "aws-key",
+					FilePath:     "test/app.yml",
+					Status:       "false_positive",
+					Assignee:     &assignee,
+					Comment:      "Dummy key of test fixture",
+					TriagedBy:    "bob",
+				}
+				scanMock.On("GetFindingById", mock.Anything, int64(10), "abc").Return(&finding, nil).Once()
+				trxMock.On("Create", mock.Anything).Return(&tx, nil).Once()
+				scanMock.On("EditFindingTriage", mock.Anything, &tx, req).Return(model.FindingTriageResponse{
+					RepositoryId: 3,
+					Fingerprint:  "5d41402a",
+					TriageStatus: "false_positive",
+					Assignee:     &assignee,
+				}, nil).Once()
+				scanMock.On("AddFindingComment", mock.Anything, &tx, model.AddFindingCommentRequest{
+					ScanningId:   10,
+					FindingId:    "abc",
+					RepositoryId: 3,
+					Fingerprint:  "5d41402a",
+					Rule:         "aws-key",
+					FilePath:     "test/app.yml",
+					Comment:      "Dummy key of test fixture",
+					CreatedBy:    "bob",
+				}).Return(model.FindingComment{Id: 1}, nil).Once()
+			},
+			args: model.TriageFindingRequest{
+				ScanningId: 10,
+				FindingId:  "abc",
+				Status:     "false_positive",
+				Assignee:   &assignee,
+				Comment:    "Dummy key of test fixture",
+				TriagedBy:  "bob",
+			},
+			want: model.FindingTriageResponse{
+				RepositoryId: 3,
+				Fingerprint:  "5d41402a",
+				TriageStatus: "false_positive",
+				Assignee:     &assignee,
+			},
+		},
+		{
+			name: "finding not found",
+			mock: func() {
+				scanMock.On("GetFindingById", mock.Anything, int64(10), "def").Return(nil, nil).Once()
+			},
+			args:    model.TriageFindingRequest{ScanningId: 10, FindingId: "def", Status: "confirmed", TriagedBy: "bob"},
+			wantErr: true,
+		},
+		{
+			name: "finding without fingerprint",
+			mock: func() {
+				legacy := model.FindingListResponse{ScanningId: 9, RepositoryId: 3, FindingId: "old"}
+				scanMock.On("GetFindingById", mock.Anything, int64(9), "old").Return(&legacy, nil).Once()
+			},
+			args:    model.TriageFindingRequest{ScanningId: 9, FindingId: "old", Status: "confirmed", TriagedBy: "bob"},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range listTests {
+		test.mock()
+
+		scanUsecase := scanningUsecase{
+			scanningRepository: scanMock,
+			trxRepository:      trxMock,
+		}
+
+		res, err := scanUsecase.TriageFinding(context.Background(), test.args)
+
+		if (err != nil) != test.wantErr {
+			t.Errorf("TriageFinding() got error : %s", err)
+		}
+		assert.Equal(t, test.want, res)
+	}
+	scanMock.AssertExpectations(t)
+	trxMock.AssertExpectations(t)
+}
+
+func TestGetFindingComments(t *testing.T) {
+	scanMock := new(mocks.IScanningRepository)
+
+	// Thread is shared by findings of the same fingerprint in other scannings
+	finding := model.FindingListResponse{ScanningId: 12, RepositoryId: 3, FindingId: "xyz", Fingerprint: "5d41402a"}
+	comments := []model.FindingComment{
+		{Id: 1, RepositoryId: 3, Fingerprint: "5d41402a", ScanningId: 10, FindingId: "abc", Comment: "Dummy key of test fixture", CreatedBy: "bob"},
+	}
+	scanMock.On("GetFindingById", mock.Anything, int64(12), "xyz").Return(&finding, nil).Once()
+	scanMock.On("GetFindingComments", mock.Anything, int64(3), "5d41402a").Return(comments, nil).Once()
+
+	scanUsecase := scanningUsecase{
+		scanningRepository: scanMock,
+	}
+
+	res, err := scanUsecase.GetFindingComments(context.Background(), 12, "xyz")
+	assert.Nil(t, err)
+	assert.Equal(t, comments, res)
+	scanMock.AssertExpectations(t)
+}
+
+func TestAddBaseline(t *testing.T) {
+	scanMock := new(mocks.IScanningRepository)
+	repoMock := new(mocks.IRepositoryRepository)
+	trxMock := new(mocks.ITrxRepository)
+	tx := &model.Trx{DB: &sqlx.DB{}}
+	trxMock.On("Create", mock.Anything).Return(tx, nil)
+
+	listTests := []struct {
+		name    string
+		mock    func()
+		args    model.AddBaselineRequest
+		want    model.BaselineResponse
+		wantErr bool
+	}{
+		{
+			name: "ok",
+			mock: func() {
+				scanMock.On("GetScanningById", mock.Anything, int64(10)).Return(&model.ScanningResponse{Id: 10, RepoId: 3, Status: "success", Mode: "standard"}, nil).Once()
+				// Repository is locked before its next version is taken in the same transaction
+				repoMock.On("LockRepository", mock.Anything, tx, int64(3)).Return(nil).Once()
+				scanMock.On("AddBaseline", mock.Anything, tx, model.AddBaselineRequest{RepositoryId: 3, ScanningId: 10, CreatedBy: "bob"}).
+					Return(model.BaselineResponse{Id: 7, RepositoryId: 3, Version: 2, ScanningId: 10, IsActive: true}, nil).Once()
+			},
+			args: model.AddBaselineRequest{RepositoryId: 3, ScanningId: 10, CreatedBy: "bob"},
+			want: model.BaselineResponse{Id: 7, RepositoryId: 3, Version: 2, ScanningId: 10, IsActive: true},
+		},
+		{
+			name: "repository cannot be locked",
+			mock: func() {
+				scanMock.On("GetScanningById", mock.Anything, int64(14)).Return(&model.ScanningResponse{Id: 14, RepoId: 3, Status: "success", Mode: "standard"}, nil).Once()
+				repoMock.On("LockRepository", mock.Anything, tx, int64(3)).Return(serror.New("canceling statement due to lock timeout")).Once()
+			},
+			args:    model.AddBaselineRequest{RepositoryId: 3, ScanningId: 14, CreatedBy: "bob"},
+			wantErr: true,
+		},
+		{
+			name: "scanning of another repository",
+			mock: func() {
+				scanMock.On("GetScanningById", mock.Anything, int64(11)).Return(&model.ScanningResponse{Id: 11, RepoId: 4, Status: "success", Mode: "standard"}, nil).Once()
+			},
+			args:    model.AddBaselineRequest{RepositoryId: 3, ScanningId: 11, CreatedBy: "bob"},
+			wantErr: true,
+		},
+		{
+			name: "diff scanning",
+			mock: func() {
+				scanMock.On("GetScanningById", mock.Anything, int64(12)).Return(&model.ScanningResponse{Id: 12, RepoId: 3, Status: "success", Mode: "diff"}, nil).Once()
+			},
+			args:    model.AddBaselineRequest{RepositoryId: 3, ScanningId: 12, CreatedBy: "bob"},
+			wantErr: true,
+		},
+		{
+			name: "failed scanning",
+			mock: func() {
+				scanMock.On("GetScanningById", mock.Anything, int64(13)).Return(&model.ScanningResponse{Id: 13, RepoId: 3, Status: "failure", Mode: "standard"}, nil).Once()
+			},
+			args:    model.AddBaselineRequest{RepositoryId: 3, ScanningId: 13, CreatedBy: "bob"},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range listTests {
+		test.mock()
+
+		scanUsecase := scanningUsecase{
+			repositoryRepository: repoMock,
+			scanningRepository:   scanMock,
+			trxRepository:        trxMock,
+		}
+
+		res, err := scanUsecase.AddBaseline(context.Background(), test.args)
+
+		if (err != nil) != test.wantErr {
+			t.Errorf("AddBaseline() got error : %s", err)
+		}
+		assert.Equal(t, test.want, res)
+	}
+	scanMock.AssertExpectations(t)
+	repoMock.AssertExpectations(t)
+}
+
+func TestActivateBaseline(t *testing.T) {
+	scanMock := new(mocks.IScanningRepository)
+
+	scanMock.On("ActivateBaseline", mock.Anything, mock.Anything, model.ActivateBaselineRequest{RepositoryId: 3, Version: 1, ActivatedBy: "alice"}).
+		Return(&model.BaselineResponse{Id: 5, RepositoryId: 3, Version: 1, IsActive: true}, nil).Once()
+	scanMock.On("ActivateBaseline", mock.Anything, mock.Anything, model.ActivateBaselineRequest{RepositoryId: 3, Version: 9, ActivatedBy: "alice"}).
+		Return(nil, nil).Once()
+
+	scanUsecase := scanningUsecase{
+		scanningRepository: scanMock,
+	}
+
+	res, err := scanUsecase.ActivateBaseline(context.Background(), model.ActivateBaselineRequest{RepositoryId: 3, Version: 1, ActivatedBy: "alice"})
+	assert.Nil(t, err)
+	assert.Equal(t, model.BaselineResponse{Id: 5, RepositoryId: 3, Version: 1, IsActive: true}, res)
+
+	// Rolling back to version which does not exist
+	_, err = scanUsecase.ActivateBaseline(context.Background(), model.ActivateBaselineRequest{RepositoryId: 3, Version: 9, ActivatedBy: "alice"})
+	assert.NotNil(t, err)
+	assert.Equal(t, http.StatusBadRequest, err.Code())
+	scanMock.AssertExpectations(t)
+}
+
+func TestCancelScanning(t *testing.T) {
+	scanMock := new(mocks.IScanningRepository)
+	repoMock := new(mocks.IRepositoryRepository)
+	archiveMock := new(mocks.IArchiveRepository)
+	dir := "/tmp/reposcan-uploads/upload1"
+
+	// Scanning id 12 is running in this process
+	running := newRunningScans()
+	runningCtx, cancel := context.WithCancel(context.Background())
+	running.add(12, cancel)
+
+	listTests := []struct {
+		name    string
+		mock    func()
+		args    int64
+		want    model.ScanningResponse
+		wantErr bool
+	}{
+		{
+			name: "cancel queued",
+			mock: func() {
+				scanMock.On("GetScanningById", mock.Anything, int64(10)).Return(&model.ScanningResponse{Id: 10, Status: "queued"}, nil).Once()
+				scanMock.On("CancelScanningById", mock.Anything, mock.Anything, int64(10)).Return(&model.ScanningResponse{Id: 10, RepoId: 3, Status: "cancelled"}, nil).Once()
+				// Repository is not an upload
+				repoMock.On("DeactivateUploadRepository", mock.Anything, mock.Anything, int64(3)).Return(nil, nil).Once()
+			},
+			args: 10,
+			want: model.ScanningResponse{Id: 10, RepoId: 3, Status: "cancelled"},
+		},
+		{
+			name: "cancel queued upload",
+			mock: func() {
+				scanMock.On("GetScanningById", mock.Anything, int64(14)).Return(&model.ScanningResponse{Id: 14, Status: "queued"}, nil).Once()
+				scanMock.On("CancelScanningById", mock.Anything, mock.Anything, int64(14)).Return(&model.ScanningResponse{Id: 14, RepoId: 5, Status: "cancelled"}, nil).Once()
+				// Extracted archive is removed once upload repository is deactivated
+				repoMock.On("DeactivateUploadRepository", mock.Anything, mock.Anything, int64(5)).Return(&dir, nil).Once()
+				archiveMock.On("Remove", dir).Return(nil).Once()
+			},
+			args: 14,
+			want: model.ScanningResponse{Id: 14, RepoId: 5, Status: "cancelled"},
+		},
+		{
+			name: "already finished",
+			mock: func() {
+				scanMock.On("GetScanningById", mock.Anything, int64(11)).Return(&model.ScanningResponse{Id: 11, Status: "success"}, nil).Once()
+			},
+			args:    11,
+			want:    model.ScanningResponse{},
+			wantErr: true,
+		},
+		{
+			name: "cancel running",
+			mock: func() {
+				scanMock.On("GetScanningById", mock.Anything, int64(12)).Return(&model.ScanningResponse{Id: 12, Status: "in_progress"}, nil).Once()
+			},
+			args: 12,
+			want: model.ScanningResponse{Id: 12, Status: "in_progress"},
+		},
+		{
+			name: "not found",
+			mock: func() {
+				scanMock.On("GetScanningById", mock.Anything, int64(13)).Return(nil, nil).Once()
+			},
+			args:    13,
+			want:    model.ScanningResponse{},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range listTests {
+		test.mock()
+
+		scanUsecase := scanningUsecase{
+			repositoryRepository: repoMock,
+			scanningRepository:   scanMock,
+			archiveRepository:    archiveMock,
+			runningScans:         running,
+		}
+
+		res, err := scanUsecase.CancelScanning(context.Background(), test.args)
+
+		if (err != nil) != test.wantErr {
+			t.Errorf("CancelScanning() got error : %s", err)
+		}
+		assert.Equal(t, test.want, res)
+	}
+	scanMock.AssertExpectations(t)
+	repoMock.AssertExpectations(t)
+	archiveMock.AssertExpectations(t)
+	assert.Equal(t, context.Canceled, runningCtx.Err())
+}
+
+func TestRevealFinding(t *testing.T) {
+	scanMock := new(mocks.IScanningRepository)
+	secretMock := new(mocks.ISecretRepository)
+
+	revealedAt := time.Date(2022, time.December, 8, 1, 2, 3, 0, time.UTC)
+	secret := model.FindingSecret{ScanningId: 10, FindingId: "abc", SecretEncrypted: "c2VjcmV0"}
+
+	listTests := []struct {
+		name    string
+		mock    func()
+		args    model.RevealFindingRequest
+		want    model.RevealFindingResponse
+		wantErr bool
+	}{
+		{
+			name: "ok",
+			mock: func() {
+				req := model.RevealFindingRequest{ScanningId: 10, FindingId: "abc", Reason: "rotate token", RevealedBy: "alice"}
+				scanMock.On("GetFindingSecret", mock.Anything, int64(10), "abc").Return(&secret, nil).Once()
+				secretMock.On("Reveal", secret).Return("_authToken=0a1b2c3d", nil).Once()
+				scanMock.On("AddFindingReveal", mock.Anything, mock.Anything, req).Return(model.RevealFindingResponse{
+					ScanningId: 10,
+					FindingId:  "abc",
+					Reason:     "rotate token",
+					RevealedBy: "alice",
+					RevealedAt: revealedAt,
+				}, nil).Once()
+			},
+			args: model.RevealFindingRequest{ScanningId: 10, FindingId: "abc", Reason: "rotate token", RevealedBy: "alice"},
+			want: model.RevealFindingResponse{
+				ScanningId:  10,
+				FindingId:   "abc",
+				LineContent: "_authToken=0a1b2c3d",
+				Reason:      "rotate token",
+				RevealedBy:  "alice",
+				RevealedAt:  revealedAt,
+			},
+		},
+		{
+			name: "secret is not kept",
+			mock: func() {
+				scanMock.On("GetFindingSecret", mock.Anything, int64(10), "def").Return(nil, nil).Once()
+			},
+			args:    model.RevealFindingRequest{ScanningId: 10, FindingId: "def", Reason: "rotate token", RevealedBy: "alice"},
+			want:    model.RevealFindingResponse{},
+			wantErr: true,
+		},
+		{
+			name: "secret is not returned unless audited",
+			mock: func() {
+				req := model.RevealFindingRequest{ScanningId: 10, FindingId: "abc", Reason: "audit down", RevealedBy: "bob"}
+				scanMock.On("GetFindingSecret", mock.Anything, int64(10), "abc").Return(&secret, nil).Once()
+				secretMock.On("Reveal", secret).Return("_authToken=0a1b2c3d", nil).Once()
+				scanMock.On("AddFindingReveal", mock.Anything, mock.Anything, req).
+					Return(model.RevealFindingResponse{}, serror.New("connection refused")).Once()
+			},
+			args:    model.RevealFindingRequest{ScanningId: 10, FindingId: "abc", Reason: "audit down", RevealedBy: "bob"},
+			want:    model.RevealFindingResponse{},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range listTests {
+		test.mock()
+
+		scanUsecase := scanningUsecase{
+			scanningRepository: scanMock,
+			secretRepository:   secretMock,
+		}
+
+		res, err := scanUsecase.RevealFinding(context.Background(), test.args)
+
+		if (err != nil) != test.wantErr {
+			t.Errorf("RevealFinding() got error : %s", err)
+		}
+		assert.Equal(t, test.want, res)
+	}
+	scanMock.AssertExpectations(t)
+	secretMock.AssertExpectations(t)
+}
+
+// ignoreRules suppresses findings of listed rules like .reposcanignore of scanned work tree
+type ignoreRules map[string]string
+
+func (r ignoreRules) Suppress(findings []model.Finding) {
+	for idx := range findings {
+		if reason, ok := r[findings[idx].Rule]; ok && findings[idx].Suppression == nil {
+			findings[idx].Suppression = &model.FindingSuppression{Source: "ignore_file", Reason: reason}
+		}
+	}
+}
+
+func TestStartScanningInQueue(t *testing.T) {
+	repoMock := new(mocks.IRepositoryRepository)
+	scanMock := new(mocks.IScanningRepository)
+	grabMock := new(mocks.IGrabScanner)
+	secretMock := new(mocks.ISecretRepository)
+	trxMock := new(mocks.ITrxRepository)
+	running := newRunningScans()
+
+	// Successful scanning is finished with its findings, their secrets and fingerprints in a transaction
+	tx := &model.Trx{DB: &sqlx.DB{}}
+	trxMock.On("Create", mock.Anything).Return(tx, nil)
+
+	listTests := []struct {
+		name    string
+		option  model.ScanningOption
+		mock    func()
+		wantErr bool
+	}{
+		{
+			name: "ok",
+			mock: func() {
+				scanMock.On("ClaimScanning", mock.Anything, "scanner-1/1", time.Minute, time.Duration(0)).Return(nil, nil).Once()
+			},
+			wantErr: false,
+		},
+		{
+			name: "scan one",
+			mock: func() {
+				q := []model.ScanningListResponse{
+					{
+						Id:         10,
+						RepoId:     3,
+						Name:       "JQuery",
+						Url:        "github.com/jquery/jquery",
+						Status:     "queued",
+						IsFullScan: true,
+					},
+				}
+				r := model.ScanResult{
+					Engines: []model.ScanEngineInfo{{Name: "grab", Version: "v1"}},
+					Findings: []model.Finding{
+						{ID: "abc", Engine: "grab", FilePath: ".npmrc", Action: "filename", LineContent: "_authToken=0a1b2c3d"},
+					},
+				}
+				redacted := []model.Finding{
+					{ID: "abc", Engine: "grab", FilePath: ".npmrc", Action: "filename", LineContent: "_au****", SecretHash: "9f86d081"},
+				}
+				secrets := []model.FindingSecret{{ScanningId: 10, FindingId: "abc", SecretEncrypted: "c2VjcmV0"}}
+				settings := model.RepositorySettings{SkipPaths: []string{"docs/**"}, ScanTests: true, CommitDepth: 1000}
+
+				scanMock.On("ClaimScanning", mock.Anything, "scanner-1/1", time.Minute, time.Duration(0)).Return(&q[0], nil).Once()
+				repoMock.On("GetRepositorySettings", mock.Anything, int64(3)).Return(&settings, nil).Once()
+				grabMock.On("StartScanningSession", mock.Anything, model.ScanTarget{
+					ScanningId: 10,
+					Url:        "github.com/jquery/jquery",
+					Settings:   settings,
+				}).Return(r, nil).Once()
+				secretMock.On("Redact", int64(10), r.Findings).Return(redacted, secrets, nil).Once()
+				scanMock.On("AddFindingSecrets", mock.Anything, tx, secrets).Return(nil).Once()
+				scanMock.On("GetPreviousScanning", mock.Anything, int64(3), "", int64(10)).Return(nil, nil).Once()
+				scanMock.On("AddFindingFingerprints", mock.Anything, tx, []model.FindingFingerprint{{
+					RepositoryId: 3,
+					ScanningId:   10,
+					Fingerprint:  fingerprintFinding(redacted[0]),
+					FilePath:     ".npmrc",
+					Occurrence:   "new",
+				}}).Return(nil).Once()
+				scanMock.On("GetActiveBaseline", mock.Anything, int64(3)).Return(nil, nil).Once()
+				scanMock.On("AddFindings", mock.Anything, tx, int64(10), mock.MatchedBy(func(findings []model.Finding) bool {
+					return len(findings) == 1 && findings[0].ID == "abc" && findings[0].Occurrence == "new"
+				})).Return(nil).Once()
+				scanMock.On("EditScanningStatusById", mock.Anything, tx, mock.MatchedBy(func(req model.EditScanningStatusRequest) bool {
+					// Only redacted findings are stored, unless scanning is claimed by another worker meanwhile
+					return req.Id == 10 && req.Status == "success" && req.ClaimedBy == "scanner-1/1" &&
+						string(req.Engines) == `[{"name":"grab","version":"v1","capabilities":null}]` &&
+						strings.Contains(string(req.Findings), `"Engine":"grab"`) &&
+						strings.Contains(string(req.Findings), `"SecretHash":"9f86d081"`) &&
+						strings.Contains(string(req.Findings), `"Occurrence":"new"`) &&
+						!strings.Contains(string(req.Findings), "0a1b2c3d")
+				})).Return(model.ScanningResponse{}, nil).Once()
+				scanMock.On("ClaimScanning", mock.Anything, "scanner-1/1", time.Minute, time.Duration(0)).Return(nil, nil).Once()
+			},
+			wantErr: false,
+		},
+		{
+			name: "scan not finished",
+			mock: func() {
+				q := []model.ScanningListResponse{
+					{
+						Id:         19,
+						RepoId:     3,
+						Name:       "JQuery",
+						Url:        "github.com/jquery/jquery",
+						Status:     "queued",
+						IsFullScan: true,
+					},
+				}
+				r := model.ScanResult{
+					Findings: []model.Finding{
+						{ID: "abc", Engine: "grab", FilePath: ".npmrc", Action: "filename", LineContent: "_authToken=0a1b2c3d"},
+					},
+				}
+				redacted := []model.Finding{
+					{ID: "abc", Engine: "grab", FilePath: ".npmrc", Action: "filename", LineContent: "_au****", SecretHash: "9f86d081"},
+				}
+				secrets := []model.FindingSecret{{ScanningId: 19, FindingId: "abc", SecretEncrypted: "c2VjcmV0"}}
+
+				// Secrets and fingerprints are written in the transaction rolled back once scanning cannot be finished
+				scanMock.On("ClaimScanning", mock.Anything, "scanner-1/1", time.Minute, time.Duration(0)).Return(&q[0], nil).Once()
+				repoMock.On("GetRepositorySettings", mock.Anything, int64(3)).Return(nil, nil).Once()
+				grabMock.On("StartScanningSession", mock.Anything, model.ScanTarget{
+					ScanningId: 19,
+					Url:        "github.com/jquery/jquery",
+				}).Return(r, nil).Once()
+				secretMock.On("Redact", int64(19), r.Findings).Return(redacted, secrets, nil).Once()
+				scanMock.On("GetPreviousScanning", mock.Anything, int64(3), "", int64(19)).Return(nil, nil).Once()
+				scanMock.On("GetActiveBaseline", mock.Anything, int64(3)).Return(nil, nil).Once()
+				scanMock.On("AddFindingSecrets", mock.Anything, tx, secrets).Return(nil).Once()
+				scanMock.On("AddFindingFingerprints", mock.Anything, tx, mock.Anything).Return(nil).Once()
+				scanMock.On("AddFindings", mock.Anything, tx, int64(19), mock.Anything).Return(nil).Once()
+				scanMock.On("EditScanningStatusById", mock.Anything, tx, mock.MatchedBy(func(req model.EditScanningStatusRequest) bool {
+					return req.Id == 19 && req.Status == "success"
+				})).Return(model.ScanningResponse{}, serror.New("Scanning id[19] is claimed by another worker")).Once()
+				scanMock.On("ClaimScanning", mock.Anything, "scanner-1/1", time.Minute, time.Duration(0)).Return(nil, nil).Once()
+			},
+			wantErr: false,
+		},
+		{
+			name: "scan since last successful scanning",
+			mock: func() {
+				ref := "develop"
+				q := []model.ScanningListResponse{
+					{
+						Id:     11,
+						RepoId: 3,
+						Name:   "JQuery",
+						Url:    "github.com/jquery/jquery",
+						Status: "queued",
+						Ref:    &ref,
+					},
+				}
+				headCommit := "0a1b2c3d"
+				last := model.ScanningResponse{
+					Id:         10,
+					RepoId:     3,
+					Status:     "success",
+					HeadCommit: &headCommit,
+				}
+				r := model.ScanResult{
+					Engines:     []model.ScanEngineInfo{{Name: "native", Version: "v1"}},
+					SinceCommit: "0a1b2c3d",
+					HeadCommit:  "4e5f6a7b",
+				}
+
+				scanMock.On("ClaimScanning", mock.Anything, "scanner-1/1", time.Minute, time.Duration(0)).Return(&q[0], nil).Once()
+				scanMock.On("GetLastSuccessfulScanning", mock.Anything, int64(3), "develop").Return(&last, nil).Once()
+				repoMock.On("GetRepositorySettings", mock.Anything, int64(3)).Return(nil, nil).Once()
+				grabMock.On("StartScanningSession", mock.Anything, model.ScanTarget{
+					ScanningId:  11,
+					Url:         "github.com/jquery/jquery",
+					Ref:         "develop",
+					SinceCommit: "0a1b2c3d",
+				}).Return(r, nil).Once()
+				secretMock.On("Redact", int64(11), r.Findings).Return([]model.Finding{}, []model.FindingSecret{}, nil).Once()
+				scanMock.On("GetPreviousScanning", mock.Anything, int64(3), "develop", int64(11)).Return(&last, nil).Once()
+				scanMock.On("GetActiveBaseline", mock.Anything, int64(3)).Return(nil, nil).Once()
+				scanMock.On("AddFindings", mock.Anything, mock.Anything, int64(11), []model.Finding{}).Return(nil).Once()
+				scanMock.On("EditScanningStatusById", mock.Anything, mock.Anything, mock.MatchedBy(func(req model.EditScanningStatusRequest) bool {
+					return req.Id == 11 && req.Status == "success" &&
+						req.SinceCommit == "0a1b2c3d" && req.HeadCommit == "4e5f6a7b"
+				})).Return(model.ScanningResponse{}, nil).Once()
+				scanMock.On("ClaimScanning", mock.Anything, "scanner-1/1", time.Minute, time.Duration(0)).Return(nil, nil).Once()
+			},
+			wantErr: false,
+		},
+		{
+			name: "scan diff",
+			mock: func() {
+				baseRef, headRef := "main", "feature"
+				q := []model.ScanningListResponse{
+					{
+						Id:      12,
+						RepoId:  3,
+						Name:    "JQuery",
+						Url:     "github.com/jquery/jquery",
+						Status:  "queued",
+						Mode:    "diff",
+						BaseRef: &baseRef,
+						Ref:     &headRef,
+					},
+				}
+				r := model.ScanResult{
+					Engines:     []model.ScanEngineInfo{{Name: "native", Version: "v1"}},
+					SinceCommit: "0a1b2c3d",
+					HeadCommit:  "4e5f6a7b",
+				}
+
+				// No last successful scanning is looked up since diff is scanned from merge base
+				scanMock.On("ClaimScanning", mock.Anything, "scanner-1/1", time.Minute, time.Duration(0)).Return(&q[0], nil).Once()
+				repoMock.On("GetRepositorySettings", mock.Anything, int64(3)).Return(nil, nil).Once()
+				grabMock.On("StartScanningSession", mock.Anything, model.ScanTarget{
+					ScanningId: 12,
+					Url:        "github.com/jquery/jquery",
+					Mode:       "diff",
+					BaseRef:    "main",
+					Ref:        "feature",
+				}).Return(r, nil).Once()
+				secretMock.On("Redact", int64(12), r.Findings).Return([]model.Finding{}, []model.FindingSecret{}, nil).Once()
+				scanMock.On("GetActiveBaseline", mock.Anything, int64(3)).Return(nil, nil).Once()
+				scanMock.On("AddFindings", mock.Anything, mock.Anything, int64(12), []model.Finding{}).Return(nil).Once()
+				scanMock.On("EditScanningStatusById", mock.Anything, mock.Anything, mock.MatchedBy(func(req model.EditScanningStatusRequest) bool {
+					return req.Id == 12 && req.Status == "success" &&
+						req.SinceCommit == "0a1b2c3d" && req.HeadCommit == "4e5f6a7b"
+				})).Return(model.ScanningResponse{}, nil).Once()
+				scanMock.On("ClaimScanning", mock.Anything, "scanner-1/1", time.Minute, time.Duration(0)).Return(nil, nil).Once()
+			},
+			wantErr: false,
+		},
+		{
+			name: "track findings",
+			mock: func() {
+				q := []model.ScanningListResponse{
+					{
+						Id:     15,
+						RepoId: 5,
+						Name:   "Spring",
+						Url:    "github.com/spring/spring",
+						Status: "queued",
+					},
+				}
+				headCommit := "0a1b2c3d"
+				kept := model.Finding{ID: "k", Rule: "aws-key", FilePath: "app.yml", Line: 3, LineContent: "AKIA****", SecretHash: "11"}
+				moved := model.Finding{ID: "m", Rule: "aws-key", FilePath: "app.yml", Line: 9, LineContent: "AKIA****", SecretHash: "11"}
+				added := model.Finding{ID: "a", Rule: "npm-token", FilePath: ".npmrc", Line: 1, LineContent: "_au****", SecretHash: "22"}
+				removed := model.Finding{ID: "r", Rule: "password", FilePath: "db.yml", Line: 2, LineContent: "pa****", SecretHash: "33"}
+				untouched := model.Finding{ID: "u", Rule: "password", FilePath: "old.yml", Line: 5, LineContent: "pa****", SecretHash: "44"}
+				gone := model.Finding{ID: "g", Rule: "password", FilePath: "db.yml", Line: 7, LineContent: "se****", SecretHash: "55"}
+				for _, f := range []*model.Finding{&kept, &removed, &untouched, &gone} {
+					f.Fingerprint = fingerprintFinding(*f)
+					f.Occurrence = "recurring"
+				}
+				gone.Occurrence = "fixed"
+				// Findings stored before fingerprints were introduced are ignored
+				legacy := model.Finding{ID: "l", Rule: "password", FilePath: "db.yml", LineContent: "****"}
+				previousFindings, _ := json.Marshal([]model.Finding{kept, removed, untouched, gone, legacy})
+				previous := model.ScanningResponse{
+					Id:         14,
+					RepoId:     5,
+					Status:     "success",
+					Findings:   types.JSONText(previousFindings),
+					HeadCommit: &headCommit,
+				}
+				r := model.ScanResult{
+					Engines:      []model.ScanEngineInfo{{Name: "native", Version: "v1"}},
+					Findings:     []model.Finding{moved, added},
+					SinceCommit:  "0a1b2c3d",
+					HeadCommit:   "4e5f6a7b",
+					ChangedFiles: []string{"app.yml", ".npmrc", "db.yml"},
+					// Added finding is listed in .reposcanignore
+					IgnoredFingerprints: map[string]string{fingerprintFinding(added): "revoked token"},
+				}
+
+				scanMock.On("ClaimScanning", mock.Anything, "scanner-1/1", time.Minute, time.Duration(0)).Return(&q[0], nil).Once()
+				scanMock.On("GetLastSuccessfulScanning", mock.Anything, int64(5), "").Return(&previous, nil).Once()
+				repoMock.On("GetRepositorySettings", mock.Anything, int64(5)).Return(nil, nil).Once()
+				grabMock.On("StartScanningSession", mock.Anything, model.ScanTarget{
+					ScanningId:  15,
+					Url:         "github.com/spring/spring",
+					SinceCommit: "0a1b2c3d",
+				}).Return(r, nil).Once()
+				secretMock.On("Redact", int64(15), r.Findings).Return(r.Findings, []model.FindingSecret{}, nil).Once()
+				scanMock.On("GetPreviousScanning", mock.Anything, int64(5), "", int64(15)).Return(&previous, nil).Once()
+				scanMock.On("AddFindingFingerprints", mock.Anything, mock.Anything, mock.MatchedBy(func(fingerprints []model.FindingFingerprint) bool {
+					occurrences := map[string]string{}
+					for _, f := range fingerprints {
+						occurrences[f.Rule+"@"+f.FilePath] = f.Occurrence
+					}
+					return len(fingerprints) == 4 && reflect.DeepEqual(occurrences, map[string]string{
+						"aws-key@app.yml":  "recurring",
+						"npm-token@.npmrc": "new",
+						"password@db.yml":  "fixed",
+						"password@old.yml": "recurring",
+					})
+				})).Return(nil).Once()
+				// Kept finding is in baseline, so is the moved one
+				scanMock.On("GetActiveBaseline", mock.Anything, int64(5)).Return(&model.BaselineResponse{Id: 7, Version: 2}, nil).Once()
+				scanMock.On("GetBaselineFingerprints", mock.Anything, int64(7)).Return([]string{kept.Fingerprint}, nil).Once()
+				// Secret of carried finding is copied, so it can be revealed from this scanning
+				scanMock.On("CopyFindingSecrets", mock.Anything, tx, model.CopyFindingSecretsRequest{
+					FromScanningId: 14,
+					ToScanningId:   15,
+					FindingIds:     []string{"u"},
+				}).Return(nil).Once()
+				scanMock.On("AddFindings", mock.Anything, mock.Anything, int64(15), mock.Anything).Return(nil).Once()
+				scanMock.On("EditScanningStatusById", mock.Anything, mock.Anything, mock.MatchedBy(func(req model.EditScanningStatusRequest) bool {
+					var findings []model.Finding
+					if req.Id != 15 || req.Status != "success" || json.Unmarshal(req.Findings, &findings) != nil {
+						return false
+					}
+					occurrences := map[string]string{}
+					suppressed := map[string]bool{}
+					for _, f := range findings {
+						occurrences[f.ID] = f.Occurrence
+						suppressed[f.ID] = f.Suppression != nil
+					}
+					// Suppressed finding is kept and tracked as well
+					if findings[0].BaselineVersion != 2 || findings[1].BaselineVersion != 0 ||
+						!reflect.DeepEqual(findings[1].Suppression, &model.FindingSuppression{Source: "ignore_file", Reason: "revoked token"}) ||
+						!reflect.DeepEqual(suppressed, map[string]bool{"m": false, "a": true, "r": false, "u": false}) {
+						return false
+					}
+					// Moved finding keeps its fingerprint, untouched file is not scanned again so its finding is carried
+					return findings[0].Fingerprint == kept.Fingerprint && reflect.DeepEqual(occurrences, map[string]string{
+						"m": "recurring",
+						"a": "new",
+						"r": "fixed",
+						"u": "recurring",
+					})
+				})).Return(model.ScanningResponse{}, nil).Once()
+				scanMock.On("ClaimScanning", mock.Anything, "scanner-1/1", time.Minute, time.Duration(0)).Return(nil, nil).Once()
+			},
+			wantErr: false,
+		},
+		{
+			name: "carry over findings",
+			mock: func() {
+				q := []model.ScanningListResponse{
+					{
+						Id:     21,
+						RepoId: 6,
+						Name:   "Spring",
+						Url:    "github.com/spring/spring",
+						Status: "queued",
+					},
+				}
+				headCommit := "0a1b2c3d"
+				// Findings of unchanged file were in baseline version 1 and suppressed by .reposcanignore or inline comment
+				baselined := model.Finding{ID: "b", Rule: "aws-key", FilePath: "old.yml", Line: 3, LineContent: "AKIA****", SecretHash: "11",
+					BaselineVersion: 1}
+				ignored := model.Finding{ID: "i", Rule: "password", FilePath: "old.yml", Line: 5, LineContent: "pa****", SecretHash: "22",
+					Suppression: &model.FindingSuppression{Source: "ignore_file", Reason: "old.yml"}}
+				inline := model.Finding{ID: "c", Rule: "password", FilePath: "old.yml", Line: 7, LineContent: "se****", SecretHash: "33",
+					Suppression: &model.FindingSuppression{Source: "inline", Reason: "test fixture"}}
+				token := model.Finding{ID: "t", Rule: "npm-token", FilePath: "old.yml", Line: 9, LineContent: "_au****", SecretHash: "44"}
+				for _, f := range []*model.Finding{&baselined, &ignored, &inline, &token} {
+					f.Fingerprint = fingerprintFinding(*f)
+					f.Occurrence = "recurring"
+				}
+				previousFindings, _ := json.Marshal([]model.Finding{baselined, ignored, inline, token})
+				previous := model.ScanningResponse{
+					Id:         20,
+					RepoId:     6,
+					Status:     "success",
+					Findings:   types.JSONText(previousFindings),
+					HeadCommit: &headCommit,
+				}
+				// .reposcanignore no longer lists old.yml but npm-token rule now
+				r := model.ScanResult{
+					Engines:      []model.ScanEngineInfo{{Name: "native", Version: "v1"}},
+					Findings:     []model.Finding{},
+					SinceCommit:  "0a1b2c3d",
+					HeadCommit:   "4e5f6a7b",
+					ChangedFiles: []string{"app.yml"},
+					IgnoreFile:   ignoreRules{"npm-token": "rotated"},
+				}
+
+				scanMock.On("ClaimScanning", mock.Anything, "scanner-1/1", time.Minute, time.Duration(0)).Return(&q[0], nil).Once()
+				scanMock.On("GetLastSuccessfulScanning", mock.Anything, int64(6), "").Return(&previous, nil).Once()
+				repoMock.On("GetRepositorySettings", mock.Anything, int64(6)).Return(nil, nil).Once()
+				grabMock.On("StartScanningSession", mock.Anything, model.ScanTarget{
+					ScanningId:  21,
+					Url:         "github.com/spring/spring",
+					SinceCommit: "0a1b2c3d",
+				}).Return(r, nil).Once()
+				secretMock.On("Redact", int64(21), r.Findings).Return(r.Findings, []model.FindingSecret{}, nil).Once()
+				scanMock.On("GetPreviousScanning", mock.Anything, int64(6), "", int64(21)).Return(&previous, nil).Once()
+				scanMock.On("AddFindingFingerprints", mock.Anything, tx, mock.Anything).Return(nil).Once()
+				// Baseline is cleared since previous scanning
+				scanMock.On("GetActiveBaseline", mock.Anything, int64(6)).Return(nil, nil).Once()
+				scanMock.On("CopyFindingSecrets", mock.Anything, tx, model.CopyFindingSecretsRequest{
+					FromScanningId: 20,
+					ToScanningId:   21,
+					FindingIds:     []string{"b", "i", "c", "t"},
+				}).Return(nil).Once()
+				scanMock.On("AddFindings", mock.Anything, tx, int64(21), mock.Anything).Return(nil).Once()
+				scanMock.On("EditScanningStatusById", mock.Anything, tx, mock.MatchedBy(func(req model.EditScanningStatusRequest) bool {
+					var findings []model.Finding
+					if req.Id != 21 || req.Status != "success" || json.Unmarshal(req.Findings, &findings) != nil || len(findings) != 4 {
+						return false
+					}
+					baselines := map[string]int64{}
+					suppressions := map[string]*model.FindingSuppression{}
+					for _, f := range findings {
+						baselines[f.ID] = f.BaselineVersion
+						suppressions[f.ID] = f.Suppression
+					}
+					return reflect.DeepEqual(baselines, map[string]int64{"b": 0, "i": 0, "c": 0, "t": 0}) &&
+						reflect.DeepEqual(suppressions, map[string]*model.FindingSuppression{
+							"b": nil,
+							"i": nil,
+							"c": {Source: "inline", Reason: "test fixture"},
+							"t": {Source: "ignore_file", Reason: "rotated"},
+						})
+				})).Return(model.ScanningResponse{}, nil).Once()
+				scanMock.On("ClaimScanning", mock.Anything, "scanner-1/1", time.Minute, time.Duration(0)).Return(nil, nil).Once()
+			},
+			wantErr: false,
+		},
+		{
+			name:   "scan timed out",
+			option: model.ScanningOption{Timeout: 10 * time.Millisecond},
+			mock: func() {
+				q := []model.ScanningListResponse{
+					{
+						Id:         13,
+						RepoId:     3,
+						Name:       "JQuery",
+						Url:        "github.com/jquery/jquery",
+						Status:     "queued",
+						IsFullScan: true,
+					},
+				}
+
+				scanMock.On("ClaimScanning", mock.Anything, "scanner-1/1", time.Minute, time.Duration(0)).Return(&q[0], nil).Once()
+				// Scanning goes on with default settings once they cannot be read
+				repoMock.On("GetRepositorySettings", mock.Anything, int64(3)).Return(nil, serror.New("connection refused")).Once()
+				grabMock.On("StartScanningSession", mock.Anything, model.ScanTarget{
+					ScanningId: 13,
+					Url:        "github.com/jquery/jquery",
+				}).Run(func(args mock.Arguments) {
+					<-args.Get(0).(context.Context).Done()
+				}).Return(model.ScanResult{}, serror.New("context deadline exceeded")).Once()
+				scanMock.On("EditScanningStatusById", mock.Anything, mock.Anything, mock.MatchedBy(func(req model.EditScanningStatusRequest) bool {
+					// Timeout is kept as last error as well
+					return req.Id == 13 && req.Status == "failure" &&
+						strings.Contains(string(req.Findings), "maximum duration of 10ms") &&
+						req.LastError == "scanning timed out after 10ms" && len(req.LastErrorStack) > 0
+				})).Return(model.ScanningResponse{}, nil).Once()
+				scanMock.On("ClaimScanning", mock.Anything, "scanner-1/1", time.Minute, time.Duration(0)).Return(nil, nil).Once()
+			},
+			wantErr: false,
+		},
+		{
+			name: "scan cancelled",
+			mock: func() {
+				q := []model.ScanningListResponse{
+					{
+						Id:         14,
+						RepoId:     3,
+						Name:       "JQuery",
+						Url:        "github.com/jquery/jquery",
+						Status:     "queued",
+						IsFullScan: true,
+					},
+				}
+
+				scanMock.On("ClaimScanning", mock.Anything, "scanner-1/1", time.Minute, time.Duration(0)).Return(&q[0], nil).Once()
+				repoMock.On("GetRepositorySettings", mock.Anything, int64(3)).Return(nil, nil).Once()
+				grabMock.On("StartScanningSession", mock.Anything, model.ScanTarget{
+					ScanningId: 14,
+					Url:        "github.com/jquery/jquery",
+				}).Run(func(args mock.Arguments) {
+					// Pretend cancel is requested while scanning
+					assert.True(t, running.cancel(14))
+					<-args.Get(0).(context.Context).Done()
+				}).Return(model.ScanResult{}, serror.New("context canceled")).Once()
+				scanMock.On("EditScanningStatusById", mock.Anything, mock.Anything, mock.MatchedBy(func(req model.EditScanningStatusRequest) bool {
+					return req.Id == 14 && req.Status == "cancelled"
+				})).Return(model.ScanningResponse{}, nil).Once()
+				scanMock.On("ClaimScanning", mock.Anything, "scanner-1/1", time.Minute, time.Duration(0)).Return(nil, nil).Once()
+			},
+			wantErr: false,
+		},
+		{
+			name: "scan retried",
+			// Retry is not due while the test runs
+			option: model.ScanningOption{RetryBackoff: time.Hour, RetryBackoffMax: 2 * time.Hour},
+			mock: func() {
+				q := []model.ScanningListResponse{
+					{
+						Id:          16,
+						RepoId:      3,
+						Name:        "JQuery",
+						Url:         "github.com/jquery/jquery",
+						Status:      "queued",
+						IsFullScan:  true,
+						Attempts:    1,
+						MaxAttempts: 3,
+					},
+				}
+				errx := serror.New("dial tcp: connection refused")
+				errx.AddComments("[repository][StartScanningSession] while clone repository")
+
+				scanMock.On("ClaimScanning", mock.Anything, "scanner-1/1", time.Minute, time.Duration(0)).Return(&q[0], nil).Once()
+				repoMock.On("GetRepositorySettings", mock.Anything, int64(3)).Return(nil, nil).Once()
+				grabMock.On("StartScanningSession", mock.Anything, model.ScanTarget{
+					ScanningId: 16,
+					Url:        "github.com/jquery/jquery",
+				}).Return(model.ScanResult{}, errx).Once()
+				scanMock.On("EditScanningStatusById", mock.Anything, mock.Anything, mock.MatchedBy(func(req model.EditScanningStatusRequest) bool {
+					return req.Id == 16 && req.Status == "queued" && req.ClaimedBy == "scanner-1/1" &&
+						req.RetryDelay >= 30*time.Minute && req.RetryDelay <= time.Hour &&
+						req.LastError == "dial tcp: connection refused" &&
+						strings.Contains(strings.Join(req.LastErrorStack, "\n"), "while clone repository")
+				})).Return(model.ScanningResponse{}, nil).Once()
+				scanMock.On("ClaimScanning", mock.Anything, "scanner-1/1", time.Minute, time.Duration(0)).Return(nil, nil).Once()
+			},
+			wantErr: false,
+		},
+		{
+			name: "scan run out of attempts",
+			mock: func() {
+				q := []model.ScanningListResponse{
+					{
+						Id:          17,
+						RepoId:      3,
+						Name:        "JQuery",
+						Url:         "github.com/jquery/jquery",
+						Status:      "queued",
+						IsFullScan:  true,
+						Attempts:    3,
+						MaxAttempts: 3,
+					},
+				}
+
+				scanMock.On("ClaimScanning", mock.Anything, "scanner-1/1", time.Minute, time.Duration(0)).Return(&q[0], nil).Once()
+				repoMock.On("GetRepositorySettings", mock.Anything, int64(3)).Return(nil, nil).Once()
+				grabMock.On("StartScanningSession", mock.Anything, model.ScanTarget{
+					ScanningId: 17,
+					Url:        "github.com/jquery/jquery",
+				}).Return(model.ScanResult{}, serror.New("503 Service Unavailable")).Once()
+				scanMock.On("EditScanningStatusById", mock.Anything, mock.Anything, mock.MatchedBy(func(req model.EditScanningStatusRequest) bool {
+					return req.Id == 17 && req.Status == "dead" && req.RetryDelay == 0 &&
+						req.LastError == "503 Service Unavailable"
+				})).Return(model.ScanningResponse{}, nil).Once()
+				scanMock.On("ClaimScanning", mock.Anything, "scanner-1/1", time.Minute, time.Duration(0)).Return(nil, nil).Once()
+			},
+			wantErr: false,
+		},
+		{
+			name: "scan failed permanently",
+			mock: func() {
+				q := []model.ScanningListResponse{
+					{
+						Id:          18,
+						RepoId:      3,
+						Name:        "JQuery",
+						Url:         "github.com/jquery/jquery",
+						Status:      "queued",
+						IsFullScan:  true,
+						Attempts:    1,
+						MaxAttempts: 3,
+					},
+				}
+
+				scanMock.On("ClaimScanning", mock.Anything, "scanner-1/1", time.Minute, time.Duration(0)).Return(&q[0], nil).Once()
+				repoMock.On("GetRepositorySettings", mock.Anything, int64(3)).Return(nil, nil).Once()
+				grabMock.On("StartScanningSession", mock.Anything, model.ScanTarget{
+					ScanningId: 18,
+					Url:        "github.com/jquery/jquery",
+				}).Return(model.ScanResult{}, serror.New("repository not found")).Once()
+				scanMock.On("EditScanningStatusById", mock.Anything, mock.Anything, mock.MatchedBy(func(req model.EditScanningStatusRequest) bool {
+					return req.Id == 18 && req.Status == "failure" && req.LastError == "repository not found"
+				})).Return(model.ScanningResponse{}, nil).Once()
+				scanMock.On("ClaimScanning", mock.Anything, "scanner-1/1", time.Minute, time.Duration(0)).Return(nil, nil).Once()
+			},
+			wantErr: false,
+		},
+	}
+
+	for _, test := range listTests {
+		test.mock()
+
+		// Scannings are claimed by the only worker of instance
+		option := test.option
+		option.Instance = "scanner-1"
+		option.Lease = time.Minute
+		scanUsecase := scanningUsecase{
+			repositoryRepository: repoMock,
+			scanningRepository:   scanMock,
+			secretRepository:     secretMock,
+			trxRepository:        trxMock,
+			grabScanner:          grabMock,
+			option:               option,
+			workers:              newScanWorkers(1),
+			runningScans:         running,
+		}
+
+		err := scanUsecase.StartScanningInQueue(context.Background())
+		repoMock.AssertExpectations(t)
+		scanMock.AssertExpectations(t)
+		grabMock.AssertExpectations(t)
+		secretMock.AssertExpectations(t)
+
+		if (err != nil) != test.wantErr {
+			t.Errorf("StartScanningInQueue() got error : %s", err)
+		}
+
+		if test.wantErr {
+			assert.Error(t, err.Cause())
+		}
+	}
+}
+
+func TestStartScanningInQueueWorkers(t *testing.T) {
+	repoMock := new(mocks.IRepositoryRepository)
+	scanMock := new(mocks.IScanningRepository)
+	grabMock := new(mocks.IGrabScanner)
+
+	// 4 scannings are consumed by 3 workers, the first 3 of them must be running at once
+	const workers = 3
+	var claimMu sync.Mutex
+	claimedBy := map[int64]string{}
+	isWorker := mock.MatchedBy(func(claimed_by string) bool {
+		return claimed_by == "scanner-1/1" || claimed_by == "scanner-1/2" || claimed_by == "scanner-1/3"
+	})
+	for id := int64(1); id <= 4; id++ {
+		scanning := model.ScanningListResponse{
+			Id:         id,
+			RepoId:     id,
+			Url:        "github.com/jquery/jquery",
+			Status:     "in_progress",
+			IsFullScan: true,
+		}
+		scanMock.On("ClaimScanning", mock.Anything, isWorker, time.Duration(0), time.Duration(0)).Run(func(args mock.Arguments) {
+			claimMu.Lock()
+			defer claimMu.Unlock()
+			claimedBy[scanning.Id] = args.String(1)
+		}).Return(&scanning, nil).Once()
+	}
+	// Each worker stops once it finds queue empty
+	scanMock.On("ClaimScanning", mock.Anything, isWorker, time.Duration(0), time.Duration(0)).Return(nil, nil).Times(workers)
+	scanMock.On("EditScanningStatusById", mock.Anything, mock.Anything, mock.MatchedBy(func(req model.EditScanningStatusRequest) bool {
+		return req.Status == "failure"
+	})).Return(model.ScanningResponse{}, nil).Times(4)
+	repoMock.On("GetRepositorySettings", mock.Anything, mock.Anything).Return(nil, nil).Times(4)
+
+	var mu sync.Mutex
+	running, maxRunning := 0, 0
+	scanned := map[int64]int{}
+	var arrived sync.WaitGroup
+	arrived.Add(workers)
+	allArrived := make(chan struct{})
+	go func() {
+		arrived.Wait()
+		close(allArrived)
+	}()
+	grabMock.On("StartScanningSession", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		mu.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		scanned[args.Get(1).(model.ScanTarget).ScanningId]++
+		first := len(scanned) <= workers
+		mu.Unlock()
+
+		if first {
+			arrived.Done()
+		}
+		select {
+		case <-allArrived:
+		case <-time.After(5 * time.Second):
+			t.Error("scannings are not running in parallel")
+		}
+
+		mu.Lock()
+		running--
+		mu.Unlock()
+	}).Return(model.ScanResult{}, serror.New("repository not found")).Times(4)
+
+	scanUsecase := scanningUsecase{
+		repositoryRepository: repoMock,
+		scanningRepository:   scanMock,
+		grabScanner:          grabMock,
+		option:               model.ScanningOption{Instance: "scanner-1"},
+		workers:              newScanWorkers(workers),
+		runningScans:         newRunningScans(),
+	}
+
+	err := scanUsecase.StartScanningInQueue(context.Background())
+	assert.Nil(t, err)
+	repoMock.AssertExpectations(t)
+	scanMock.AssertExpectations(t)
+	grabMock.AssertExpectations(t)
+
+	// Every scanning is picked by a single worker
+	assert.Equal(t, workers, maxRunning)
+	assert.Equal(t, map[int64]int{1: 1, 2: 1, 3: 1, 4: 1}, scanned)
+	// Scannings running at once are claimed by different workers
+	assert.Len(t, claimedBy, 4)
+	assert.ElementsMatch(t, []string{"scanner-1/1", "scanner-1/2", "scanner-1/3"}, []string{claimedBy[1], claimedBy[2], claimedBy[3]})
+	assert.Equal(t, 0, scanUsecase.workers.running())
+
+	// Another call starts idle workers again
+	scanMock.On("ClaimScanning", mock.Anything, isWorker, time.Duration(0), time.Duration(0)).Return(nil, nil).Times(workers)
+	err = scanUsecase.StartScanningInQueue(context.Background())
+	assert.Nil(t, err)
+	scanMock.AssertExpectations(t)
 }
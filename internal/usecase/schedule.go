@@ -0,0 +1,216 @@
+package usecase
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"repo-scanner/internal/constants"
+	"repo-scanner/internal/utils/serror"
+	"repo-scanner/internal/utils/uttime"
+)
+
+// Scheduler looks for due scheduled scannings as often, schedules are at most one minute precise anyway
+const scheduleInterval = 30 * time.Second
+
+// Macros of common schedules
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var cronMonthNames = map[string]int{
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+}
+
+var cronDayNames = map[string]int{
+	"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+}
+
+// cronField is set of values matching a field of cron expression, bit n is set once value n matches
+type cronField uint64
+
+func (f cronField) has(value int) bool {
+	return f&(1<<uint(value)) != 0
+}
+
+// cronSchedule is parsed cron expression "minute hour day-of-month month day-of-week".
+// Day matches either day of month or day of week once both are restricted, as in cron
+type cronSchedule struct {
+	minute, hour, dom, month, dow cronField
+	domAny, dowAny                bool
+}
+
+// parseCronSchedule parses standard 5 fields cron expression or one of its macros, e.g. "30 2 * * MON-FRI" or "@daily".
+// Fields accept *, lists, ranges and steps, months and days of week accept their 3 letters names, 7 is Sunday as well
+func parseCronSchedule(expr string) (res cronSchedule, err error) {
+	expr = strings.TrimSpace(expr)
+	if macro, ok := cronMacros[strings.ToLower(expr)]; ok {
+		expr = macro
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return res, fmt.Errorf("expected 5 fields in cron expression %q, found %d", expr, len(fields))
+	}
+
+	if res.minute, err = parseCronField(fields[0], 0, 59, nil); err != nil {
+		return res, fmt.Errorf("invalid minute: %v", err)
+	}
+	if res.hour, err = parseCronField(fields[1], 0, 23, nil); err != nil {
+		return res, fmt.Errorf("invalid hour: %v", err)
+	}
+	if res.dom, err = parseCronField(fields[2], 1, 31, nil); err != nil {
+		return res, fmt.Errorf("invalid day of month: %v", err)
+	}
+	if res.month, err = parseCronField(fields[3], 1, 12, cronMonthNames); err != nil {
+		return res, fmt.Errorf("invalid month: %v", err)
+	}
+	if res.dow, err = parseCronField(fields[4], 0, 7, cronDayNames); err != nil {
+		return res, fmt.Errorf("invalid day of week: %v", err)
+	}
+	// Sunday is either 0 or 7
+	if res.dow.has(7) {
+		res.dow |= 1
+	}
+	res.domAny = strings.HasPrefix(fields[2], "*") || fields[2] == "?"
+	res.dowAny = strings.HasPrefix(fields[4], "*") || fields[4] == "?"
+	return res, nil
+}
+
+// parseCronField parses comma separated list of values, ranges and steps in [min, max]
+func parseCronField(field string, min int, max int, names map[string]int) (res cronField, err error) {
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			step, err = strconv.Atoi(part[i+1:])
+			if err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step in %q", part)
+			}
+			part = part[:i]
+		}
+
+		from, to := min, max
+		switch {
+		case part == "*" || part == "?":
+		case strings.Contains(part, "-"):
+			bounds := strings.SplitN(part, "-", 2)
+			if from, err = parseCronValue(bounds[0], min, max, names); err != nil {
+				return 0, err
+			}
+			if to, err = parseCronValue(bounds[1], min, max, names); err != nil {
+				return 0, err
+			}
+			if from > to {
+				return 0, fmt.Errorf("invalid range %q", part)
+			}
+		default:
+			if from, err = parseCronValue(part, min, max, names); err != nil {
+				return 0, err
+			}
+			// Single value with step runs from the value up to max, e.g. "5/15"
+			if step == 1 {
+				to = from
+			}
+		}
+
+		for value := from; value <= to; value += step {
+			res |= 1 << uint(value)
+		}
+	}
+	return res, nil
+}
+
+func parseCronValue(value string, min int, max int, names map[string]int) (int, error) {
+	if n, ok := names[strings.ToLower(value)]; ok {
+		return n, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < min || n > max {
+		return 0, fmt.Errorf("%q is not in %d-%d", value, min, max)
+	}
+	return n, nil
+}
+
+func (s cronSchedule) dayMatches(t time.Time) bool {
+	dom, dow := s.dom.has(t.Day()), s.dow.has(int(t.Weekday()))
+	if s.domAny || s.dowAny {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+// next returns first time after t which schedule is due at in location of t, zero time if it is never due,
+// e.g. on 30th of February
+func (s cronSchedule) next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+
+	// Every field is moved forward to its next matching value, once a field wraps around the larger ones are checked again
+	limit := t.Year() + 5
+wrap:
+	for t.Year() <= limit {
+		for !s.month.has(int(t.Month())) {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			if t.Month() == time.January {
+				continue wrap
+			}
+		}
+		for !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			if t.Day() == 1 {
+				continue wrap
+			}
+		}
+		for !s.hour.has(t.Hour()) {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			if t.Hour() == 0 {
+				continue wrap
+			}
+		}
+		for !s.minute.has(t.Minute()) {
+			t = t.Add(time.Minute)
+			if t.Minute() == 0 {
+				continue wrap
+			}
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// nextScheduledScan returns when cron expression expr is due next in timezone zone,
+// in DefaultTimezone as other timestamps are stored
+func nextScheduledScan(expr string, zone string) (res time.Time, errx serror.SError) {
+	schedule, err := parseCronSchedule(expr)
+	if err != nil {
+		errx = serror.Newi(http.StatusBadRequest, "Invalid scan schedule|Invalid scan schedule")
+		errx.AddCommentf("[usecase][nextScheduledScan] while parse %q: %v", expr, err)
+		return
+	}
+
+	var loc *time.Location
+	loc, errx = uttime.GetTimezone(zone)
+	if errx != nil {
+		errx = serror.Newi(http.StatusBadRequest, "Invalid scan timezone|Invalid scan timezone")
+		errx.AddCommentf("[usecase][nextScheduledScan] while get timezone %q", zone)
+		return
+	}
+
+	currentTime, _ := uttime.NowWithTimezone(constants.DefaultTimezone)
+	res = schedule.next(currentTime.In(loc))
+	if res.IsZero() {
+		errx = serror.Newi(http.StatusBadRequest, "Scan schedule is never due|Scan schedule is never due")
+		errx.AddCommentf("[usecase][nextScheduledScan] while get next run of %q", expr)
+		return
+	}
+	return res.In(currentTime.Location()), nil
+}
@@ -0,0 +1,78 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCronSchedule(t *testing.T) {
+	valid := []string{
+		"* * * * *",
+		"*/15 * * * *",
+		"0 2 * * MON-FRI",
+		"30 8,12,18 1-7 jan-mar sun",
+		"5/10 0 ? * 7",
+		"@daily",
+		" @Weekly ",
+	}
+	for _, expr := range valid {
+		_, err := parseCronSchedule(expr)
+		assert.Nil(t, err, expr)
+	}
+
+	invalid := []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"10-5 * * * *",
+		"* * * foo *",
+		"@fortnightly",
+	}
+	for _, expr := range invalid {
+		_, err := parseCronSchedule(expr)
+		assert.NotNil(t, err, expr)
+	}
+}
+
+func TestCronScheduleNext(t *testing.T) {
+	utc := time.UTC
+	jakarta, _ := time.LoadLocation("Asia/Jakarta")
+	// Tuesday
+	from := time.Date(2022, 12, 20, 10, 7, 30, 0, utc)
+
+	listTests := []struct {
+		expr string
+		from time.Time
+		want time.Time
+	}{
+		{expr: "* * * * *", from: from, want: time.Date(2022, 12, 20, 10, 8, 0, 0, utc)},
+		{expr: "*/15 * * * *", from: from, want: time.Date(2022, 12, 20, 10, 15, 0, 0, utc)},
+		{expr: "0 2 * * *", from: from, want: time.Date(2022, 12, 21, 2, 0, 0, 0, utc)},
+		// Due time itself is not next
+		{expr: "0 2 * * *", from: time.Date(2022, 12, 21, 2, 0, 0, 0, utc), want: time.Date(2022, 12, 22, 2, 0, 0, 0, utc)},
+		{expr: "30 2 * * sat,SUN", from: from, want: time.Date(2022, 12, 24, 2, 30, 0, 0, utc)},
+		{expr: "0 0 * * 7", from: from, want: time.Date(2022, 12, 25, 0, 0, 0, 0, utc)},
+		{expr: "@monthly", from: from, want: time.Date(2023, 1, 1, 0, 0, 0, 0, utc)},
+		{expr: "0 9 * feb-mar mon", from: from, want: time.Date(2023, 2, 6, 9, 0, 0, 0, utc)},
+		// Either day of month or day of week matches once both are restricted
+		{expr: "0 0 13 * fri", from: from, want: time.Date(2022, 12, 23, 0, 0, 0, 0, utc)},
+		{expr: "0 0 29 2 *", from: from, want: time.Date(2024, 2, 29, 0, 0, 0, 0, utc)},
+		{expr: "0 0 30 2 *", from: from, want: time.Time{}},
+		{expr: "0 2 * * *", from: from.In(jakarta), want: time.Date(2022, 12, 21, 2, 0, 0, 0, jakarta)},
+	}
+
+	for _, test := range listTests {
+		schedule, err := parseCronSchedule(test.expr)
+		assert.Nil(t, err, test.expr)
+		got := schedule.next(test.from)
+		assert.True(t, test.want.Equal(got), "%s: want %v, got %v", test.expr, test.want, got)
+	}
+}
@@ -0,0 +1,64 @@
+package usecase
+
+import (
+	"sync"
+)
+
+// scanWorkers is pool of workers consuming scanning queue, each worker is identified by number from 1 for logging.
+// A worker finding queue empty stops unless new scanning is queued meanwhile, which is told by pending
+type scanWorkers struct {
+	mu      sync.Mutex
+	size    int
+	busy    map[int]bool
+	pending bool
+}
+
+func newScanWorkers(size int) *scanWorkers {
+	return &scanWorkers{
+		size: size,
+		busy: map[int]bool{},
+	}
+}
+
+// start reserves every idle worker and returns their numbers,
+// busy workers are told to check the queue again once none is idle
+func (w *scanWorkers) start() (res []int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for worker := 1; worker <= w.size; worker++ {
+		if !w.busy[worker] {
+			w.busy[worker] = true
+			res = append(res, worker)
+		}
+	}
+	if len(res) == 0 {
+		w.pending = true
+	}
+	return
+}
+
+// idle releases worker finding queue empty, false if it must check the queue again
+func (w *scanWorkers) idle(worker int) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.pending {
+		w.pending = false
+		return false
+	}
+	delete(w.busy, worker)
+	return true
+}
+
+// release stops worker regardless of pending scannings
+func (w *scanWorkers) release(worker int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.busy, worker)
+}
+
+// running returns number of busy workers
+func (w *scanWorkers) running() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.busy)
+}
@@ -0,0 +1,29 @@
+package usecase
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScanWorkers(t *testing.T) {
+	workers := newScanWorkers(2)
+
+	assert.Equal(t, []int{1, 2}, workers.start())
+	assert.Equal(t, 2, workers.running())
+
+	// New scanning is queued while every worker is busy
+	assert.Nil(t, workers.start())
+	assert.False(t, workers.idle(1))
+	assert.True(t, workers.idle(1))
+	assert.Equal(t, 1, workers.running())
+
+	// Released worker is started again
+	assert.Equal(t, []int{1}, workers.start())
+	workers.release(1)
+	workers.release(2)
+	assert.Equal(t, 0, workers.running())
+
+	// Pool without workers never starts any
+	assert.Nil(t, newScanWorkers(0).start())
+}
@@ -0,0 +1,14 @@
+package utfloat
+
+import (
+	"strconv"
+)
+
+// StringToFloat to casting string to float
+func StringToFloat(value string, def float64) float64 {
+	r, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		r = def
+	}
+	return r
+}
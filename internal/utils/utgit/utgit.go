@@ -0,0 +1,65 @@
+package utgit
+
+import (
+	"path/filepath"
+	"strings"
+
+	"repo-scanner/internal/constants"
+
+	"gopkg.in/src-d/go-git.v4/plumbing/transport"
+)
+
+// LocalPath returns path of repository url given as absolute local path or file:// url
+func LocalPath(url string) (string, bool) {
+	if strings.HasPrefix(url, constants.LocalRepositoryScheme) {
+		return strings.TrimPrefix(url, constants.LocalRepositoryScheme), true
+	}
+	if filepath.IsAbs(url) {
+		return url, true
+	}
+	return "", false
+}
+
+// LocalPathUnder resolves symlinks of local repository path and tells whether it lies under root,
+// no path is accepted without root
+func LocalPathUnder(path string, root string) (string, bool) {
+	if root == "" || !filepath.IsAbs(path) {
+		return "", false
+	}
+
+	resolvedRoot, err := filepath.EvalSymlinks(filepath.Clean(root))
+	if err != nil {
+		return "", false
+	}
+	resolved, err := filepath.EvalSymlinks(filepath.Clean(path))
+	if err != nil {
+		return "", false
+	}
+
+	rel, err := filepath.Rel(resolvedRoot, resolved)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return resolved, true
+}
+
+// IsRemote tells whether url is git remote to be cloned directly,
+// e.g. https://gitea.local/org/repo.git, ssh://git@host/repo.git, git@host:repo.git or git://host/repo
+func IsRemote(url string) bool {
+	endpoint, err := transport.NewEndpoint(url)
+	if err != nil {
+		return false
+	}
+
+	switch endpoint.Protocol {
+	case "http", "https", "ssh", "git":
+		return endpoint.Host != "" && strings.Trim(endpoint.Path, "/") != ""
+	}
+	return false
+}
+
+// IsDirectClone tells whether repository of url is cloned by go-git instead of git provider API
+func IsDirectClone(url string) bool {
+	_, isLocal := LocalPath(url)
+	return isLocal || IsRemote(url)
+}
@@ -0,0 +1,2 @@
+ALTER TABLE reposcan.scannings
+    DROP COLUMN IF EXISTS scan_engines;
@@ -0,0 +1,2 @@
+ALTER TABLE reposcan.scannings
+    ADD COLUMN scan_engines jsonb NOT NULL DEFAULT '[]'::jsonb;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS reposcan.rules;
+DROP TYPE IF EXISTS reposcan.rule_part;
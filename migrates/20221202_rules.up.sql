@@ -0,0 +1,24 @@
+CREATE TYPE reposcan.rule_part AS ENUM (
+	'filename',
+	'extension',
+	'path',
+	'content');
+
+CREATE TABLE reposcan.rules (
+    rule_id serial NOT NULL,
+    rule_name varchar NOT NULL,
+    description varchar NOT NULL,
+    comment varchar NOT NULL DEFAULT '',
+    part reposcan.rule_part NOT NULL,
+    pattern varchar NOT NULL,
+    is_active bit(1) NOT NULL DEFAULT '1'::"bit",
+    created_by varchar NOT NULL DEFAULT 'SYSTEM'::character varying,
+    created_at timestamp NOT NULL DEFAULT now(),
+    modified_by varchar NOT NULL DEFAULT 'SYSTEM'::character varying,
+    modified_at timestamp NOT NULL DEFAULT now(),
+    deleted_by varchar,
+    deleted_at timestamp,
+    CONSTRAINT rules_pkey PRIMARY KEY (rule_id)
+);
+CREATE UNIQUE INDEX rules_rule_name_idx ON reposcan.rules USING btree(rule_name) WHERE deleted_at IS NULL;
+CREATE INDEX rules_is_active_idx ON reposcan.rules USING btree(is_active);
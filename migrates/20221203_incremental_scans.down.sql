@@ -0,0 +1,4 @@
+ALTER TABLE reposcan.scannings
+    DROP COLUMN IF EXISTS head_commit,
+    DROP COLUMN IF EXISTS since_commit,
+    DROP COLUMN IF EXISTS is_full_scan;
@@ -0,0 +1,4 @@
+ALTER TABLE reposcan.scannings
+    ADD COLUMN is_full_scan bit(1) NOT NULL DEFAULT '1'::"bit",
+    ADD COLUMN since_commit varchar,
+    ADD COLUMN head_commit varchar;
@@ -0,0 +1,5 @@
+ALTER TABLE reposcan.scannings
+    DROP COLUMN IF EXISTS ref;
+
+ALTER TABLE reposcan.repositories
+    DROP COLUMN IF EXISTS default_ref;
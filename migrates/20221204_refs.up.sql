@@ -0,0 +1,5 @@
+ALTER TABLE reposcan.repositories
+    ADD COLUMN default_ref varchar;
+
+ALTER TABLE reposcan.scannings
+    ADD COLUMN ref varchar;
@@ -0,0 +1,5 @@
+ALTER TABLE reposcan.scannings
+    DROP COLUMN IF EXISTS base_ref,
+    DROP COLUMN IF EXISTS scan_mode;
+
+DROP TYPE IF EXISTS reposcan.scan_mode;
@@ -0,0 +1,7 @@
+CREATE TYPE reposcan.scan_mode AS ENUM (
+	'standard',
+	'diff');
+
+ALTER TABLE reposcan.scannings
+    ADD COLUMN scan_mode reposcan.scan_mode NOT NULL DEFAULT 'standard'::reposcan.scan_mode,
+    ADD COLUMN base_ref varchar;
@@ -0,0 +1,2 @@
+ALTER TABLE reposcan.repositories
+    DROP COLUMN IF EXISTS is_upload;
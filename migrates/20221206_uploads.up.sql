@@ -0,0 +1,2 @@
+ALTER TABLE reposcan.repositories
+    ADD COLUMN is_upload bit(1) NOT NULL DEFAULT '0'::"bit";
@@ -0,0 +1,17 @@
+UPDATE reposcan.scannings
+SET scanning_status = 'failure'::reposcan.scanning_status
+WHERE scanning_status = 'cancelled'::reposcan.scanning_status;
+
+ALTER TYPE reposcan.scanning_status RENAME TO scanning_status_old;
+CREATE TYPE reposcan.scanning_status AS ENUM (
+	'queued',
+	'in_progress',
+	'success',
+	'failure');
+
+ALTER TABLE reposcan.scannings ALTER COLUMN scanning_status DROP DEFAULT;
+ALTER TABLE reposcan.scannings
+    ALTER COLUMN scanning_status TYPE reposcan.scanning_status USING scanning_status::text::reposcan.scanning_status;
+ALTER TABLE reposcan.scannings
+    ALTER COLUMN scanning_status SET DEFAULT 'queued'::reposcan.scanning_status;
+DROP TYPE reposcan.scanning_status_old;
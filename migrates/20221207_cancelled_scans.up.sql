@@ -0,0 +1 @@
+ALTER TYPE reposcan.scanning_status ADD VALUE IF NOT EXISTS 'cancelled';
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS reposcan.finding_reveals;
+DROP TABLE IF EXISTS reposcan.finding_secrets;
@@ -0,0 +1,29 @@
+CREATE TABLE reposcan.finding_secrets (
+    scanning_id bigint NOT NULL,
+    finding_id varchar NOT NULL,
+    secret_encrypted varchar NOT NULL,
+    created_at timestamp NOT NULL DEFAULT now(),
+    CONSTRAINT finding_secrets_pkey PRIMARY KEY (scanning_id, finding_id),
+    CONSTRAINT finding_secrets_scanning_id_fkey FOREIGN KEY (scanning_id) REFERENCES reposcan.scannings(scanning_id) ON DELETE CASCADE
+);
+
+CREATE TABLE reposcan.finding_reveals (
+    reveal_id serial NOT NULL,
+    scanning_id bigint NOT NULL,
+    finding_id varchar NOT NULL,
+    reason varchar NOT NULL,
+    revealed_by varchar NOT NULL,
+    revealed_at timestamp NOT NULL DEFAULT now(),
+    CONSTRAINT finding_reveals_pkey PRIMARY KEY (reveal_id),
+    CONSTRAINT finding_reveals_scanning_id_fkey FOREIGN KEY (scanning_id) REFERENCES reposcan.scannings(scanning_id)
+);
+CREATE INDEX finding_reveals_scanning_id_idx ON reposcan.finding_reveals USING btree(scanning_id, finding_id);
+
+-- Secret values stored by earlier scannings are masked, they cannot be hashed without the key
+UPDATE reposcan.scannings
+SET findings = (
+    SELECT jsonb_agg(CASE WHEN COALESCE(f->>'LineContent', '') = '' THEN f
+        ELSE f || '{"LineContent": "****"}'::jsonb END)
+    FROM jsonb_array_elements(findings) f)
+WHERE jsonb_typeof(findings) = 'array'
+AND jsonb_array_length(findings) > 0;
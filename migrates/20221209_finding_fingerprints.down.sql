@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS reposcan.finding_fingerprints;
+
+DROP TYPE IF EXISTS reposcan.finding_occurrence;
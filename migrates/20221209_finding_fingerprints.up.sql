@@ -0,0 +1,21 @@
+CREATE TYPE reposcan.finding_occurrence AS ENUM (
+	'new',
+	'recurring',
+	'fixed');
+
+CREATE TABLE reposcan.finding_fingerprints (
+    repository_id bigint NOT NULL,
+    fingerprint varchar NOT NULL,
+    rule varchar NOT NULL,
+    file_path varchar NOT NULL,
+    occurrence reposcan.finding_occurrence NOT NULL DEFAULT 'new'::reposcan.finding_occurrence,
+    first_scanning_id bigint NOT NULL,
+    last_scanning_id bigint NOT NULL,
+    first_seen_at timestamp NOT NULL DEFAULT now(),
+    last_seen_at timestamp NOT NULL DEFAULT now(),
+    CONSTRAINT finding_fingerprints_pkey PRIMARY KEY (repository_id, fingerprint),
+    CONSTRAINT finding_fingerprints_repository_id_fkey FOREIGN KEY (repository_id) REFERENCES reposcan.repositories(repository_id) ON DELETE CASCADE,
+    CONSTRAINT finding_fingerprints_first_scanning_id_fkey FOREIGN KEY (first_scanning_id) REFERENCES reposcan.scannings(scanning_id),
+    CONSTRAINT finding_fingerprints_last_scanning_id_fkey FOREIGN KEY (last_scanning_id) REFERENCES reposcan.scannings(scanning_id)
+);
+CREATE INDEX finding_fingerprints_last_scanning_id_idx ON reposcan.finding_fingerprints USING btree(last_scanning_id);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS reposcan.findings;
+
+DROP TYPE IF EXISTS reposcan.finding_severity;
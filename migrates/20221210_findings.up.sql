@@ -0,0 +1,68 @@
+CREATE TYPE reposcan.finding_severity AS ENUM (
+	'low',
+	'medium',
+	'high');
+
+CREATE TABLE reposcan.findings (
+    scanning_id bigint NOT NULL,
+    finding_id varchar NOT NULL,
+    engine varchar NOT NULL DEFAULT '',
+    rule varchar NOT NULL DEFAULT '',
+    severity reposcan.finding_severity NOT NULL,
+    file_path varchar NOT NULL DEFAULT '',
+    line bigint NOT NULL DEFAULT 0,
+    commit_hash varchar NOT NULL DEFAULT '',
+    commit_author varchar NOT NULL DEFAULT '',
+    fingerprint varchar NOT NULL DEFAULT '',
+    occurrence reposcan.finding_occurrence,
+    finding jsonb NOT NULL,
+    created_at timestamp NOT NULL DEFAULT now(),
+    CONSTRAINT findings_pkey PRIMARY KEY (scanning_id, finding_id),
+    CONSTRAINT findings_scanning_id_fkey FOREIGN KEY (scanning_id) REFERENCES reposcan.scannings(scanning_id) ON DELETE CASCADE
+);
+CREATE INDEX findings_scanning_id_severity_idx ON reposcan.findings USING btree(scanning_id, severity);
+CREATE INDEX findings_scanning_id_rule_idx ON reposcan.findings USING btree(scanning_id, rule);
+CREATE INDEX findings_fingerprint_idx ON reposcan.findings USING btree(fingerprint);
+
+-- Findings of earlier successful scannings are copied from their findings column,
+-- severity is given by matched part as scan engines do
+INSERT INTO reposcan.findings(
+    scanning_id,
+    finding_id,
+    engine,
+    rule,
+    severity,
+    file_path,
+    line,
+    commit_hash,
+    commit_author,
+    fingerprint,
+    occurrence,
+    finding,
+    created_at
+)
+SELECT
+    s.scanning_id,
+    f->>'ID',
+    COALESCE(f->>'Engine', ''),
+    COALESCE(f->>'Rule', ''),
+    (CASE
+        WHEN COALESCE(f->>'Severity', '') <> '' THEN f->>'Severity'
+        WHEN f->>'Action' = 'content' THEN 'high'
+        WHEN f->>'Action' = 'entropy' THEN 'low'
+        ELSE 'medium' END)::reposcan.finding_severity,
+    COALESCE(f->>'FilePath', ''),
+    COALESCE((f->>'Line')::bigint, 0),
+    COALESCE(f->>'CommitHash', ''),
+    COALESCE(f->>'CommitAuthor', ''),
+    COALESCE(f->>'Fingerprint', ''),
+    NULLIF(COALESCE(f->>'Occurrence', ''), '')::reposcan.finding_occurrence,
+    f,
+    COALESCE(s.finished_at, now())
+FROM
+    reposcan.scannings s,
+    jsonb_array_elements(CASE WHEN jsonb_typeof(s.findings) = 'array' THEN s.findings ELSE '[]'::jsonb END) f
+WHERE
+    s.scanning_status = 'success'::reposcan.scanning_status
+AND COALESCE(f->>'ID', '') <> ''
+ON CONFLICT (scanning_id, finding_id) DO NOTHING;
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS reposcan.finding_comments;
+
+ALTER TABLE reposcan.finding_fingerprints DROP COLUMN IF EXISTS triaged_at;
+ALTER TABLE reposcan.finding_fingerprints DROP COLUMN IF EXISTS triaged_by;
+ALTER TABLE reposcan.finding_fingerprints DROP COLUMN IF EXISTS assignee;
+ALTER TABLE reposcan.finding_fingerprints DROP COLUMN IF EXISTS triage_status;
+
+DROP TYPE IF EXISTS reposcan.triage_status;
@@ -0,0 +1,26 @@
+CREATE TYPE reposcan.triage_status AS ENUM (
+	'open',
+	'confirmed',
+	'false_positive',
+	'accepted_risk',
+	'resolved');
+
+-- Triage is kept by fingerprint, so it carries over to the same finding in later scannings
+ALTER TABLE reposcan.finding_fingerprints ADD COLUMN triage_status reposcan.triage_status NOT NULL DEFAULT 'open'::reposcan.triage_status;
+ALTER TABLE reposcan.finding_fingerprints ADD COLUMN assignee varchar;
+ALTER TABLE reposcan.finding_fingerprints ADD COLUMN triaged_by varchar;
+ALTER TABLE reposcan.finding_fingerprints ADD COLUMN triaged_at timestamp;
+
+CREATE TABLE reposcan.finding_comments (
+    comment_id serial NOT NULL,
+    repository_id bigint NOT NULL,
+    fingerprint varchar NOT NULL,
+    scanning_id bigint NOT NULL,
+    finding_id varchar NOT NULL,
+    comment varchar NOT NULL,
+    created_by varchar NOT NULL,
+    created_at timestamp NOT NULL DEFAULT now(),
+    CONSTRAINT finding_comments_pkey PRIMARY KEY (comment_id),
+    CONSTRAINT finding_comments_fingerprint_fkey FOREIGN KEY (repository_id, fingerprint) REFERENCES reposcan.finding_fingerprints(repository_id, fingerprint) ON DELETE CASCADE
+);
+CREATE INDEX finding_comments_fingerprint_idx ON reposcan.finding_comments USING btree(repository_id, fingerprint);
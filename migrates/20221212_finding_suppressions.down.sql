@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS reposcan.findings_scanning_id_suppression_source_idx;
+
+ALTER TABLE reposcan.findings DROP COLUMN IF EXISTS suppression_reason;
+ALTER TABLE reposcan.findings DROP COLUMN IF EXISTS suppression_source;
@@ -0,0 +1,4 @@
+-- Suppressed findings are stored as well, so that suppressions made in repository can be audited
+ALTER TABLE reposcan.findings ADD COLUMN suppression_source varchar;
+ALTER TABLE reposcan.findings ADD COLUMN suppression_reason varchar;
+CREATE INDEX findings_scanning_id_suppression_source_idx ON reposcan.findings USING btree(scanning_id, suppression_source);
@@ -0,0 +1,7 @@
+ALTER TABLE reposcan.findings DROP COLUMN IF EXISTS baseline_version;
+
+ALTER TABLE reposcan.repositories DROP CONSTRAINT IF EXISTS repositories_baseline_id_fkey;
+ALTER TABLE reposcan.repositories DROP COLUMN IF EXISTS baseline_id;
+
+DROP TABLE IF EXISTS reposcan.baseline_fingerprints;
+DROP TABLE IF EXISTS reposcan.baselines;
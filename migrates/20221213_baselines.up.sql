@@ -0,0 +1,27 @@
+-- Baseline is a versioned snapshot of finding fingerprints of a scanning, findings in active baseline
+-- of a repository are kept in later scannings but left out of its findings list
+CREATE TABLE reposcan.baselines (
+    baseline_id serial NOT NULL,
+    repository_id bigint NOT NULL,
+    version int NOT NULL,
+    scanning_id bigint NOT NULL,
+    created_by varchar NOT NULL,
+    created_at timestamp NOT NULL DEFAULT now(),
+    CONSTRAINT baselines_pkey PRIMARY KEY (baseline_id),
+    CONSTRAINT baselines_repository_id_version_key UNIQUE (repository_id, version),
+    CONSTRAINT baselines_repository_id_fkey FOREIGN KEY (repository_id) REFERENCES reposcan.repositories(repository_id) ON DELETE CASCADE,
+    CONSTRAINT baselines_scanning_id_fkey FOREIGN KEY (scanning_id) REFERENCES reposcan.scannings(scanning_id) ON DELETE CASCADE
+);
+
+CREATE TABLE reposcan.baseline_fingerprints (
+    baseline_id bigint NOT NULL,
+    fingerprint varchar NOT NULL,
+    CONSTRAINT baseline_fingerprints_pkey PRIMARY KEY (baseline_id, fingerprint),
+    CONSTRAINT baseline_fingerprints_baseline_id_fkey FOREIGN KEY (baseline_id) REFERENCES reposcan.baselines(baseline_id) ON DELETE CASCADE
+);
+
+-- Active baseline is switched by pointing repository to one of its versions, so refresh and rollback are atomic
+ALTER TABLE reposcan.repositories ADD COLUMN baseline_id bigint;
+ALTER TABLE reposcan.repositories ADD CONSTRAINT repositories_baseline_id_fkey FOREIGN KEY (baseline_id) REFERENCES reposcan.baselines(baseline_id) ON DELETE SET NULL;
+
+ALTER TABLE reposcan.findings ADD COLUMN baseline_version int;
@@ -0,0 +1 @@
+ALTER TABLE reposcan.repositories DROP COLUMN IF EXISTS scan_settings;
@@ -0,0 +1,3 @@
+-- Scan settings of a repository, e.g. {"skip_paths": ["docs/**"], "skip_extensions": ["csv"], "scan_tests": true,
+-- "commit_depth": 1000, "threads": 4}, unset ones fall back to SKIP_* environment variables and engine defaults
+ALTER TABLE reposcan.repositories ADD COLUMN scan_settings jsonb NOT NULL DEFAULT '{}'::jsonb;
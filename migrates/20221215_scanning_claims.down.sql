@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS reposcan.scannings_queued_idx;
+ALTER TABLE reposcan.scannings DROP COLUMN IF EXISTS claimed_by;
@@ -0,0 +1,5 @@
+-- Worker instance which claimed a scanning off the queue, e.g. "scanner-7d9f:42/3" for worker 3 of process 42 on host scanner-7d9f
+ALTER TABLE reposcan.scannings ADD COLUMN claimed_by varchar;
+-- Queued scannings are claimed oldest first
+CREATE INDEX scannings_queued_idx ON reposcan.scannings USING btree(created_at)
+    WHERE scanning_status = 'queued'::reposcan.scanning_status AND deleted_by IS NULL;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS reposcan.scannings_lease_expires_at_idx;
+ALTER TABLE reposcan.scannings
+    DROP COLUMN IF EXISTS lease_expires_at,
+    DROP COLUMN IF EXISTS attempts;
@@ -0,0 +1,16 @@
+-- Claimed scanning is leased to its worker until lease_expires_at, which is renewed by heartbeat of the worker.
+-- Scanning whose lease expires is abandoned, e.g. its process crashed, so reaper queues it again or fails it
+-- once it has been attempted too many times
+ALTER TABLE reposcan.scannings
+    ADD COLUMN lease_expires_at timestamp,
+    ADD COLUMN attempts int NOT NULL DEFAULT 0;
+CREATE INDEX scannings_lease_expires_at_idx ON reposcan.scannings USING btree(lease_expires_at)
+    WHERE scanning_status = 'in_progress'::reposcan.scanning_status;
+
+-- Scannings left in progress by earlier versions are recovered once they would have timed out
+UPDATE reposcan.scannings
+SET
+    lease_expires_at = COALESCE(scanning_at, now()) + interval '1 hour',
+    attempts = 1
+WHERE
+    scanning_status = 'in_progress'::reposcan.scanning_status;
@@ -0,0 +1,27 @@
+UPDATE reposcan.scannings
+SET scanning_status = 'failure'::reposcan.scanning_status
+WHERE scanning_status = 'dead'::reposcan.scanning_status;
+
+-- Partial indexes compare scanning_status with the old type, so they are created again once it is replaced
+DROP INDEX IF EXISTS reposcan.scannings_queued_idx;
+DROP INDEX IF EXISTS reposcan.scannings_lease_expires_at_idx;
+
+ALTER TYPE reposcan.scanning_status RENAME TO scanning_status_old;
+CREATE TYPE reposcan.scanning_status AS ENUM (
+	'queued',
+	'in_progress',
+	'success',
+	'failure',
+	'cancelled');
+
+ALTER TABLE reposcan.scannings ALTER COLUMN scanning_status DROP DEFAULT;
+ALTER TABLE reposcan.scannings
+    ALTER COLUMN scanning_status TYPE reposcan.scanning_status USING scanning_status::text::reposcan.scanning_status;
+ALTER TABLE reposcan.scannings
+    ALTER COLUMN scanning_status SET DEFAULT 'queued'::reposcan.scanning_status;
+DROP TYPE reposcan.scanning_status_old;
+
+CREATE INDEX scannings_queued_idx ON reposcan.scannings USING btree(created_at)
+    WHERE scanning_status = 'queued'::reposcan.scanning_status AND deleted_by IS NULL;
+CREATE INDEX scannings_lease_expires_at_idx ON reposcan.scannings USING btree(lease_expires_at)
+    WHERE scanning_status = 'in_progress'::reposcan.scanning_status;
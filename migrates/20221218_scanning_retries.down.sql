@@ -0,0 +1,5 @@
+ALTER TABLE reposcan.scannings
+    DROP COLUMN IF EXISTS max_attempts,
+    DROP COLUMN IF EXISTS next_attempt_at,
+    DROP COLUMN IF EXISTS last_error,
+    DROP COLUMN IF EXISTS last_error_stack;
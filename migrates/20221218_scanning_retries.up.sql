@@ -0,0 +1,8 @@
+-- Scanning failing for transient error is queued again until it is attempted max_attempts times,
+-- it is not claimed before next_attempt_at. Scanning running out of attempts is dead,
+-- message and comment stack of its last error are kept
+ALTER TABLE reposcan.scannings
+    ADD COLUMN max_attempts int NOT NULL DEFAULT 3,
+    ADD COLUMN next_attempt_at timestamp,
+    ADD COLUMN last_error text,
+    ADD COLUMN last_error_stack text[];
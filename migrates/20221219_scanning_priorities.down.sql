@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS reposcan.scannings_queued_idx;
+ALTER TABLE reposcan.scannings DROP COLUMN IF EXISTS priority;
+CREATE INDEX scannings_queued_idx ON reposcan.scannings USING btree(created_at)
+    WHERE scanning_status = 'queued'::reposcan.scanning_status AND deleted_by IS NULL;
@@ -0,0 +1,6 @@
+-- Queued scannings are claimed by priority, higher first, then oldest first. Waiting scanning is raised one priority
+-- every SCAN_PRIORITY_AGING when it is claimed, so that low priority ones are not starved by a flood of urgent ones
+ALTER TABLE reposcan.scannings ADD COLUMN priority int NOT NULL DEFAULT 0;
+DROP INDEX IF EXISTS reposcan.scannings_queued_idx;
+CREATE INDEX scannings_queued_idx ON reposcan.scannings USING btree(priority DESC, queued_at)
+    WHERE scanning_status = 'queued'::reposcan.scanning_status AND deleted_by IS NULL;
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS reposcan.repositories_next_scheduled_scan_at_idx;
+ALTER TABLE reposcan.repositories
+    DROP COLUMN IF EXISTS scan_schedule,
+    DROP COLUMN IF EXISTS scan_timezone,
+    DROP COLUMN IF EXISTS next_scheduled_scan_at,
+    DROP COLUMN IF EXISTS last_scheduled_scan_at;
@@ -0,0 +1,9 @@
+-- Repository is scanned every time its scan_schedule cron expression is due in scan_timezone. Scheduler of any replica
+-- queues the due scan and moves next_scheduled_scan_at forward in the same transaction, so a run is never queued twice
+ALTER TABLE reposcan.repositories
+    ADD COLUMN scan_schedule varchar,
+    ADD COLUMN scan_timezone varchar,
+    ADD COLUMN next_scheduled_scan_at timestamp,
+    ADD COLUMN last_scheduled_scan_at timestamp;
+CREATE INDEX repositories_next_scheduled_scan_at_idx ON reposcan.repositories USING btree(next_scheduled_scan_at)
+    WHERE scan_schedule IS NOT NULL AND deleted_by IS NULL;
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS reposcan.scannings_queued_unique_idx;
@@ -0,0 +1,33 @@
+-- Scanning triggered while an identical one is still queued and never attempted is coalesced into it,
+-- the partial unique index keeps concurrent triggers from queueing both. Retried scannings are not coalesced
+-- as they wait for their backoff
+WITH duplicates AS (
+    SELECT
+        scanning_id,
+        row_number() OVER (
+            PARTITION BY repository_id, scan_mode, is_full_scan, COALESCE(base_ref, ''), COALESCE(ref, '')
+            ORDER BY queued_at, scanning_id
+        ) AS rank
+    FROM
+        reposcan.scannings
+    WHERE
+        scanning_status = 'queued'::reposcan.scanning_status
+    AND attempts = 0
+    AND deleted_by IS NULL
+)
+UPDATE reposcan.scannings s
+SET
+    scanning_status = 'cancelled'::reposcan.scanning_status,
+    findings = jsonb_build_object('reason', 'Duplicate of an earlier queued scanning'),
+    finished_at = now(),
+    modified_by = 'Automated',
+    modified_at = now()
+FROM
+    duplicates
+WHERE
+    s.scanning_id = duplicates.scanning_id
+AND duplicates.rank > 1;
+
+CREATE UNIQUE INDEX scannings_queued_unique_idx ON reposcan.scannings
+    USING btree(repository_id, scan_mode, is_full_scan, COALESCE(base_ref, ''), COALESCE(ref, ''))
+    WHERE scanning_status = 'queued'::reposcan.scanning_status AND attempts = 0 AND deleted_by IS NULL;